@@ -1,16 +1,18 @@
 package cilium
 
 import (
-	"fmt"
-
 	"github.com/Azure/mcp-kubernetes/pkg/command"
 	"github.com/Azure/mcp-kubernetes/pkg/config"
 	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
 	"github.com/Azure/mcp-kubernetes/pkg/tools"
 )
 
 // CiliumExecutor implements the CommandExecutor interface for cilium commands
-type CiliumExecutor struct{}
+type CiliumExecutor struct {
+	// readOnly rejects commands that modify state
+	readOnly bool
+}
 
 // This line ensures CiliumExecutor implements the CommandExecutor interface
 var _ tools.CommandExecutor = (*CiliumExecutor)(nil)
@@ -20,21 +22,48 @@ func NewExecutor() *CiliumExecutor {
 	return &CiliumExecutor{}
 }
 
+// NewReadOnlyExecutor creates a CiliumExecutor that only runs commands that don't modify state
+func NewReadOnlyExecutor() *CiliumExecutor {
+	return &CiliumExecutor{readOnly: true}
+}
+
 // Execute handles cilium command execution
 func (e *CiliumExecutor) Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
 	ciliumCmd, ok := params["command"].(string)
 	if !ok {
-		return "", fmt.Errorf("invalid command parameter")
+		return "", toolerror.New(toolerror.InvalidArguments, "invalid command parameter")
 	}
 
 	// Validate the command against security settings
-	validator := security.NewValidator(cfg.SecurityConfig)
+	validator := security.NewValidator(cfg.SecurityConfig).ForSession(tools.SessionID(params))
 	err := validator.ValidateCommand(ciliumCmd, security.CommandTypeCilium)
 	if err != nil {
 		return "", err
 	}
+	if e.readOnly && !validator.IsReadOnlyCommand(ciliumCmd, security.CommandTypeCilium) {
+		return "", toolerror.New(toolerror.InvalidArguments, "command modifies state and is not available in cilium, use cilium_write")
+	}
+
+	// Resolve the timeout for this operation
+	requestedTimeout, err := tools.GetTimeoutSeconds(params)
+	if err != nil {
+		return "", err
+	}
+	operation := security.ExtractOperation(ciliumCmd, security.CommandTypeCilium)
+	timeout := cfg.ResolveTimeout(security.CommandTypeCilium, operation, requestedTimeout)
 
 	// Execute the command
-	process := command.NewShellProcess("cilium", cfg.Timeout)
+	process := command.NewShellProcess("cilium", timeout)
+	process.Logger = tools.Logger(params)
+	process.Env = cfg.CommandEnv(security.CommandTypeCilium)
+	process.Dir = cfg.CommandDir(tools.SessionID(params))
+	process.Cassettes = cfg.Cassettes
+	process.Context = tools.Context(params)
+	if validator.IsReadOnlyCommand(ciliumCmd, security.CommandTypeCilium) {
+		// Only read-only commands are safe to retry after transient errors
+		process.Retry = cfg.RetryPolicy()
+	} else if cfg.ClusterChanged != nil {
+		defer cfg.ClusterChanged()
+	}
 	return process.Run(ciliumCmd)
 }
@@ -1,16 +1,44 @@
 package cilium
 
 import (
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // RegisterCilium registers the cilium tool
 func RegisterCilium() mcp.Tool {
-	return mcp.NewTool("cilium",
-		mcp.WithDescription("Run Cilium CNI commands for network policies and observability"),
+	// Connectivity tests deploy workloads and config changes restart the agents
+	return newCiliumTool("cilium",
+		"Run Cilium CNI commands for network policies and observability",
+		"The cilium command to execute (e.g., 'cilium status', 'cilium endpoint list')",
+		tools.Annotations{Destructive: true})
+}
+
+// RegisterCiliumReadOnly registers the read-only variant of the cilium tool, registered together with RegisterCiliumWrite
+func RegisterCiliumReadOnly() mcp.Tool {
+	return newCiliumTool("cilium",
+		"Run read-only Cilium CNI commands for network policies and observability. Use cilium_write for commands that modify the cluster",
+		"The cilium command to execute (e.g., 'cilium status', 'cilium endpoint list')",
+		tools.ReadOnlyAnnotations)
+}
+
+// RegisterCiliumWrite registers the write variant of the cilium tool
+func RegisterCiliumWrite() mcp.Tool {
+	return newCiliumTool(tools.WriteVariantName("cilium"),
+		"Run Cilium CNI commands that modify the cluster, such as connectivity test, config set and hubble enable. Use cilium for read-only commands",
+		"The cilium command to execute (e.g., 'cilium connectivity test', 'cilium hubble enable')",
+		tools.Annotations{Destructive: true})
+}
+
+// newCiliumTool creates a cilium tool definition
+func newCiliumTool(name, description, commandDescription string, annotations tools.Annotations) mcp.Tool {
+	return mcp.NewTool(name,
+		mcp.WithDescription(description),
+		tools.WithAnnotations(annotations),
 		mcp.WithString("command",
 			mcp.Required(),
-			mcp.Description("The cilium command to execute (e.g., 'cilium status', 'cilium endpoint list')"),
+			mcp.Description(commandDescription),
 		),
+		tools.WithTimeoutSeconds(),
 	)
 }
@@ -0,0 +1,283 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Cassette modes
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// cassetteEnvSkip lists host specific variables that are not recorded
+var cassetteEnvSkip = map[string]bool{
+	"PATH": true, "HOME": true, "LANG": true, "LC_ALL": true, "TMPDIR": true, "USER": true,
+	"USERPROFILE": true, "SYSTEMROOT": true, "TEMP": true, "TMP": true, "APPDATA": true, "LOCALAPPDATA": true,
+}
+
+// sensitiveEnvPattern matches variable names whose values are redacted in cassettes
+var sensitiveEnvPattern = regexp.MustCompile(`(?i)token|secret|password|key|credential`)
+
+// unsafeNameChars matches characters not used in cassette file names
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// booleanFlags lists common flags that never take a separate value, so the
+// following argument is positional when matching calls
+var booleanFlags = map[string]bool{
+	"-A": true, "--all-namespaces": true, "--all": true, "--overwrite": true, "--ignore-not-found": true,
+	"--force": true, "--wait": true, "-w": true, "--watch": true, "--show-labels": true, "-R": true,
+	"--recursive": true, "--follow": true, "--previous": true, "--ignore-daemonsets": true,
+	"--delete-emptydir-data": true, "--atomic": true, "--install": true, "--create-namespace": true,
+	"--verbose": true, "--no-headers": true, "-i": true, "-t": true, "--stdin": true, "--tty": true,
+}
+
+// Cassette is a recorded command execution
+type Cassette struct {
+	Argv []string `json:"argv"`
+	// Env holds the recorded environment without host specific variables, secrets are redacted
+	Env      map[string]string `json:"env,omitempty"`
+	Stdin    string            `json:"stdin,omitempty"`
+	Stdout   string            `json:"stdout"`
+	Stderr   string            `json:"stderr"`
+	ExitCode int               `json:"exitCode"`
+	// Error is set when the command could not be started, e.g. the binary is missing
+	Error string `json:"error,omitempty"`
+
+	file string
+}
+
+// CassetteStore records command executions to cassette files or replays them
+type CassetteStore struct {
+	mode string
+	dir  string
+
+	mu   sync.Mutex
+	seq  int
+	byID map[string][]*Cassette
+	used map[string]int
+	all  []*Cassette
+}
+
+// NewCassetteRecorder creates a store that records every execution as a file in dir
+func NewCassetteRecorder(dir string) (*CassetteStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &CassetteStore{mode: CassetteRecord, dir: dir, seq: len(existing)}, nil
+}
+
+// LoadCassettes creates a store that replays the cassette files in dir in file name order
+func LoadCassettes(dir string) (*CassetteStore, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no cassettes found in %s", dir)
+	}
+	sort.Strings(files)
+
+	store := &CassetteStore{
+		mode: CassetteReplay,
+		dir:  dir,
+		byID: make(map[string][]*Cassette),
+		used: make(map[string]int),
+	}
+	for _, file := range files {
+		// #nosec G304: cassette files are read from the directory given by the operator
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		cassette := &Cassette{}
+		if err := json.Unmarshal(data, cassette); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %w", file, err)
+		}
+		if len(cassette.Argv) == 0 {
+			return nil, fmt.Errorf("invalid cassette %s: argv is empty", file)
+		}
+		cassette.file = filepath.Base(file)
+		key := matchKey(cassette.Argv, cassette.Stdin)
+		store.byID[key] = append(store.byID[key], cassette)
+		store.all = append(store.all, cassette)
+	}
+	return store, nil
+}
+
+// Replaying checks if executions are served from cassettes
+func (c *CassetteStore) Replaying() bool {
+	return c.mode == CassetteReplay
+}
+
+// replay returns the recorded result of a command. Identical calls are served by their
+// recordings in order, the last recording is reused once all of them were served.
+func (c *CassetteStore) replay(argv []string, stdin string) (string, string, error) {
+	key := matchKey(argv, stdin)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	recordings := c.byID[key]
+	if len(recordings) == 0 {
+		return "", "", c.unmatchedError(argv)
+	}
+	index := c.used[key]
+	if index >= len(recordings) {
+		index = len(recordings) - 1
+	}
+	c.used[key] = index + 1
+
+	cassette := recordings[index]
+	switch {
+	case cassette.Error != "":
+		return cassette.Stdout, cassette.Stderr, errors.New(cassette.Error)
+	case cassette.ExitCode != 0:
+		return cassette.Stdout, cassette.Stderr, fmt.Errorf("exit status %d", cassette.ExitCode)
+	}
+	return cassette.Stdout, cassette.Stderr, nil
+}
+
+// unmatchedError reports a call without recording and the closest recorded call
+func (c *CassetteStore) unmatchedError(argv []string) error {
+	units := matchUnits(argv)
+	var closest *Cassette
+	best := -1
+	for _, cassette := range c.all {
+		distance := editDistance(units, matchUnits(cassette.Argv))
+		if best < 0 || distance < best {
+			closest, best = cassette, distance
+		}
+	}
+	return fmt.Errorf("replay: no recorded call matches %q in %s; closest recorded call is %q (%s)",
+		strings.Join(argv, " "), c.dir, strings.Join(closest.Argv, " "), closest.file)
+}
+
+// record writes the result of a command to a new cassette file
+func (c *CassetteStore) record(argv, env []string, stdin, stdout, stderr string, runErr error) error {
+	cassette := Cassette{
+		Argv:   argv,
+		Env:    cassetteEnv(env),
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(runErr, &exitErr):
+		cassette.ExitCode = exitErr.ExitCode()
+	case runErr != nil:
+		cassette.ExitCode = -1
+		cassette.Error = runErr.Error()
+	}
+
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.seq++
+	name := fmt.Sprintf("%04d-%s.json", c.seq, cassetteName(argv))
+	c.mu.Unlock()
+
+	return os.WriteFile(filepath.Join(c.dir, name), data, 0o600)
+}
+
+// cassetteName builds a readable file name from the command and its first positional arguments
+func cassetteName(argv []string) string {
+	parts := []string{filepath.Base(argv[0])}
+	for _, arg := range argv[1:] {
+		if len(parts) == 3 {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			parts = append(parts, arg)
+		}
+	}
+	name := strings.Join(parts, "-")
+	return strings.Trim(unsafeNameChars.ReplaceAllString(name, "_"), "_")
+}
+
+// cassetteEnv returns the recorded subset of an environment
+func cassetteEnv(env []string) map[string]string {
+	recorded := make(map[string]string)
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		if cassetteEnvSkip[name] || strings.HasPrefix(name, "LC_") {
+			continue
+		}
+		if sensitiveEnvPattern.MatchString(name) {
+			value = "<redacted>"
+		}
+		recorded[name] = value
+	}
+	if len(recorded) == 0 {
+		return nil
+	}
+	return recorded
+}
+
+// matchUnits splits argv into the command, positional arguments in order and
+// flags with their values, sorted so that flag order doesn't matter
+func matchUnits(argv []string) []string {
+	units := []string{filepath.Base(argv[0])}
+	var flags []string
+	for i := 1; i < len(argv); i++ {
+		arg := argv[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			units = append(units, arg)
+			continue
+		}
+		if arg == "--" {
+			units = append(units, argv[i:]...)
+			break
+		}
+		if !strings.Contains(arg, "=") && !booleanFlags[arg] && i+1 < len(argv) && !strings.HasPrefix(argv[i+1], "-") {
+			arg += "=" + argv[i+1]
+			i++
+		}
+		flags = append(flags, arg)
+	}
+	sort.Strings(flags)
+	return append(units, flags...)
+}
+
+// matchKey is the key identifying equivalent calls
+func matchKey(argv []string, stdin string) string {
+	return strings.Join(matchUnits(argv), "\x00") + "\x00stdin=" + stdin
+}
+
+// editDistance returns the number of unit insertions, deletions and substitutions between two calls
+func editDistance(a, b []string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewCassetteRecorder(dir)
+	if err != nil {
+		t.Fatalf("NewCassetteRecorder() unexpected error = %v", err)
+	}
+
+	sp := NewShellProcess("echo", 5)
+	sp.Env = []string{"PATH=" + os.Getenv("PATH"), "KUBECONFIG=/etc/kubeconfig", "API_TOKEN=abc"}
+	sp.Cassettes = recorder
+	recorded, err := sp.Run("get pods -n prod --output wide")
+	if err != nil {
+		t.Fatalf("Run() unexpected error = %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 || filepath.Base(files[0]) != "0001-echo-get-pods.json" {
+		t.Fatalf("cassette files = %v, want 0001-echo-get-pods.json", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		t.Fatalf("invalid cassette: %v", err)
+	}
+	wantEnv := map[string]string{"KUBECONFIG": "/etc/kubeconfig", "API_TOKEN": "<redacted>"}
+	if !reflect.DeepEqual(cassette.Env, wantEnv) {
+		t.Errorf("cassette env = %v, want %v", cassette.Env, wantEnv)
+	}
+
+	store, err := LoadCassettes(dir)
+	if err != nil {
+		t.Fatalf("LoadCassettes() unexpected error = %v", err)
+	}
+	if !store.Replaying() {
+		t.Error("Expected loaded cassettes to replay")
+	}
+
+	// Replay never runs the binary and tolerates reordered flags
+	replay := NewShellProcess("nonexistent-binary", 5)
+	replay.Cassettes = store
+	output, err := replay.Exec("echo get pods --output wide -n prod")
+	if err != nil {
+		t.Fatalf("Exec() unexpected error = %v", err)
+	}
+	if output != recorded {
+		t.Errorf("replayed output = %q, want %q", output, recorded)
+	}
+
+	_, err = replay.Exec("echo get pods -n staging --output wide")
+	if err == nil {
+		t.Fatal("Expected unmatched call to fail")
+	}
+	if !strings.Contains(err.Error(), "closest recorded call is \"echo get pods -n prod --output wide\"") {
+		t.Errorf("unmatched error = %v, want closest candidate", err)
+	}
+}
+
+func TestCassetteReplayInOrder(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewCassetteRecorder(dir)
+	if err != nil {
+		t.Fatalf("NewCassetteRecorder() unexpected error = %v", err)
+	}
+
+	// Identical calls with different results, the second one failing; the
+	// script and the working directory of the commands live in a scratch
+	// directory so nothing is written into the package directory
+	scratch := t.TempDir()
+	script := filepath.Join(scratch, "state.sh")
+	for _, content := range []string{"echo first", "echo second >&2; exit 3"} {
+		if err := os.WriteFile(script, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		sp := NewShellProcess("sh", 5)
+		sp.Dir = scratch
+		sp.Cassettes = recorder
+		if _, err := sp.Exec("sh " + script); err != nil {
+			t.Fatalf("Exec() unexpected error = %v", err)
+		}
+	}
+
+	store, err := LoadCassettes(dir)
+	if err != nil {
+		t.Fatalf("LoadCassettes() unexpected error = %v", err)
+	}
+
+	want := []string{"first\n", "second\n", "second\n"}
+	for i, w := range want {
+		sp := NewShellProcess("sh", 5)
+		sp.Dir = scratch
+		sp.Cassettes = store
+		output, err := sp.Exec("sh " + script)
+		if err != nil {
+			t.Fatalf("Exec() #%d unexpected error = %v", i, err)
+		}
+		if output != w {
+			t.Errorf("Exec() #%d = %q, want %q", i, output, w)
+		}
+	}
+
+	sp := NewShellProcess("sh", 5)
+	sp.Dir = scratch
+	sp.Cassettes = store
+	sp.ReturnErrOutput = false
+	if _, err := sp.Exec("sh " + script); err == nil || err.Error() != "exit status 3" {
+		t.Errorf("Exec() error = %v, want recorded exit status", err)
+	}
+}
+
+func TestMatchUnits(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		match bool
+	}{
+		{"reordered flags", "kubectl get pods -n prod -o wide", "kubectl get pods -o wide -n prod", true},
+		{"flags before positional", "kubectl -n prod get pods", "kubectl get pods -n prod", true},
+		{"boolean flag before positional", "kubectl label --overwrite pods web a=b", "kubectl label pods web a=b --overwrite", true},
+		{"different value", "kubectl get pods -n prod", "kubectl get pods -n dev", false},
+		{"different positional order", "kubectl cp a b", "kubectl cp b a", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := matchKey(strings.Fields(tt.a), "")
+			b := matchKey(strings.Fields(tt.b), "")
+			if (a == b) != tt.match {
+				t.Errorf("matchKey(%q) == matchKey(%q) is %v, want %v", tt.a, tt.b, a == b, tt.match)
+			}
+		})
+	}
+}
+
+func TestLoadCassettesEmptyDir(t *testing.T) {
+	if _, err := LoadCassettes(t.TempDir()); err == nil {
+		t.Error("Expected error for a directory without cassettes")
+	}
+}
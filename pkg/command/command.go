@@ -3,10 +3,15 @@ package command
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
 	"github.com/google/shlex"
 )
 
@@ -16,8 +21,42 @@ type ShellProcess struct {
 	StripNewlines   bool
 	ReturnErrOutput bool
 	Timeout         int // in seconds
+	// Env is the explicit environment for the command; nil inherits the server environment
+	Env []string
+	// Stdin is piped to the command; nil reads from the null device
+	Stdin io.Reader
+	// Dir is the working directory of the command; empty uses the server's working directory
+	Dir string
+	// Retry is the policy for retrying transient failures; nil disables retries
+	Retry *RetryPolicy
+	// Retries is the number of retries performed by the last execution
+	Retries int
+	// Cassettes records every execution, or replays recorded executions instead of running commands
+	Cassettes *CassetteStore
+	// Logger logs the start and end of executions, retries and warnings; nil disables logging
+	Logger *slog.Logger
+	// Context stops the command when it is cancelled; nil never stops it before its timeout
+	Context context.Context
 }
 
+// TimeoutError is returned when a command does not finish within its timeout
+type TimeoutError struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("command timed out after %s: %s", e.Timeout, e.Command)
+}
+
+// ErrorCode reports the error as a timeout
+func (e *TimeoutError) ErrorCode() toolerror.Code {
+	return toolerror.Timeout
+}
+
+// ErrCancelled is returned when the context of a command is cancelled before it finishes
+var ErrCancelled = errors.New("command was cancelled")
+
 // NewShellProcess creates a new ShellProcess
 func NewShellProcess(command string, timeout int) *ShellProcess {
 	return &ShellProcess{
@@ -42,56 +81,141 @@ func (s *ShellProcess) Run(args string) (string, error) {
 
 // Exec runs the commands and returns the output
 func (s *ShellProcess) Exec(commands string) (string, error) {
-	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout)*time.Second)
+	// Create a context with timeout shared by all attempts
+	parent := s.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, time.Duration(s.Timeout)*time.Second)
 	defer cancel()
 
-	var cmd *exec.Cmd
-
 	// Parse the command string with proper handling of quotes
 	parts, err := shlex.Split(commands)
 	if err != nil {
 		return "", err
 	}
 
-	if len(parts) > 1 {
-		// Command with arguments
-		// #nosec G204: Subprocess launched with a potential tainted input or cmd arguments
-		cmd = exec.CommandContext(ctx, parts[0], parts[1:]...)
-	} else if len(parts) == 1 {
-		// Single command without arguments
-		// #nosec G204: Subprocess launched with a potential tainted input or cmd arguments
-		cmd = exec.CommandContext(ctx, parts[0])
-	} else {
+	if len(parts) == 0 {
 		// Empty command
 		return "", nil
 	}
 
+	logger := s.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	logger.Debug("command started", "command", commands)
+	start := time.Now()
+
+	s.Retries = 0
+	for {
+		stdout, stderr, err := s.execOnce(ctx, parts)
+		logWarnings(logger, commands, stderr)
+
+		// Check for timeout
+		if ctx.Err() == context.DeadlineExceeded {
+			logger.Warn("command timed out", "command", commands, "timeout", time.Duration(s.Timeout)*time.Second)
+			return "", &TimeoutError{Command: commands, Timeout: time.Duration(s.Timeout) * time.Second}
+		}
+		if ctx.Err() == context.Canceled {
+			logger.Info("command cancelled", "command", commands)
+			return "", fmt.Errorf("%w: %s", ErrCancelled, commands)
+		}
+
+		// Retry transient failures while the policy and the deadline allow it.
+		// Commands reading stdin are never retried since their input is consumed.
+		if err != nil && s.Retry != nil && s.Stdin == nil && s.Retries < s.Retry.MaxRetries &&
+			IsTransientError(stderr) && s.Retry.wait(ctx, s.Retries+1) {
+			s.Retries++
+			logger.Warn("retrying command after transient error", "command", commands, "retry", s.Retries, "error", firstLine(stderr))
+			continue
+		}
+		logger.Debug("command finished", "command", commands, "duration", time.Since(start).Round(time.Millisecond),
+			"retries", s.Retries, "failed", err != nil)
+
+		// Handle errors
+		if err != nil {
+			if s.ReturnErrOutput && stderr != "" {
+				return s.withRetryNote(stderr), nil
+			}
+			return "", err
+		}
+
+		// Process output
+		output := stdout
+		if s.StripNewlines {
+			output = strings.TrimSpace(output)
+		}
+
+		return s.withRetryNote(output), nil
+	}
+}
+
+// execOnce runs a single attempt of the command and returns its stdout and stderr
+func (s *ShellProcess) execOnce(ctx context.Context, parts []string) (string, string, error) {
+	// Cassettes need the input to match and record calls
+	stdin := s.Stdin
+	var stdinData []byte
+	if s.Cassettes != nil && s.Stdin != nil {
+		data, err := io.ReadAll(s.Stdin)
+		if err != nil {
+			return "", "", err
+		}
+		stdinData = data
+		stdin = bytes.NewReader(data)
+	}
+	if s.Cassettes != nil && s.Cassettes.Replaying() {
+		return s.Cassettes.replay(parts, string(stdinData))
+	}
+
+	// #nosec G204: Subprocess launched with a potential tainted input or cmd arguments
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
+
+	// Without an explicit Stdin commands read from the null device and can never prompt
+	cmd.Stdin = stdin
+	cmd.Dir = s.Dir
+	if s.Env != nil {
+		cmd.Env = s.Env
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	// Execute the command
-	err = cmd.Run()
+	err := cmd.Run()
 
-	// Check for timeout
-	if ctx.Err() == context.DeadlineExceeded {
-		return "", ctx.Err()
+	// Timed out executions are not recorded since replaying them can't reproduce the timeout
+	if s.Cassettes != nil && ctx.Err() == nil {
+		if recordErr := s.Cassettes.record(parts, s.Env, string(stdinData), stdout.String(), stderr.String(), err); recordErr != nil {
+			return stdout.String(), stderr.String(), fmt.Errorf("failed to record cassette: %w", recordErr)
+		}
 	}
+	return stdout.String(), stderr.String(), err
+}
 
-	// Handle errors
-	if err != nil {
-		if s.ReturnErrOutput && stderr.Len() > 0 {
-			return stderr.String(), nil
+// logWarnings logs the warnings of a command, like the deprecated API warnings of kubectl
+func logWarnings(logger *slog.Logger, commands, stderr string) {
+	for _, line := range strings.Split(stderr, "\n") {
+		if warning, ok := strings.CutPrefix(strings.TrimSpace(line), "Warning:"); ok {
+			logger.Warn("command warning", "command", commands, "warning", strings.TrimSpace(warning))
 		}
-		return "", err
 	}
+}
 
-	// Process output
-	output := stdout.String()
-	if s.StripNewlines {
-		output = strings.TrimSpace(output)
-	}
+// firstLine returns the first line of a text
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
 
-	return output, nil
+// withRetryNote appends the number of retries to the output when the command was retried
+func (s *ShellProcess) withRetryNote(output string) string {
+	if s.Retries == 0 {
+		return output
+	}
+	if output != "" && !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+	return output + fmt.Sprintf("(retried %d time(s) after transient errors)\n", s.Retries)
 }
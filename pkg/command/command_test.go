@@ -1,8 +1,13 @@
 package command
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestExecBasicCommand(t *testing.T) {
@@ -151,6 +156,29 @@ func TestTimeout(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected timeout error, got none")
 	}
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Errorf("Expected *TimeoutError, got: %T", err)
+	} else if timeoutErr.Timeout != time.Second {
+		t.Errorf("Expected timeout of 1s, got: %s", timeoutErr.Timeout)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sp := NewShellProcess("sleep", 10)
+	sp.Context = ctx
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := sp.Exec("sleep 5")
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("Expected ErrCancelled, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Cancelled command ran for %s", elapsed)
+	}
 }
 
 func TestReturnErrOutput(t *testing.T) {
@@ -172,3 +200,31 @@ func TestReturnErrOutput(t *testing.T) {
 		t.Errorf("Expected error when ReturnErrOutput=false, got none")
 	}
 }
+
+func TestLogger(t *testing.T) {
+	var logs bytes.Buffer
+	sp := NewShellProcess("sh", 5)
+	sp.Logger = slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	sp.Retry = &RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	script := flakyCommand(t, 1, "connection refused")
+	script = strings.Replace(script, "echo ok", "echo 'Warning: v1beta1 CronJob is deprecated' >&2; echo ok", 1)
+	if _, err := sp.Exec(script); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	text := logs.String()
+	for _, want := range []string{
+		`level=DEBUG msg="command started"`,
+		`level=WARN msg="retrying command after transient error"`,
+		`retry=1 error="connection refused"`,
+		`level=WARN msg="command warning"`,
+		`warning="v1beta1 CronJob is deprecated"`,
+		`level=DEBUG msg="command finished"`,
+		`retries=1 failed=false`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in logs, got:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// baseEnvAllowlist lists the variables passed through from the server environment to every command
+var baseEnvAllowlist = []string{
+	"PATH", "HOME", "KUBECONFIG", "LANG", "LC_ALL", "TMPDIR", "USER",
+	// Required for binaries to work on Windows
+	"USERPROFILE", "SYSTEMROOT", "TEMP", "TMP", "APPDATA", "LOCALAPPDATA",
+}
+
+// commonEnv is set for every command to disable color output and interactive prompts
+var commonEnv = map[string]string{
+	"TERM":     "dumb",
+	"NO_COLOR": "1",
+}
+
+// commandEnv holds the variables set deliberately per command type
+var commandEnv = map[string]map[string]string{
+	"kubectl": {
+		// Use a plain unified diff so kubectl diff never picks up a user's external diff tool
+		"KUBECTL_EXTERNAL_DIFF": "diff -u -N",
+	},
+}
+
+// EnvOptions configures the environment built for a command
+type EnvOptions struct {
+	// Kubeconfig pins KUBECONFIG to this file when set
+	Kubeconfig string
+	// Extra lists additional variables, either NAME to pass through or NAME=VALUE to set
+	Extra []string
+}
+
+// BuildEnv builds an explicit environment for the given command type from the
+// allowlist, the per-command settings and the configured additions
+func BuildEnv(commandType string, opts EnvOptions) []string {
+	env := make(map[string]string)
+
+	// Pass through allowlisted variables from the server environment
+	for _, name := range baseEnvAllowlist {
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = value
+		}
+	}
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "LC_") {
+			if name, value, found := strings.Cut(kv, "="); found {
+				env[name] = value
+			}
+		}
+	}
+
+	for name, value := range commonEnv {
+		env[name] = value
+	}
+	for name, value := range commandEnv[commandType] {
+		env[name] = value
+	}
+
+	// Apply configured additions
+	for _, extra := range opts.Extra {
+		name, value, found := strings.Cut(extra, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if found {
+			env[name] = value
+		} else if value, ok := os.LookupEnv(name); ok {
+			env[name] = value
+		}
+	}
+
+	if opts.Kubeconfig != "" {
+		env["KUBECONFIG"] = opts.Kubeconfig
+	}
+
+	result := make([]string, 0, len(env))
+	for name, value := range env {
+		result = append(result, name+"="+value)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,95 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func envMap(env []string) map[string]string {
+	result := make(map[string]string)
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		result[name] = value
+	}
+	return result
+}
+
+func TestBuildEnvAllowlist(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin:/bin")
+	t.Setenv("LC_TIME", "C")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("HELM_NAMESPACE", "other")
+
+	env := envMap(BuildEnv("helm", EnvOptions{}))
+
+	if env["PATH"] != "/usr/bin:/bin" {
+		t.Errorf("Expected PATH to be passed through, got %q", env["PATH"])
+	}
+	if env["LC_TIME"] != "C" {
+		t.Errorf("Expected LC_TIME to be passed through, got %q", env["LC_TIME"])
+	}
+	if _, ok := env["AWS_SECRET_ACCESS_KEY"]; ok {
+		t.Error("Expected AWS_SECRET_ACCESS_KEY to be dropped")
+	}
+	if _, ok := env["HELM_NAMESPACE"]; ok {
+		t.Error("Expected HELM_NAMESPACE to be dropped")
+	}
+	if env["NO_COLOR"] != "1" || env["TERM"] != "dumb" {
+		t.Errorf("Expected color output to be disabled, got NO_COLOR=%q TERM=%q", env["NO_COLOR"], env["TERM"])
+	}
+	if _, ok := env["KUBECTL_EXTERNAL_DIFF"]; ok {
+		t.Error("Expected KUBECTL_EXTERNAL_DIFF to be set only for kubectl")
+	}
+}
+
+func TestBuildEnvKubectl(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/home/user/.kube/config")
+	t.Setenv("KUBECTL_EXTERNAL_DIFF", "meld")
+
+	env := envMap(BuildEnv("kubectl", EnvOptions{Kubeconfig: "/etc/mcp/kubeconfig"}))
+
+	if env["KUBECONFIG"] != "/etc/mcp/kubeconfig" {
+		t.Errorf("Expected KUBECONFIG to be pinned, got %q", env["KUBECONFIG"])
+	}
+	if env["KUBECTL_EXTERNAL_DIFF"] != "diff -u -N" {
+		t.Errorf("Expected KUBECTL_EXTERNAL_DIFF to be set deliberately, got %q", env["KUBECTL_EXTERNAL_DIFF"])
+	}
+}
+
+func TestBuildEnvExtra(t *testing.T) {
+	t.Setenv("AZURE_CONFIG_DIR", "/home/user/.azure")
+	t.Setenv("HTTPS_PROXY", "http://proxy:3128")
+
+	env := envMap(BuildEnv("kubectl", EnvOptions{Extra: []string{"AZURE_CONFIG_DIR", "FOO=bar", "UNSET_VAR"}}))
+
+	if env["AZURE_CONFIG_DIR"] != "/home/user/.azure" {
+		t.Errorf("Expected AZURE_CONFIG_DIR to be passed through, got %q", env["AZURE_CONFIG_DIR"])
+	}
+	if env["FOO"] != "bar" {
+		t.Errorf("Expected FOO=bar, got %q", env["FOO"])
+	}
+	if _, ok := env["UNSET_VAR"]; ok {
+		t.Error("Expected unset pass-through variable to be omitted")
+	}
+	if _, ok := env["HTTPS_PROXY"]; ok {
+		t.Error("Expected HTTPS_PROXY to be dropped unless configured")
+	}
+}
+
+func TestExecWithEnv(t *testing.T) {
+	t.Setenv("MCP_TEST_SECRET", "secret")
+
+	sp := NewShellProcess("env", 5)
+	sp.Env = BuildEnv("kubectl", EnvOptions{})
+	output, err := sp.Exec("env")
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if strings.Contains(output, "MCP_TEST_SECRET") {
+		t.Errorf("Expected MCP_TEST_SECRET to be absent from the command environment")
+	}
+	if !strings.Contains(output, "NO_COLOR=1") {
+		t.Errorf("Expected NO_COLOR=1 in the command environment, got: %q", output)
+	}
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// transientErrorPatterns lists lowercase stderr fragments of transient API server failures
+var transientErrorPatterns = []string{
+	"connection refused",
+	"connection reset by peer",
+	"etcdserver: request timed out",
+	"etcdserver: leader changed",
+	"tls handshake timeout",
+	"i/o timeout",
+	"http2: client connection lost",
+	"unexpected eof",
+	"the server is currently unable to handle the request",
+	"the server has received too many requests",
+}
+
+// RetryPolicy defines how failed commands with transient errors are retried
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt
+	MaxRetries int
+	// BaseDelay is the delay before the first retry, doubled for every further retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries
+	MaxDelay time.Duration
+}
+
+// NewRetryPolicy creates a retry policy with the default backoff delays
+func NewRetryPolicy(maxRetries int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: maxRetries,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   8 * time.Second,
+	}
+}
+
+// IsTransientError checks if the stderr of a failed command indicates a transient failure
+func IsTransientError(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+	for _, pattern := range transientErrorPatterns {
+		if strings.Contains(stderr, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the jittered exponential backoff delay before the given retry (starting at 1)
+func (p *RetryPolicy) delay(retry int) time.Duration {
+	backoff := p.BaseDelay << (retry - 1)
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	// Full jitter between half and the whole backoff
+	// #nosec G404: jitter does not need a cryptographically secure random source
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// wait sleeps for the backoff delay before the given retry and reports whether the
+// retry fits within the context deadline
+func (p *RetryPolicy) wait(ctx context.Context, retry int) bool {
+	delay := p.delay(retry)
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
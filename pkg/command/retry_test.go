@@ -0,0 +1,112 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyCommand returns a shell command that fails with the given stderr until it has run failures+1 times
+func flakyCommand(t *testing.T, failures int, stderr string) string {
+	counter := filepath.Join(t.TempDir(), "count")
+	script := fmt.Sprintf(`n=$(cat %[1]s 2>/dev/null || echo 0); n=$((n+1)); echo $n > %[1]s; `+
+		`if [ $n -le %[2]d ]; then echo '%[3]s' >&2; exit 1; fi; echo ok`, counter, failures, stderr)
+	return fmt.Sprintf("sh -c %q", script)
+}
+
+func fastRetryPolicy(maxRetries int) *RetryPolicy {
+	return &RetryPolicy{MaxRetries: maxRetries, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+func TestRetryTransientError(t *testing.T) {
+	sp := NewShellProcess("sh", 5)
+	sp.Retry = fastRetryPolicy(3)
+	output, err := sp.Exec(flakyCommand(t, 2, "dial tcp 10.0.0.1:443: connect: connection refused"))
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if sp.Retries != 2 {
+		t.Errorf("Expected 2 retries, got %d", sp.Retries)
+	}
+	if !strings.HasPrefix(output, "ok\n") {
+		t.Errorf("Expected command output, got: %q", output)
+	}
+	if !strings.Contains(output, "retried 2 time(s)") {
+		t.Errorf("Expected retry count in output, got: %q", output)
+	}
+}
+
+func TestRetryGivesUpAfterMaxRetries(t *testing.T) {
+	sp := NewShellProcess("sh", 5)
+	sp.Retry = fastRetryPolicy(1)
+	output, err := sp.Exec(flakyCommand(t, 5, "etcdserver: request timed out"))
+
+	if err != nil {
+		t.Fatalf("Expected no error with ReturnErrOutput, got: %v", err)
+	}
+	if sp.Retries != 1 {
+		t.Errorf("Expected 1 retry, got %d", sp.Retries)
+	}
+	if !strings.Contains(output, "etcdserver: request timed out") {
+		t.Errorf("Expected error output, got: %q", output)
+	}
+}
+
+func TestNoRetryForPermanentError(t *testing.T) {
+	sp := NewShellProcess("sh", 5)
+	sp.Retry = fastRetryPolicy(3)
+	output, _ := sp.Exec(flakyCommand(t, 1, "Error from server (NotFound): pods \"x\" not found"))
+
+	if sp.Retries != 0 {
+		t.Errorf("Expected no retries, got %d", sp.Retries)
+	}
+	if strings.Contains(output, "retried") {
+		t.Errorf("Expected no retry note, got: %q", output)
+	}
+}
+
+func TestNoRetryWithoutPolicy(t *testing.T) {
+	sp := NewShellProcess("sh", 5)
+	_, _ = sp.Exec(flakyCommand(t, 1, "connection refused"))
+
+	if sp.Retries != 0 {
+		t.Errorf("Expected no retries without a retry policy, got %d", sp.Retries)
+	}
+}
+
+func TestRetryWithinTimeout(t *testing.T) {
+	sp := NewShellProcess("sh", 1)
+	sp.Retry = &RetryPolicy{MaxRetries: 10, BaseDelay: 2 * time.Second, MaxDelay: 2 * time.Second}
+
+	start := time.Now()
+	_, _ = sp.Exec(flakyCommand(t, 10, "TLS handshake timeout"))
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected retries to stay within the timeout, took %s", elapsed)
+	}
+	if sp.Retries != 0 {
+		t.Errorf("Expected no retries when the backoff exceeds the deadline, got %d", sp.Retries)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   bool
+	}{
+		{"The connection to the server 10.0.0.1:443 was refused - did you specify the right host or port? connection refused", true},
+		{"Error from server: etcdserver: request timed out", true},
+		{"Unable to connect to the server: net/http: TLS handshake timeout", true},
+		{"Error from server (Forbidden): pods is forbidden", false},
+		{"error: the server doesn't have a resource type \"foo\"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsTransientError(tt.stderr); got != tt.want {
+			t.Errorf("IsTransientError(%q) = %v, want %v", tt.stderr, got, tt.want)
+		}
+	}
+}
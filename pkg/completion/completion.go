@@ -0,0 +1,353 @@
+// Package completion completes the arguments of prompts and resource templates with namespaces, resource
+// kinds, object names and contexts of the cluster.
+package completion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/resources"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Reference types of completion requests
+const (
+	RefPrompt   = "ref/prompt"
+	RefResource = "ref/resource"
+)
+
+// MaxValues is the maximum number of values of a completion result
+const MaxValues = 100
+
+// Cache durations of completion values
+const (
+	// ObjectsTTL applies to namespaces and object names, contexts are read from the kubeconfig every time
+	ObjectsTTL = 30 * time.Second
+	// KindsTTL applies to the resource kinds of api-resources, which rarely change
+	KindsTTL = 5 * time.Minute
+)
+
+// maxCacheEntries caps the number of cached value lists, whose keys come from client input
+const maxCacheEntries = 500
+
+// kindArguments maps prompt arguments naming an object to the kind of the object
+var kindArguments = map[string]string{
+	"pod":         "pods",
+	"service":     "services",
+	"deployment":  "deployments",
+	"statefulset": "statefulsets",
+	"daemonset":   "daemonsets",
+	"job":         "jobs",
+	"cronjob":     "cronjobs",
+	"configmap":   "configmaps",
+	"secret":      "secrets",
+	"ingress":     "ingresses",
+	"node":        "nodes",
+	"pvc":         "persistentvolumeclaims",
+}
+
+// commonKinds are completed when the backend can't list the API resources
+var commonKinds = []string{
+	"configmaps", "cronjobs.batch", "daemonsets.apps", "deployments.apps", "endpointslices.discovery.k8s.io",
+	"events", "horizontalpodautoscalers.autoscaling", "ingresses.networking.k8s.io", "jobs.batch", "namespaces",
+	"networkpolicies.networking.k8s.io", "nodes", "persistentvolumeclaims", "persistentvolumes", "pods",
+	"replicasets.apps", "secrets", "serviceaccounts", "services", "statefulsets.apps", "storageclasses.storage.k8s.io",
+}
+
+// Request is a completion/complete request
+type Request struct {
+	Ref struct {
+		Type string `json:"type"`
+		// Name of a prompt
+		Name string `json:"name,omitempty"`
+		// URI or URI template of a resource
+		URI string `json:"uri,omitempty"`
+	} `json:"ref"`
+	Argument struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"argument"`
+	// Context holds the arguments that were already resolved
+	Context struct {
+		Arguments map[string]string `json:"arguments,omitempty"`
+	} `json:"context"`
+}
+
+// Completer lists the values of arguments with kubectl tool calls, so completions are subject to the same
+// access level, namespace policy and backend as tool calls
+type Completer struct {
+	executor tools.CommandExecutor
+	cfg      *config.ConfigData
+	now      func() time.Time
+	// contexts lists the contexts of the kubeconfig
+	contexts func() ([]string, error)
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// cacheEntry holds listed values until they expire
+type cacheEntry struct {
+	values  []string
+	expires time.Time
+}
+
+// NewCompleter creates a completer running kubectl commands with an executor
+func NewCompleter(executor tools.CommandExecutor, cfg *config.ConfigData) *Completer {
+	c := &Completer{
+		executor: executor,
+		cfg:      cfg,
+		now:      time.Now,
+		cache:    make(map[string]cacheEntry),
+	}
+	c.contexts = c.kubeconfigContexts
+	return c
+}
+
+// Complete returns the values of the requested argument starting with its current value. The events of the
+// commands listing the values are logged for the session of the request.
+func (c *Completer) Complete(sessionID string, req Request) (*mcp.CompleteResult, error) {
+	values, err := c.values(sessionID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, value := range values {
+		if strings.HasPrefix(value, req.Argument.Value) {
+			matches = append(matches, value)
+		}
+	}
+	sort.Strings(matches)
+
+	result := &mcp.CompleteResult{}
+	result.Completion.Values = matches
+	if result.Completion.Values == nil {
+		result.Completion.Values = []string{}
+	}
+	if len(matches) > MaxValues {
+		result.Completion.Values = matches[:MaxValues]
+		result.Completion.Total = len(matches)
+		result.Completion.HasMore = true
+	}
+	return result, nil
+}
+
+// values returns all values of the requested argument, nil if it can't be completed
+func (c *Completer) values(sessionID string, req Request) ([]string, error) {
+	args := req.Context.Arguments
+	context := args["context"]
+	if req.Ref.Type == RefResource {
+		// Resource URIs use placeholders for the current context and cluster scope
+		context = placeholderValue(context)
+	}
+	// The context is passed to kubectl, so only contexts of the kubeconfig are used
+	if context != "" && req.Argument.Name != "context" {
+		known, err := c.knownContext(context)
+		if err != nil || !known {
+			return nil, err
+		}
+	}
+
+	switch req.Ref.Type {
+	case RefResource:
+		if req.Ref.URI != resources.ObjectTemplate && req.Ref.URI != resources.ListTemplate {
+			return nil, nil
+		}
+		switch req.Argument.Name {
+		case "context":
+			contexts, err := c.contexts()
+			return append(contexts, resources.Placeholder), err
+		case "namespace":
+			namespaces, err := c.namespaces(sessionID, context)
+			return append(namespaces, resources.Placeholder), err
+		case "kind":
+			return c.kinds(sessionID, context)
+		case "name":
+			if args["kind"] == "" {
+				return nil, nil
+			}
+			return c.names(sessionID, context, placeholderValue(args["namespace"]), args["kind"])
+		case "format":
+			return []string{resources.FormatYAML, resources.FormatJSON}, nil
+		}
+	case RefPrompt:
+		switch req.Argument.Name {
+		case "context":
+			return c.contexts()
+		case "namespace":
+			return c.namespaces(sessionID, context)
+		case "kind":
+			return c.kinds(sessionID, context)
+		case "name":
+			if args["kind"] == "" {
+				return nil, nil
+			}
+			return c.names(sessionID, context, args["namespace"], args["kind"])
+		}
+		if kind, ok := kindArguments[req.Argument.Name]; ok {
+			return c.names(sessionID, context, args["namespace"], kind)
+		}
+	}
+	return nil, nil
+}
+
+// namespaces lists the namespaces allowed by the security policy
+func (c *Completer) namespaces(sessionID, context string) ([]string, error) {
+	names, err := c.names(sessionID, context, "", "namespaces")
+	if err != nil || c.cfg.SecurityConfig == nil {
+		return names, err
+	}
+	var allowed []string
+	for _, name := range names {
+		if c.cfg.SecurityConfig.IsNamespaceAllowed(name) {
+			allowed = append(allowed, name)
+		}
+	}
+	return allowed, nil
+}
+
+// names lists the names of the objects of a kind, namespaced kinds use the default namespace if none is given
+func (c *Completer) names(sessionID, context, namespace, kind string) ([]string, error) {
+	params := map[string]interface{}{
+		"_tool_name": "kubectl_resources",
+		"operation":  "get",
+		"resource":   kind,
+		"args":       "-o name",
+	}
+	if context != "" {
+		params["context"] = context
+	}
+	if namespace != "" {
+		params["namespace"] = namespace
+	}
+	return c.cached(sessionID, ObjectsTTL, params, func(line string) (string, bool) {
+		// Objects are printed as kind.group/name
+		_, name, ok := strings.Cut(line, "/")
+		return name, ok && name != ""
+	})
+}
+
+// kinds lists the resource types of the API server, or common kinds if the backend can't list them
+func (c *Completer) kinds(sessionID, context string) ([]string, error) {
+	params := map[string]interface{}{
+		"_tool_name": "kubectl_cluster",
+		"operation":  "api-resources",
+		"resource":   "",
+		"args":       "-o name",
+	}
+	if context != "" {
+		params["context"] = context
+	}
+	kinds, err := c.cached(sessionID, KindsTTL, params, func(line string) (string, bool) {
+		return line, !strings.ContainsAny(line, " :")
+	})
+	if err != nil || len(kinds) == 0 {
+		logging.Logger().Debug("completing common resource kinds, listing API resources failed", "error", err)
+		return commonKinds, nil
+	}
+	return kinds, nil
+}
+
+// cached runs a kubectl tool call for a session and returns the values parsed from its output lines, results
+// are cached for a duration and shared by all sessions. parse returns false for lines without a value, like
+// errors and warnings.
+func (c *Completer) cached(sessionID string, ttl time.Duration, params map[string]interface{}, parse func(line string) (string, bool)) ([]string, error) {
+	key := fmt.Sprintf("%v\x00%v\x00%v\x00%v\x00%v", params["operation"], params["resource"], params["namespace"], params["context"], params["args"])
+	c.mu.Lock()
+	entry, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.values, nil
+	}
+
+	if sessionID != "" {
+		params[tools.SessionIDParam] = sessionID
+	}
+	output, err := c.executor.Execute(params, c.cfg)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, line := range strings.Split(output, "\n") {
+		if value, ok := parse(strings.TrimSpace(line)); ok {
+			values = append(values, value)
+		}
+	}
+
+	c.mu.Lock()
+	c.makeRoomLocked()
+	c.cache[key] = cacheEntry{values: values, expires: c.now().Add(ttl)}
+	c.mu.Unlock()
+	return values, nil
+}
+
+// makeRoomLocked drops cached values until another entry fits in the cache, expired entries first and then
+// the entries expiring soonest. c.mu must be held.
+func (c *Completer) makeRoomLocked() {
+	if len(c.cache) < maxCacheEntries {
+		return
+	}
+	now := c.now()
+	for key, entry := range c.cache {
+		if !now.Before(entry.expires) {
+			delete(c.cache, key)
+		}
+	}
+	for len(c.cache) >= maxCacheEntries {
+		var oldest string
+		var oldestExpiry time.Time
+		for key, entry := range c.cache {
+			if oldest == "" || entry.expires.Before(oldestExpiry) {
+				oldest, oldestExpiry = key, entry.expires
+			}
+		}
+		delete(c.cache, oldest)
+	}
+}
+
+// knownContext checks if a context is one of the contexts of the kubeconfig
+func (c *Completer) knownContext(context string) (bool, error) {
+	contexts, err := c.contexts()
+	if err != nil {
+		return false, err
+	}
+	for _, name := range contexts {
+		if name == context {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// kubeconfigContexts lists the contexts of the kubeconfig used by commands
+func (c *Completer) kubeconfigContexts() ([]string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if c.cfg.Kubeconfig != "" {
+		rules.ExplicitPath = c.cfg.Kubeconfig
+	}
+	kubeconfig, err := rules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	contexts := make([]string, 0, len(kubeconfig.Contexts))
+	for name := range kubeconfig.Contexts {
+		contexts = append(contexts, name)
+	}
+	return contexts, nil
+}
+
+// placeholderValue returns an empty value for the placeholder of resource URIs
+func placeholderValue(value string) string {
+	if value == resources.Placeholder {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,337 @@
+package completion
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	"github.com/Azure/mcp-kubernetes/pkg/native"
+	"github.com/Azure/mcp-kubernetes/pkg/resources"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+const testManifests = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shipping
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: kube-system
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-1
+  namespace: shop
+spec:
+  containers:
+  - name: web
+    image: nginx
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: worker-1
+  namespace: shop
+spec:
+  containers:
+  - name: worker
+    image: busybox
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+spec:
+  ports:
+  - port: 80
+`
+
+// countingExecutor counts the commands run by an executor and records the session of the last one
+type countingExecutor struct {
+	tools.CommandExecutor
+	calls   int
+	session string
+}
+
+// Execute implements tools.CommandExecutor
+func (e *countingExecutor) Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	e.calls++
+	e.session = tools.SessionID(params)
+	return e.CommandExecutor.Execute(params, cfg)
+}
+
+// newTestCompleter creates a completer of a simulated cluster, restricted to the shop and shipping namespaces
+func newTestCompleter(t *testing.T) (*Completer, *countingExecutor) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cluster.yaml")
+	if err := os.WriteFile(path, []byte(testManifests), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	backend, err := native.NewSimulatedBackend(path)
+	if err != nil {
+		t.Fatalf("NewSimulatedBackend() unexpected error = %v", err)
+	}
+
+	securityConfig := &security.SecurityConfig{AccessLevel: security.AccessLevelReadOnly}
+	securityConfig.SetAllowedNamespaces("shop,shipping")
+	cfg := &config.ConfigData{AccessLevel: "readonly", Timeout: 30, SecurityConfig: securityConfig}
+	executor := &countingExecutor{CommandExecutor: kubectl.NewKubectlToolExecutorWithBackend(backend, false)}
+	completer := NewCompleter(executor, cfg)
+	completer.contexts = func() ([]string, error) { return []string{"prod", "staging"}, nil }
+	return completer, executor
+}
+
+// newRequest creates a completion request of an argument
+func newRequest(refType, ref, argument, value string, args map[string]string) Request {
+	var req Request
+	req.Ref.Type = refType
+	if refType == RefPrompt {
+		req.Ref.Name = ref
+	} else {
+		req.Ref.URI = ref
+	}
+	req.Argument.Name = argument
+	req.Argument.Value = value
+	req.Context.Arguments = args
+	return req
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want []string
+	}{
+		{
+			name: "resource namespaces are filtered by policy",
+			req:  newRequest(RefResource, resources.ObjectTemplate, "namespace", "", nil),
+			want: []string{"_", "shipping", "shop"},
+		},
+		{
+			name: "prefix",
+			req:  newRequest(RefResource, resources.ListTemplate, "namespace", "sho", nil),
+			want: []string{"shop"},
+		},
+		{
+			name: "resource names of a kind in a namespace",
+			req:  newRequest(RefResource, resources.ObjectTemplate, "name", "w", map[string]string{"context": "_", "namespace": "shop", "kind": "pods"}),
+			want: []string{"web-1", "worker-1"},
+		},
+		{
+			name: "resource names need a kind",
+			req:  newRequest(RefResource, resources.ObjectTemplate, "name", "", map[string]string{"namespace": "shop"}),
+			want: []string{},
+		},
+		{
+			name: "resource contexts",
+			req:  newRequest(RefResource, resources.ObjectTemplate, "context", "", nil),
+			want: []string{"_", "prod", "staging"},
+		},
+		{
+			name: "resource formats",
+			req:  newRequest(RefResource, resources.ObjectTemplate, "format", "y", nil),
+			want: []string{"yaml"},
+		},
+		{
+			name: "kinds fall back to common kinds",
+			req:  newRequest(RefResource, resources.ListTemplate, "kind", "dep", nil),
+			want: []string{"deployments.apps"},
+		},
+		{
+			name: "other resources",
+			req:  newRequest(RefResource, "k8s://_/_/cluster-info", "namespace", "", nil),
+			want: []string{},
+		},
+		{
+			name: "prompt namespaces",
+			req:  newRequest(RefPrompt, "troubleshoot-crashloop", "namespace", "", nil),
+			want: []string{"shipping", "shop"},
+		},
+		{
+			name: "prompt pods in the namespace argument",
+			req:  newRequest(RefPrompt, "troubleshoot-crashloop", "pod", "", map[string]string{"namespace": "shop"}),
+			want: []string{"web-1", "worker-1"},
+		},
+		{
+			name: "prompt services",
+			req:  newRequest(RefPrompt, "service-no-endpoints", "service", "we", map[string]string{"namespace": "shop"}),
+			want: []string{"web"},
+		},
+		{
+			name: "resource names of an unknown context",
+			req:  newRequest(RefResource, resources.ObjectTemplate, "name", "", map[string]string{"context": "x --kubeconfig=/tmp/config", "namespace": "shop", "kind": "pods"}),
+			want: []string{},
+		},
+		{
+			name: "prompt namespaces of an unknown context",
+			req:  newRequest(RefPrompt, "troubleshoot-crashloop", "namespace", "", map[string]string{"context": "-A"}),
+			want: []string{},
+		},
+		{
+			name: "prompt arguments without values",
+			req:  newRequest(RefPrompt, "dns-resolution-failure", "hostname", "", nil),
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completer, _ := newTestCompleter(t)
+			result, err := completer.Complete("", tt.req)
+			if err != nil {
+				t.Fatalf("Complete() unexpected error = %v", err)
+			}
+			if got := strings.Join(result.Completion.Values, ","); got != strings.Join(tt.want, ",") {
+				t.Errorf("Complete() = %v, want %v", result.Completion.Values, tt.want)
+			}
+			if result.Completion.HasMore {
+				t.Errorf("Complete() has more values than %v", result.Completion.Values)
+			}
+		})
+	}
+}
+
+func TestCompleteErrors(t *testing.T) {
+	completer, _ := newTestCompleter(t)
+
+	// Names of a namespace outside the policy are denied like tool calls
+	_, err := completer.Complete("", newRequest(RefPrompt, "troubleshoot-crashloop", "pod", "", map[string]string{"namespace": "kube-system"}))
+	if err == nil {
+		t.Error("Complete() expected error for a denied namespace")
+	}
+
+	completer.contexts = func() ([]string, error) { return nil, errors.New("no kubeconfig") }
+	if _, err := completer.Complete("", newRequest(RefPrompt, "x", "context", "", nil)); err == nil {
+		t.Error("Complete() expected error without kubeconfig")
+	}
+}
+
+func TestCompleteCache(t *testing.T) {
+	completer, executor := newTestCompleter(t)
+	now := time.Now()
+	completer.now = func() time.Time { return now }
+
+	req := newRequest(RefPrompt, "troubleshoot-crashloop", "pod", "", map[string]string{"namespace": "shop"})
+	for _, value := range []string{"", "w", "we"} {
+		req.Argument.Value = value
+		if _, err := completer.Complete("s1", req); err != nil {
+			t.Fatalf("Complete() unexpected error = %v", err)
+		}
+	}
+	if executor.calls != 1 {
+		t.Errorf("executor calls = %d, want 1 for cached names", executor.calls)
+	}
+	// Commands run for the session of the request, so their events are only logged to it
+	if executor.session != "s1" {
+		t.Errorf("executor session = %q, want s1", executor.session)
+	}
+
+	now = now.Add(ObjectsTTL + time.Second)
+	if _, err := completer.Complete("", req); err != nil {
+		t.Fatalf("Complete() unexpected error = %v", err)
+	}
+	if executor.calls != 2 {
+		t.Errorf("executor calls = %d, want 2 after expiry", executor.calls)
+	}
+}
+
+// staticExecutor answers every command with the same output
+type staticExecutor struct {
+	output string
+}
+
+// Execute implements tools.CommandExecutor
+func (e staticExecutor) Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	return e.output, nil
+}
+
+func TestCompleteCacheBounded(t *testing.T) {
+	completer := NewCompleter(staticExecutor{output: "pod/web-1"}, &config.ConfigData{})
+	now := time.Now()
+	completer.now = func() time.Time { return now }
+
+	first := newRequest(RefPrompt, "troubleshoot-crashloop", "pod", "", map[string]string{"namespace": "ns-first"})
+	if _, err := completer.Complete("", first); err != nil {
+		t.Fatalf("Complete() unexpected error = %v", err)
+	}
+	now = now.Add(time.Second)
+	for i := 0; i < maxCacheEntries; i++ {
+		req := newRequest(RefPrompt, "troubleshoot-crashloop", "pod", "", map[string]string{"namespace": fmt.Sprintf("ns-%d", i)})
+		if _, err := completer.Complete("", req); err != nil {
+			t.Fatalf("Complete() unexpected error = %v", err)
+		}
+	}
+	if len(completer.cache) != maxCacheEntries {
+		t.Errorf("cache entries = %d, want %d", len(completer.cache), maxCacheEntries)
+	}
+	for key := range completer.cache {
+		if strings.Contains(key, "ns-first") {
+			t.Error("the entry expiring soonest was not evicted")
+		}
+	}
+}
+
+func TestCompleteLimit(t *testing.T) {
+	completer, _ := newTestCompleter(t)
+	var contexts []string
+	for i := 0; i < MaxValues+20; i++ {
+		contexts = append(contexts, fmt.Sprintf("cluster-%03d", i))
+	}
+	completer.contexts = func() ([]string, error) { return contexts, nil }
+
+	result, err := completer.Complete("", newRequest(RefPrompt, "x", "context", "cluster-", nil))
+	if err != nil {
+		t.Fatalf("Complete() unexpected error = %v", err)
+	}
+	if len(result.Completion.Values) != MaxValues || result.Completion.Total != MaxValues+20 || !result.Completion.HasMore {
+		t.Errorf("Complete() = %d values of %d (has more %v), want %d of %d", len(result.Completion.Values),
+			result.Completion.Total, result.Completion.HasMore, MaxValues, MaxValues+20)
+	}
+}
+
+func TestKubeconfigContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: c
+  cluster: {server: "https://example.com"}
+users:
+- name: u
+  user: {}
+contexts:
+- name: prod
+  context: {cluster: c, user: u}
+- name: staging
+  context: {cluster: c, user: u}
+current-context: prod
+`
+	if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	completer := NewCompleter(nil, &config.ConfigData{Kubeconfig: path})
+	result, err := completer.Complete("", newRequest(RefResource, resources.ObjectTemplate, "context", "", nil))
+	if err != nil {
+		t.Fatalf("Complete() unexpected error = %v", err)
+	}
+	if got := strings.Join(result.Completion.Values, ","); got != "_,prod,staging" {
+		t.Errorf("Complete() = %s, want _,prod,staging", got)
+	}
+}
@@ -3,10 +3,11 @@ package config
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
 	"strings"
 
+	"github.com/Azure/mcp-kubernetes/pkg/command"
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
 	"github.com/Azure/mcp-kubernetes/pkg/security"
 	"github.com/Azure/mcp-kubernetes/pkg/telemetry"
 	"github.com/Azure/mcp-kubernetes/pkg/version"
@@ -17,10 +18,48 @@ import (
 type ConfigData struct {
 	// Map of additional tools enabled
 	AdditionalTools map[string]bool
+	// Register tools mixing read and write operations as a read-only tool and a <tool>_write tool
+	SplitTools bool
+	// Directory with YAML prompt definitions added to the built-in prompts
+	PromptsDir string
 	// Command execution timeout in seconds
 	Timeout int
+	// Maximum command execution timeout in seconds, caps per-operation and per-call timeouts
+	MaxTimeout int
+	// Timeout policy per command type and operation
+	TimeoutPolicy *TimeoutPolicy
+	// Maximum number of retries for read-only commands failing with transient errors, 0 disables retries
+	MaxRetries int
+	// Cache results of read-only kubectl commands
+	CacheEnabled bool
+	// ClusterChanged is called after a helm or cilium command that may have changed cluster objects ran,
+	// so cached kubectl results are dropped. Nil does nothing.
+	ClusterChanged func()
+	// Summarize describe and log outputs larger than SummarizeThreshold bytes, with the client's model if it
+	// supports sampling and by truncating them otherwise
+	SummarizeOutputs   bool
+	SummarizeThreshold int
+	// Run long operations as background jobs when calls ask for it
+	BackgroundJobs bool
+	// Directory persisting the metadata of background jobs, empty uses the user cache directory
+	JobsDir string
+	// Backend for kubectl tools, "kubectl" spawns the binary, "native" uses client-go where supported
+	// and "simulated" answers from an in-memory cluster
+	Backend string
+	// Manifest file or directory loaded into the simulated cluster
+	SimulatedData string
+	// Directory to record command executions to as cassette files
+	Record string
+	// Directory to replay recorded command executions from instead of running commands
+	Replay string
+	// Cassettes records or replays command executions, nil runs commands normally
+	Cassettes *command.CassetteStore
 	// Security configuration
 	SecurityConfig *security.SecurityConfig
+	// Kubeconfig file pinned for all subprocesses, empty uses the server's KUBECONFIG
+	Kubeconfig string
+	// Extra environment variables for subprocesses, as NAME to pass through or NAME=VALUE to set
+	ExtraEnv []string
 
 	// Command-line specific options
 	Transport       string
@@ -28,6 +67,8 @@ type ConfigData struct {
 	Port            int
 	AccessLevel     string
 	AllowNamespaces string
+	Workspace       string
+	AllowedURLHosts string
 
 	// OTLP endpoint for OpenTelemetry traces
 	OTLPEndpoint string
@@ -39,13 +80,19 @@ type ConfigData struct {
 // NewConfig creates and returns a new configuration instance
 func NewConfig() *ConfigData {
 	return &ConfigData{
-		AdditionalTools: make(map[string]bool),
-		Timeout:         60,
-		SecurityConfig:  security.NewSecurityConfig(),
-		Transport:       "stdio",
-		Port:            8000,
-		AccessLevel:     "readonly",
-		AllowNamespaces: "",
+		AdditionalTools:    make(map[string]bool),
+		Timeout:            60,
+		MaxTimeout:         900,
+		TimeoutPolicy:      NewTimeoutPolicy(60, 900),
+		MaxRetries:         3,
+		CacheEnabled:       true,
+		SummarizeThreshold: 16384,
+		Backend:            "kubectl",
+		SecurityConfig:     security.NewSecurityConfig(),
+		Transport:          "stdio",
+		Port:               8000,
+		AccessLevel:        "readonly",
+		AllowNamespaces:    "",
 	}
 }
 
@@ -56,15 +103,49 @@ func (cfg *ConfigData) ParseFlags() error {
 	flag.StringVar(&cfg.Host, "host", "127.0.0.1", "Host to listen for the server (only used with transport sse or streamable-http)")
 	flag.IntVar(&cfg.Port, "port", 8000, "Port to listen for the server (only used with transport sse or streamable-http)")
 	flag.IntVar(&cfg.Timeout, "timeout", 60, "Timeout for command execution in seconds, default is 60s")
+	flag.IntVar(&cfg.MaxTimeout, "max-timeout", 900, "Maximum timeout in seconds for any command, including per-call timeout_seconds")
+	operationTimeouts := flag.String("operation-timeouts", "",
+		"Comma-separated per-operation timeouts in seconds (e.g. kubectl.drain=900,helm.upgrade=1200,helm=120)")
+	timeoutConfig := flag.String("timeout-config", "", "Path to a JSON file with the timeout policy (default, max and operations)")
+	flag.IntVar(&cfg.MaxRetries, "max-retries", 3,
+		"Maximum number of retries for read-only commands failing with transient API server errors (0 disables retries)")
+	flag.BoolVar(&cfg.CacheEnabled, "cache", true,
+		"Cache results of read-only kubectl commands for a short time and coalesce identical concurrent calls")
+	flag.BoolVar(&cfg.SummarizeOutputs, "summarize-outputs", false,
+		"Return a summary instead of describe and log outputs larger than --summarize-threshold, written by the client's model through sampling or truncated if the client doesn't support it")
+	flag.IntVar(&cfg.SummarizeThreshold, "summarize-threshold", 16384, "Size in bytes above which outputs are summarized (only used with --summarize-outputs)")
+	flag.BoolVar(&cfg.BackgroundJobs, "background-jobs", false,
+		"Let calls run drains, rollout status and cilium connectivity tests as background jobs, followed with the job_status, job_output and job_cancel tools")
+	flag.StringVar(&cfg.JobsDir, "jobs-dir", "",
+		"Directory persisting the status of background jobs across reconnects and restarts (default mcp-kubernetes/jobs in the user cache directory, only used with --background-jobs)")
+	flag.StringVar(&cfg.Backend, "backend", "kubectl",
+		"Backend for kubectl tools (kubectl, native or simulated). native runs get, describe, delete, apply, patch, scale, label and events with client-go, simulated answers from an in-memory cluster")
+	flag.StringVar(&cfg.SimulatedData, "simulated-data", "",
+		"Manifest file or directory loaded into the in-memory cluster (only used with --backend=simulated)")
+	flag.StringVar(&cfg.Record, "record", "", "Directory to record every command execution to as cassette files")
+	flag.StringVar(&cfg.Replay, "replay", "", "Directory with cassette files to serve command executions from, without running any CLI")
 
 	// Tools configuration
 	additionalTools := flag.String("additional-tools", "",
 		"Comma-separated list of additional tools to support (kubectl is always enabled). Available: helm,cilium,hubble")
+	flag.BoolVar(&cfg.SplitTools, "split-tools", false,
+		"Register tools mixing read and write operations as a read-only tool and a <tool>_write tool, so clients can auto-approve the read-only tools")
+	flag.StringVar(&cfg.PromptsDir, "prompts-dir", "",
+		"Directory with YAML prompt definitions added to the built-in prompts, replacing built-in prompts of the same name")
+
+	// Subprocess environment
+	flag.StringVar(&cfg.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file used by all commands (default is the KUBECONFIG environment variable)")
+	extraEnv := flag.String("env", "",
+		"Comma-separated list of extra environment variables for commands, as NAME to pass through or NAME=VALUE to set")
 
 	// Security settings
 	flag.StringVar(&cfg.AccessLevel, "access-level", "readonly", "Access level (readonly, readwrite, or admin)")
 	flag.StringVar(&cfg.AllowNamespaces, "allow-namespaces", "",
 		"Comma-separated list of namespaces to allow (empty means all allowed)")
+	flag.StringVar(&cfg.Workspace, "workspace", "",
+		"Workspace directory that local file paths in commands must resolve into (empty disables the sandbox)")
+	flag.StringVar(&cfg.AllowedURLHosts, "allowed-url-hosts", "",
+		"Comma-separated list of hosts allowed for URL file sources like -f https://... when a workspace is set")
 
 	// OTLP settings
 	flag.StringVar(&cfg.OTLPEndpoint, "otlp-endpoint", "", "OTLP endpoint for OpenTelemetry traces (e.g. localhost:4317, default \"\")")
@@ -92,10 +173,54 @@ func (cfg *ConfigData) ParseFlags() error {
 		return fmt.Errorf("invalid access level '%s'. Valid values are: readonly, readwrite, admin", cfg.AccessLevel)
 	}
 
+	switch cfg.Backend {
+	case "kubectl", "native", "simulated":
+	default:
+		return fmt.Errorf("invalid backend '%s'. Valid values are: kubectl, native, simulated", cfg.Backend)
+	}
+
+	if err := cfg.setupCassettes(); err != nil {
+		return err
+	}
+
 	if cfg.AllowNamespaces != "" {
 		cfg.SecurityConfig.SetAllowedNamespaces(cfg.AllowNamespaces)
 	}
 
+	if err := cfg.SecurityConfig.SetWorkspace(cfg.Workspace); err != nil {
+		return err
+	}
+	cfg.SecurityConfig.SetAllowedURLHosts(cfg.AllowedURLHosts)
+
+	// Build the timeout policy
+	cfg.TimeoutPolicy = NewTimeoutPolicy(cfg.Timeout, cfg.MaxTimeout)
+	if flag.CommandLine.Changed("timeout") {
+		cfg.TimeoutPolicy.SetDefault(cfg.Timeout)
+	}
+	if *timeoutConfig != "" {
+		if err := cfg.TimeoutPolicy.LoadFile(*timeoutConfig); err != nil {
+			return err
+		}
+	}
+	if *operationTimeouts != "" {
+		if err := cfg.TimeoutPolicy.SetOperationTimeouts(*operationTimeouts); err != nil {
+			return err
+		}
+	}
+	// The policy is the source of truth, the timeout config file may have changed the default and max
+	cfg.Timeout, cfg.MaxTimeout = cfg.TimeoutPolicy.Default, cfg.TimeoutPolicy.Max
+
+	// Parse extra environment variables
+	if *extraEnv != "" {
+		for _, env := range strings.Split(*extraEnv, ",") {
+			env = strings.TrimSpace(env)
+			if env == "" {
+				continue
+			}
+			cfg.ExtraEnv = append(cfg.ExtraEnv, env)
+		}
+	}
+
 	// Parse additional tools
 	if *additionalTools != "" {
 		for _, tool := range strings.Split(*additionalTools, ",") {
@@ -110,6 +235,57 @@ func (cfg *ConfigData) ParseFlags() error {
 	return nil
 }
 
+// setupCassettes creates the cassette store for --record or --replay
+func (cfg *ConfigData) setupCassettes() error {
+	if cfg.Record == "" && cfg.Replay == "" {
+		return nil
+	}
+	if cfg.Record != "" && cfg.Replay != "" {
+		return fmt.Errorf("--record and --replay cannot be used together")
+	}
+	// The native backend doesn't run commands, so its calls can't be recorded or replayed
+	if cfg.Backend != "kubectl" {
+		return fmt.Errorf("--record and --replay require --backend=kubectl")
+	}
+
+	var err error
+	if cfg.Record != "" {
+		cfg.Cassettes, err = command.NewCassetteRecorder(cfg.Record)
+	} else {
+		cfg.Cassettes, err = command.LoadCassettes(cfg.Replay)
+	}
+	return err
+}
+
+// CommandEnv returns the explicit environment for subprocesses of the given command type
+func (cfg *ConfigData) CommandEnv(commandType string) []string {
+	return command.BuildEnv(commandType, command.EnvOptions{
+		Kubeconfig: cfg.Kubeconfig,
+		Extra:      cfg.ExtraEnv,
+	})
+}
+
+// RetryPolicy returns the retry policy for read-only commands, or nil if retries are disabled
+func (cfg *ConfigData) RetryPolicy() *command.RetryPolicy {
+	if cfg.MaxRetries <= 0 {
+		return nil
+	}
+	return command.NewRetryPolicy(cfg.MaxRetries)
+}
+
+// CommandDir returns the working directory for the subprocesses of a session when the sandbox is enabled:
+// the first root of its client, or the workspace
+func (cfg *ConfigData) CommandDir(sessionID string) string {
+	if cfg.SecurityConfig == nil {
+		return ""
+	}
+	dirs, _ := cfg.SecurityConfig.SandboxDirs(sessionID)
+	if len(dirs) == 0 {
+		return ""
+	}
+	return dirs[0]
+}
+
 // InitializeTelemetry initializes the telemetry service
 func (cfg *ConfigData) InitializeTelemetry(ctx context.Context, serviceName, serviceVersion string) {
 	// Create telemetry configuration
@@ -123,7 +299,7 @@ func (cfg *ConfigData) InitializeTelemetry(ctx context.Context, serviceName, ser
 	// Initialize telemetry service
 	cfg.TelemetryService = telemetry.NewService(telemetryConfig)
 	if err := cfg.TelemetryService.Initialize(ctx); err != nil {
-		log.Printf("Failed to initialize telemetry: %v", err)
+		logging.Logger().Warn("failed to initialize telemetry", "error", err)
 		// Continue without telemetry - this is not a fatal error
 	}
 
@@ -123,3 +123,44 @@ type ValidationError struct {
 func (e *ValidationError) Error() string {
 	return e.Message
 }
+
+func TestSetupCassettes(t *testing.T) {
+	tests := []struct {
+		name        string
+		record      bool
+		replay      bool
+		backend     string
+		expectError bool
+	}{
+		{"Disabled", false, false, "kubectl", false},
+		{"Record", true, false, "kubectl", false},
+		{"Replay without cassettes", false, true, "kubectl", true},
+		{"Record and replay", true, true, "kubectl", true},
+		{"Record with native backend", true, false, "native", true},
+		{"Record with simulated backend", true, false, "simulated", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.Backend = tt.backend
+			if tt.record {
+				cfg.Record = t.TempDir()
+			}
+			if tt.replay {
+				cfg.Replay = t.TempDir()
+			}
+
+			err := cfg.setupCassettes()
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+			if !tt.expectError && tt.record && (cfg.Cassettes == nil || cfg.Cassettes.Replaying()) {
+				t.Errorf("Expected a recording cassette store")
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultOperationTimeouts holds the built-in per-operation timeouts in seconds.
+// Keys are "<command type>.<operation>" or just "<command type>".
+var defaultOperationTimeouts = map[string]int{
+	"kubectl.get":         30,
+	"kubectl.describe":    30,
+	"kubectl.drain":       600,
+	"kubectl.rollout":     300,
+	"helm.install":        600,
+	"helm.upgrade":        600,
+	"helm.rollback":       600,
+	"helm.uninstall":      300,
+	"cilium.install":      600,
+	"cilium.upgrade":      600,
+	"cilium.uninstall":    300,
+	"cilium.connectivity": 900,
+}
+
+// TimeoutPolicy defines command execution timeouts per command type and operation
+type TimeoutPolicy struct {
+	// Default timeout in seconds used when no operation-specific value is set
+	Default int `json:"default"`
+	// Max is the upper bound in seconds for any timeout, including per-call overrides
+	Max int `json:"max"`
+	// Operations maps "<command type>.<operation>" or "<command type>" to a configured timeout in seconds
+	Operations map[string]int `json:"operations"`
+
+	// builtins holds the built-in operation timeouts, used for operations without a configured timeout
+	builtins map[string]int
+	// defaultSet is set when the user chose the default timeout, which built-in timeouts never undercut
+	defaultSet bool
+}
+
+// NewTimeoutPolicy creates a timeout policy with the built-in operation timeouts
+func NewTimeoutPolicy(defaultTimeout, maxTimeout int) *TimeoutPolicy {
+	return &TimeoutPolicy{
+		Default:    defaultTimeout,
+		Max:        maxTimeout,
+		Operations: make(map[string]int),
+		builtins:   defaultOperationTimeouts,
+	}
+}
+
+// SetDefault sets a default timeout chosen by the user. Built-in operation timeouts shorter than it are
+// raised to it, e.g. get uses 120s with --timeout=120 while drain keeps its longer built-in timeout.
+func (p *TimeoutPolicy) SetDefault(seconds int) {
+	p.Default = seconds
+	p.defaultSet = true
+}
+
+// SetOperationTimeouts parses a comma-separated list of key=seconds pairs,
+// e.g. "kubectl.drain=900,helm=120", and merges them into the policy
+func (p *TimeoutPolicy) SetOperationTimeouts(timeouts string) error {
+	for _, entry := range strings.Split(timeouts, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid operation timeout '%s', expected <command>.<operation>=<seconds>", entry)
+		}
+
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || seconds <= 0 {
+			return fmt.Errorf("invalid timeout value '%s' for '%s', must be a positive number of seconds", value, key)
+		}
+
+		p.Operations[strings.TrimSpace(key)] = seconds
+	}
+
+	return nil
+}
+
+// LoadFile merges timeouts from a JSON file into the policy
+func (p *TimeoutPolicy) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read timeout config: %w", err)
+	}
+
+	var fileConfig TimeoutPolicy
+	if err := json.Unmarshal(data, &fileConfig); err != nil {
+		return fmt.Errorf("failed to parse timeout config %s: %w", path, err)
+	}
+
+	if fileConfig.Default > 0 {
+		p.SetDefault(fileConfig.Default)
+	}
+	if fileConfig.Max > 0 {
+		p.Max = fileConfig.Max
+	}
+	for key, seconds := range fileConfig.Operations {
+		if seconds <= 0 {
+			return fmt.Errorf("invalid timeout value %d for '%s' in %s, must be positive", seconds, key, path)
+		}
+		p.Operations[key] = seconds
+	}
+
+	return nil
+}
+
+// Validate checks that the default timeout is positive and within the max timeout
+func (p *TimeoutPolicy) Validate() error {
+	if p.Default <= 0 {
+		return fmt.Errorf("invalid timeout %d: must be a positive number of seconds", p.Default)
+	}
+	if p.Max < p.Default {
+		return fmt.Errorf("invalid max timeout %d: must not be lower than timeout %d", p.Max, p.Default)
+	}
+	return nil
+}
+
+// Resolve returns the timeout in seconds for the given command type and operation. Configured
+// timeouts take precedence over built-in ones, the operation over the command type. A positive
+// requested value overrides both, and the result is always capped by Max when Max is set.
+func (p *TimeoutPolicy) Resolve(commandType, operation string, requested int) int {
+	keys := []string{commandType}
+	if operation != "" {
+		keys = []string{commandType + "." + operation, commandType}
+	}
+
+	timeout, found := p.Default, false
+	for _, key := range keys {
+		if seconds, ok := p.Operations[key]; ok {
+			timeout, found = seconds, true
+			break
+		}
+	}
+	if !found {
+		for _, key := range keys {
+			if seconds, ok := p.builtins[key]; ok {
+				timeout = seconds
+				if p.defaultSet && timeout < p.Default {
+					timeout = p.Default
+				}
+				break
+			}
+		}
+	}
+
+	if requested > 0 {
+		timeout = requested
+	}
+
+	if p.Max > 0 && timeout > p.Max {
+		timeout = p.Max
+	}
+
+	return timeout
+}
+
+// ResolveTimeout returns the timeout in seconds for a command, falling back to
+// the global timeout when no timeout policy is configured
+func (cfg *ConfigData) ResolveTimeout(commandType, operation string, requested int) int {
+	if cfg.TimeoutPolicy == nil {
+		if requested > 0 {
+			return requested
+		}
+		return cfg.Timeout
+	}
+	return cfg.TimeoutPolicy.Resolve(commandType, operation, requested)
+}
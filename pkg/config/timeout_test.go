@@ -0,0 +1,154 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTimeoutPolicyResolve(t *testing.T) {
+	policy := NewTimeoutPolicy(60, 900)
+	policy.Operations["hubble"] = 120
+
+	tests := []struct {
+		name        string
+		commandType string
+		operation   string
+		requested   int
+		want        int
+	}{
+		{"Default timeout", "kubectl", "logs", 0, 60},
+		{"Short read operation", "kubectl", "get", 0, 30},
+		{"Long drain operation", "kubectl", "drain", 0, 600},
+		{"Helm upgrade", "helm", "upgrade", 0, 600},
+		{"Command type override", "hubble", "observe", 0, 120},
+		{"Per-call override", "kubectl", "get", 120, 120},
+		{"Per-call override capped", "kubectl", "drain", 3600, 900},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.Resolve(tt.commandType, tt.operation, tt.requested)
+			if got != tt.want {
+				t.Errorf("Resolve(%s, %s, %d) = %d, want %d", tt.commandType, tt.operation, tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeoutPolicySetOperationTimeouts(t *testing.T) {
+	policy := NewTimeoutPolicy(60, 900)
+
+	if err := policy.SetOperationTimeouts("kubectl.drain=800, helm=120,"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := policy.Resolve("kubectl", "drain", 0); got != 800 {
+		t.Errorf("Expected drain timeout 800, got %d", got)
+	}
+	if got := policy.Resolve("helm", "list", 0); got != 120 {
+		t.Errorf("Expected helm timeout 120, got %d", got)
+	}
+
+	invalid := []string{"kubectl.drain", "kubectl.drain=abc", "kubectl.drain=0", "kubectl.drain=-5"}
+	for _, value := range invalid {
+		if err := policy.SetOperationTimeouts(value); err == nil {
+			t.Errorf("Expected error for %q, got none", value)
+		}
+	}
+}
+
+func TestTimeoutPolicyLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timeouts.json")
+	content := `{"default": 45, "max": 1200, "operations": {"kubectl.drain": 1000}}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write timeout config: %v", err)
+	}
+
+	policy := NewTimeoutPolicy(60, 900)
+	if err := policy.LoadFile(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if policy.Default != 45 {
+		t.Errorf("Expected default 45, got %d", policy.Default)
+	}
+	if policy.Max != 1200 {
+		t.Errorf("Expected max 1200, got %d", policy.Max)
+	}
+	if got := policy.Resolve("kubectl", "drain", 0); got != 1000 {
+		t.Errorf("Expected drain timeout 1000, got %d", got)
+	}
+	if got := policy.Resolve("kubectl", "get", 0); got != 45 {
+		t.Errorf("Expected built-in get timeout 30 to be raised to the default 45, got %d", got)
+	}
+}
+
+func TestValidateTimeoutsFromFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"max below the flag default", `{"max": 30}`, true},
+		{"default above the flag max", `{"default": 1000}`, true},
+		{"default and max above the flag max", `{"default": 1000, "max": 1200}`, false},
+		{"max between the defaults", `{"default": 20, "max": 30}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "timeouts.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("Failed to write timeout config: %v", err)
+			}
+			cfg := NewConfig()
+			if err := cfg.TimeoutPolicy.LoadFile(path); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			valid := NewValidator(cfg).validateTimeouts()
+			if valid == tt.wantErr {
+				t.Errorf("validateTimeouts() = %v, wantErr %v", valid, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTimeoutPolicyUserDefault(t *testing.T) {
+	policy := NewTimeoutPolicy(60, 900)
+	policy.SetDefault(120)
+
+	tests := []struct {
+		commandType string
+		operation   string
+		want        int
+	}{
+		{"kubectl", "logs", 120},
+		{"kubectl", "get", 120},
+		{"kubectl", "drain", 600},
+		{"helm", "install", 600},
+	}
+	for _, tt := range tests {
+		if got := policy.Resolve(tt.commandType, tt.operation, 0); got != tt.want {
+			t.Errorf("Resolve(%s, %s) = %d, want %d", tt.commandType, tt.operation, got, tt.want)
+		}
+	}
+
+	// Configured command type timeouts take precedence over built-in operation timeouts
+	if err := policy.SetOperationTimeouts("kubectl=90"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := policy.Resolve("kubectl", "drain", 0); got != 90 {
+		t.Errorf("Expected configured kubectl timeout 90 for drain, got %d", got)
+	}
+}
+
+func TestResolveTimeoutWithoutPolicy(t *testing.T) {
+	cfg := &ConfigData{Timeout: 60}
+
+	if got := cfg.ResolveTimeout("kubectl", "drain", 0); got != 60 {
+		t.Errorf("Expected global timeout 60, got %d", got)
+	}
+	if got := cfg.ResolveTimeout("kubectl", "drain", 10); got != 10 {
+		t.Errorf("Expected requested timeout 10, got %d", got)
+	}
+}
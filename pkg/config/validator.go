@@ -65,6 +65,7 @@ func (v *Validator) validateAdditionalTools() bool {
 // validateKubeconfig checks if kubectl is properly configured and can connect to the cluster
 func (v *Validator) validateKubeconfig() bool {
 	cmd := exec.Command("kubectl", "version", "--request-timeout=15s")
+	cmd.Env = v.config.CommandEnv("kubectl")
 	if err := cmd.Run(); err != nil {
 		v.errors = append(v.errors, "kubectl is not properly configured or cannot connect to the cluster: "+err.Error())
 		return false
@@ -72,6 +73,39 @@ func (v *Validator) validateKubeconfig() bool {
 	return true
 }
 
+// validateTimeouts checks if the configured timeouts are consistent, using the timeout policy once it's built
+// since the timeout config file may override the flags
+func (v *Validator) validateTimeouts() bool {
+	if v.config.TimeoutPolicy != nil {
+		if err := v.config.TimeoutPolicy.Validate(); err != nil {
+			v.errors = append(v.errors, err.Error())
+			return false
+		}
+		return true
+	}
+
+	if v.config.Timeout <= 0 {
+		v.errors = append(v.errors, fmt.Sprintf("Invalid timeout %d: must be a positive number of seconds", v.config.Timeout))
+		return false
+	}
+
+	if v.config.MaxTimeout < v.config.Timeout {
+		v.errors = append(v.errors, fmt.Sprintf("Invalid max timeout %d: must not be lower than timeout %d", v.config.MaxTimeout, v.config.Timeout))
+		return false
+	}
+
+	return true
+}
+
+// validateSimulated checks that only kubectl tools are enabled with the simulated backend
+func (v *Validator) validateSimulated() bool {
+	if len(v.config.AdditionalTools) > 0 {
+		v.errors = append(v.errors, "Additional tools are not available with the simulated backend")
+		return false
+	}
+	return true
+}
+
 // Validate runs all validation checks
 func (v *Validator) Validate() bool {
 	// Reset errors before validation
@@ -79,10 +113,22 @@ func (v *Validator) Validate() bool {
 
 	// Run all validation checks
 	validTools := v.validateAdditionalTools()
+	validTimeouts := v.validateTimeouts()
+
+	// The simulated cluster needs neither the CLIs nor a cluster, but can't serve additional tools
+	if v.config.Backend == "simulated" {
+		return v.validateSimulated() && validTools && validTimeouts
+	}
+
+	// Replayed executions need neither the CLIs nor a cluster
+	if v.config.Cassettes != nil && v.config.Cassettes.Replaying() {
+		return validTools && validTimeouts
+	}
+
 	validCli := v.validateCli()
 	validKubeconfig := v.validateKubeconfig()
 
-	return validTools && validCli && validKubeconfig
+	return validTools && validTimeouts && validCli && validKubeconfig
 }
 
 // GetErrors returns all errors found during validation
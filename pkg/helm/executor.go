@@ -1,16 +1,18 @@
 package helm
 
 import (
-	"fmt"
-
 	"github.com/Azure/mcp-kubernetes/pkg/command"
 	"github.com/Azure/mcp-kubernetes/pkg/config"
 	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
 	"github.com/Azure/mcp-kubernetes/pkg/tools"
 )
 
 // HelmExecutor implements the CommandExecutor interface for helm commands
-type HelmExecutor struct{}
+type HelmExecutor struct {
+	// readOnly rejects commands that modify state
+	readOnly bool
+}
 
 var _ tools.CommandExecutor = (*HelmExecutor)(nil)
 
@@ -19,21 +21,55 @@ func NewExecutor() *HelmExecutor {
 	return &HelmExecutor{}
 }
 
+// NewReadOnlyExecutor creates a HelmExecutor that only runs commands that don't modify state
+func NewReadOnlyExecutor() *HelmExecutor {
+	return &HelmExecutor{readOnly: true}
+}
+
 // Execute handles helm command execution
 func (e *HelmExecutor) Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
 	helmCmd, ok := params["command"].(string)
 	if !ok {
-		return "", fmt.Errorf("invalid command parameter")
+		return "", toolerror.New(toolerror.InvalidArguments, "invalid command parameter")
 	}
 
 	// Validate the command against security settings
-	validator := security.NewValidator(cfg.SecurityConfig)
+	validator := security.NewValidator(cfg.SecurityConfig).ForSession(tools.SessionID(params))
 	err := validator.ValidateCommand(helmCmd, security.CommandTypeHelm)
 	if err != nil {
 		return "", err
 	}
+	if e.readOnly && !validator.IsReadOnlyCommand(helmCmd, security.CommandTypeHelm) {
+		return "", toolerror.New(toolerror.InvalidArguments, "command modifies state and is not available in helm, use helm_write")
+	}
+
+	// Resolve the timeout for this operation
+	requestedTimeout, err := tools.GetTimeoutSeconds(params)
+	if err != nil {
+		return "", err
+	}
+	operation := security.ExtractOperation(helmCmd, security.CommandTypeHelm)
+	timeout := cfg.ResolveTimeout(security.CommandTypeHelm, operation, requestedTimeout)
 
 	// Execute the command
-	process := command.NewShellProcess("helm", cfg.Timeout)
-	return process.Run(helmCmd)
+	process := command.NewShellProcess("helm", timeout)
+	process.Logger = tools.Logger(params)
+	process.Env = cfg.CommandEnv(security.CommandTypeHelm)
+	process.Dir = cfg.CommandDir(tools.SessionID(params))
+	process.Cassettes = cfg.Cassettes
+	process.Context = tools.Context(params)
+	if validator.IsReadOnlyCommand(helmCmd, security.CommandTypeHelm) {
+		// Only read-only commands are safe to retry after transient errors
+		process.Retry = cfg.RetryPolicy()
+	} else if cfg.ClusterChanged != nil {
+		defer cfg.ClusterChanged()
+	}
+	output, err := process.Run(helmCmd)
+	if err == nil {
+		// helm errors are returned as output, report the ones that are recognized as typed errors
+		if typedErr := toolerror.FromHelmOutput(output); typedErr != nil {
+			return "", typedErr
+		}
+	}
+	return output, err
 }
@@ -1,16 +1,44 @@
 package helm
 
 import (
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // RegisterHelm registers the helm tool
 func RegisterHelm() mcp.Tool {
-	return mcp.NewTool("helm",
-		mcp.WithDescription("Run Helm package manager commands for Kubernetes"),
+	// Repository commands change the local helm configuration and all commands may reach chart repositories
+	return newHelmTool("helm",
+		"Run Helm package manager commands for Kubernetes",
+		"The helm command to execute (e.g., 'helm list', 'helm install myapp ./chart')",
+		tools.Annotations{Idempotent: true, OpenWorld: true})
+}
+
+// RegisterHelmReadOnly registers the read-only variant of the helm tool, registered together with RegisterHelmWrite
+func RegisterHelmReadOnly() mcp.Tool {
+	return newHelmTool("helm",
+		"Run read-only Helm package manager commands for Kubernetes. Use helm_write for commands that modify state",
+		"The helm command to execute (e.g., 'helm list', 'helm status myrelease')",
+		tools.Annotations{ReadOnly: true, OpenWorld: true})
+}
+
+// RegisterHelmWrite registers the write variant of the helm tool
+func RegisterHelmWrite() mcp.Tool {
+	return newHelmTool(tools.WriteVariantName("helm"),
+		"Run Helm package manager commands that modify state, such as repo add, repo update and repo remove. Use helm for read-only commands",
+		"The helm command to execute (e.g., 'helm repo add bitnami https://charts.bitnami.com/bitnami')",
+		tools.Annotations{Idempotent: true, OpenWorld: true})
+}
+
+// newHelmTool creates a helm tool definition
+func newHelmTool(name, description, commandDescription string, annotations tools.Annotations) mcp.Tool {
+	return mcp.NewTool(name,
+		mcp.WithDescription(description),
+		tools.WithAnnotations(annotations),
 		mcp.WithString("command",
 			mcp.Required(),
-			mcp.Description("The helm command to execute (e.g., 'helm list', 'helm install myapp ./chart')"),
+			mcp.Description(commandDescription),
 		),
+		tools.WithTimeoutSeconds(),
 	)
 }
@@ -1,11 +1,10 @@
 package hubble
 
 import (
-	"fmt"
-
 	"github.com/Azure/mcp-kubernetes/pkg/command"
 	"github.com/Azure/mcp-kubernetes/pkg/config"
 	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
 	"github.com/Azure/mcp-kubernetes/pkg/tools"
 )
 
@@ -24,17 +23,34 @@ func NewExecutor() *HubbleExecutor {
 func (e *HubbleExecutor) Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
 	hubbleCmd, ok := params["command"].(string)
 	if !ok {
-		return "", fmt.Errorf("invalid command parameter")
+		return "", toolerror.New(toolerror.InvalidArguments, "invalid command parameter")
 	}
 
 	// Validate the command against security settings
-	validator := security.NewValidator(cfg.SecurityConfig)
+	validator := security.NewValidator(cfg.SecurityConfig).ForSession(tools.SessionID(params))
 	err := validator.ValidateCommand(hubbleCmd, security.CommandTypeHubble)
 	if err != nil {
 		return "", err
 	}
 
+	// Resolve the timeout for this operation
+	requestedTimeout, err := tools.GetTimeoutSeconds(params)
+	if err != nil {
+		return "", err
+	}
+	operation := security.ExtractOperation(hubbleCmd, security.CommandTypeHubble)
+	timeout := cfg.ResolveTimeout(security.CommandTypeHubble, operation, requestedTimeout)
+
 	// Execute the command
-	process := command.NewShellProcess("hubble", cfg.Timeout)
+	process := command.NewShellProcess("hubble", timeout)
+	process.Logger = tools.Logger(params)
+	process.Env = cfg.CommandEnv(security.CommandTypeHubble)
+	process.Dir = cfg.CommandDir(tools.SessionID(params))
+	process.Cassettes = cfg.Cassettes
+	process.Context = tools.Context(params)
+	if validator.IsReadOnlyCommand(hubbleCmd, security.CommandTypeHubble) {
+		// Only read-only commands are safe to retry after transient errors
+		process.Retry = cfg.RetryPolicy()
+	}
 	return process.Run(hubbleCmd)
 }
@@ -1,16 +1,19 @@
 package hubble
 
 import (
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
-// RegisterHubble registers the hubble tool
+// RegisterHubble registers the hubble tool. All hubble operations only observe the cluster.
 func RegisterHubble() mcp.Tool {
 	return mcp.NewTool("hubble",
 		mcp.WithDescription("Run Hubble observability commands for network monitoring and debugging"),
+		tools.WithAnnotations(tools.ReadOnlyAnnotations),
 		mcp.WithString("command",
 			mcp.Required(),
 			mcp.Description("The hubble command to execute (e.g., 'hubble status', 'hubble observe', 'hubble list nodes')"),
 		),
+		tools.WithTimeoutSeconds(),
 	)
 }
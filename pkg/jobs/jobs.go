@@ -0,0 +1,382 @@
+// Package jobs runs long tool calls, like drains, rollout status or cilium connectivity tests, in
+// the background. Calls started as jobs return a job ID at once, and the job tools report the status and
+// output of the job to the session that started it. The metadata of jobs is persisted, so their status
+// survives reconnects of clients and restarts of the server.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// States of jobs
+const (
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+	StateCancelled = "cancelled"
+	// StateInterrupted is the state of jobs whose server stopped before they finished
+	StateInterrupted = "interrupted"
+)
+
+// Limits of jobs
+const (
+	// MaxRunningJobs is the maximum number of jobs a session runs at once
+	MaxRunningJobs = 4
+	// MaxJobs is the maximum number of jobs kept, finished jobs are dropped oldest first
+	MaxJobs = 64
+	// Retention is how long finished jobs are kept
+	Retention = time.Hour
+	// MaxOutputBytes is the size of the output kept per job, larger outputs keep their end
+	MaxOutputBytes = 1 << 20
+)
+
+const (
+	// heartbeatInterval is how often running jobs update their persisted metadata
+	heartbeatInterval = 30 * time.Second
+	// staleAfter is the age of the metadata of running jobs after which their server is considered stopped
+	staleAfter = 3 * heartbeatInterval
+	// cancelWait is how long job_cancel waits for a cancelled job to stop
+	cancelWait = 10 * time.Second
+)
+
+// idPattern matches job IDs
+var idPattern = regexp.MustCompile(`^job-[0-9a-f]{16}$`)
+
+// Job is the metadata of a job
+type Job struct {
+	ID   string `json:"id"`
+	Tool string `json:"tool"`
+	// Call describes the tool call, like kubectl_resources drain node-1
+	Call      string    `json:"call"`
+	State     string    `json:"state"`
+	StartedAt time.Time `json:"startedAt"`
+	// UpdatedAt is when the metadata was last persisted, running jobs update it periodically
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// Error is the error of failed and interrupted jobs
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"errorCode,omitempty"`
+	// OutputBytes is the size of the output of finished jobs
+	OutputBytes int `json:"outputBytes"`
+}
+
+// Func runs the tool call of a job until it completes or its context is cancelled
+type Func func(ctx context.Context) (*mcp.CallToolResult, error)
+
+// job is a job known to the manager
+type job struct {
+	Job
+	// owner is the session that started or adopted the job, empty once it closed
+	owner  string
+	output string
+	// local is set for jobs run by this server, whose output and cancellation are available
+	local  bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Manager runs jobs and keeps them for the sessions that own them
+type Manager struct {
+	// dir persists the metadata of jobs, empty keeps it in memory only
+	dir string
+	now func() time.Time
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// NewManager creates a manager persisting the metadata of jobs in dir, or keeping it in memory if dir is
+// empty. Expired jobs of earlier servers are removed from dir.
+func NewManager(dir string) (*Manager, error) {
+	m := &Manager{dir: dir, now: time.Now, jobs: make(map[string]*job)}
+	if err := m.cleanup(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Start runs a job for a session. The job keeps the values of ctx but isn't cancelled with it.
+func (m *Manager) Start(ctx context.Context, sessionID, tool, call string, run Func) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expire()
+
+	running := 0
+	for _, j := range m.jobs {
+		if j.owner == sessionID && j.State == StateRunning {
+			running++
+		}
+	}
+	if running >= MaxRunningJobs {
+		return Job{}, &toolerror.Error{
+			Code:    toolerror.Unavailable,
+			Message: fmt.Sprintf("the session already runs %d jobs, the maximum", running),
+			Hint:    "Wait for a job to finish, or cancel one with " + JobCancelToolName + ".",
+		}
+	}
+	if len(m.jobs) >= MaxJobs && !m.evict() {
+		return Job{}, &toolerror.Error{
+			Code:    toolerror.Unavailable,
+			Message: fmt.Sprintf("the server already runs %d jobs, the maximum", len(m.jobs)),
+			Hint:    "Wait for a job to finish and retry.",
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+	now := m.now()
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	j := &job{
+		Job:    Job{ID: id, Tool: tool, Call: call, State: StateRunning, StartedAt: now, UpdatedAt: now},
+		owner:  sessionID,
+		local:  true,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.jobs[id] = j
+	m.persist(j.Job)
+	logging.ForSession(sessionID).Info("job started", "job", id, "call", call)
+
+	go m.run(jobCtx, sessionID, j, run)
+	return j.Job, nil
+}
+
+// run runs the call of a job, and updates its persisted metadata until it finishes
+func (m *Manager) run(ctx context.Context, sessionID string, j *job, run Func) {
+	defer close(j.done)
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	type outcome struct {
+		result *mcp.CallToolResult
+		err    error
+	}
+	finished := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logging.ForSession(sessionID).Error("panic in job", "job", j.ID, "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+				finished <- outcome{err: fmt.Errorf("internal error in job: %v", recovered)}
+			}
+		}()
+		result, err := run(ctx)
+		finished <- outcome{result: result, err: err}
+	}()
+
+	for {
+		select {
+		case o := <-finished:
+			m.finish(j, o.result, o.err, ctx.Err() != nil)
+			return
+		case <-heartbeat.C:
+			m.mu.Lock()
+			j.UpdatedAt = m.now()
+			m.persist(j.Job)
+			m.mu.Unlock()
+		}
+	}
+}
+
+// finish records the outcome of a job
+func (m *Manager) finish(j *job, result *mcp.CallToolResult, err error, cancelled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer j.cancel()
+
+	output := resultText(result)
+	j.OutputBytes = len(output)
+	if len(output) > MaxOutputBytes {
+		output = fmt.Sprintf("[Output truncated to its last %d bytes]\n%s", MaxOutputBytes, output[len(output)-MaxOutputBytes:])
+	}
+	j.output = output
+
+	now := m.now()
+	j.UpdatedAt, j.FinishedAt = now, &now
+	switch {
+	case cancelled:
+		j.State = StateCancelled
+	case err != nil:
+		j.State, j.Error = StateFailed, err.Error()
+	case result != nil && result.IsError:
+		j.State, j.Error = StateFailed, output
+		if result.Meta != nil {
+			j.ErrorCode, _ = result.Meta.AdditionalFields[tools.ErrorCodeMetaKey].(string)
+		}
+	default:
+		j.State = StateSucceeded
+	}
+	m.persist(j.Job)
+	logging.ForSession(j.owner).Info("job finished", "job", j.ID, "state", j.State, "duration", now.Sub(j.StartedAt).Round(time.Second))
+}
+
+// Status returns a job of a session
+func (m *Manager) Status(sessionID, id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, err := m.lookup(sessionID, id)
+	if err != nil {
+		return Job{}, err
+	}
+	return j.Job, nil
+}
+
+// List returns the jobs of a session, oldest first
+func (m *Manager) List(sessionID string) []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expire()
+
+	var jobs []Job
+	for _, j := range m.jobs {
+		if j.owner == sessionID {
+			jobs = append(jobs, j.Job)
+		}
+	}
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].StartedAt.Before(jobs[b].StartedAt) })
+	return jobs
+}
+
+// Output returns a job of a session with its output, which is empty until the job finished
+func (m *Manager) Output(sessionID, id string) (Job, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, err := m.lookup(sessionID, id)
+	if err != nil {
+		return Job{}, "", err
+	}
+	if !j.local && j.State != StateRunning {
+		return Job{}, "", &toolerror.Error{
+			Code:    toolerror.NotFound,
+			Message: fmt.Sprintf("the output of job %s isn't available, it ran in an earlier server process", id),
+			Hint:    "Run the call again to see its output.",
+		}
+	}
+	return j.Job, j.output, nil
+}
+
+// Cancel cancels a running job of a session and waits for it to stop
+func (m *Manager) Cancel(sessionID, id string) (Job, error) {
+	m.mu.Lock()
+	j, err := m.lookup(sessionID, id)
+	if err != nil || j.State != StateRunning {
+		m.mu.Unlock()
+		if err != nil {
+			return Job{}, err
+		}
+		return j.Job, nil
+	}
+	if !j.local {
+		m.mu.Unlock()
+		return Job{}, toolerror.Newf(toolerror.InvalidArguments, "job %s runs in another server process and can't be cancelled here", id)
+	}
+	j.cancel()
+	m.mu.Unlock()
+
+	select {
+	case <-j.done:
+	case <-time.After(cancelWait):
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return j.Job, nil
+}
+
+// CloseSession detaches the jobs of a closed session. They keep running, and the next session asking for
+// them by ID adopts them.
+func (m *Manager) CloseSession(sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, j := range m.jobs {
+		if j.owner == sessionID {
+			j.owner = ""
+		}
+	}
+}
+
+// lookup returns a job of a session. Detached jobs and jobs of earlier servers are adopted by the session.
+func (m *Manager) lookup(sessionID, id string) (*job, error) {
+	m.expire()
+	j, ok := m.jobs[id]
+	if !ok {
+		j = m.load(id)
+	}
+	if j == nil || (j.owner != "" && j.owner != sessionID) {
+		return nil, &toolerror.Error{
+			Code:    toolerror.NotFound,
+			Message: fmt.Sprintf("job %s doesn't exist, has expired or belongs to another session", id),
+			Hint:    "Check the job ID, or list the jobs of the session with " + JobStatusToolName + " without job_id.",
+		}
+	}
+	j.owner = sessionID
+	return j, nil
+}
+
+// expire drops the jobs that finished before the retention period
+func (m *Manager) expire() {
+	cutoff := m.now().Add(-Retention)
+	for id, j := range m.jobs {
+		if j.FinishedAt != nil && j.FinishedAt.Before(cutoff) {
+			m.drop(id)
+		}
+	}
+}
+
+// evict drops the oldest finished job, false if all jobs are running
+func (m *Manager) evict() bool {
+	var oldest *job
+	for _, j := range m.jobs {
+		if j.FinishedAt != nil && (oldest == nil || j.FinishedAt.Before(*oldest.FinishedAt)) {
+			oldest = j
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	m.drop(oldest.ID)
+	return true
+}
+
+// drop forgets a job and removes its persisted metadata
+func (m *Manager) drop(id string) {
+	delete(m.jobs, id)
+	m.remove(id)
+}
+
+// newID returns a random job ID. IDs can't be guessed, since detached jobs are adopted by ID.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return "job-" + hex.EncodeToString(b), nil
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	if result == nil {
+		return ""
+	}
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,227 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// wait waits for a job of a manager to finish
+func wait(t *testing.T, m *Manager, id string) {
+	t.Helper()
+	m.mu.Lock()
+	j := m.jobs[id]
+	m.mu.Unlock()
+	select {
+	case <-j.done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("job %s didn't finish", id)
+	}
+}
+
+// blocking returns a job function running until its context is cancelled
+func blocking() Func {
+	return func(ctx context.Context) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+}
+
+func TestManager_Finish(t *testing.T) {
+	tests := []struct {
+		name          string
+		result        *mcp.CallToolResult
+		err           error
+		wantState     string
+		wantError     string
+		wantErrorCode string
+		wantOutput    string
+	}{
+		{
+			name:       "succeeded",
+			result:     mcp.NewToolResultText("node/node-1 drained"),
+			wantState:  StateSucceeded,
+			wantOutput: "node/node-1 drained",
+		},
+		{
+			name:      "failed",
+			err:       errors.New("connection refused"),
+			wantState: StateFailed,
+			wantError: "connection refused",
+		},
+		{
+			name:          "error result",
+			result:        tools.ErrorResult(toolerror.New(toolerror.Timeout, "command timed out")),
+			wantState:     StateFailed,
+			wantError:     "command timed out",
+			wantErrorCode: string(toolerror.Timeout),
+			wantOutput:    "command timed out",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewManager("")
+			if err != nil {
+				t.Fatalf("NewManager() unexpected error = %v", err)
+			}
+			started, err := m.Start(context.Background(), "s1", "kubectl_resources", "kubectl_resources drain node-1",
+				func(ctx context.Context) (*mcp.CallToolResult, error) { return tt.result, tt.err })
+			if err != nil {
+				t.Fatalf("Start() unexpected error = %v", err)
+			}
+			if !idPattern.MatchString(started.ID) || started.State != StateRunning {
+				t.Errorf("Start() = %+v, want a running job", started)
+			}
+			wait(t, m, started.ID)
+
+			job, output, err := m.Output("s1", started.ID)
+			if err != nil {
+				t.Fatalf("Output() unexpected error = %v", err)
+			}
+			if job.State != tt.wantState || !strings.Contains(job.Error, tt.wantError) || job.ErrorCode != tt.wantErrorCode {
+				t.Errorf("Output() job = %+v, want state %s, error %q and code %q", job, tt.wantState, tt.wantError, tt.wantErrorCode)
+			}
+			if !strings.Contains(output, tt.wantOutput) || job.OutputBytes != len(output) || job.FinishedAt == nil {
+				t.Errorf("Output() = %+v, %q, want output %q", job, output, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestManager_Ownership(t *testing.T) {
+	m, err := NewManager("")
+	if err != nil {
+		t.Fatalf("NewManager() unexpected error = %v", err)
+	}
+	job, err := m.Start(context.Background(), "s1", "cilium", "cilium connectivity test", blocking())
+	if err != nil {
+		t.Fatalf("Start() unexpected error = %v", err)
+	}
+
+	if _, err := m.Status("s2", job.ID); toolerror.CodeOf(err) != toolerror.NotFound {
+		t.Errorf("Status() of another session error = %v, want not found", err)
+	}
+	if jobs := m.List("s2"); len(jobs) != 0 {
+		t.Errorf("List() of another session = %+v, want none", jobs)
+	}
+	if _, err := m.Cancel("s2", job.ID); toolerror.CodeOf(err) != toolerror.NotFound {
+		t.Errorf("Cancel() of another session error = %v, want not found", err)
+	}
+
+	// A reconnecting client adopts the jobs of its closed session
+	m.CloseSession("s1")
+	if _, err := m.Status("s2", job.ID); err != nil {
+		t.Fatalf("Status() of a detached job unexpected error = %v", err)
+	}
+	if jobs := m.List("s2"); len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Errorf("List() after adoption = %+v, want %s", jobs, job.ID)
+	}
+	if _, err := m.Status("s1", job.ID); toolerror.CodeOf(err) != toolerror.NotFound {
+		t.Errorf("Status() of the closed session error = %v, want not found", err)
+	}
+
+	cancelled, err := m.Cancel("s2", job.ID)
+	if err != nil || cancelled.State != StateCancelled {
+		t.Errorf("Cancel() = %+v, %v, want a cancelled job", cancelled, err)
+	}
+}
+
+func TestManager_Limits(t *testing.T) {
+	m, err := NewManager("")
+	if err != nil {
+		t.Fatalf("NewManager() unexpected error = %v", err)
+	}
+	for i := 0; i < MaxRunningJobs; i++ {
+		if _, err := m.Start(context.Background(), "s1", "cilium", "cilium connectivity test", blocking()); err != nil {
+			t.Fatalf("Start() unexpected error = %v", err)
+		}
+	}
+	if _, err := m.Start(context.Background(), "s1", "cilium", "cilium connectivity test", blocking()); toolerror.CodeOf(err) != toolerror.Unavailable {
+		t.Errorf("Start() beyond the running jobs of a session error = %v, want unavailable", err)
+	}
+	if _, err := m.Start(context.Background(), "s2", "cilium", "cilium connectivity test", blocking()); err != nil {
+		t.Errorf("Start() in another session unexpected error = %v", err)
+	}
+	for _, job := range m.List("s1") {
+		if _, err := m.Cancel("s1", job.ID); err != nil {
+			t.Fatalf("Cancel() unexpected error = %v", err)
+		}
+	}
+
+	// Finished jobs expire after the retention period
+	now := time.Now()
+	m.now = func() time.Time { return now.Add(Retention + time.Minute) }
+	if jobs := m.List("s1"); len(jobs) != 0 {
+		t.Errorf("List() after the retention period = %+v, want none", jobs)
+	}
+	if jobs := m.List("s2"); len(jobs) != 1 {
+		t.Errorf("List() of running jobs after the retention period = %+v, want one", jobs)
+	}
+}
+
+func TestManager_Persistence(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewManager(dir)
+	if err != nil {
+		t.Fatalf("NewManager() unexpected error = %v", err)
+	}
+	finished, err := m.Start(context.Background(), "s1", "cilium", "cilium connectivity test",
+		func(ctx context.Context) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("all tests passed"), nil
+		})
+	if err != nil {
+		t.Fatalf("Start() unexpected error = %v", err)
+	}
+	wait(t, m, finished.ID)
+	running, err := m.Start(context.Background(), "s1", "kubectl_resources", "kubectl_resources drain node-1", blocking())
+	if err != nil {
+		t.Fatalf("Start() unexpected error = %v", err)
+	}
+
+	// A new server, like one started for a reconnecting stdio client, reports the jobs of the old one
+	restarted, err := NewManager(dir)
+	if err != nil {
+		t.Fatalf("NewManager() unexpected error = %v", err)
+	}
+	job, err := restarted.Status("s2", finished.ID)
+	if err != nil || job.State != StateSucceeded || job.OutputBytes != 0 {
+		t.Errorf("Status() of a finished job = %+v, %v, want succeeded without output", job, err)
+	}
+	if _, _, err := restarted.Output("s2", finished.ID); toolerror.CodeOf(err) != toolerror.NotFound {
+		t.Errorf("Output() of a job of another server error = %v, want not found", err)
+	}
+	job, err = restarted.Status("s2", running.ID)
+	if err != nil || job.State != StateRunning {
+		t.Errorf("Status() of a job running elsewhere = %+v, %v, want running", job, err)
+	}
+	if _, err := restarted.Cancel("s2", running.ID); toolerror.CodeOf(err) != toolerror.InvalidArguments {
+		t.Errorf("Cancel() of a job running elsewhere error = %v, want invalid arguments", err)
+	}
+
+	// Jobs whose server stopped updating them are interrupted
+	restarted.now = func() time.Time { return time.Now().Add(staleAfter + time.Minute) }
+	job, err = restarted.Status("s2", running.ID)
+	if err != nil || job.State != StateInterrupted || job.Error == "" {
+		t.Errorf("Status() of a stale job = %+v, %v, want interrupted", job, err)
+	}
+	if _, err := m.Cancel("s1", running.ID); err != nil {
+		t.Fatalf("Cancel() unexpected error = %v", err)
+	}
+
+	// Expired jobs are removed when a server starts
+	m.now = func() time.Time { return time.Now().Add(2 * Retention) }
+	if err := m.cleanup(); err != nil {
+		t.Fatalf("cleanup() unexpected error = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("cleanup() kept %d files, want none", len(entries))
+	}
+}
@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// BackgroundParam runs a tool call as a background job
+const BackgroundParam = "background"
+
+// JobIDMetaKey is the _meta key of the job ID in the results of calls started as jobs
+const JobIDMetaKey = "jobId"
+
+// longOperations lists the operations of each tool that can run as jobs. Operations of kubectl tools
+// are "operation resource" for rollout and the operation otherwise, those of the other tools are the
+// first word of their command. Only operations the security validator allows at some access level are
+// listed, helm and cilium installs and upgrades are denied at every level.
+var longOperations = map[string][]string{
+	"kubectl_resources": {"drain"},
+	"kubectl_workloads": {"rollout status"},
+	"cilium":            {"connectivity"},
+}
+
+// Supports checks if a tool, or the tool a variant was split from, has operations that can run as jobs
+func Supports(toolName string) bool {
+	toolName, _ = tools.BaseToolName(toolName)
+	_, ok := longOperations[toolName]
+	return ok
+}
+
+// WithBackground adds the background parameter to the definition of a tool supporting jobs
+func WithBackground(toolName string) mcp.ToolOption {
+	toolName, _ = tools.BaseToolName(toolName)
+	return mcp.WithBoolean(BackgroundParam,
+		mcp.Description(fmt.Sprintf("Run the call as a background job and return its ID at once, for operations that may take minutes (%s). "+
+			"Follow the job with %s, %s and %s", strings.Join(longOperations[toolName], ", "), JobStatusToolName, JobOutputToolName, JobCancelToolName)),
+	)
+}
+
+// Middleware runs calls with the background parameter as jobs of the calling session
+func (m *Manager) Middleware() tools.Middleware {
+	return func(next tools.Handler) tools.Handler {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args, _ := req.Params.Arguments.(map[string]interface{})
+			if background, _ := args[BackgroundParam].(bool); !background {
+				return next(ctx, req)
+			}
+			toolName, _ := tools.BaseToolName(req.Params.Name)
+			if !contains(longOperations[toolName], operationOf(toolName, args)) {
+				return tools.ErrorResult(toolerror.Newf(toolerror.InvalidArguments, "%s is only supported for these operations of %s: %s",
+					BackgroundParam, req.Params.Name, strings.Join(longOperations[toolName], ", "))), nil
+			}
+
+			jobArgs := make(map[string]interface{}, len(args))
+			for key, value := range args {
+				if key != BackgroundParam {
+					jobArgs[key] = value
+				}
+			}
+			jobReq := req
+			jobReq.Params.Arguments = jobArgs
+
+			var sessionID string
+			if session := server.ClientSessionFromContext(ctx); session != nil {
+				sessionID = session.SessionID()
+			}
+			job, err := m.Start(ctx, sessionID, req.Params.Name, describeCall(jobReq), func(ctx context.Context) (*mcp.CallToolResult, error) {
+				return next(ctx, jobReq)
+			})
+			if err != nil {
+				return tools.ErrorResult(err), nil
+			}
+
+			result := mcp.NewToolResultText(fmt.Sprintf("Started job %s (%s). Check its status with %s, and read its output with %s once it finished.",
+				job.ID, job.Call, JobStatusToolName, JobOutputToolName))
+			result.Meta = mcp.NewMetaFromMap(map[string]any{JobIDMetaKey: job.ID})
+			return result, nil
+		}
+	}
+}
+
+// operationOf returns the operation of a call of a tool supporting jobs
+func operationOf(toolName string, args map[string]interface{}) string {
+	if command, ok := args["command"].(string); ok {
+		return security.ExtractOperation(command, toolName)
+	}
+	operation, _ := args["operation"].(string)
+	if resource, _ := args["resource"].(string); operation == "rollout" {
+		return operation + " " + resource
+	}
+	return operation
+}
+
+// describeCall describes a tool call, like kubectl_resources drain node-1 or cilium connectivity test
+func describeCall(req mcp.CallToolRequest) string {
+	args, _ := req.Params.Arguments.(map[string]interface{})
+	toolName, _ := tools.BaseToolName(req.Params.Name)
+	parts := []string{req.Params.Name}
+	for _, name := range []string{"operation", "resource", "name", "command", "args", "namespace"} {
+		if value, ok := args[name].(string); ok && value != "" {
+			switch name {
+			case "namespace":
+				value = name + "=" + value
+			case "command":
+				// Commands start with the binary, which is named like the tool
+				value = strings.TrimPrefix(value, toolName+" ")
+			}
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// contains checks if a string is in a list
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
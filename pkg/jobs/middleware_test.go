@@ -0,0 +1,124 @@
+package jobs
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestManager_Middleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		tool       string
+		args       map[string]interface{}
+		wantJob    bool
+		wantCall   string
+		wantErrMsg string
+	}{
+		{
+			name:     "drain",
+			tool:     "kubectl_resources",
+			args:     map[string]interface{}{"operation": "drain", "resource": "", "name": "node-1", "background": true},
+			wantJob:  true,
+			wantCall: "kubectl_resources drain node-1",
+		},
+		{
+			name:     "rollout status",
+			tool:     "kubectl_workloads",
+			args:     map[string]interface{}{"operation": "rollout", "resource": "status", "name": "deployment/web", "namespace": "shop", "background": true},
+			wantJob:  true,
+			wantCall: "kubectl_workloads rollout status deployment/web namespace=shop",
+		},
+		{
+			name:     "cilium connectivity test",
+			tool:     "cilium",
+			args:     map[string]interface{}{"command": "cilium connectivity test --test dns", "background": true},
+			wantJob:  true,
+			wantCall: "cilium connectivity test --test dns",
+		},
+		{
+			name:       "cilium install",
+			tool:       "cilium",
+			args:       map[string]interface{}{"command": "cilium install", "background": true},
+			wantErrMsg: "background is only supported for these operations of cilium: connectivity",
+		},
+		{
+			name: "foreground",
+			tool: "kubectl_resources",
+			args: map[string]interface{}{"operation": "drain", "resource": "", "name": "node-1"},
+		},
+		{
+			name:       "unsupported operation",
+			tool:       "kubectl_resources",
+			args:       map[string]interface{}{"operation": "get", "resource": "pods", "background": true},
+			wantErrMsg: "background is only supported for these operations of kubectl_resources: drain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewManager("")
+			if err != nil {
+				t.Fatalf("NewManager() unexpected error = %v", err)
+			}
+			calls := make(chan map[string]interface{}, 1)
+			handler := m.Middleware()(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls <- req.Params.Arguments.(map[string]interface{})
+				return mcp.NewToolResultText("done"), nil
+			})
+			req := mcp.CallToolRequest{}
+			req.Params.Name = tt.tool
+			req.Params.Arguments = tt.args
+
+			result, err := handler(context.Background(), req)
+			if err != nil {
+				t.Fatalf("handler() unexpected error = %v", err)
+			}
+			if tt.wantErrMsg != "" {
+				if !result.IsError || !strings.Contains(resultText(result), tt.wantErrMsg) {
+					t.Errorf("handler() = %q, want error %q", resultText(result), tt.wantErrMsg)
+				}
+				return
+			}
+			args := <-calls
+			if _, ok := args[BackgroundParam]; ok {
+				t.Errorf("the call received the background parameter: %v", args)
+			}
+			if !tt.wantJob {
+				if result.Meta != nil {
+					t.Errorf("handler() started a job: %+v", result.Meta)
+				}
+				return
+			}
+
+			id, _ := result.Meta.AdditionalFields[JobIDMetaKey].(string)
+			wait(t, m, id)
+			job, output, err := m.Output("", id)
+			if err != nil || job.Call != tt.wantCall || job.Tool != tt.tool || output != "done" {
+				t.Errorf("Output() = %+v, %q, %v, want call %q with output done", job, output, err, tt.wantCall)
+			}
+		})
+	}
+}
+
+func TestLongOperationsAreAllowed(t *testing.T) {
+	// Every operation offered as a job must pass the security validator at some access level
+	secConfig := security.NewSecurityConfig()
+	secConfig.AccessLevel = security.AccessLevelAdmin
+	validator := security.NewValidator(secConfig)
+
+	for toolName, operations := range longOperations {
+		commandType := toolName
+		if strings.HasPrefix(toolName, "kubectl_") {
+			commandType = security.CommandTypeKubectl
+		}
+		for _, operation := range operations {
+			if err := validator.ValidateCommand(operation+" x", commandType); err != nil {
+				t.Errorf("%s operation %q is denied at admin level: %v", toolName, operation, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+)
+
+// errInterrupted is the error of jobs whose server stopped before they finished
+var errInterrupted = errors.New("the server running the job stopped before it finished")
+
+// path returns the file persisting the metadata of a job
+func (m *Manager) path(id string) string {
+	return filepath.Join(m.dir, id+".json")
+}
+
+// persist writes the metadata of a job. Files are replaced atomically, so servers sharing the directory
+// never read partial metadata.
+func (m *Manager) persist(job Job) {
+	if m.dir == "" {
+		return
+	}
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err == nil {
+		tmp := m.path(job.ID) + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o600); err == nil {
+			err = os.Rename(tmp, m.path(job.ID))
+		}
+	}
+	if err != nil {
+		logging.Logger().Warn("failed to persist job metadata", "job", job.ID, "error", err)
+	}
+}
+
+// remove removes the persisted metadata of a job
+func (m *Manager) remove(id string) {
+	if m.dir == "" {
+		return
+	}
+	if err := os.Remove(m.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logging.Logger().Warn("failed to remove job metadata", "job", id, "error", err)
+	}
+}
+
+// read reads the persisted metadata of a job
+func (m *Manager) read(id string) (Job, error) {
+	var job Job
+	data, err := os.ReadFile(m.path(id))
+	if err == nil {
+		err = json.Unmarshal(data, &job)
+	}
+	return job, err
+}
+
+// load returns a job persisted by another server, nil if there is none. Finished jobs and jobs of stopped
+// servers are kept by the manager, jobs still running elsewhere are read again on every lookup.
+func (m *Manager) load(id string) *job {
+	if m.dir == "" || !idPattern.MatchString(id) {
+		return nil
+	}
+	persisted, err := m.read(id)
+	if err != nil || persisted.ID != id {
+		return nil
+	}
+	// The output stays with the server that ran the job
+	j := &job{Job: persisted}
+	j.OutputBytes = 0
+	if j.State != StateRunning {
+		m.jobs[id] = j
+		return j
+	}
+	if m.now().Sub(j.UpdatedAt) <= staleAfter {
+		return j
+	}
+
+	j.State, j.Error, j.FinishedAt = StateInterrupted, errInterrupted.Error(), &j.UpdatedAt
+	m.jobs[id] = j
+	m.persist(j.Job)
+	return j
+}
+
+// cleanup creates the directory of persisted jobs and removes the jobs that expired
+func (m *Manager) cleanup() error {
+	if m.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read jobs directory: %w", err)
+	}
+
+	cutoff := m.now().Add(-Retention)
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || !idPattern.MatchString(id) {
+			continue
+		}
+		job, err := m.read(id)
+		if err != nil || job.UpdatedAt.Before(cutoff) {
+			m.remove(id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package jobs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Names of the job tools
+const (
+	JobStatusToolName = "job_status"
+	JobOutputToolName = "job_output"
+	JobCancelToolName = "job_cancel"
+)
+
+// DefaultOutputLines is the number of lines returned by a job_output call without limit
+const DefaultOutputLines = 500
+
+// jobIDDescription describes the job_id parameter
+const jobIDDescription = "ID of the job, as returned by the call that started it"
+
+// RegisterJobTools returns the definitions of the job tools
+func RegisterJobTools() []mcp.Tool {
+	return []mcp.Tool{
+		mcp.NewTool(JobStatusToolName,
+			mcp.WithDescription("Get the status of a background job, or list the jobs of the session without job_id. "+
+				"Jobs run while the client waits for other calls, poll them every 10 to 30 seconds"),
+			tools.WithAnnotations(tools.ReadOnlyAnnotations),
+			mcp.WithString("job_id", mcp.Description(jobIDDescription+", omit it to list the jobs of the session")),
+		),
+		mcp.NewTool(JobOutputToolName,
+			mcp.WithDescription("Read the output of a finished background job"),
+			tools.WithAnnotations(tools.ReadOnlyAnnotations),
+			mcp.WithString("job_id", mcp.Required(), mcp.Description(jobIDDescription)),
+			mcp.WithNumber("offset",
+				mcp.Description("Number of lines to skip"),
+				mcp.Min(0),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description(fmt.Sprintf("Maximum number of lines to return, default %d", DefaultOutputLines)),
+				mcp.Min(1),
+			),
+		),
+		mcp.NewTool(JobCancelToolName,
+			mcp.WithDescription("Cancel a running background job. Its command is stopped, which may leave an operation like a drain or an upgrade half done"),
+			tools.WithAnnotations(tools.Annotations{Destructive: true, Idempotent: true}),
+			mcp.WithString("job_id", mcp.Required(), mcp.Description(jobIDDescription)),
+		),
+	}
+}
+
+// Executor runs the job tools, telling them apart by the injected tool name
+type Executor struct {
+	manager *Manager
+}
+
+// NewExecutor creates an executor of the job tools of a manager
+func NewExecutor(manager *Manager) *Executor {
+	return &Executor{manager: manager}
+}
+
+// Execute runs a job tool and returns its text
+func (e *Executor) Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	result, err := e.ExecuteStructured(params, cfg)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// ExecuteStructured runs a job tool. job_status and job_cancel return the jobs as structured content.
+func (e *Executor) ExecuteStructured(params map[string]interface{}, cfg *config.ConfigData) (*tools.Result, error) {
+	sessionID := tools.SessionID(params)
+	id, _ := params["job_id"].(string)
+	toolName, _ := params["_tool_name"].(string)
+	if id == "" && toolName != JobStatusToolName {
+		return nil, toolerror.New(toolerror.InvalidArguments, "job_id is required")
+	}
+
+	switch toolName {
+	case JobStatusToolName:
+		if id == "" {
+			jobs := e.manager.List(sessionID)
+			return &tools.Result{Text: describeJobs(jobs, e.manager.now()), Structured: &JobList{Jobs: jobs}}, nil
+		}
+		job, err := e.manager.Status(sessionID, id)
+		if err != nil {
+			return nil, err
+		}
+		return &tools.Result{Text: describeJob(job, e.manager.now()), Structured: job}, nil
+	case JobOutputToolName:
+		return e.output(sessionID, id, params)
+	case JobCancelToolName:
+		job, err := e.manager.Cancel(sessionID, id)
+		if err != nil {
+			return nil, err
+		}
+		return &tools.Result{Text: describeJob(job, e.manager.now()), Structured: job}, nil
+	default:
+		return nil, toolerror.Newf(toolerror.InvalidArguments, "unknown tool: %s", toolName)
+	}
+}
+
+// JobList is the structured content of job_status without job_id
+type JobList struct {
+	Jobs []Job `json:"jobs"`
+}
+
+// output returns a range of the lines of the output of a finished job
+func (e *Executor) output(sessionID, id string, params map[string]interface{}) (*tools.Result, error) {
+	offset, err := intParam(params, "offset", 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := intParam(params, "limit", DefaultOutputLines)
+	if err != nil {
+		return nil, err
+	}
+	job, output, err := e.manager.Output(sessionID, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.State == StateRunning {
+		return &tools.Result{Text: describeJob(job, e.manager.now()) + ". Its output is available once it finished."}, nil
+	}
+	if output == "" {
+		return &tools.Result{Text: fmt.Sprintf("Job %s %s without output", job.ID, job.State)}, nil
+	}
+
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	total := len(lines)
+	if offset > total {
+		offset = total
+	}
+	end := min(offset+limit, total)
+	text := strings.Join(lines[offset:end], "\n")
+	if offset > 0 || end < total {
+		text += fmt.Sprintf("\n\n[Lines %d-%d of %d.", offset+1, end, total)
+		if end < total {
+			text += fmt.Sprintf(" Call again with offset=%d for more.", end)
+		}
+		text += "]"
+	}
+	return &tools.Result{Text: text}, nil
+}
+
+// describeJob describes the state of a job for the model
+func describeJob(job Job, now time.Time) string {
+	text := fmt.Sprintf("Job %s (%s) ", job.ID, job.Call)
+	switch job.State {
+	case StateRunning:
+		return text + fmt.Sprintf("is running for %s", now.Sub(job.StartedAt).Round(time.Second))
+	case StateInterrupted:
+		return text + "was interrupted: " + job.Error
+	}
+
+	text += fmt.Sprintf("%s after %s", job.State, job.FinishedAt.Sub(job.StartedAt).Round(time.Second))
+	if job.State == StateFailed {
+		message, _, _ := strings.Cut(job.Error, "\n")
+		text += ": " + message
+	}
+	if job.OutputBytes > 0 {
+		text += fmt.Sprintf(". Read its %d bytes of output with %s", job.OutputBytes, JobOutputToolName)
+	}
+	return text
+}
+
+// describeJobs describes the jobs of a session for the model
+func describeJobs(jobs []Job, now time.Time) string {
+	if len(jobs) == 0 {
+		return "The session has no jobs"
+	}
+	descriptions := make([]string, len(jobs))
+	for i, job := range jobs {
+		descriptions[i] = describeJob(job, now)
+	}
+	return strings.Join(descriptions, "\n")
+}
+
+// intParam returns a non-negative number argument, or a default if it isn't given
+func intParam(params map[string]interface{}, name string, defaultValue int) (int, error) {
+	value, ok := params[name]
+	if !ok || value == nil {
+		return defaultValue, nil
+	}
+	number, ok := value.(float64)
+	if !ok || number < 0 {
+		return 0, toolerror.Newf(toolerror.InvalidArguments, "%s must be a non-negative number", name)
+	}
+	return int(number), nil
+}
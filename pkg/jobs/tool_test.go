@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestExecutor(t *testing.T) {
+	m, err := NewManager("")
+	if err != nil {
+		t.Fatalf("NewManager() unexpected error = %v", err)
+	}
+	job, err := m.Start(context.Background(), "s1", "cilium", "cilium connectivity test",
+		func(ctx context.Context) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("test 1 passed\ntest 2 passed\ntest 3 passed\n"), nil
+		})
+	if err != nil {
+		t.Fatalf("Start() unexpected error = %v", err)
+	}
+	wait(t, m, job.ID)
+	executor := NewExecutor(m)
+	cfg := config.NewConfig()
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		want    string
+		wantErr toolerror.Code
+	}{
+		{
+			name:   "status",
+			params: map[string]interface{}{"_tool_name": JobStatusToolName, "job_id": job.ID},
+			want:   "Read its 42 bytes of output with job_output",
+		},
+		{
+			name:   "list",
+			params: map[string]interface{}{"_tool_name": JobStatusToolName},
+			want:   "Job " + job.ID + " (cilium connectivity test) succeeded",
+		},
+		{
+			name:   "output",
+			params: map[string]interface{}{"_tool_name": JobOutputToolName, "job_id": job.ID},
+			want:   "test 1 passed\ntest 2 passed\ntest 3 passed",
+		},
+		{
+			name:   "output page",
+			params: map[string]interface{}{"_tool_name": JobOutputToolName, "job_id": job.ID, "offset": float64(1), "limit": float64(1)},
+			want:   "test 2 passed\n\n[Lines 2-2 of 3. Call again with offset=2 for more.]",
+		},
+		{
+			name:   "cancel finished job",
+			params: map[string]interface{}{"_tool_name": JobCancelToolName, "job_id": job.ID},
+			want:   "succeeded",
+		},
+		{
+			name:    "missing job ID",
+			params:  map[string]interface{}{"_tool_name": JobOutputToolName},
+			wantErr: toolerror.InvalidArguments,
+		},
+		{
+			name:    "unknown job",
+			params:  map[string]interface{}{"_tool_name": JobStatusToolName, "job_id": "job-0000000000000000"},
+			wantErr: toolerror.NotFound,
+		},
+		{
+			name:    "job of another session",
+			params:  map[string]interface{}{"_tool_name": JobStatusToolName, "job_id": job.ID, tools.SessionIDParam: "s2"},
+			wantErr: toolerror.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := tt.params[tools.SessionIDParam]; !ok {
+				tt.params[tools.SessionIDParam] = "s1"
+			}
+			result, err := executor.ExecuteStructured(tt.params, cfg)
+			if tt.wantErr != "" {
+				if toolerror.CodeOf(err) != tt.wantErr {
+					t.Errorf("ExecuteStructured() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteStructured() unexpected error = %v", err)
+			}
+			if !strings.Contains(result.Text, tt.want) {
+				t.Errorf("ExecuteStructured() = %q, want %q", result.Text, tt.want)
+			}
+		})
+	}
+}
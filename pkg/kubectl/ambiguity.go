@@ -0,0 +1,324 @@
+package kubectl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// batchItemParam marks the operations of a batch, which run concurrently and never ask the user
+const batchItemParam = "_batch_item"
+
+// defaultContainerAnnotation names the container kubectl picks for pods with several containers
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// clusterScopedResources lists the resource names, short names and kinds of cluster scoped resources
+var clusterScopedResources = map[string]bool{
+	"node": true, "nodes": true, "no": true,
+	"namespace": true, "namespaces": true, "ns": true,
+	"persistentvolume": true, "persistentvolumes": true, "pv": true,
+	"storageclass": true, "storageclasses": true, "sc": true,
+	"clusterrole": true, "clusterroles": true,
+	"clusterrolebinding": true, "clusterrolebindings": true,
+	"customresourcedefinition": true, "customresourcedefinitions": true, "crd": true, "crds": true,
+	"priorityclass": true, "priorityclasses": true, "pc": true,
+	"ingressclass": true, "ingressclasses": true,
+	"runtimeclass": true, "runtimeclasses": true,
+	"csidriver": true, "csidrivers": true,
+	"csinode": true, "csinodes": true,
+	"volumeattachment": true, "volumeattachments": true,
+	"apiservice": true, "apiservices": true,
+	"mutatingwebhookconfiguration": true, "mutatingwebhookconfigurations": true,
+	"validatingwebhookconfiguration": true, "validatingwebhookconfigurations": true,
+	"certificatesigningrequest": true, "certificatesigningrequests": true, "csr": true,
+}
+
+// nodeOperations are the operations of kubectl_resources that only apply to nodes
+var nodeOperations = map[string]bool{"cordon": true, "uncordon": true, "drain": true, "taint": true}
+
+// ambiguity is a choice kubectl would fail on or guess, which the user is asked to make
+type ambiguity struct {
+	// message asks the user to choose
+	message string
+	// problem describes the ambiguity in errors
+	problem string
+	// param is the parameter the model sets to a choice when the user can't be asked
+	param   string
+	choices []string
+	// resolve continues the call with the chosen value
+	resolve func(choice string) map[string]interface{}
+}
+
+// SetElicitor sets the elicitor asking users to resolve ambiguous calls. Without one, ambiguous calls
+// fail with an error listing the choices.
+func (e *KubectlToolExecutor) SetElicitor(elicit tools.Elicitor) {
+	e.elicit = elicit
+}
+
+// resolveAmbiguity checks a command for an ambiguous pod container, kubeconfig context or namespace. The
+// user chooses with elicitation and the parameters of the call are returned with the choice, nil if the
+// command isn't ambiguous.
+func (e *KubectlToolExecutor) resolveAmbiguity(toolName, operation, fullCommand string, params map[string]interface{}, cfg *config.ConfigData) (map[string]interface{}, error) {
+	parsed, err := parseCommand(fullCommand, cfg.Kubeconfig)
+	if err != nil {
+		return nil, nil
+	}
+
+	var found *ambiguity
+	for _, check := range []func(string, string, parsedCommand, map[string]interface{}, *config.ConfigData) *ambiguity{
+		e.ambiguousContext, e.ambiguousNamespace, e.ambiguousContainer,
+	} {
+		if found = check(toolName, operation, parsed, params, cfg); found != nil {
+			break
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+
+	sessionID := tools.SessionID(params)
+	err = tools.ErrElicitationUnavailable
+	if e.elicit != nil && params[batchItemParam] == nil {
+		var choice string
+		choice, err = e.elicit(sessionID, found.message, found.choices)
+		if err == nil {
+			if !contains(found.choices, choice) {
+				return nil, toolerror.Newf(toolerror.InvalidArguments, "the client returned '%s', which isn't one of the choices", choice)
+			}
+			logging.ForSession(sessionID).Debug("user resolved an ambiguous call", "tool", toolName, "operation", operation, "choice", choice)
+			return found.resolve(choice), nil
+		}
+	}
+
+	message := fmt.Sprintf("%s: %s", found.problem, strings.Join(found.choices, ", "))
+	switch {
+	case errors.Is(err, tools.ErrElicitationDeclined):
+		message += ", and the user declined to choose"
+	case !errors.Is(err, tools.ErrElicitationUnavailable):
+		logging.ForSession(sessionID).Warn("asking the user to resolve an ambiguous call failed", "tool", toolName, "error", err)
+	}
+	return nil, &toolerror.Error{
+		Code:    toolerror.InvalidArguments,
+		Message: message,
+		Hint:    fmt.Sprintf("Call again with %s set to one of: %s.", found.param, strings.Join(found.choices, ", ")),
+	}
+}
+
+// ambiguousContext checks if the --context of a command isn't a context of the kubeconfig but is part of
+// the names of several
+func (e *KubectlToolExecutor) ambiguousContext(toolName, operation string, parsed parsedCommand, params map[string]interface{}, cfg *config.ConfigData) *ambiguity {
+	context := parsed.flags["--context"]
+	if context == "" {
+		return nil
+	}
+	contexts, err := e.contexts(cfg.Kubeconfig)
+	if err != nil || contains(contexts, context) {
+		return nil
+	}
+	var matches []string
+	for _, name := range contexts {
+		if strings.Contains(strings.ToLower(name), strings.ToLower(context)) {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) < 2 {
+		return nil
+	}
+	sort.Strings(matches)
+
+	return &ambiguity{
+		message: fmt.Sprintf("The context %q matches several contexts of the kubeconfig. Which context should %s %s use?", context, toolName, operation),
+		problem: fmt.Sprintf("context '%s' matches several contexts of the kubeconfig", context),
+		param:   ContextParam,
+		choices: matches,
+		resolve: func(choice string) map[string]interface{} {
+			return withParam(params, ContextParam, choice)
+		},
+	}
+}
+
+// ambiguousNamespace checks if a namespaced command has no namespace while several namespaces are allowed.
+// kubectl would use the namespace of the kubeconfig context, which may not be one of them.
+func (e *KubectlToolExecutor) ambiguousNamespace(toolName, operation string, parsed parsedCommand, params map[string]interface{}, cfg *config.ConfigData) *ambiguity {
+	if parsed.namespace != "" || !isNamespaced(toolName, operation, parsed) || cfg.SecurityConfig == nil {
+		return nil
+	}
+	if _, ok := parsed.flags["--filename"]; ok || params["manifest"] != nil {
+		return nil
+	}
+	choices := e.namespaceChoices(params, cfg)
+	if len(choices) < 2 {
+		return nil
+	}
+	return &ambiguity{
+		message: fmt.Sprintf("No namespace was given and several namespaces are allowed. Which namespace should %s %s use?", toolName, operation),
+		problem: "no namespace was given and several namespaces are allowed",
+		param:   NamespaceParam,
+		choices: choices,
+		resolve: func(choice string) map[string]interface{} {
+			return withParam(params, NamespaceParam, choice)
+		},
+	}
+}
+
+// isNamespaced checks if a command of a tool with a namespace parameter acts on namespaced objects
+func isNamespaced(toolName, operation string, parsed parsedCommand) bool {
+	if !contains(toolTypedParams[toolName], NamespaceParam) || nodeOperations[operation] {
+		return false
+	}
+	for _, arg := range parsed.positional {
+		if arg == "--" {
+			break
+		}
+		kind, _, _ := strings.Cut(arg, "/")
+		kind, _, _ = strings.Cut(strings.ToLower(kind), ".")
+		if clusterScopedResources[kind] {
+			return false
+		}
+	}
+	return true
+}
+
+// namespaceChoices lists the allowed namespaces. Namespaces allowed by patterns are listed from the cluster.
+func (e *KubectlToolExecutor) namespaceChoices(params map[string]interface{}, cfg *config.ConfigData) []string {
+	names, patterns := cfg.SecurityConfig.AllowedNamespaces()
+	if patterns {
+		output, err := e.execute(map[string]interface{}{
+			"_tool_name":         "kubectl_resources",
+			"operation":          "get",
+			"resource":           "namespaces",
+			"args":               "-o name",
+			tools.SessionIDParam: params[tools.SessionIDParam],
+		}, cfg)
+		if err == nil {
+			for _, line := range strings.Split(output, "\n") {
+				name := strings.TrimPrefix(strings.TrimSpace(line), "namespace/")
+				if name != "" && !strings.Contains(name, " ") && cfg.SecurityConfig.IsNamespaceAllowed(name) {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+
+	sort.Strings(names)
+	var choices []string
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			choices = append(choices, name)
+		}
+	}
+	return choices
+}
+
+// ambiguousContainer checks if logs or exec name a pod with several containers and no default container
+func (e *KubectlToolExecutor) ambiguousContainer(toolName, operation string, parsed parsedCommand, params map[string]interface{}, cfg *config.ConfigData) *ambiguity {
+	if toolName != "kubectl_diagnostics" || (operation != "logs" && operation != "exec") || len(parsed.positional) < 2 {
+		return nil
+	}
+	for _, flag := range []string{"--container", "--all-containers", "--selector"} {
+		if _, ok := parsed.flags[flag]; ok {
+			return nil
+		}
+	}
+	pod := parsed.positional[1]
+	if kind, name, ok := strings.Cut(pod, "/"); ok {
+		if kind != "pod" && kind != "pods" && kind != "po" {
+			return nil
+		}
+		pod = name
+	}
+
+	containers := e.podContainers(pod, parsed, params, cfg)
+	if len(containers) < 2 {
+		return nil
+	}
+	location := "pod " + pod
+	if parsed.namespace != "" {
+		location += " in namespace " + parsed.namespace
+	}
+	return &ambiguity{
+		message: fmt.Sprintf("The %s has several containers. Which container should %s use?", location, operation),
+		problem: fmt.Sprintf("%s has several containers", location),
+		param:   ContainerParam,
+		choices: containers,
+		resolve: func(choice string) map[string]interface{} {
+			return withParam(params, ContainerParam, choice)
+		},
+	}
+}
+
+// podContainers lists the containers of a pod, nil if the pod can't be read or has a default container
+func (e *KubectlToolExecutor) podContainers(pod string, parsed parsedCommand, params map[string]interface{}, cfg *config.ConfigData) []string {
+	query := map[string]interface{}{
+		"_tool_name":         "kubectl_resources",
+		"operation":          "get",
+		"resource":           "pods",
+		NameParam:            pod,
+		"args":               "-o json",
+		tools.SessionIDParam: params[tools.SessionIDParam],
+	}
+	if context := parsed.flags["--context"]; context != "" {
+		query[ContextParam] = context
+	}
+	if parsed.namespace != "" {
+		query[NamespaceParam] = parsed.namespace
+	}
+	output, err := e.execute(query, cfg)
+	if err != nil {
+		return nil
+	}
+
+	var object struct {
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+		Spec struct {
+			Containers []struct {
+				Name string `json:"name"`
+			} `json:"containers"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal([]byte(output), &object); err != nil || object.Metadata.Annotations[defaultContainerAnnotation] != "" {
+		return nil
+	}
+	var containers []string
+	for _, container := range object.Spec.Containers {
+		containers = append(containers, container.Name)
+	}
+	return containers
+}
+
+// withParam returns a copy of params with a parameter set
+func withParam(params map[string]interface{}, name string, value interface{}) map[string]interface{} {
+	updated := make(map[string]interface{}, len(params)+1)
+	for key, v := range params {
+		updated[key] = v
+	}
+	updated[name] = value
+	return updated
+}
+
+// kubeconfigContexts lists the contexts of a kubeconfig, the default one when kubeconfig is empty
+func kubeconfigContexts(kubeconfig string) ([]string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	loaded, err := rules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	contexts := make([]string, 0, len(loaded.Contexts))
+	for name := range loaded.Contexts {
+		contexts = append(contexts, name)
+	}
+	return contexts, nil
+}
@@ -0,0 +1,192 @@
+package kubectl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// podBackend answers get pods with a pod and records the other requests
+type podBackend struct {
+	pod      string
+	requests []BackendRequest
+}
+
+func (b *podBackend) Supports(req BackendRequest) bool {
+	return true
+}
+
+func (b *podBackend) Execute(req BackendRequest) (string, error) {
+	if req.Operation == "get" && req.Resource == "pods" && strings.Contains(req.Args, "-o json") {
+		return b.pod, nil
+	}
+	b.requests = append(b.requests, req)
+	return "output", nil
+}
+
+const (
+	twoContainerPod     = `{"kind":"Pod","metadata":{"name":"web"},"spec":{"containers":[{"name":"app"},{"name":"sidecar"}]}}`
+	defaultContainerPod = `{"kind":"Pod","metadata":{"name":"web","annotations":{"kubectl.kubernetes.io/default-container":"app"}},"spec":{"containers":[{"name":"app"},{"name":"sidecar"}]}}`
+)
+
+func TestKubectlToolExecutor_ResolveAmbiguity(t *testing.T) {
+	tests := []struct {
+		name              string
+		params            map[string]interface{}
+		pod               string
+		allowedNamespaces string
+		choice            string
+		elicitErr         error
+		noElicitor        bool
+		wantChoices       []string
+		wantArgs          string
+		wantErr           string
+	}{
+		{
+			name:        "container chosen by the user",
+			params:      map[string]interface{}{"_tool_name": "kubectl_diagnostics", "operation": "logs", "resource": "", "name": "web", "namespace": "shop"},
+			pod:         twoContainerPod,
+			choice:      "sidecar",
+			wantChoices: []string{"app", "sidecar"},
+			wantArgs:    "web --namespace=shop --container=sidecar",
+		},
+		{
+			name:       "container without elicitation",
+			params:     map[string]interface{}{"_tool_name": "kubectl_diagnostics", "operation": "logs", "resource": "", "name": "web", "namespace": "shop"},
+			pod:        twoContainerPod,
+			noElicitor: true,
+			wantErr:    "pod web in namespace shop has several containers: app, sidecar",
+		},
+		{
+			name:        "container declined",
+			params:      map[string]interface{}{"_tool_name": "kubectl_diagnostics", "operation": "exec", "resource": "", "args": "pod/web -n shop -- ls"},
+			pod:         twoContainerPod,
+			elicitErr:   tools.ErrElicitationDeclined,
+			wantChoices: []string{"app", "sidecar"},
+			wantErr:     "has several containers: app, sidecar, and the user declined to choose",
+		},
+		{
+			name:     "default container",
+			params:   map[string]interface{}{"_tool_name": "kubectl_diagnostics", "operation": "logs", "resource": "", "name": "web", "namespace": "shop"},
+			pod:      defaultContainerPod,
+			wantArgs: "web --namespace=shop",
+		},
+		{
+			name:     "container given",
+			params:   map[string]interface{}{"_tool_name": "kubectl_diagnostics", "operation": "logs", "resource": "", "name": "web", "namespace": "shop", "container": "app"},
+			pod:      twoContainerPod,
+			wantArgs: "web --namespace=shop --container=app",
+		},
+		{
+			name:              "namespace chosen by the user",
+			params:            map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "get", "resource": "pods"},
+			allowedNamespaces: "web,shop",
+			choice:            "shop",
+			wantChoices:       []string{"shop", "web"},
+			wantArgs:          "--namespace=shop",
+		},
+		{
+			name:              "namespace without elicitation",
+			params:            map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "get", "resource": "pods"},
+			allowedNamespaces: "web,shop",
+			noElicitor:        true,
+			wantErr:           "no namespace was given and several namespaces are allowed: shop, web",
+		},
+		{
+			name:              "single allowed namespace",
+			params:            map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "get", "resource": "pods"},
+			allowedNamespaces: "shop",
+		},
+		{
+			name:              "cluster scoped resource",
+			params:            map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "get", "resource": "nodes"},
+			allowedNamespaces: "web,shop",
+		},
+		{
+			name:              "node operation",
+			params:            map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "cordon", "resource": "", "name": "node-1"},
+			allowedNamespaces: "web,shop",
+			wantArgs:          "node-1",
+		},
+		{
+			name:        "context chosen by the user",
+			params:      map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "get", "resource": "pods", "context": "prod", "args": "-n shop"},
+			choice:      "prod-us",
+			wantChoices: []string{"prod-eu", "prod-us"},
+			wantArgs:    "--context=prod-us -n shop",
+		},
+		{
+			name:   "exact context",
+			params: map[string]interface{}{"_tool_name": "kubectl_resources", "operation": "get", "resource": "pods", "context": "dev", "args": "-n shop"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &podBackend{pod: tt.pod}
+			executor := NewKubectlToolExecutorWithBackend(backend, false)
+			executor.contexts = func(kubeconfig string) ([]string, error) {
+				return []string{"dev", "prod-us", "prod-eu"}, nil
+			}
+			var asked [][]string
+			if !tt.noElicitor {
+				executor.SetElicitor(func(sessionID, message string, choices []string) (string, error) {
+					if sessionID != "s1" {
+						t.Errorf("elicited in session %q, want s1", sessionID)
+					}
+					asked = append(asked, choices)
+					return tt.choice, tt.elicitErr
+				})
+			}
+			securityConfig := &security.SecurityConfig{AccessLevel: security.AccessLevelAdmin}
+			securityConfig.SetAllowedNamespaces(tt.allowedNamespaces)
+			cfg := &config.ConfigData{AccessLevel: "admin", Timeout: 30, SecurityConfig: securityConfig}
+			tt.params[tools.SessionIDParam] = "s1"
+
+			_, err := executor.Execute(tt.params, cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || toolerror.CodeOf(err) != toolerror.InvalidArguments {
+					t.Errorf("Execute() error = %v, want %q", err, tt.wantErr)
+				}
+				if !strings.Contains(toolerror.HintOf(err), "one of:") {
+					t.Errorf("hint %q doesn't list the choices", toolerror.HintOf(err))
+				}
+			} else if err != nil {
+				t.Fatalf("Execute() unexpected error = %v", err)
+			}
+
+			if len(tt.wantChoices) == 0 && len(asked) != 0 {
+				t.Errorf("asked the user to choose from %v", asked)
+			}
+			if len(tt.wantChoices) > 0 && (len(asked) != 1 || strings.Join(asked[0], ",") != strings.Join(tt.wantChoices, ",")) {
+				t.Errorf("asked the user to choose from %v, want %v", asked, tt.wantChoices)
+			}
+			if tt.wantArgs != "" && (len(backend.requests) != 1 || backend.requests[0].Args != tt.wantArgs) {
+				t.Errorf("backend requests = %+v, want args %q", backend.requests, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestKubectlToolExecutor_BatchDoesNotElicit(t *testing.T) {
+	backend := &podBackend{pod: twoContainerPod}
+	executor := NewKubectlToolExecutorWithBackend(backend, false)
+	executor.SetElicitor(func(sessionID, message string, choices []string) (string, error) {
+		t.Errorf("batch operation asked the user to choose from %v", choices)
+		return choices[0], nil
+	})
+
+	result, err := executor.ExecuteStructured(batchParams(
+		map[string]interface{}{"tool": "kubectl_diagnostics", "operation": "logs", "resource": "", "name": "web", "namespace": "shop"},
+	), newBatchConfig())
+	if err != nil {
+		t.Fatalf("ExecuteStructured() unexpected error = %v", err)
+	}
+	if output := result.Structured.(*BatchOutput); !strings.Contains(output.Results[0].Error, "several containers: app, sidecar") {
+		t.Errorf("batch result = %+v, want an error listing the containers", output.Results[0])
+	}
+}
@@ -0,0 +1,42 @@
+package kubectl
+
+import "context"
+
+// Backend names selectable with --backend
+const (
+	BackendKubectl   = "kubectl"
+	BackendNative    = "native"
+	BackendSimulated = "simulated"
+)
+
+// BackendRequest is a validated kubectl tool call passed to an execution backend
+type BackendRequest struct {
+	ToolName  string
+	Operation string
+	Resource  string
+	Args      string
+	// Manifest is the inline manifest for apply, empty when args reference files
+	Manifest string
+	// Dir is the directory relative file paths in args resolve against
+	Dir string
+	// Timeout in seconds for the whole operation
+	Timeout int
+	// Context stops the operation when the tool call is cancelled, nil never stops it before its timeout
+	Context context.Context
+}
+
+// Backend executes kubectl tool operations without spawning the kubectl binary.
+// Requests reach a backend only after access level and security validation.
+type Backend interface {
+	// Supports reports whether the backend implements the request, including all of its flags.
+	// Unsupported requests are executed with the kubectl binary instead.
+	Supports(req BackendRequest) bool
+	// Execute runs the request and returns output compatible with kubectl
+	Execute(req BackendRequest) (string, error)
+}
+
+// ScopeResolver is implemented by backends that can tell whether objects of a kind are namespaced
+type ScopeResolver interface {
+	// KindScope reports whether objects of a kind are namespaced, or an error if the kind is unknown
+	KindScope(apiVersion, kind string) (namespaced bool, err error)
+}
@@ -0,0 +1,270 @@
+package kubectl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// BatchToolName is the name of the tool running several read-only operations in one call
+const BatchToolName = "kubectl_batch"
+
+// MaxBatchOperations is the maximum number of operations in a batch
+const MaxBatchOperations = 10
+
+// BatchOutput is the structured content returned by kubectl_batch
+type BatchOutput struct {
+	Results []BatchResult `json:"results" jsonschema:"description=Results of the operations in request order"`
+}
+
+// BatchResult is the outcome of one operation of a batch
+type BatchResult struct {
+	Tool      string `json:"tool"`
+	Operation string `json:"operation"`
+	Resource  string `json:"resource"`
+	Output    string `json:"output,omitempty"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"errorCode,omitempty" jsonschema:"description=Typed error code of a failed operation, such as NotFound or Forbidden"`
+}
+
+// batchItemParams lists the parameters of batch operations besides tool, operation and resource
+var batchItemParams = []string{"args", NameParam, NamespaceParam, AllNamespacesParam, SelectorParam,
+	FieldSelectorParam, ContainerParam, SinceParam, TailParam}
+
+// createBatchTool creates the tool running several read-only operations concurrently
+func createBatchTool() mcp.Tool {
+	description := fmt.Sprintf(`Run up to %d independent read-only kubectl operations concurrently in one call, instead of one call per operation.
+
+Each operation takes the same parameters as a call of its tool: tool, operation, resource, args and the typed parameters like name, namespace or selector. Only read-only operations are accepted:
+- kubectl_resources: get, describe
+- kubectl_diagnostics: logs, events, top
+- kubectl_cluster: cluster-info, api-resources, api-versions, explain
+- kubectl_config: diff, auth can-i, config current-context, config get-contexts
+- kubectl_workloads: rollout status, rollout history
+
+A batch containing an operation that modifies the cluster is rejected. The operations share the timeout of the batch, and each one returns its own output or error.
+
+Examples:
+- Inspect an app: operations=[{tool='kubectl_resources', operation='get', resource='pods', namespace='shop', selector='app=web'}, {tool='kubectl_resources', operation='get', resource='services', namespace='shop'}, {tool='kubectl_diagnostics', operation='events', resource='', namespace='shop'}]`, MaxBatchOperations)
+
+	itemTools := make([]string, 0, len(readOperations))
+	for name := range readOperations {
+		itemTools = append(itemTools, name)
+	}
+	sort.Strings(itemTools)
+
+	properties := map[string]any{
+		"tool": map[string]any{
+			"type":        "string",
+			"description": "The kubectl tool of the operation",
+			"enum":        itemTools,
+		},
+		"operation": map[string]any{"type": "string", "description": "The read-only operation to perform"},
+		"resource":  map[string]any{"type": "string", "description": "The resource type, or the subcommand for operations taking one"},
+		"args":      map[string]any{"type": "string", "description": "Additional kubectl arguments"},
+	}
+	for _, name := range batchItemParams[1:] {
+		schemaType := "string"
+		switch name {
+		case AllNamespacesParam:
+			schemaType = "boolean"
+		case TailParam:
+			schemaType = "number"
+		}
+		properties[name] = map[string]any{"type": schemaType, "description": typedParams[name].description}
+	}
+
+	return mcp.NewTool(BatchToolName,
+		mcp.WithDescription(description),
+		tools.WithAnnotations(tools.ReadOnlyAnnotations),
+		mcp.WithArray("operations",
+			mcp.Required(),
+			mcp.Description("The read-only operations to run"),
+			mcp.MinItems(1),
+			mcp.MaxItems(MaxBatchOperations),
+			mcp.Items(map[string]any{
+				"type":       "object",
+				"properties": properties,
+				"required":   []string{"tool", "operation", "resource"},
+			}),
+		),
+		tools.WithTimeoutSeconds(),
+		mcp.WithOutputSchema[BatchOutput](),
+	)
+}
+
+// executeBatch runs the operations of a kubectl_batch call concurrently. The batch is rejected if any
+// operation modifies the cluster, operations failing at run time report their error in their result.
+func (e *KubectlToolExecutor) executeBatch(params map[string]interface{}, cfg *config.ConfigData) (*tools.Result, error) {
+	items, err := parseBatchItems(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// All operations run until the deadline of the batch
+	requested, err := tools.GetTimeoutSeconds(params)
+	if err != nil {
+		return nil, err
+	}
+	timeout := cfg.ResolveTimeout(security.CommandTypeKubectl, "", requested)
+
+	// Operations run with the readonly access level, so they can't modify the cluster even if the
+	// operation checks above let one through
+	batchCfg := readOnlyConfig(cfg)
+
+	results := make([]BatchResult, len(items))
+	done := make([]bool, len(items))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, item := range items {
+		item[tools.TimeoutSecondsParam] = timeout
+		item[tools.SessionIDParam] = params[tools.SessionIDParam]
+		item[tools.ContextParam] = params[tools.ContextParam]
+		item[batchItemParam] = true
+		results[i] = BatchResult{
+			Tool:      item["_tool_name"].(string),
+			Operation: item["operation"].(string),
+			Resource:  item["resource"].(string),
+		}
+		wg.Add(1)
+		go func(i int, item map[string]interface{}) {
+			defer wg.Done()
+			output, err := e.Execute(item, batchCfg)
+			mu.Lock()
+			defer mu.Unlock()
+			results[i].Output = output
+			if err != nil {
+				results[i].Error = err.Error()
+				results[i].ErrorCode = string(toolerror.CodeOf(err))
+			}
+			done[i] = true
+		}(i, item)
+	}
+
+	// Operations ignoring their timeout are reported as timed out when the deadline passes
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		deadline = time.After(time.Duration(timeout) * time.Second)
+	}
+	select {
+	case <-finished:
+	case <-deadline:
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	output := BatchOutput{Results: make([]BatchResult, len(results))}
+	for i := range results {
+		if !done[i] {
+			err := toolerror.Newf(toolerror.Timeout, "operation didn't complete within the batch timeout of %ds", timeout)
+			results[i].Error, results[i].ErrorCode = err.Error(), string(toolerror.Timeout)
+		}
+		output.Results[i] = results[i]
+	}
+	return &tools.Result{Text: renderBatch(output), Structured: &output}, nil
+}
+
+// parseBatchItems validates the operations of a batch and returns their parameters with the tool
+// name injected. Operations that modify the cluster are rejected.
+func parseBatchItems(params map[string]interface{}) ([]map[string]interface{}, error) {
+	operations, ok := params["operations"].([]interface{})
+	if !ok || len(operations) == 0 {
+		return nil, toolerror.New(toolerror.InvalidArguments, "operations parameter is required and must be a non-empty list")
+	}
+	if len(operations) > MaxBatchOperations {
+		return nil, toolerror.Newf(toolerror.InvalidArguments, "a batch can run at most %d operations, got %d", MaxBatchOperations, len(operations))
+	}
+
+	items := make([]map[string]interface{}, 0, len(operations))
+	for i, value := range operations {
+		operation, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "operation %d must be an object, got %T", i+1, value)
+		}
+
+		item := map[string]interface{}{}
+		for _, key := range []string{"tool", "operation", "resource"} {
+			if _, ok := operation[key].(string); !ok {
+				return nil, toolerror.Newf(toolerror.InvalidArguments, "operation %d: %s is required and must be a string", i+1, key)
+			}
+		}
+		toolName := operation["tool"].(string)
+		if _, ok := readOperations[toolName]; !ok {
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "operation %d: tool '%s' can't be used in a batch", i+1, toolName)
+		}
+		item["_tool_name"] = toolName
+		item["operation"] = operation["operation"]
+		item["resource"] = operation["resource"]
+		item["args"] = ""
+		for key, value := range operation {
+			switch {
+			case key == "tool" || key == "operation" || key == "resource":
+			case contains(batchItemParams, key):
+				item[key] = value
+			default:
+				return nil, toolerror.Newf(toolerror.InvalidArguments, "operation %d: parameter '%s' is not supported in a batch", i+1, key)
+			}
+		}
+
+		name := item["operation"].(string)
+		if readOperations[toolName][name] != nil {
+			name += " " + item["resource"].(string)
+		}
+		if !isReadOperation(toolName, item["operation"].(string), item["resource"].(string)) {
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "operation %d: '%s' of %s is not a read-only operation, batches can only run read-only operations",
+				i+1, name, toolName)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// renderBatch renders the results of a batch as text, one section per operation
+func renderBatch(output BatchOutput) string {
+	var b strings.Builder
+	for i, result := range output.Results {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### [%d] %s %s %s\n", i+1, result.Tool, result.Operation, result.Resource)
+		if result.Error != "" {
+			fmt.Fprintf(&b, "Error: %s\n", result.Error)
+			if result.ErrorCode != "" {
+				fmt.Fprintf(&b, "Error code: %s\n", result.ErrorCode)
+			}
+			continue
+		}
+		b.WriteString(result.Output)
+		if !strings.HasSuffix(result.Output, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// readOnlyConfig returns a copy of a configuration with the readonly access level, both for the tool checks
+// and for the security validation of commands. The server's configuration is left unchanged.
+func readOnlyConfig(cfg *config.ConfigData) *config.ConfigData {
+	readOnly := *cfg
+	readOnly.AccessLevel = AccessLevelReadOnly
+	readOnly.SplitTools = false
+	if cfg.SecurityConfig != nil {
+		securityConfig := *cfg.SecurityConfig
+		securityConfig.AccessLevel = security.AccessLevelReadOnly
+		readOnly.SecurityConfig = &securityConfig
+	}
+	return &readOnly
+}
@@ -0,0 +1,174 @@
+package kubectl
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// blockingBackend blocks every request until it is released
+type blockingBackend struct {
+	release chan struct{}
+}
+
+func (b *blockingBackend) Supports(req BackendRequest) bool {
+	return true
+}
+
+func (b *blockingBackend) Execute(req BackendRequest) (string, error) {
+	<-b.release
+	return "late output", nil
+}
+
+// batchParams creates the arguments of a kubectl_batch call
+func batchParams(operations ...map[string]interface{}) map[string]interface{} {
+	items := make([]interface{}, len(operations))
+	for i, operation := range operations {
+		items[i] = operation
+	}
+	return map[string]interface{}{"_tool_name": BatchToolName, "operations": items}
+}
+
+func newBatchConfig() *config.ConfigData {
+	securityConfig := &security.SecurityConfig{AccessLevel: security.AccessLevelReadWrite}
+	securityConfig.SetAllowedNamespaces("shop")
+	return &config.ConfigData{AccessLevel: AccessLevelReadWrite, Timeout: 30, SecurityConfig: securityConfig}
+}
+
+func TestKubectlToolExecutor_ExecuteBatch(t *testing.T) {
+	backend := &stubBackend{supports: true}
+	executor := NewKubectlToolExecutorWithBackend(backend, false)
+
+	params := batchParams(
+		map[string]interface{}{"tool": "kubectl_resources", "operation": "get", "resource": "pods", "namespace": "shop", "selector": "app=web"},
+		map[string]interface{}{"tool": "kubectl_diagnostics", "operation": "events", "resource": "", "namespace": "shop"},
+		map[string]interface{}{"tool": "kubectl_resources", "operation": "get", "resource": "secrets", "namespace": "kube-system"},
+		map[string]interface{}{"tool": "kubectl_workloads", "operation": "rollout", "resource": "status", "args": "deployment/web -n shop"},
+	)
+	params[tools.TimeoutSecondsParam] = float64(20)
+	result, err := executor.ExecuteStructured(params, newBatchConfig())
+	if err != nil {
+		t.Fatalf("ExecuteStructured() unexpected error = %v", err)
+	}
+
+	output, ok := result.Structured.(*BatchOutput)
+	if !ok || len(output.Results) != 4 {
+		t.Fatalf("Expected 4 batch results, got %+v", result.Structured)
+	}
+	for _, i := range []int{0, 1, 3} {
+		if output.Results[i].Output != "native output" || output.Results[i].Error != "" {
+			t.Errorf("result %d = %+v, want native output", i, output.Results[i])
+		}
+	}
+	// Failed operations report their error without failing the batch
+	if denied := output.Results[2]; denied.Error == "" || denied.ErrorCode != string(toolerror.PolicyDenied) {
+		t.Errorf("result 2 = %+v, want policy denial", denied)
+	}
+	if output.Results[1].Tool != "kubectl_diagnostics" || output.Results[1].Operation != "events" {
+		t.Errorf("results are not in request order: %+v", output.Results)
+	}
+	if !strings.Contains(result.Text, "### [3] kubectl_resources get secrets\nError: ") {
+		t.Errorf("Unexpected batch text %q", result.Text)
+	}
+
+	// Operations run with the shared timeout of the batch
+	if len(backend.requests) != 3 {
+		t.Fatalf("backend executed %d requests, want 3", len(backend.requests))
+	}
+	for _, req := range backend.requests {
+		if req.Timeout != 20 {
+			t.Errorf("request %+v timeout = %d, want 20", req, req.Timeout)
+		}
+	}
+}
+
+func TestKubectlToolExecutor_ExecuteBatchRejected(t *testing.T) {
+	tooMany := make([]map[string]interface{}, MaxBatchOperations+1)
+	for i := range tooMany {
+		tooMany[i] = map[string]interface{}{"tool": "kubectl_resources", "operation": "get", "resource": "pods"}
+	}
+
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		expectError string
+	}{
+		{name: "no operations", params: batchParams(), expectError: "operations parameter is required"},
+		{name: "too many operations", params: batchParams(tooMany...), expectError: "at most 10 operations"},
+		{name: "not an object", params: map[string]interface{}{"_tool_name": BatchToolName, "operations": []interface{}{"get pods"}}, expectError: "operation 1 must be an object"},
+		{name: "missing resource", params: batchParams(map[string]interface{}{"tool": "kubectl_resources", "operation": "get"}), expectError: "operation 1: resource is required"},
+		{name: "mutating operation", params: batchParams(
+			map[string]interface{}{"tool": "kubectl_resources", "operation": "get", "resource": "pods"},
+			map[string]interface{}{"tool": "kubectl_resources", "operation": "delete", "resource": "pods", "name": "web"},
+		), expectError: "operation 2: 'delete' of kubectl_resources is not a read-only operation"},
+		{name: "mutating subcommand", params: batchParams(map[string]interface{}{"tool": "kubectl_workloads", "operation": "rollout", "resource": "undo"}), expectError: "'rollout undo' of kubectl_workloads is not a read-only operation"},
+		{name: "exec", params: batchParams(map[string]interface{}{"tool": "kubectl_diagnostics", "operation": "exec", "resource": "", "args": "web -- date"}), expectError: "is not a read-only operation"},
+		{name: "tool without read operations", params: batchParams(map[string]interface{}{"tool": "kubectl_metadata", "operation": "label", "resource": "pods"}), expectError: "tool 'kubectl_metadata' can't be used in a batch"},
+		{name: "nested batch", params: batchParams(map[string]interface{}{"tool": BatchToolName, "operation": "get", "resource": "pods"}), expectError: "can't be used in a batch"},
+		{name: "unsupported parameter", params: batchParams(map[string]interface{}{"tool": "kubectl_resources", "operation": "get", "resource": "", "manifest": "kind: Pod"}), expectError: "parameter 'manifest' is not supported in a batch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &stubBackend{supports: true}
+			executor := NewKubectlToolExecutorWithBackend(backend, false)
+			_, err := executor.ExecuteStructured(tt.params, newBatchConfig())
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("ExecuteStructured() error = %v, want %q", err, tt.expectError)
+			}
+			if toolerror.CodeOf(err) != toolerror.InvalidArguments {
+				t.Errorf("error code = %q, want InvalidArguments", toolerror.CodeOf(err))
+			}
+			// Nothing runs when the batch is rejected
+			if len(backend.requests) != 0 {
+				t.Errorf("backend executed requests of a rejected batch: %+v", backend.requests)
+			}
+		})
+	}
+}
+
+func TestReadOnlyConfig(t *testing.T) {
+	cfg := newBatchConfig()
+	batchCfg := readOnlyConfig(cfg)
+	if batchCfg.AccessLevel != AccessLevelReadOnly || batchCfg.SecurityConfig.AccessLevel != security.AccessLevelReadOnly {
+		t.Errorf("access levels = %q, %q, want readonly", batchCfg.AccessLevel, batchCfg.SecurityConfig.AccessLevel)
+	}
+	if !batchCfg.SecurityConfig.IsNamespaceAllowed("shop") || batchCfg.SecurityConfig.IsNamespaceAllowed("kube-system") {
+		t.Error("namespace restrictions of the server are not kept")
+	}
+	if cfg.AccessLevel != AccessLevelReadWrite || cfg.SecurityConfig.AccessLevel != security.AccessLevelReadWrite {
+		t.Errorf("server access levels changed to %q, %q", cfg.AccessLevel, cfg.SecurityConfig.AccessLevel)
+	}
+
+	// A command that modifies the cluster is denied by the validator of the batch configuration
+	validator := security.NewValidator(batchCfg.SecurityConfig)
+	if err := validator.ValidateCommand("kubectl delete pod web -n shop", security.CommandTypeKubectl); err == nil {
+		t.Error("ValidateCommand() expected an error for delete with the batch configuration")
+	}
+}
+
+func TestKubectlToolExecutor_ExecuteBatchDeadline(t *testing.T) {
+	backend := &blockingBackend{release: make(chan struct{})}
+	defer close(backend.release)
+	executor := NewKubectlToolExecutorWithBackend(backend, false)
+
+	params := batchParams(map[string]interface{}{"tool": "kubectl_cluster", "operation": "cluster-info", "resource": "", "args": ""})
+	params[tools.TimeoutSecondsParam] = float64(1)
+	start := time.Now()
+	result, err := executor.ExecuteStructured(params, newBatchConfig())
+	if err != nil {
+		t.Fatalf("ExecuteStructured() unexpected error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("batch returned after %s, want about 1s", elapsed)
+	}
+	output := result.Structured.(*BatchOutput)
+	if output.Results[0].ErrorCode != string(toolerror.Timeout) {
+		t.Errorf("result = %+v, want timeout", output.Results[0])
+	}
+}
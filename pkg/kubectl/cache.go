@@ -0,0 +1,258 @@
+package kubectl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/command"
+	"github.com/google/shlex"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// CacheParam is the optional argument controlling the result cache, "bypass" skips cached results
+const CacheParam = "cache"
+
+// maxCacheEntries caps the number of cached results. When the cache is full, expired results are dropped
+// first, then the results expiring soonest.
+const maxCacheEntries = 1000
+
+// cacheTTLs holds the time-to-live of cached results per read-only operation.
+// Operations not listed here are never cached.
+var cacheTTLs = map[string]time.Duration{
+	"api-resources": 10 * time.Minute,
+	"api-versions":  10 * time.Minute,
+	"explain":       30 * time.Minute,
+	"cluster-info":  time.Minute,
+	"get":           5 * time.Second,
+	"describe":      5 * time.Second,
+	"events":        5 * time.Second,
+	"top":           5 * time.Second,
+}
+
+// flagAliases maps short kubectl flags to their long form for key normalization
+var flagAliases = map[string]string{
+	"-n": "--namespace",
+	"-o": "--output",
+	"-l": "--selector",
+	"-A": "--all-namespaces",
+	"-c": "--container",
+}
+
+// valueFlags lists kubectl flags that take a separate value
+var valueFlags = map[string]bool{
+	"--namespace": true, "--output": true, "--selector": true, "--container": true,
+	"--context": true, "--cluster": true, "--user": true, "--field-selector": true,
+	"--sort-by": true, "--api-version": true, "--api-group": true, "--kubeconfig": true,
+}
+
+// cacheEntry is a cached command result
+type cacheEntry struct {
+	output    string
+	namespace string
+	expires   time.Time
+}
+
+// resultCache is a read-through TTL cache for read-only kubectl commands that
+// coalesces concurrent identical calls
+type resultCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	// generation counts invalidations, results of commands that ran across one are not cached
+	generation uint64
+	group      singleflight.Group
+	now        func() time.Time
+}
+
+// newResultCache creates an empty result cache
+func newResultCache() *resultCache {
+	return &resultCache{
+		entries: make(map[string]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// parsedCommand holds the normalized form of a kubectl command
+type parsedCommand struct {
+	operation string
+	namespace string
+	key       string
+	// positional holds the positional arguments, starting with the operation
+	positional []string
+	// flags maps the long form of flags to their value, empty for flags without a value
+	flags map[string]string
+}
+
+// parseCommand normalizes a kubectl command so that equivalent invocations share a key.
+// Flags are canonicalized and sorted, positional arguments keep their order.
+func parseCommand(fullCmd, kubeconfig string) (parsedCommand, error) {
+	args, err := shlex.Split(fullCmd)
+	if err != nil {
+		return parsedCommand{}, err
+	}
+	if len(args) > 0 && args[0] == "kubectl" {
+		args = args[1:]
+	}
+
+	var positional, flags []string
+	var namespace string
+	values := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positional = append(positional, arg)
+			continue
+		}
+		if arg == "--" {
+			positional = append(positional, args[i:]...)
+			break
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		if alias, ok := flagAliases[name]; ok {
+			name = alias
+		}
+		if !hasValue && valueFlags[name] && i+1 < len(args) {
+			value, hasValue = args[i+1], true
+			i++
+		}
+
+		switch name {
+		case "--namespace":
+			namespace = value
+		case "--all-namespaces":
+			namespace = "*"
+		}
+
+		values[name] = value
+		if hasValue {
+			flags = append(flags, name+"="+value)
+		} else {
+			flags = append(flags, name)
+		}
+	}
+	sort.Strings(flags)
+
+	parsed := parsedCommand{namespace: namespace, positional: positional, flags: values}
+	if len(positional) > 0 {
+		parsed.operation = positional[0]
+	}
+	parsed.key = kubeconfig + "\x00" + strings.Join(positional, "\x00") + "\x00" + strings.Join(flags, "\x00")
+	return parsed, nil
+}
+
+// get returns the cached output for a key if it has not expired
+func (c *resultCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if c.now().After(entry.expires) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.output, true
+}
+
+// do returns the cached result for a read-only command, or runs it once for all
+// concurrent callers and caches the output
+func (c *resultCache) do(parsed parsedCommand, bypass bool, run func() (string, error)) (string, error) {
+	ttl := cacheTTLs[parsed.operation]
+	if !bypass {
+		if output, ok := c.get(parsed.key); ok {
+			return output, nil
+		}
+	}
+
+	// Calls after an invalidation don't join commands that started before it
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+
+	result, err, _ := c.group.Do(fmt.Sprintf("%d\x00%s", generation, parsed.key), func() (interface{}, error) {
+		output, err := run()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		// Don't cache failures, including transient errors returned as output, or output that a mutation
+		// may have changed while the command ran
+		if err == nil && !command.IsTransientError(output) && c.generation == generation {
+			c.makeRoomLocked()
+			c.entries[parsed.key] = cacheEntry{
+				output:    output,
+				namespace: parsed.namespace,
+				expires:   c.now().Add(ttl),
+			}
+		}
+		return output, err
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+// makeRoomLocked drops entries until another one fits in the cache, c.mu must be held
+func (c *resultCache) makeRoomLocked() {
+	if len(c.entries) < maxCacheEntries {
+		return
+	}
+	now := c.now()
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+	for len(c.entries) >= maxCacheEntries {
+		var oldest string
+		var oldestExpiry time.Time
+		for key, entry := range c.entries {
+			if oldest == "" || entry.expires.Before(oldestExpiry) {
+				oldest, oldestExpiry = key, entry.expires
+			}
+		}
+		delete(c.entries, oldest)
+	}
+}
+
+// invalidate drops cached results that a mutation in the given namespace may have changed.
+// Mutations without an explicit namespace, e.g. manifests or cluster-scoped resources,
+// drop the whole cache. Results without an explicit namespace are in the namespace of the
+// kubeconfig context, which may be any namespace, so every mutation drops them.
+func (c *resultCache) invalidate(namespace string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for key, entry := range c.entries {
+		if namespace == "" || namespace == "*" || entry.namespace == namespace || entry.namespace == "*" ||
+			entry.namespace == "" {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// currentContext returns the current context of the kubeconfig kubectl uses, which selects the cluster of
+// commands without --context. An empty kubeconfig uses the KUBECONFIG loading rules of kubectl.
+func currentContext(kubeconfig string) string {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	config, err := rules.Load()
+	if err != nil {
+		return ""
+	}
+	return config.CurrentContext
+}
+
+// isCacheable checks if the results of an operation are cached
+func isCacheable(operation string) bool {
+	_, ok := cacheTTLs[operation]
+	return ok
+}
@@ -0,0 +1,260 @@
+package kubectl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseCommandNormalization(t *testing.T) {
+	equivalent := []string{
+		"kubectl get pods -n prod -o wide",
+		"get pods --namespace prod --output=wide",
+		"kubectl get pods -o wide --namespace=prod",
+	}
+
+	var key string
+	for i, cmd := range equivalent {
+		parsed, err := parseCommand(cmd, "")
+		if err != nil {
+			t.Fatalf("parseCommand(%q) unexpected error = %v", cmd, err)
+		}
+		if parsed.operation != "get" {
+			t.Errorf("parseCommand(%q) operation = %v, want get", cmd, parsed.operation)
+		}
+		if parsed.namespace != "prod" {
+			t.Errorf("parseCommand(%q) namespace = %v, want prod", cmd, parsed.namespace)
+		}
+		if i > 0 && parsed.key != key {
+			t.Errorf("parseCommand(%q) key differs from %q", cmd, equivalent[0])
+		}
+		key = parsed.key
+	}
+
+	different := []string{
+		"get pods -n dev -o wide",
+		"get deployments -n prod -o wide",
+		"get pods -n prod -o wide --context other",
+	}
+	for _, cmd := range different {
+		parsed, _ := parseCommand(cmd, "")
+		if parsed.key == key {
+			t.Errorf("parseCommand(%q) key should differ", cmd)
+		}
+	}
+
+	other, _ := parseCommand(equivalent[0], "/other/kubeconfig")
+	if other.key == key {
+		t.Error("Expected kubeconfig to be part of the key")
+	}
+
+	all, _ := parseCommand("get pods -A", "")
+	if all.namespace != "*" {
+		t.Errorf("Expected all namespaces marker, got %v", all.namespace)
+	}
+}
+
+func TestResultCacheTTL(t *testing.T) {
+	cache := newResultCache()
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	run := func() (string, error) {
+		calls++
+		return "output", nil
+	}
+
+	parsed, _ := parseCommand("get pods -n prod", "")
+	for i := 0; i < 3; i++ {
+		if _, err := cache.do(parsed, false, run); err != nil {
+			t.Fatalf("do() unexpected error = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 execution within TTL, got %d", calls)
+	}
+
+	_, _ = cache.do(parsed, true, run)
+	if calls != 2 {
+		t.Errorf("Expected bypass to execute the command, got %d executions", calls)
+	}
+
+	now = now.Add(cacheTTLs["get"] + time.Second)
+	_, _ = cache.do(parsed, false, run)
+	if calls != 3 {
+		t.Errorf("Expected execution after TTL expiry, got %d executions", calls)
+	}
+}
+
+func TestResultCacheSkipsFailures(t *testing.T) {
+	cache := newResultCache()
+	parsed, _ := parseCommand("get pods", "")
+
+	calls := 0
+	run := func() (string, error) {
+		calls++
+		return "The connection to the server was refused: connection refused", nil
+	}
+	_, _ = cache.do(parsed, false, run)
+	_, _ = cache.do(parsed, false, run)
+	if calls != 2 {
+		t.Errorf("Expected transient failures not to be cached, got %d executions", calls)
+	}
+}
+
+func TestResultCacheCoalescing(t *testing.T) {
+	cache := newResultCache()
+	parsed, _ := parseCommand("api-resources", "")
+
+	var calls int32
+	release := make(chan struct{})
+	run := func() (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "resources", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			output, err := cache.do(parsed, false, run)
+			if err != nil || output != "resources" {
+				t.Errorf("do() = %q, %v", output, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("Expected concurrent identical calls to be coalesced, got %d executions", calls)
+	}
+}
+
+func TestResultCacheInvalidate(t *testing.T) {
+	cache := newResultCache()
+	run := func() (string, error) { return "output", nil }
+
+	commands := map[string]string{
+		"prod":    "get pods -n prod",
+		"dev":     "get pods -n dev",
+		"all":     "get pods -A",
+		"default": "get pods",
+	}
+	fill := func() map[string]parsedCommand {
+		parsed := make(map[string]parsedCommand)
+		for name, cmd := range commands {
+			parsed[name], _ = parseCommand(cmd, "")
+			_, _ = cache.do(parsed[name], false, run)
+		}
+		return parsed
+	}
+
+	// Results without a namespace are in the namespace of the context, which may be prod
+	parsed := fill()
+	cache.invalidate("prod")
+	for name, want := range map[string]bool{"prod": false, "dev": true, "all": false, "default": false} {
+		if _, ok := cache.get(parsed[name].key); ok != want {
+			t.Errorf("After invalidating prod, cached %s = %v, want %v", name, ok, want)
+		}
+	}
+
+	parsed = fill()
+	cache.invalidate("")
+	for name := range commands {
+		if _, ok := cache.get(parsed[name].key); ok {
+			t.Errorf("After invalidating without namespace, %s should not be cached", name)
+		}
+	}
+}
+
+func TestResultCacheInvalidateDuringRun(t *testing.T) {
+	cache := newResultCache()
+	parsed, _ := parseCommand("get pods -n prod", "")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = cache.do(parsed, false, func() (string, error) {
+			close(started)
+			<-release
+			return "before delete", nil
+		})
+	}()
+	<-started
+	cache.invalidate("prod")
+
+	// Reads after the mutation don't join the read that started before it
+	output, err := cache.do(parsed, false, func() (string, error) { return "after delete", nil })
+	if err != nil || output != "after delete" {
+		t.Errorf("do() after invalidate = %q, %v, want fresh output", output, err)
+	}
+	close(release)
+	<-done
+
+	if output, ok := cache.get(parsed.key); !ok || output != "after delete" {
+		t.Errorf("cached output = %q, %v, want the output read after the mutation", output, ok)
+	}
+}
+
+func TestResultCacheBounded(t *testing.T) {
+	cache := newResultCache()
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	run := func() (string, error) { return "output", nil }
+
+	first, _ := parseCommand("get pods -n first", "")
+	_, _ = cache.do(first, false, run)
+	now = now.Add(time.Second)
+	for i := 0; i < maxCacheEntries; i++ {
+		parsed, _ := parseCommand(fmt.Sprintf("get pods -n ns-%d", i), "")
+		_, _ = cache.do(parsed, false, run)
+	}
+
+	if len(cache.entries) > maxCacheEntries {
+		t.Errorf("Expected at most %d entries, got %d", maxCacheEntries, len(cache.entries))
+	}
+	if _, ok := cache.get(first.key); ok {
+		t.Error("Expected the entry expiring soonest to be evicted")
+	}
+
+	now = now.Add(cacheTTLs["get"] + time.Second)
+	last, _ := parseCommand("get pods -n last", "")
+	_, _ = cache.do(last, false, run)
+	if len(cache.entries) != 1 {
+		t.Errorf("Expected expired entries to be swept when the cache is full, got %d entries", len(cache.entries))
+	}
+}
+
+func TestCurrentContext(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	write := func(context string) {
+		content := "apiVersion: v1\nkind: Config\ncurrent-context: " + context + "\ncontexts:\n" +
+			"- name: one\n  context:\n    cluster: one\n- name: two\n  context:\n    cluster: two\n"
+		if err := os.WriteFile(kubeconfig, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("one")
+	if got := currentContext(kubeconfig); got != "one" {
+		t.Errorf("currentContext() = %q, want one", got)
+	}
+	write("two")
+	if got := currentContext(kubeconfig); got != "two" {
+		t.Errorf("currentContext() after switching = %q, want two", got)
+	}
+	if got := currentContext(filepath.Join(t.TempDir(), "missing")); got != "" {
+		t.Errorf("currentContext() of a missing kubeconfig = %q, want empty", got)
+	}
+}
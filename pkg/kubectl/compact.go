@@ -0,0 +1,440 @@
+package kubectl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+	"sigs.k8s.io/yaml"
+)
+
+// Parameters controlling how kubectl_resources renders objects
+const (
+	VerbosityParam = "verbosity"
+	ColumnsParam   = "columns"
+)
+
+// Verbosity levels
+const (
+	VerbosityFull    = "full"
+	VerbosityCompact = "compact"
+	VerbosityTable   = "table"
+)
+
+// lastAppliedAnnotation holds the previous manifest of objects managed with kubectl apply
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// emptyObject matches fields that hold an empty object
+var emptyObject = map[string]interface{}{}
+
+// kindDefaults maps kinds to the paths of fields the API server fills in by default and
+// their default values. Paths are dotted field names, with [] selecting every list item.
+// Fields with these values are dropped from compact output.
+var kindDefaults = map[string]map[string]interface{}{
+	"Deployment": {
+		"spec.revisionHistoryLimit":    float64(10),
+		"spec.progressDeadlineSeconds": float64(600),
+	},
+	"StatefulSet": {
+		"spec.revisionHistoryLimit": float64(10),
+		"spec.podManagementPolicy":  "OrderedReady",
+	},
+	"DaemonSet": {
+		"spec.revisionHistoryLimit": float64(10),
+	},
+	"Service": {
+		"spec.sessionAffinity":       "None",
+		"spec.internalTrafficPolicy": "Cluster",
+		"spec.ipFamilyPolicy":        "SingleStack",
+		"spec.ports[].protocol":      "TCP",
+	},
+}
+
+// podSpecDefaults maps the paths of pod spec fields, relative to the pod spec, to their default values
+var podSpecDefaults = map[string]interface{}{
+	"dnsPolicy":                                    "ClusterFirst",
+	"schedulerName":                                "default-scheduler",
+	"enableServiceLinks":                           true,
+	"terminationGracePeriodSeconds":                float64(30),
+	"priority":                                     float64(0),
+	"preemptionPolicy":                             "PreemptLowerPriority",
+	"securityContext":                              emptyObject,
+	"containers[].terminationMessagePath":          "/dev/termination-log",
+	"containers[].terminationMessagePolicy":        "File",
+	"containers[].resources":                       emptyObject,
+	"containers[].ports[].protocol":                "TCP",
+	"initContainers[].terminationMessagePath":      "/dev/termination-log",
+	"initContainers[].terminationMessagePolicy":    "File",
+	"initContainers[].resources":                   emptyObject,
+	"initContainers[].ports[].protocol":            "TCP",
+	"ephemeralContainers[].terminationMessagePath": "/dev/termination-log",
+}
+
+// podSpecPaths maps the kinds embedding a pod spec to the path of the pod spec
+var podSpecPaths = map[string]string{
+	"Pod":                   "spec",
+	"PodTemplate":           "template.spec",
+	"ReplicationController": "spec.template.spec",
+	"ReplicaSet":            "spec.template.spec",
+	"Deployment":            "spec.template.spec",
+	"StatefulSet":           "spec.template.spec",
+	"DaemonSet":             "spec.template.spec",
+	"Job":                   "spec.template.spec",
+	"CronJob":               "spec.jobTemplate.spec.template.spec",
+}
+
+// noisyConditionFields are condition timestamps that rarely help diagnosing an object
+var noisyConditionFields = []string{"lastProbeTime", "lastHeartbeatTime", "lastUpdateTime"}
+
+// defaultTolerationKeys are the tolerations added to every pod by the DefaultTolerationSeconds admission plugin
+var defaultTolerationKeys = map[string]bool{
+	"node.kubernetes.io/not-ready":   true,
+	"node.kubernetes.io/unreachable": true,
+}
+
+// withVerbosityParams adds the verbosity and columns parameters to a tool definition
+func withVerbosityParams() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString(VerbosityParam,
+			mcp.Description("Optional rendering of get results: 'full' (default) returns kubectl's output unchanged, 'compact' strips managedFields, last-applied annotations, API server defaults of built-in kinds and condition timestamps from -o yaml/json output, 'table' renders objects as a compact table with the columns given in 'columns'. Don't pass -o in args with 'table'"),
+			mcp.Enum(VerbosityFull, VerbosityCompact, VerbosityTable),
+		),
+		mcp.WithString(ColumnsParam,
+			mcp.Description("Comma-separated columns for verbosity 'table': kind, name, namespace, status, age, kind specific fields such as ready, restarts, node, ip, type, clusterIP, ports, or a field path like .spec.nodeName. Defaults to name, status, age and the kind specific fields"),
+		),
+	}
+}
+
+// getVerbosity returns the verbosity requested in params, defaulting to full
+func getVerbosity(params map[string]interface{}) (string, error) {
+	value, ok := params[VerbosityParam]
+	if !ok || value == nil {
+		return VerbosityFull, nil
+	}
+	verbosity, ok := value.(string)
+	if !ok {
+		return "", toolerror.Newf(toolerror.InvalidArguments, "%s must be a string, got %T", VerbosityParam, value)
+	}
+	switch verbosity {
+	case "":
+		return VerbosityFull, nil
+	case VerbosityCompact, VerbosityFull, VerbosityTable:
+		return verbosity, nil
+	default:
+		return "", toolerror.Newf(toolerror.InvalidArguments, "invalid %s '%s'. Valid values are: %s, %s, %s", VerbosityParam, verbosity, VerbosityCompact, VerbosityFull, VerbosityTable)
+	}
+}
+
+// getColumns returns the table columns requested in params, nil for the default columns
+func getColumns(params map[string]interface{}) []string {
+	value, _ := params[ColumnsParam].(string)
+	var columns []string
+	for _, column := range strings.Split(value, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// outputFormatOf returns the structured format selected by -o in kubectl arguments, or empty if there is none
+func outputFormatOf(args string) string {
+	fields := strings.Fields(args)
+	for i, field := range fields {
+		var value string
+		switch {
+		case field == "-o" || field == "--output":
+			if i+1 < len(fields) {
+				value = fields[i+1]
+			}
+		case strings.HasPrefix(field, "--output="):
+			value = strings.TrimPrefix(field, "--output=")
+		case strings.HasPrefix(field, "-o") && !strings.HasPrefix(field, "--"):
+			value = strings.TrimPrefix(strings.TrimPrefix(field, "-o"), "=")
+		default:
+			continue
+		}
+		if value == "yaml" || value == "json" {
+			return value
+		}
+		return ""
+	}
+	return ""
+}
+
+// compactOutput strips noise from kubectl -o yaml or -o json output. Output that can't be
+// parsed, such as kubectl errors, is returned unchanged.
+func compactOutput(output, format string) string {
+	data := []byte(output)
+	if format == "yaml" {
+		converted, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return output
+		}
+		data = converted
+	}
+
+	var obj interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return output
+	}
+	obj = compactValue(obj)
+
+	if format == "yaml" {
+		compacted, err := yaml.Marshal(obj)
+		if err != nil {
+			return output
+		}
+		return string(compacted)
+	}
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(obj); err != nil {
+		return output
+	}
+	return out.String()
+}
+
+// compactValue strips noise from a decoded kubectl object or list of objects. Only fields at
+// known paths are removed, so user data such as labels, ConfigMap data or custom resource
+// fields is kept even when it shares a name or a value with a default.
+func compactValue(value interface{}) interface{} {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	if items, ok := obj["items"].([]interface{}); ok && isListKind(obj) {
+		for _, item := range items {
+			compactValue(item)
+		}
+		return obj
+	}
+
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+		delete(metadata, "selfLink")
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, lastAppliedAnnotation)
+			if len(annotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+	}
+	if status, ok := obj["status"].(map[string]interface{}); ok {
+		if conditions, ok := status["conditions"].([]interface{}); ok {
+			for _, condition := range conditions {
+				if condition, ok := condition.(map[string]interface{}); ok {
+					for _, field := range noisyConditionFields {
+						delete(condition, field)
+					}
+				}
+			}
+		}
+	}
+
+	kind, _ := obj["kind"].(string)
+	for path, def := range kindDefaults[kind] {
+		removeDefault(obj, strings.Split(path, "."), def)
+	}
+	if path, ok := podSpecPaths[kind]; ok {
+		if spec, ok := nestedField(obj, strings.Split(path, ".")...).(map[string]interface{}); ok {
+			compactPodSpec(spec)
+		}
+	}
+	return obj
+}
+
+// compactPodSpec strips default values, default tolerations and the deprecated serviceAccount field from a pod spec
+func compactPodSpec(spec map[string]interface{}) {
+	for path, def := range podSpecDefaults {
+		removeDefault(spec, strings.Split(path, "."), def)
+	}
+	// serviceAccount is a deprecated copy of serviceAccountName
+	if account, ok := spec["serviceAccount"]; ok && reflect.DeepEqual(account, spec["serviceAccountName"]) {
+		delete(spec, "serviceAccount")
+	}
+	if tolerations, ok := spec["tolerations"].([]interface{}); ok {
+		kept := make([]interface{}, 0, len(tolerations))
+		for _, toleration := range tolerations {
+			if !isDefaultToleration(toleration) {
+				kept = append(kept, toleration)
+			}
+		}
+		if len(kept) == 0 {
+			delete(spec, "tolerations")
+		} else {
+			spec["tolerations"] = kept
+		}
+	}
+}
+
+// removeDefault deletes the field at path from value if it holds def. A path segment ending
+// in [] selects every item of the list held by the field.
+func removeDefault(value interface{}, path []string, def interface{}) {
+	obj, ok := value.(map[string]interface{})
+	if !ok || len(path) == 0 {
+		return
+	}
+	key := path[0]
+	if len(path) == 1 {
+		if child, ok := obj[key]; ok && reflect.DeepEqual(child, def) {
+			delete(obj, key)
+		}
+		return
+	}
+	if name, isList := strings.CutSuffix(key, "[]"); isList {
+		items, _ := obj[name].([]interface{})
+		for _, item := range items {
+			removeDefault(item, path[1:], def)
+		}
+		return
+	}
+	removeDefault(obj[key], path[1:], def)
+}
+
+// isListKind checks if an object is a list of objects returned by kubectl: a v1 List or a
+// built-in list kind such as PodList, whose items don't carry their own spec
+func isListKind(obj map[string]interface{}) bool {
+	kind, _ := obj["kind"].(string)
+	if kind == "List" {
+		return true
+	}
+	_, hasSpec := obj["spec"]
+	return strings.HasSuffix(kind, "List") && !hasSpec
+}
+
+// isDefaultToleration checks if a toleration was added by the DefaultTolerationSeconds admission plugin
+func isDefaultToleration(value interface{}) bool {
+	toleration, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	key, _ := toleration["key"].(string)
+	seconds, _ := toleration["tolerationSeconds"].(float64)
+	return defaultTolerationKeys[key] && seconds == 300
+}
+
+// renderTable renders kubectl JSON output as a table with the given columns, or the default
+// columns for the returned objects if none are given
+func renderTable(output string, columns []string, now time.Time) (string, error) {
+	objects, err := decodeObjects(output)
+	if err != nil {
+		return "", err
+	}
+	summaries := make([]ResourceSummary, len(objects))
+	for i, obj := range objects {
+		summaries[i] = summarizeObject(obj, now)
+	}
+	if len(summaries) == 0 {
+		return "No resources found\n", nil
+	}
+	if len(columns) == 0 {
+		columns = defaultColumns(summaries)
+	}
+
+	var out strings.Builder
+	w := tabwriter.NewWriter(&out, 0, 8, 3, ' ', 0)
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		parts := strings.Split(strings.TrimPrefix(column, "."), ".")
+		headers[i] = strings.ToUpper(parts[len(parts)-1])
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+
+	for i, summary := range summaries {
+		values := make([]string, len(columns))
+		for j, column := range columns {
+			values[j] = columnValue(column, summary, objects[i])
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	_ = w.Flush()
+	return out.String(), nil
+}
+
+// defaultColumns returns the columns shown when none are selected: the namespace and kind when
+// objects differ in them, name, status, age and the kind specific fields
+func defaultColumns(summaries []ResourceSummary) []string {
+	namespaces := make(map[string]bool)
+	kinds := make(map[string]bool)
+	fields := make(map[string]bool)
+	hasStatus := false
+	for _, s := range summaries {
+		namespaces[s.Namespace] = true
+		kinds[s.Kind] = true
+		hasStatus = hasStatus || s.Status != ""
+		for key := range s.Fields {
+			fields[key] = true
+		}
+	}
+
+	var columns []string
+	if len(namespaces) > 1 {
+		columns = append(columns, "namespace")
+	}
+	if len(kinds) > 1 {
+		columns = append(columns, "kind")
+	}
+	columns = append(columns, "name")
+	if hasStatus {
+		columns = append(columns, "status")
+	}
+	columns = append(columns, "age")
+
+	extra := make([]string, 0, len(fields))
+	for key := range fields {
+		extra = append(extra, key)
+	}
+	sort.Strings(extra)
+	return append(columns, extra...)
+}
+
+// columnValue returns the value of a table column for an object
+func columnValue(column string, summary ResourceSummary, obj map[string]interface{}) string {
+	var value string
+	switch column {
+	case "kind":
+		value = summary.Kind
+	case "name":
+		value = summary.Name
+	case "namespace":
+		value = summary.Namespace
+	case "status":
+		value = summary.Status
+	case "age":
+		value = summary.Age
+	default:
+		if strings.HasPrefix(column, ".") {
+			value = formatValue(nestedField(obj, strings.Split(strings.TrimPrefix(column, "."), ".")...))
+		} else {
+			value = summary.Fields[column]
+		}
+	}
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+// formatValue renders a decoded JSON value for a table cell
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,184 @@
+package kubectl
+
+import (
+	"strings"
+	"testing"
+)
+
+const deploymentJSON = `{
+    "apiVersion": "apps/v1",
+    "kind": "Deployment",
+    "metadata": {
+        "name": "web",
+        "namespace": "default",
+        "annotations": {
+            "deployment.kubernetes.io/revision": "3",
+            "kubectl.kubernetes.io/last-applied-configuration": "{\"apiVersion\":\"apps/v1\"}"
+        },
+        "managedFields": [{"manager": "kubectl", "operation": "Apply"}]
+    },
+    "spec": {
+        "replicas": 2,
+        "revisionHistoryLimit": 10,
+        "progressDeadlineSeconds": 300,
+        "template": {
+            "spec": {
+                "dnsPolicy": "ClusterFirst",
+                "schedulerName": "default-scheduler",
+                "securityContext": {},
+                "serviceAccount": "web",
+                "serviceAccountName": "web",
+                "tolerations": [
+                    {"key": "node.kubernetes.io/not-ready", "operator": "Exists", "effect": "NoExecute", "tolerationSeconds": 300},
+                    {"key": "dedicated", "operator": "Exists"}
+                ],
+                "volumes": [{"name": "cache", "emptyDir": {}}],
+                "containers": [{
+                    "name": "app",
+                    "image": "nginx",
+                    "ports": [{"containerPort": 80, "protocol": "TCP"}],
+                    "resources": {},
+                    "terminationMessagePath": "/dev/termination-log",
+                    "terminationMessagePolicy": "File"
+                }]
+            }
+        }
+    },
+    "status": {
+        "conditions": [
+            {"type": "Available", "status": "True", "lastTransitionTime": "2025-01-01T11:00:00Z", "lastUpdateTime": "2025-01-01T11:00:00Z"}
+        ]
+    }
+}`
+
+func TestCompactOutput(t *testing.T) {
+	compacted := compactOutput(deploymentJSON, "json")
+
+	for _, stripped := range []string{
+		"managedFields", "last-applied-configuration", "revisionHistoryLimit", "dnsPolicy", "schedulerName",
+		"securityContext", "\"serviceAccount\"", "not-ready", "\"protocol\"", "\"resources\"", "terminationMessage", "lastUpdateTime",
+	} {
+		if strings.Contains(compacted, stripped) {
+			t.Errorf("compact output contains %s:\n%s", stripped, compacted)
+		}
+	}
+	for _, kept := range []string{
+		"deployment.kubernetes.io/revision", "\"progressDeadlineSeconds\": 300", "\"serviceAccountName\": \"web\"",
+		"\"key\": \"dedicated\"", "\"emptyDir\": {}", "\"containerPort\": 80", "lastTransitionTime",
+	} {
+		if !strings.Contains(compacted, kept) {
+			t.Errorf("compact output is missing %s:\n%s", kept, compacted)
+		}
+	}
+	if len(compacted) >= len(deploymentJSON) {
+		t.Errorf("compact output is not smaller: %d >= %d bytes", len(compacted), len(deploymentJSON))
+	}
+}
+
+func TestCompactOutputKeepsUserData(t *testing.T) {
+	list := `{
+    "apiVersion": "v1",
+    "kind": "List",
+    "items": [
+        {
+            "apiVersion": "v1",
+            "kind": "ConfigMap",
+            "metadata": {"name": "settings", "labels": {"protocol": "TCP", "tier": ""}, "annotations": {"priority": "0"}},
+            "data": {"dnsPolicy": "ClusterFirst", "revisionHistoryLimit": "10", "empty": ""}
+        },
+        {
+            "apiVersion": "example.com/v1",
+            "kind": "Gateway",
+            "metadata": {"name": "edge", "managedFields": [{"manager": "kubectl"}]},
+            "spec": {"protocol": "TCP", "priority": 0, "serviceAccount": "edge", "routes": [], "options": {}}
+        }
+    ]
+}`
+	compacted := compactOutput(list, "json")
+
+	for _, kept := range []string{
+		"\"protocol\": \"TCP\"", "\"tier\": \"\"", "\"priority\": \"0\"", "\"dnsPolicy\": \"ClusterFirst\"",
+		"\"revisionHistoryLimit\": \"10\"", "\"empty\": \"\"", "\"priority\": 0", "\"serviceAccount\": \"edge\"",
+		"\"routes\": []", "\"options\": {}",
+	} {
+		if !strings.Contains(compacted, kept) {
+			t.Errorf("compact output is missing %s:\n%s", kept, compacted)
+		}
+	}
+	if strings.Contains(compacted, "managedFields") {
+		t.Errorf("compact output contains managedFields:\n%s", compacted)
+	}
+}
+
+func TestCompactOutputYAML(t *testing.T) {
+	yamlOutput := "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\nspec:\n  sessionAffinity: None\n  type: ClusterIP\n"
+	want := "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\nspec:\n  type: ClusterIP\n"
+	if got := compactOutput(yamlOutput, "yaml"); got != want {
+		t.Errorf("compactOutput() = %q, want %q", got, want)
+	}
+
+	// Errors returned as output are passed on unchanged
+	errorOutput := "Error from server (NotFound): pods \"web\" not found\n"
+	if got := compactOutput(errorOutput, "json"); got != errorOutput {
+		t.Errorf("compactOutput() = %q, want unchanged error", got)
+	}
+}
+
+func TestOutputFormatOf(t *testing.T) {
+	tests := map[string]string{
+		"web -o yaml":      "yaml",
+		"-ojson":           "json",
+		"-o=yaml":          "yaml",
+		"--output=json":    "json",
+		"--output yaml -A": "yaml",
+		"-o wide":          "",
+		"-o jsonpath={.a}": "",
+		"-n default":       "",
+	}
+	for args, want := range tests {
+		if got := outputFormatOf(args); got != want {
+			t.Errorf("outputFormatOf(%q) = %q, want %q", args, got, want)
+		}
+	}
+}
+
+func TestRenderTableDefaultColumns(t *testing.T) {
+	table, err := renderTable(podListJSON, nil, structuredTestNow)
+	if err != nil {
+		t.Fatalf("renderTable() unexpected error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(table), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("renderTable() = %q, want header and two rows", table)
+	}
+	for _, column := range []string{"KIND", "NAME", "STATUS", "AGE", "CLUSTERIP", "RESTARTS"} {
+		if !strings.Contains(lines[0], column) {
+			t.Errorf("header %q is missing %s", lines[0], column)
+		}
+	}
+	if strings.Contains(lines[0], "NAMESPACE") {
+		t.Errorf("header %q shows a namespace column for a single namespace", lines[0])
+	}
+}
+
+func TestGetVerbosity(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{nil, VerbosityFull, false},
+		{"", VerbosityFull, false},
+		{"compact", VerbosityCompact, false},
+		{"full", VerbosityFull, false},
+		{"table", VerbosityTable, false},
+		{"verbose", "", true},
+		{true, "", true},
+	}
+	for _, tt := range tests {
+		got, err := getVerbosity(map[string]interface{}{VerbosityParam: tt.value})
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("getVerbosity(%v) = %q, %v, want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
@@ -1,40 +1,118 @@
 package kubectl
 
 import (
-	"fmt"
+	"io"
 	"strings"
 
 	"github.com/Azure/mcp-kubernetes/pkg/command"
 	"github.com/Azure/mcp-kubernetes/pkg/config"
 	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
 	"github.com/Azure/mcp-kubernetes/pkg/tools"
 )
 
 // KubectlExecutor implements the CommandExecutor interface for kubectl commands
-type KubectlExecutor struct{}
+type KubectlExecutor struct {
+	cache *resultCache
+}
 
 // This line ensures KubectlExecutor implements the CommandExecutor interface
 var _ tools.CommandExecutor = (*KubectlExecutor)(nil)
 
 // NewExecutor creates a new KubectlExecutor instance
 func NewExecutor() *KubectlExecutor {
-	return &KubectlExecutor{}
+	return &KubectlExecutor{
+		cache: newResultCache(),
+	}
 }
 
-// executeKubectlCommand executes a kubectl command with the given arguments
-func (e *KubectlExecutor) executeKubectlCommand(cmd string, args string, cfg *config.ConfigData) (string, error) {
-	process := command.NewShellProcess("kubectl", cfg.Timeout)
-
-	var fullCmd string
+// buildKubectlCommand builds the full kubectl command line from a command and its arguments
+func buildKubectlCommand(cmd string, args string) string {
 	if strings.HasPrefix(cmd, "kubectl ") {
 		// If command already includes "kubectl", use it as is (for backward compatibility)
-		fullCmd = cmd
-	} else {
-		// Otherwise build the command
-		fullCmd = "kubectl " + cmd
-		if args != "" {
-			fullCmd += " " + args
+		return cmd
+	}
+
+	// Otherwise build the command
+	fullCmd := "kubectl " + cmd
+	if args != "" {
+		fullCmd += " " + args
+	}
+	return fullCmd
+}
+
+// runKubectlCommand runs a kubectl command through the result cache. Cacheable read-only
+// commands are served from the cache, and any other command invalidates the cached
+// results of its namespace after it ran.
+func (e *KubectlExecutor) runKubectlCommand(cmd string, args string, stdin io.Reader, timeout int, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	if !cfg.CacheEnabled || e.cache == nil {
+		return e.executeKubectlCommand(cmd, args, stdin, timeout, params, cfg)
+	}
+
+	fullCmd := buildKubectlCommand(cmd, args)
+	parsed, err := parseCommand(fullCmd, cfg.Kubeconfig)
+	if err != nil {
+		return e.executeKubectlCommand(cmd, args, stdin, timeout, params, cfg)
+	}
+
+	if !security.NewValidator(cfg.SecurityConfig).IsReadOnlyCommand(fullCmd, security.CommandTypeKubectl) {
+		defer e.cache.invalidate(parsed.namespace)
+		return e.executeKubectlCommand(cmd, args, stdin, timeout, params, cfg)
+	}
+
+	if !isCacheable(parsed.operation) || stdin != nil {
+		return e.executeKubectlCommand(cmd, args, stdin, timeout, params, cfg)
+	}
+
+	// Results of the current context must not be served after switching to another one
+	if _, ok := parsed.flags["--context"]; !ok {
+		kubeconfig := cfg.Kubeconfig
+		if path, ok := parsed.flags["--kubeconfig"]; ok {
+			kubeconfig = path
 		}
+		parsed.key += "\x00" + currentContext(kubeconfig)
+	}
+
+	cacheMode, _ := params[CacheParam].(string)
+	return e.cache.do(parsed, cacheMode == "bypass", func() (string, error) {
+		return e.executeKubectlCommand(cmd, args, stdin, timeout, params, cfg)
+	})
+}
+
+// invalidateCache drops cached results affected by a mutating command that ran outside the cache
+func (e *KubectlExecutor) invalidateCache(fullCmd string, cfg *config.ConfigData) {
+	if !cfg.CacheEnabled || e.cache == nil {
+		return
+	}
+	parsed, err := parseCommand(fullCmd, cfg.Kubeconfig)
+	if err != nil {
+		parsed.namespace = ""
+	}
+	e.cache.invalidate(parsed.namespace)
+}
+
+// InvalidateCache drops all cached results, e.g. after another tool changed cluster objects
+func (e *KubectlExecutor) InvalidateCache() {
+	if e.cache != nil {
+		e.cache.invalidate("")
+	}
+}
+
+// executeKubectlCommand executes a kubectl command with the given arguments
+func (e *KubectlExecutor) executeKubectlCommand(cmd string, args string, stdin io.Reader, timeout int, params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	process := command.NewShellProcess("kubectl", timeout)
+	process.Logger = tools.Logger(params)
+	process.Env = cfg.CommandEnv(security.CommandTypeKubectl)
+	process.Dir = cfg.CommandDir(tools.SessionID(params))
+	process.Cassettes = cfg.Cassettes
+	process.Stdin = stdin
+	process.Context = tools.Context(params)
+
+	fullCmd := buildKubectlCommand(cmd, args)
+
+	// Only read-only commands are safe to retry after transient errors
+	if security.NewValidator(cfg.SecurityConfig).IsReadOnlyCommand(fullCmd, security.CommandTypeKubectl) {
+		process.Retry = cfg.RetryPolicy()
 	}
 
 	return process.Run(fullCmd)
@@ -44,18 +122,24 @@ func (e *KubectlExecutor) executeKubectlCommand(cmd string, args string, cfg *co
 func (e *KubectlExecutor) Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
 	kubectlCmd, ok := params["command"].(string)
 	if !ok {
-		return "", fmt.Errorf("invalid command parameter")
+		return "", toolerror.New(toolerror.InvalidArguments, "invalid command parameter")
 	}
 
 	// Validate the command against security settings
-	validator := security.NewValidator(cfg.SecurityConfig)
+	validator := security.NewValidator(cfg.SecurityConfig).ForSession(tools.SessionID(params))
 	err := validator.ValidateCommand(kubectlCmd, security.CommandTypeKubectl)
 	if err != nil {
 		return "", err
 	}
 
+	// Resolve the timeout for this operation
+	timeout, err := resolveTimeout(kubectlCmd, params, cfg)
+	if err != nil {
+		return "", err
+	}
+
 	// Execute the command
-	return e.executeKubectlCommand(kubectlCmd, "", cfg)
+	return e.runKubectlCommand(kubectlCmd, "", nil, timeout, params, cfg)
 }
 
 // ExecuteSpecificCommand executes a specific kubectl command with the given arguments
@@ -72,12 +156,28 @@ func (e *KubectlExecutor) ExecuteSpecificCommand(cmd string, params map[string]i
 	}
 
 	// Validate the command against security settings
-	validator := security.NewValidator(cfg.SecurityConfig)
+	validator := security.NewValidator(cfg.SecurityConfig).ForSession(tools.SessionID(params))
 	err := validator.ValidateCommand(fullCmd, security.CommandTypeKubectl)
 	if err != nil {
 		return "", err
 	}
 
+	// Resolve the timeout for this operation
+	timeout, err := resolveTimeout(fullCmd, params, cfg)
+	if err != nil {
+		return "", err
+	}
+
 	// Execute the command
-	return e.executeKubectlCommand(cmd, args, cfg)
+	return e.runKubectlCommand(cmd, args, nil, timeout, params, cfg)
+}
+
+// resolveTimeout returns the timeout for a kubectl command, honoring the optional per-call timeout
+func resolveTimeout(kubectlCmd string, params map[string]interface{}, cfg *config.ConfigData) (int, error) {
+	requestedTimeout, err := tools.GetTimeoutSeconds(params)
+	if err != nil {
+		return 0, err
+	}
+	operation := security.ExtractOperation(kubectlCmd, security.CommandTypeKubectl)
+	return cfg.ResolveTimeout(security.CommandTypeKubectl, operation, requestedTimeout), nil
 }
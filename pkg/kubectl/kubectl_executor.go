@@ -2,56 +2,142 @@ package kubectl
 
 import (
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/Azure/mcp-kubernetes/pkg/config"
 	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
 )
 
 // KubectlToolExecutor handles structured kubectl command execution for grouped tools
 type KubectlToolExecutor struct {
 	executor *KubectlExecutor
+	// backend runs supported operations without the kubectl binary, nil uses kubectl for everything
+	backend Backend
+	// fallback runs operations the backend doesn't support with the kubectl binary
+	fallback bool
+	// elicit asks users to resolve ambiguous calls, nil fails them with the choices
+	elicit tools.Elicitor
+	// contexts lists the contexts of a kubeconfig
+	contexts func(kubeconfig string) ([]string, error)
 }
 
 // NewKubectlToolExecutor creates a new kubectl tool executor
 func NewKubectlToolExecutor() *KubectlToolExecutor {
 	return &KubectlToolExecutor{
 		executor: NewExecutor(),
+		contexts: kubeconfigContexts,
 	}
 }
 
-// Execute processes structured kubectl commands with operation/resource/args parameters
+// NewKubectlToolExecutorWithBackend creates a kubectl tool executor that runs the operations
+// supported by the backend natively. Everything else runs with the kubectl binary when fallback
+// is set, and fails otherwise.
+func NewKubectlToolExecutorWithBackend(backend Backend, fallback bool) *KubectlToolExecutor {
+	return &KubectlToolExecutor{
+		executor: NewExecutor(),
+		backend:  backend,
+		fallback: fallback,
+		contexts: kubeconfigContexts,
+	}
+}
+
+// InvalidateCache drops all cached kubectl results
+func (e *KubectlToolExecutor) InvalidateCache() {
+	e.executor.InvalidateCache()
+}
+
+// Execute processes structured kubectl commands with operation/resource/args parameters. kubectl errors
+// returned as output are reported as typed errors when they are recognized.
 func (e *KubectlToolExecutor) Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	if params["_tool_name"] == BatchToolName {
+		result, err := e.executeBatch(params, cfg)
+		if err != nil {
+			return "", err
+		}
+		return result.Text, nil
+	}
+
+	output, err := e.execute(params, cfg)
+	if err == nil {
+		if typedErr := toolerror.FromKubectlOutput(output); typedErr != nil {
+			return "", typedErr
+		}
+	}
+	return output, err
+}
+
+// execute runs a structured kubectl command and returns its output
+func (e *KubectlToolExecutor) execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
 	// Extract structured parameters
 	operation, ok := params["operation"].(string)
 	if !ok {
-		return "", fmt.Errorf("operation parameter is required and must be a string")
+		return "", toolerror.Newf(toolerror.InvalidArguments, "operation parameter is required and must be a string")
 	}
 
 	resource, ok := params["resource"].(string)
 	if !ok {
-		return "", fmt.Errorf("resource parameter is required and must be a string")
+		return "", toolerror.Newf(toolerror.InvalidArguments, "resource parameter is required and must be a string")
 	}
 
+	// Write variants of split tools run like the tool they were split from
+	toolName, _ := params["_tool_name"].(string)
+	toolName, writeVariant := tools.BaseToolName(toolName)
+
+	// args is optional for tools with typed parameters
 	args, ok := params["args"].(string)
-	if !ok {
-		return "", fmt.Errorf("args parameter is required and must be a string")
+	if _, hasTyped := toolTypedParams[toolName]; !ok && (params["args"] != nil || !hasTyped) {
+		return "", toolerror.Newf(toolerror.InvalidArguments, "args parameter is required and must be a string")
 	}
 
-	// Get the tool name from params (injected by handler)
-	toolName, _ := params["_tool_name"].(string)
-
 	// Validate the operation/resource combination
 	if err := e.validateCombination(toolName, operation, resource); err != nil {
 		return "", err
 	}
 
+	// The read variants of split tools only run operations that don't modify the cluster
+	if _, ok := readOperations[toolName]; ok && cfg.SplitTools && !writeVariant && !isReadOperation(toolName, operation, resource) {
+		name := operation
+		if readOperations[toolName][operation] != nil {
+			name += " " + resource
+		}
+		return "", toolerror.Newf(toolerror.InvalidArguments, "operation '%s' modifies the cluster and is not available in %s, use %s",
+			name, toolName, tools.WriteVariantName(toolName))
+	}
+
+	// Assemble the typed parameters with the free-form args, which get stricter validation
+	if err := validateArgs(toolName, params, args); err != nil {
+		return "", err
+	}
+	typedArgs, err := buildTypedArgs(toolName, operation, params)
+	if err != nil {
+		return "", err
+	}
+	args = strings.TrimSpace(strings.Join(append(typedArgs, args), " "))
+
 	// Map operation to kubectl command
 	kubectlCommand, err := MapOperationToCommand(toolName, operation, resource)
 	if err != nil {
 		return "", err
 	}
 
+	// Inline manifests are piped to kubectl on stdin
+	manifest, _ := params["manifest"].(string)
+	var objects []security.ManifestObject
+	if manifest != "" {
+		if err := validateManifestArgs(toolName, operation, resource, args); err != nil {
+			return "", err
+		}
+		objects, err = parseManifest(manifest)
+		if err != nil {
+			return "", err
+		}
+		args = strings.TrimSpace("-f - " + args)
+	}
+
 	// Build the full command
 	fullCommand := e.buildCommand(kubectlCommand, resource, args)
 
@@ -61,13 +147,174 @@ func (e *KubectlToolExecutor) Execute(params map[string]interface{}, cfg *config
 	}
 
 	// Validate the command against security settings
-	validator := security.NewValidator(cfg.SecurityConfig)
+	validator := security.NewValidator(cfg.SecurityConfig).ForSession(tools.SessionID(params))
 	if err := validator.ValidateCommand(fullCommand, security.CommandTypeKubectl); err != nil {
 		return "", err
 	}
 
+	// Ambiguous calls continue with the choice of the user
+	resolved, err := e.resolveAmbiguity(toolName, operation, fullCommand, params, cfg)
+	if err != nil {
+		return "", err
+	}
+	if resolved != nil {
+		return e.execute(resolved, cfg)
+	}
+
+	// Validate every object of the manifest against security settings
+	var stdin io.Reader
+	if manifest != "" {
+		if err := validator.ValidateManifestObjects(objects, fullCommand, e.scopeResolver(params, cfg)); err != nil {
+			return "", err
+		}
+		stdin = strings.NewReader(manifest)
+	}
+
+	// Resolve the timeout for this operation
+	timeout, err := resolveTimeout(fullCommand, params, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	// Run supported operations on the native backend
+	if e.backend != nil {
+		req := BackendRequest{
+			ToolName:  toolName,
+			Operation: operation,
+			Resource:  resource,
+			Args:      args,
+			Manifest:  manifest,
+			Dir:       cfg.CommandDir(tools.SessionID(params)),
+			Timeout:   timeout,
+			Context:   tools.Context(params),
+		}
+		if e.backend.Supports(req) {
+			if !validator.IsReadOnlyCommand(fullCommand, security.CommandTypeKubectl) {
+				defer e.executor.invalidateCache(fullCommand, cfg)
+			}
+			return e.backend.Execute(req)
+		}
+		if !e.fallback {
+			return "", fmt.Errorf("command is not supported by the configured backend: kubectl %s", fullCommand)
+		}
+	}
+
 	// Execute the command directly
-	return e.executor.executeKubectlCommand(fullCommand, "", cfg)
+	return e.executor.runKubectlCommand(fullCommand, "", stdin, timeout, params, cfg)
+}
+
+// ExecuteStructured runs a command like Execute and adds structured content to kubectl_resources and
+// kubectl_diagnostics results. With output=json or output=summary, or verbosity=table, get and events run
+// with -o json and the objects are rendered from its output. With verbosity=compact, -o yaml and -o json
+// output is stripped of noise.
+func (e *KubectlToolExecutor) ExecuteStructured(params map[string]interface{}, cfg *config.ConfigData) (*tools.Result, error) {
+	toolName, _ := params["_tool_name"].(string)
+	if toolName == BatchToolName {
+		return e.executeBatch(params, cfg)
+	}
+	if toolName, _ = tools.BaseToolName(toolName); structuredOperations[toolName] == "" {
+		output, err := e.Execute(params, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &tools.Result{Text: output}, nil
+	}
+
+	format, err := getOutputFormat(params)
+	if err != nil {
+		return nil, err
+	}
+	verbosity, err := getVerbosity(params)
+	if err != nil {
+		return nil, err
+	}
+	operation, _ := params["operation"].(string)
+	args, _ := params["args"].(string)
+
+	if format == OutputJSON || format == OutputSummary || verbosity == VerbosityTable {
+		return e.executeRendered(params, cfg, structuredOperations[toolName], operation, args, format, verbosity)
+	}
+
+	output, err := e.Execute(params, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if verbosity == VerbosityCompact && operation == "get" {
+		outputFormat := outputFormatOf(args)
+		if format == OutputYAML {
+			outputFormat = "yaml"
+		}
+		if outputFormat != "" {
+			compacted := compactOutput(output, outputFormat)
+			return &tools.Result{
+				Text:       compacted,
+				Structured: &ResourceOutput{Text: compacted},
+				Meta:       sizeMeta(output, compacted),
+			}, nil
+		}
+	}
+	return &tools.Result{Text: output, Structured: &ResourceOutput{Text: output}}, nil
+}
+
+// executeRendered runs the rendered operation of a tool with -o json and renders the objects in the requested format
+func (e *KubectlToolExecutor) executeRendered(params map[string]interface{}, cfg *config.ConfigData, rendered, operation, args, format, verbosity string) (*tools.Result, error) {
+	mode := fmt.Sprintf("%s '%s'", OutputParam, format)
+	if verbosity == VerbosityTable {
+		if format != OutputText {
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "%s '%s' can't be combined with %s '%s'", VerbosityParam, verbosity, OutputParam, format)
+		}
+		mode = fmt.Sprintf("%s '%s'", VerbosityParam, verbosity)
+	}
+	if operation != rendered {
+		return nil, toolerror.Newf(toolerror.InvalidArguments, "%s is only supported for the %s operation", mode, rendered)
+	}
+	if hasOutputFlag(args) {
+		return nil, toolerror.Newf(toolerror.InvalidArguments, "args must not contain an output flag (-o) with %s", mode)
+	}
+
+	jsonParams := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		jsonParams[key] = value
+	}
+	jsonParams["args"] = strings.TrimSpace(args + " -o json")
+
+	output, err := e.Execute(jsonParams, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// kubectl errors are returned as output and are passed on as text
+	now := time.Now()
+	summaries, parseErr := summarizeObjects(output, now)
+	if parseErr != nil {
+		return &tools.Result{Text: output, Structured: &ResourceOutput{Text: output}}, nil
+	}
+
+	var text string
+	structured := &ResourceOutput{}
+	switch {
+	case verbosity == VerbosityTable:
+		text, err = renderTable(output, getColumns(params), now)
+		if err != nil {
+			return nil, err
+		}
+		structured.Text = text
+	case format == OutputSummary:
+		text = renderSummaries(summaries)
+		structured.Text = text
+	default:
+		text = renderSummaries(summaries)
+		structured.Items = summaries
+	}
+	return &tools.Result{Text: text, Structured: structured, Meta: sizeMeta(output, text)}, nil
+}
+
+// sizeMeta reports the size of kubectl's output and of the rendered result
+func sizeMeta(original, rendered string) map[string]interface{} {
+	return map[string]interface{}{
+		"originalBytes": len(original),
+		"renderedBytes": len(rendered),
+	}
 }
 
 // validateCombination validates if the operation/resource combination is valid for the tool
@@ -86,7 +333,7 @@ func (e *KubectlToolExecutor) validateCombination(toolName, operation, resource
 	case "kubectl_config":
 		return e.validateConfigOperation(operation, resource)
 	default:
-		return fmt.Errorf("unknown tool: %s", toolName)
+		return toolerror.Newf(toolerror.InvalidArguments, "unknown tool: %s", toolName)
 	}
 }
 
@@ -118,7 +365,7 @@ func (e *KubectlToolExecutor) validateResourcesOperation(operation string) error
 
 	allOps := append(readOnlyOps, writeOps...)
 	allOps = append(allOps, nodeOps...)
-	return fmt.Errorf("invalid operation '%s' for resources tool. Valid operations: %s",
+	return toolerror.Newf(toolerror.InvalidArguments, "invalid operation '%s' for resources tool. Valid operations: %s",
 		operation, strings.Join(allOps, ", "))
 }
 
@@ -135,13 +382,13 @@ func (e *KubectlToolExecutor) validateWorkloadsOperation(operation, resource str
 						return nil
 					}
 				}
-				return fmt.Errorf("invalid rollout subcommand '%s'. Valid subcommands: %s",
+				return toolerror.Newf(toolerror.InvalidArguments, "invalid rollout subcommand '%s'. Valid subcommands: %s",
 					resource, strings.Join(validSubcmds, ", "))
 			}
 			return nil
 		}
 	}
-	return fmt.Errorf("invalid operation '%s' for workloads tool. Valid operations: %s",
+	return toolerror.Newf(toolerror.InvalidArguments, "invalid operation '%s' for workloads tool. Valid operations: %s",
 		operation, strings.Join(validOps, ", "))
 }
 
@@ -153,7 +400,7 @@ func (e *KubectlToolExecutor) validateMetadataOperation(operation string) error
 			return nil
 		}
 	}
-	return fmt.Errorf("invalid operation '%s' for metadata tool. Valid operations: %s",
+	return toolerror.Newf(toolerror.InvalidArguments, "invalid operation '%s' for metadata tool. Valid operations: %s",
 		operation, strings.Join(validOps, ", "))
 }
 
@@ -165,7 +412,7 @@ func (e *KubectlToolExecutor) validateDiagnosticsOperation(operation string) err
 			return nil
 		}
 	}
-	return fmt.Errorf("invalid operation '%s' for diagnostics tool. Valid operations: %s",
+	return toolerror.Newf(toolerror.InvalidArguments, "invalid operation '%s' for diagnostics tool. Valid operations: %s",
 		operation, strings.Join(validOps, ", "))
 }
 
@@ -177,7 +424,7 @@ func (e *KubectlToolExecutor) validateClusterOperation(operation string) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("invalid operation '%s' for cluster tool. Valid operations: %s",
+	return toolerror.Newf(toolerror.InvalidArguments, "invalid operation '%s' for cluster tool. Valid operations: %s",
 		operation, strings.Join(validOps, ", "))
 }
 
@@ -189,7 +436,7 @@ func (e *KubectlToolExecutor) validateConfigOperation(operation, resource string
 		return nil
 	case "auth":
 		if resource != "can-i" {
-			return fmt.Errorf("auth operation requires 'can-i' as resource")
+			return toolerror.Newf(toolerror.InvalidArguments, "auth operation requires 'can-i' as resource")
 		}
 		return nil
 	case "certificate":
@@ -200,7 +447,7 @@ func (e *KubectlToolExecutor) validateConfigOperation(operation, resource string
 				return nil
 			}
 		}
-		return fmt.Errorf("invalid certificate subcommand '%s'. Valid subcommands: %s",
+		return toolerror.Newf(toolerror.InvalidArguments, "invalid certificate subcommand '%s'. Valid subcommands: %s",
 			resource, strings.Join(validSubcmds, ", "))
 	case "config":
 		// Config operations for context and configuration management
@@ -212,10 +459,10 @@ func (e *KubectlToolExecutor) validateConfigOperation(operation, resource string
 				return nil
 			}
 		}
-		return fmt.Errorf("invalid config subcommand '%s'. Valid subcommands: %s",
+		return toolerror.Newf(toolerror.InvalidArguments, "invalid config subcommand '%s'. Valid subcommands: %s",
 			resource, strings.Join(validSubcmds, ", "))
 	default:
-		return fmt.Errorf("invalid operation '%s' for config tool. Valid operations: diff, auth, certificate, config",
+		return toolerror.Newf(toolerror.InvalidArguments, "invalid operation '%s' for config tool. Valid operations: diff, auth, certificate, config",
 			operation)
 	}
 }
@@ -265,11 +512,11 @@ func (e *KubectlToolExecutor) checkAccessLevel(command string, cfg *config.Confi
 	switch cfg.AccessLevel {
 	case "readonly":
 		if category != "read-only" {
-			return fmt.Errorf("command requires %s access, but current access level is read-only", category)
+			return toolerror.Newf(toolerror.PolicyDenied, "command requires %s access, but current access level is read-only", category)
 		}
 	case "readwrite":
 		if category == "admin" {
-			return fmt.Errorf("command requires admin access, but current access level is read-write")
+			return toolerror.Newf(toolerror.PolicyDenied, "command requires admin access, but current access level is read-write")
 		}
 	case "admin":
 		// Admin can execute all commands
@@ -2,10 +2,12 @@ package kubectl
 
 import (
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/Azure/mcp-kubernetes/pkg/config"
 	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
 )
 
 func TestKubectlToolExecutor_ValidateCombination(t *testing.T) {
@@ -524,14 +526,25 @@ func TestKubectlToolExecutor_Execute(t *testing.T) {
 			errMsg:  "resource parameter is required",
 		},
 		{
-			name: "missing args",
+			name: "missing args without typed parameters",
+			params: map[string]interface{}{
+				"_tool_name": "kubectl_config",
+				"operation":  "config",
+				"resource":   "current-context",
+			},
+			wantErr: true,
+			errMsg:  "args parameter is required",
+		},
+		{
+			name: "args not a string",
 			params: map[string]interface{}{
 				"_tool_name": "kubectl_resources",
 				"operation":  "get",
 				"resource":   "pods",
+				"args":       42,
 			},
 			wantErr: true,
-			errMsg:  "args parameter is required",
+			errMsg:  "args parameter is required and must be a string",
 		},
 		{
 			name: "invalid combination",
@@ -619,3 +632,401 @@ func TestMapOperationToCommand(t *testing.T) {
 		})
 	}
 }
+
+// stubBackend records the requests it executes
+type stubBackend struct {
+	supports bool
+	output   string
+	requests []BackendRequest
+	mu       sync.Mutex
+}
+
+func (b *stubBackend) Supports(req BackendRequest) bool {
+	return b.supports
+}
+
+func (b *stubBackend) Execute(req BackendRequest) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.requests = append(b.requests, req)
+	if b.output != "" {
+		return b.output, nil
+	}
+	return "native output", nil
+}
+
+func TestKubectlToolExecutor_ExecuteWithBackend(t *testing.T) {
+	cfg := &config.ConfigData{
+		AccessLevel: "readonly",
+		SecurityConfig: &security.SecurityConfig{
+			AccessLevel: security.AccessLevelReadOnly,
+		},
+	}
+
+	backend := &stubBackend{supports: true}
+	executor := NewKubectlToolExecutorWithBackend(backend, true)
+
+	output, err := executor.Execute(map[string]interface{}{
+		"_tool_name": "kubectl_resources",
+		"operation":  "get",
+		"resource":   "pods",
+		"args":       "-n default",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Execute() unexpected error = %v", err)
+	}
+	if output != "native output" {
+		t.Errorf("Execute() = %q, want backend output", output)
+	}
+	if len(backend.requests) != 1 || backend.requests[0].Args != "-n default" || backend.requests[0].Resource != "pods" {
+		t.Errorf("backend requests = %+v", backend.requests)
+	}
+
+	// Access level checks run before the backend
+	_, err = executor.Execute(map[string]interface{}{
+		"_tool_name": "kubectl_resources",
+		"operation":  "delete",
+		"resource":   "pods",
+		"args":       "nginx",
+	}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("Execute() error = %v, want access level error", err)
+	}
+	if len(backend.requests) != 1 {
+		t.Errorf("backend executed a denied command: %+v", backend.requests)
+	}
+}
+
+func TestKubectlToolExecutor_ExecuteWithoutFallback(t *testing.T) {
+	cfg := &config.ConfigData{
+		AccessLevel: "readonly",
+		SecurityConfig: &security.SecurityConfig{
+			AccessLevel: security.AccessLevelReadOnly,
+		},
+	}
+
+	backend := &stubBackend{supports: false}
+	executor := NewKubectlToolExecutorWithBackend(backend, false)
+
+	_, err := executor.Execute(map[string]interface{}{
+		"_tool_name": "kubectl_diagnostics",
+		"operation":  "logs",
+		"resource":   "",
+		"args":       "nginx",
+	}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "not supported by the configured backend: kubectl logs nginx") {
+		t.Errorf("Execute() error = %v, want unsupported backend error", err)
+	}
+}
+
+func TestKubectlToolExecutor_ExecuteStructured(t *testing.T) {
+	cfg := &config.ConfigData{
+		AccessLevel: "readonly",
+		SecurityConfig: &security.SecurityConfig{
+			AccessLevel: security.AccessLevelReadOnly,
+		},
+	}
+
+	backend := &stubBackend{supports: true, output: podListJSON}
+	executor := NewKubectlToolExecutorWithBackend(backend, true)
+	params := func(tool, operation, args, output string) map[string]interface{} {
+		return map[string]interface{}{
+			"_tool_name": tool,
+			"operation":  operation,
+			"resource":   "pods",
+			"args":       args,
+			OutputParam:  output,
+		}
+	}
+
+	result, err := executor.ExecuteStructured(params("kubectl_resources", "get", "-n default", "json"), cfg)
+	if err != nil {
+		t.Fatalf("ExecuteStructured() unexpected error = %v", err)
+	}
+	if got := backend.requests[len(backend.requests)-1].Args; got != "-n default -o json" {
+		t.Errorf("backend args = %q, want -o json appended", got)
+	}
+	structured, ok := result.Structured.(*ResourceOutput)
+	if !ok || len(structured.Items) != 2 || structured.Items[0].Kind != "Pod" || structured.Text != "" {
+		t.Errorf("structured = %+v, want two summaries", result.Structured)
+	}
+	if !strings.HasPrefix(result.Text, "pod default/web CrashLoopBackOff") {
+		t.Errorf("text = %q, want compact rendering", result.Text)
+	}
+	if result.Meta["originalBytes"] != len(podListJSON) || result.Meta["renderedBytes"] != len(result.Text) {
+		t.Errorf("meta = %v, want original and rendered sizes", result.Meta)
+	}
+
+	result, err = executor.ExecuteStructured(params("kubectl_resources", "get", "", "summary"), cfg)
+	if err != nil {
+		t.Fatalf("ExecuteStructured() unexpected error = %v", err)
+	}
+	if structured, ok := result.Structured.(*ResourceOutput); !ok || structured.Items != nil || structured.Text != result.Text {
+		t.Errorf("structured = %+v, want summary text only", result.Structured)
+	}
+
+	// Text mode keeps kubectl output and only kubectl_resources declares structured content
+	backend.output = "NAME   READY\nweb    1/1\n"
+	result, err = executor.ExecuteStructured(params("kubectl_resources", "get", "", ""), cfg)
+	if err != nil || result.Text != backend.output || result.Meta != nil {
+		t.Fatalf("ExecuteStructured() = %+v, %v", result, err)
+	}
+	if structured, ok := result.Structured.(*ResourceOutput); !ok || structured.Text != backend.output {
+		t.Errorf("structured = %+v, want text", result.Structured)
+	}
+	result, err = executor.ExecuteStructured(params("kubectl_cluster", "cluster-info", "", ""), cfg)
+	if err != nil || result.Structured != nil {
+		t.Errorf("ExecuteStructured() for cluster = %+v, %v, want no structured content", result, err)
+	}
+
+	// kubectl_diagnostics renders events
+	backend.output = eventListJSON
+	result, err = executor.ExecuteStructured(params("kubectl_diagnostics", "events", "-n default", "json"), cfg)
+	if err != nil {
+		t.Fatalf("ExecuteStructured() for events unexpected error = %v", err)
+	}
+	if got := backend.requests[len(backend.requests)-1].Args; got != "-n default -o json" {
+		t.Errorf("backend args = %q, want -o json appended", got)
+	}
+	if structured, ok := result.Structured.(*ResourceOutput); !ok || len(structured.Items) != 1 || structured.Items[0].Status != "Warning" {
+		t.Errorf("structured = %+v, want the warning event", result.Structured)
+	}
+	if !strings.Contains(result.Text, "reason=BackOff") {
+		t.Errorf("text = %q, want compact rendering", result.Text)
+	}
+
+	for _, tc := range []map[string]interface{}{
+		params("kubectl_resources", "describe", "", "json"),
+		params("kubectl_diagnostics", "logs", "", "json"),
+		params("kubectl_diagnostics", "events", "", "yaml"),
+		params("kubectl_resources", "get", "-o wide", "json"),
+		params("kubectl_resources", "get", "", "xml"),
+	} {
+		if _, err := executor.ExecuteStructured(tc, cfg); err == nil {
+			t.Errorf("ExecuteStructured(%v) expected error", tc)
+		}
+	}
+}
+
+func TestKubectlToolExecutor_ExecuteVerbosity(t *testing.T) {
+	cfg := &config.ConfigData{
+		AccessLevel: "readonly",
+		SecurityConfig: &security.SecurityConfig{
+			AccessLevel: security.AccessLevelReadOnly,
+		},
+	}
+
+	backend := &stubBackend{supports: true}
+	executor := NewKubectlToolExecutorWithBackend(backend, true)
+	params := func(args, verbosity, columns string) map[string]interface{} {
+		return map[string]interface{}{
+			"_tool_name":   "kubectl_resources",
+			"operation":    "get",
+			"resource":     "pods",
+			"args":         args,
+			VerbosityParam: verbosity,
+			ColumnsParam:   columns,
+		}
+	}
+
+	// Full is the default, compact has to be requested
+	backend.output = "apiVersion: v1\nkind: Pod\nmetadata:\n  managedFields:\n  - manager: kubectl\n  name: web\nspec:\n  dnsPolicy: ClusterFirst\n"
+	result, err := executor.ExecuteStructured(params("web -o yaml", "", ""), cfg)
+	if err != nil || result.Text != backend.output || result.Meta != nil {
+		t.Errorf("default ExecuteStructured() = %+v, %v, want unchanged output", result, err)
+	}
+
+	result, err = executor.ExecuteStructured(params("web -o yaml", VerbosityCompact, ""), cfg)
+	if err != nil {
+		t.Fatalf("ExecuteStructured() unexpected error = %v", err)
+	}
+	if result.Text != "apiVersion: v1\nkind: Pod\nmetadata:\n  name: web\nspec: {}\n" {
+		t.Errorf("compact text = %q", result.Text)
+	}
+	if result.Meta["originalBytes"] != len(backend.output) || result.Meta["renderedBytes"] != len(result.Text) {
+		t.Errorf("meta = %v, want original and rendered sizes", result.Meta)
+	}
+
+	result, err = executor.ExecuteStructured(params("web -o yaml", VerbosityFull, ""), cfg)
+	if err != nil || result.Text != backend.output || result.Meta != nil {
+		t.Errorf("full ExecuteStructured() = %+v, %v, want unchanged output", result, err)
+	}
+
+	backend.output = podListJSON
+	result, err = executor.ExecuteStructured(params("-n default", VerbosityTable, "name,ready,.spec.nodeName"), cfg)
+	if err != nil {
+		t.Fatalf("ExecuteStructured() unexpected error = %v", err)
+	}
+	want := "NAME   READY    NODENAME\nweb    1/2      node-1\nweb    <none>   <none>\n"
+	if result.Text != want {
+		t.Errorf("table text = %q, want %q", result.Text, want)
+	}
+
+	combined := params("", VerbosityTable, "")
+	combined[OutputParam] = OutputJSON
+	if _, err := executor.ExecuteStructured(combined, cfg); err == nil {
+		t.Error("Expected error combining verbosity table with output json")
+	}
+}
+
+func TestKubectlToolExecutor_ExecuteTypedParams(t *testing.T) {
+	cfg := &config.ConfigData{
+		AccessLevel: "readonly",
+		SecurityConfig: &security.SecurityConfig{
+			AccessLevel: security.AccessLevelReadOnly,
+		},
+	}
+	cfg.SecurityConfig.SetAllowedNamespaces("prod")
+
+	backend := &stubBackend{supports: true}
+	executor := NewKubectlToolExecutorWithBackend(backend, true)
+
+	_, err := executor.Execute(map[string]interface{}{
+		"_tool_name":   "kubectl_resources",
+		"operation":    "get",
+		"resource":     "pods",
+		NameParam:      "web",
+		NamespaceParam: "prod",
+		OutputParam:    "wide",
+		"args":         "--show-labels",
+	}, cfg)
+	if err != nil {
+		t.Fatalf("Execute() unexpected error = %v", err)
+	}
+	if got := backend.requests[0].Args; got != "web --namespace=prod --output=wide --show-labels" {
+		t.Errorf("backend args = %q", got)
+	}
+
+	// Typed namespaces are checked against the allowed namespaces like args
+	_, err = executor.Execute(map[string]interface{}{
+		"_tool_name":   "kubectl_resources",
+		"operation":    "get",
+		"resource":     "pods",
+		NamespaceParam: "kube-system",
+	}, cfg)
+	if err == nil || !strings.Contains(err.Error(), "kube-system") {
+		t.Errorf("Execute() error = %v, want namespace denied", err)
+	}
+	if len(backend.requests) != 1 {
+		t.Errorf("backend ran %d requests, want 1", len(backend.requests))
+	}
+}
+
+func TestKubectlToolExecutor_ExecuteSplitTools(t *testing.T) {
+	cfg := &config.ConfigData{
+		AccessLevel: "readwrite",
+		SplitTools:  true,
+		SecurityConfig: &security.SecurityConfig{
+			AccessLevel: security.AccessLevelReadWrite,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		toolName    string
+		operation   string
+		resource    string
+		args        string
+		expectError string
+	}{
+		{name: "read operation on read variant", toolName: "kubectl_resources", operation: "get", resource: "pods", args: "-n default"},
+		{name: "write operation on read variant", toolName: "kubectl_resources", operation: "delete", resource: "pods", args: "nginx", expectError: "operation 'delete' modifies the cluster and is not available in kubectl_resources, use kubectl_resources_write"},
+		{name: "write operation on write variant", toolName: "kubectl_resources_write", operation: "delete", resource: "pods", args: "nginx"},
+		{name: "read subcommand on read variant", toolName: "kubectl_workloads", operation: "rollout", resource: "status", args: "deployment/web"},
+		{name: "write subcommand on read variant", toolName: "kubectl_workloads", operation: "rollout", resource: "undo", args: "deployment/web", expectError: "operation 'rollout undo' modifies the cluster"},
+		{name: "write subcommand on write variant", toolName: "kubectl_workloads_write", operation: "rollout", resource: "restart", args: "deployment/web"},
+		{name: "tool that isn't split", toolName: "kubectl_metadata", operation: "label", resource: "pods", args: "web tier=frontend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &stubBackend{supports: true}
+			executor := NewKubectlToolExecutorWithBackend(backend, false)
+			_, err := executor.Execute(map[string]interface{}{
+				"_tool_name": tt.toolName,
+				"operation":  tt.operation,
+				"resource":   tt.resource,
+				"args":       tt.args,
+			}, cfg)
+
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Execute() error = %v, want %q", err, tt.expectError)
+				}
+				if len(backend.requests) != 0 {
+					t.Errorf("backend executed a denied command: %+v", backend.requests)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() unexpected error = %v", err)
+			}
+			// Write variants run like the tool they were split from
+			if base := strings.TrimSuffix(tt.toolName, "_write"); backend.requests[0].ToolName != base {
+				t.Errorf("backend tool name = %q, want %q", backend.requests[0].ToolName, base)
+			}
+		})
+	}
+}
+
+func TestKubectlToolExecutor_ExecuteErrorCodes(t *testing.T) {
+	securityConfig := &security.SecurityConfig{AccessLevel: security.AccessLevelReadOnly}
+	securityConfig.SetAllowedNamespaces("default")
+	cfg := &config.ConfigData{AccessLevel: "readonly", SecurityConfig: securityConfig}
+
+	tests := []struct {
+		name       string
+		toolName   string
+		operation  string
+		resource   string
+		args       string
+		output     string
+		expectCode toolerror.Code
+	}{
+		{name: "success", toolName: "kubectl_resources", operation: "get", resource: "pods", args: "-n default"},
+		{name: "not found output", toolName: "kubectl_resources", operation: "get", resource: "pods", args: "web -n default",
+			output: "Error from server (NotFound): pods \"web\" not found\n", expectCode: toolerror.NotFound},
+		{name: "rbac denial output", toolName: "kubectl_resources", operation: "get", resource: "secrets", args: "-n default",
+			output: "Error from server (Forbidden): secrets is forbidden: User \"dev\" cannot list resource \"secrets\"\n", expectCode: toolerror.Forbidden},
+		{name: "access level", toolName: "kubectl_resources", operation: "delete", resource: "pods", args: "web -n default", expectCode: toolerror.PolicyDenied},
+		{name: "namespace not allowed", toolName: "kubectl_resources", operation: "get", resource: "pods", args: "-n kube-system", expectCode: toolerror.PolicyDenied},
+		{name: "invalid operation", toolName: "kubectl_resources", operation: "scale", resource: "pods", expectCode: toolerror.InvalidArguments},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := NewKubectlToolExecutorWithBackend(&stubBackend{supports: true, output: tt.output}, false)
+			_, err := executor.Execute(map[string]interface{}{
+				"_tool_name": tt.toolName,
+				"operation":  tt.operation,
+				"resource":   tt.resource,
+				"args":       tt.args,
+			}, cfg)
+
+			if tt.expectCode == "" {
+				if err != nil {
+					t.Fatalf("Execute() unexpected error = %v", err)
+				}
+				return
+			}
+			if code := toolerror.CodeOf(err); code != tt.expectCode {
+				t.Errorf("Execute() error = %v with code %q, want code %q", err, code, tt.expectCode)
+			}
+		})
+	}
+}
+
+const eventListJSON = `{
+    "apiVersion": "v1",
+    "kind": "List",
+    "items": [
+        {
+            "kind": "Event",
+            "metadata": {"name": "web.1", "namespace": "default"},
+            "involvedObject": {"kind": "Pod", "name": "web"},
+            "type": "Warning",
+            "reason": "BackOff",
+            "message": "Back-off restarting failed container"
+        }
+    ]
+}`
@@ -0,0 +1,186 @@
+package kubectl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxManifestSize is the maximum size in bytes of an inline manifest
+const MaxManifestSize = 1024 * 1024
+
+// manifestOperations lists the kubectl_resources operations that accept an inline manifest
+var manifestOperations = []string{"create", "apply", "replace"}
+
+// manifestDocument is the subset of a Kubernetes object needed for validation
+type manifestDocument struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name         string `yaml:"name"`
+		GenerateName string `yaml:"generateName"`
+		Namespace    string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	// Items is nil unless the document has an items list
+	Items *[]manifestDocument `yaml:"items"`
+}
+
+// parseManifest parses a YAML or JSON manifest, possibly with multiple documents,
+// and returns the objects it contains
+func parseManifest(manifest string) ([]security.ManifestObject, error) {
+	if len(manifest) > MaxManifestSize {
+		return nil, toolerror.Newf(toolerror.InvalidArguments, "manifest is too large: %d bytes (maximum %d bytes)", len(manifest), MaxManifestSize)
+	}
+
+	var objects []security.ManifestObject
+	decoder := yaml.NewDecoder(strings.NewReader(manifest))
+	for index := 1; ; index++ {
+		var doc *manifestDocument
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "invalid manifest document %d: %w", index, err)
+		}
+		// Skip empty documents, e.g. a leading "---"
+		if doc == nil {
+			continue
+		}
+
+		docObjects, err := manifestObjects(*doc, index)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, docObjects...)
+	}
+
+	if len(objects) == 0 {
+		return nil, toolerror.Newf(toolerror.InvalidArguments, "manifest does not contain any objects")
+	}
+
+	return objects, nil
+}
+
+// manifestObjects validates a single document and expands lists into their items
+func manifestObjects(doc manifestDocument, index int) ([]security.ManifestObject, error) {
+	if doc.APIVersion == "" || doc.Kind == "" {
+		return nil, toolerror.Newf(toolerror.InvalidArguments, "invalid manifest document %d: apiVersion and kind are required", index)
+	}
+
+	if isManifestList(doc) {
+		var objects []security.ManifestObject
+		for _, item := range *doc.Items {
+			itemObjects, err := manifestObjects(item, index)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, itemObjects...)
+		}
+		return objects, nil
+	}
+
+	if doc.Metadata.Name == "" && doc.Metadata.GenerateName == "" {
+		return nil, toolerror.Newf(toolerror.InvalidArguments, "invalid manifest document %d: %s has no metadata.name", index, doc.Kind)
+	}
+
+	return []security.ManifestObject{{
+		APIVersion: doc.APIVersion,
+		Kind:       doc.Kind,
+		Name:       doc.Metadata.Name,
+		Namespace:  doc.Metadata.Namespace,
+	}}, nil
+}
+
+// isManifestList checks if a document is a list that kubectl expands into its items: a v1 List, or a list
+// kind such as PodList with an items list. Other kinds ending in "List" are single objects.
+func isManifestList(doc manifestDocument) bool {
+	if doc.APIVersion == "v1" && doc.Kind == "List" {
+		return true
+	}
+	return strings.HasSuffix(doc.Kind, "List") && doc.Items != nil
+}
+
+// scopeResolver resolves whether kinds of manifest objects are namespaced with the backend if it can,
+// or with the discovery information of kubectl api-resources, which is fetched once per call
+func (e *KubectlToolExecutor) scopeResolver(params map[string]interface{}, cfg *config.ConfigData) security.ScopeResolver {
+	if resolver, ok := e.backend.(ScopeResolver); ok {
+		return resolver.KindScope
+	}
+
+	var scopes map[string]bool
+	var discoveryErr error
+	return func(apiVersion, kind string) (bool, error) {
+		if scopes == nil && discoveryErr == nil {
+			timeout := cfg.ResolveTimeout(security.CommandTypeKubectl, "api-resources", 0)
+			output, err := e.executor.runKubectlCommand("api-resources", "--no-headers", nil, timeout, params, cfg)
+			if err != nil {
+				discoveryErr = fmt.Errorf("discovery failed: %w", err)
+			} else {
+				scopes = parseAPIResources(output)
+			}
+		}
+		if discoveryErr != nil {
+			return false, discoveryErr
+		}
+		namespaced, ok := scopes[apiVersion+" "+kind]
+		if !ok {
+			return false, fmt.Errorf("kind %s is not served by %s", kind, apiVersion)
+		}
+		return namespaced, nil
+	}
+}
+
+// parseAPIResources parses kubectl api-resources --no-headers output into the scopes of kinds, keyed by
+// "<apiVersion> <kind>". The short names column may be empty, so columns are read from the end.
+func parseAPIResources(output string) map[string]bool {
+	scopes := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		kind, namespaced, apiVersion := fields[len(fields)-1], fields[len(fields)-2], fields[len(fields)-3]
+		scopes[apiVersion+" "+kind] = namespaced == "true"
+	}
+	return scopes
+}
+
+// validateManifestArgs checks that an inline manifest is used with a supported
+// operation and that args don't also reference files
+func validateManifestArgs(toolName, operation, resource, args string) error {
+	if toolName != "kubectl_resources" {
+		return toolerror.Newf(toolerror.InvalidArguments, "manifest parameter is only supported by the kubectl_resources tool")
+	}
+
+	supported := false
+	for _, op := range manifestOperations {
+		if operation == op {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return toolerror.Newf(toolerror.InvalidArguments, "manifest parameter is not supported for operation '%s'. Supported operations: %s",
+			operation, strings.Join(manifestOperations, ", "))
+	}
+
+	if resource != "" {
+		return toolerror.Newf(toolerror.InvalidArguments, "resource must be empty when a manifest is provided")
+	}
+
+	for _, arg := range strings.Fields(args) {
+		isShortFlag := !strings.HasPrefix(arg, "--") && (strings.HasPrefix(arg, "-f") || strings.HasPrefix(arg, "-k"))
+		if isShortFlag || strings.HasPrefix(arg, "--filename") || strings.HasPrefix(arg, "--kustomize") {
+			return toolerror.Newf(toolerror.InvalidArguments, "args must not contain -f/--filename or -k/--kustomize when a manifest is provided")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package kubectl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifest  string
+		wantKinds []string
+		wantErr   bool
+		errMsg    string
+	}{
+		{
+			name: "single yaml document",
+			manifest: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-config
+  namespace: dev
+data:
+  key: value`,
+			wantKinds: []string{"ConfigMap"},
+		},
+		{
+			name: "multiple yaml documents",
+			manifest: `---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: dev
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: dev
+`,
+			wantKinds: []string{"Namespace", "Deployment"},
+		},
+		{
+			name:      "json document",
+			manifest:  `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}}`,
+			wantKinds: []string{"Service"},
+		},
+		{
+			name: "list is expanded",
+			manifest: `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+- apiVersion: v1
+  kind: Secret
+  metadata:
+    name: b`,
+			wantKinds: []string{"ConfigMap", "Secret"},
+		},
+		{
+			name: "list kind with items is expanded",
+			manifest: `apiVersion: v1
+kind: PodList
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: a`,
+			wantKinds: []string{"Pod"},
+		},
+		{
+			name: "custom kind ending in List is a single object",
+			manifest: `apiVersion: music.example.com/v1
+kind: PlayList
+metadata:
+  name: favorites
+spec:
+  items:
+  - song`,
+			wantKinds: []string{"PlayList"},
+		},
+		{
+			name:     "invalid yaml",
+			manifest: "apiVersion: v1\nkind: [",
+			wantErr:  true,
+			errMsg:   "invalid manifest document 1",
+		},
+		{
+			name:     "missing kind",
+			manifest: "apiVersion: v1\nmetadata:\n  name: a",
+			wantErr:  true,
+			errMsg:   "apiVersion and kind are required",
+		},
+		{
+			name:     "missing name",
+			manifest: "apiVersion: v1\nkind: ConfigMap",
+			wantErr:  true,
+			errMsg:   "has no metadata.name",
+		},
+		{
+			name:     "empty manifest",
+			manifest: "---\n",
+			wantErr:  true,
+			errMsg:   "does not contain any objects",
+		},
+		{
+			name:     "too large",
+			manifest: strings.Repeat("#", MaxManifestSize+1),
+			wantErr:  true,
+			errMsg:   "manifest is too large",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := parseManifest(tt.manifest)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseManifest() error = nil, want error containing %v", tt.errMsg)
+				}
+				if !strings.Contains(err.Error(), tt.errMsg) {
+					t.Errorf("parseManifest() error = %v, want error containing %v", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseManifest() unexpected error = %v", err)
+			}
+			if len(objects) != len(tt.wantKinds) {
+				t.Fatalf("parseManifest() returned %d objects, want %d", len(objects), len(tt.wantKinds))
+			}
+			for i, kind := range tt.wantKinds {
+				if objects[i].Kind != kind {
+					t.Errorf("object %d kind = %v, want %v", i, objects[i].Kind, kind)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateManifestArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		toolName  string
+		operation string
+		resource  string
+		args      string
+		wantErr   bool
+		errMsg    string
+	}{
+		{"apply with namespace", "kubectl_resources", "apply", "", "-n default", false, ""},
+		{"create with no args", "kubectl_resources", "create", "", "", false, ""},
+		{"replace with force", "kubectl_resources", "replace", "", "--force", false, ""},
+		{"unsupported operation", "kubectl_resources", "delete", "", "", true, "not supported for operation 'delete'"},
+		{"unsupported tool", "kubectl_workloads", "run", "", "", true, "only supported by the kubectl_resources tool"},
+		{"resource given", "kubectl_resources", "apply", "pods", "", true, "resource must be empty"},
+		{"file flag in args", "kubectl_resources", "apply", "", "-f deploy.yaml", true, "must not contain -f"},
+		{"long file flag in args", "kubectl_resources", "apply", "", "--filename=deploy.yaml", true, "must not contain -f"},
+		{"kustomize flag in args", "kubectl_resources", "apply", "", "-k ./overlay", true, "must not contain -f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateManifestArgs(tt.toolName, tt.operation, tt.resource, tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("validateManifestArgs() error = nil, want error containing %v", tt.errMsg)
+				} else if !strings.Contains(err.Error(), tt.errMsg) {
+					t.Errorf("validateManifestArgs() error = %v, want error containing %v", err, tt.errMsg)
+				}
+			} else if err != nil {
+				t.Errorf("validateManifestArgs() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestParseAPIResources(t *testing.T) {
+	output := `pods                    po     v1                     true    Pod
+namespaces              ns     v1                     false   Namespace
+deployments             deploy apps/v1                true    Deployment
+widgets                        example.com/v1         true    Widget
+clusterwidgets                 example.com/v1         false   ClusterWidget
+`
+	want := map[string]bool{
+		"v1 Pod":                       true,
+		"v1 Namespace":                 false,
+		"apps/v1 Deployment":           true,
+		"example.com/v1 Widget":        true,
+		"example.com/v1 ClusterWidget": false,
+	}
+	scopes := parseAPIResources(output)
+	if len(scopes) != len(want) {
+		t.Fatalf("parseAPIResources() = %v, want %v", scopes, want)
+	}
+	for key, namespaced := range want {
+		if got, ok := scopes[key]; !ok || got != namespaced {
+			t.Errorf("scope of %s = %v, %v, want %v", key, got, ok, namespaced)
+		}
+	}
+}
+
+// scopedBackend is a backend resolving the scope of kinds
+type scopedBackend struct {
+	stubBackend
+	scopes map[string]bool
+}
+
+func (b *scopedBackend) KindScope(apiVersion, kind string) (bool, error) {
+	namespaced, ok := b.scopes[apiVersion+" "+kind]
+	if !ok {
+		return false, fmt.Errorf("no kind %s in %s", kind, apiVersion)
+	}
+	return namespaced, nil
+}
+
+func TestExecuteManifestResolvesScope(t *testing.T) {
+	secConfig := security.NewSecurityConfig()
+	secConfig.AccessLevel = security.AccessLevelReadWrite
+	secConfig.SetAllowedNamespaces("dev")
+	cfg := &config.ConfigData{AccessLevel: "readwrite", SecurityConfig: secConfig}
+
+	backend := &scopedBackend{
+		stubBackend: stubBackend{supports: true},
+		scopes:      map[string]bool{"example.com/v1 Widget": true, "example.com/v1 ClusterWidget": false},
+	}
+	executor := NewKubectlToolExecutorWithBackend(backend, false)
+	apply := func(kind string) error {
+		_, err := executor.Execute(map[string]interface{}{
+			"_tool_name": "kubectl_resources",
+			"operation":  "apply",
+			"resource":   "",
+			"args":       "-n dev",
+			"manifest":   "apiVersion: example.com/v1\nkind: " + kind + "\nmetadata:\n  name: w\n",
+		}, cfg)
+		return err
+	}
+
+	if err := apply("Widget"); err != nil {
+		t.Errorf("apply namespaced custom kind: unexpected error = %v", err)
+	}
+	if err := apply("ClusterWidget"); err == nil || !strings.Contains(err.Error(), "Cluster-scoped kind 'ClusterWidget'") {
+		t.Errorf("apply cluster-scoped custom kind: error = %v, want denied", err)
+	}
+	if err := apply("Gadget"); err == nil || !strings.Contains(err.Error(), "Scope of kind 'Gadget' can't be determined") {
+		t.Errorf("apply unknown kind: error = %v, want denied", err)
+	}
+	if len(backend.requests) != 1 {
+		t.Errorf("backend requests = %+v, want only the namespaced kind", backend.requests)
+	}
+}
@@ -0,0 +1,323 @@
+package kubectl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Typed parameters assembled into kubectl arguments by the executor
+const (
+	NameParam          = "name"
+	NamespaceParam     = "namespace"
+	AllNamespacesParam = "all_namespaces"
+	SelectorParam      = "selector"
+	FieldSelectorParam = "field_selector"
+	ContainerParam     = "container"
+	SinceParam         = "since"
+	TailParam          = "tail"
+	ContextParam       = "context"
+)
+
+// typedParam describes a typed parameter and how it maps to kubectl arguments
+type typedParam struct {
+	description string
+	// flag is the kubectl flag set by the parameter, empty for positional arguments
+	flag string
+	// conflicts lists the flags that can't be passed in args together with the parameter
+	conflicts []string
+	// operations lists the operations accepting the parameter, empty for all
+	operations []string
+	// pattern validates string values
+	pattern *regexp.Regexp
+}
+
+var (
+	// namePattern matches object names, optionally as type/name, and cp sources like pod:/path
+	namePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._:/-]*[A-Za-z0-9])?$`)
+	// dnsLabelPattern matches namespace and container names
+	dnsLabelPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// selectorPattern matches label and field selectors, including set based requirements
+	selectorPattern = regexp.MustCompile(`^[A-Za-z0-9._/=!,()\- ]+$`)
+	// durationPattern matches relative durations like 5s, 2m or 1h30m
+	durationPattern = regexp.MustCompile(`^([0-9]+(s|m|h))+$`)
+	// contextPattern matches kubeconfig context names, which may look like user@cluster or an ARN
+	contextPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._@:/-]*$`)
+)
+
+// typedParams defines the typed parameters
+var typedParams = map[string]typedParam{
+	NameParam: {
+		description: "Name of the object, or type/name when resource is empty",
+		pattern:     namePattern,
+	},
+	NamespaceParam: {
+		description: "Namespace of the objects",
+		flag:        "--namespace",
+		conflicts:   []string{"-n", "--namespace"},
+		pattern:     dnsLabelPattern,
+	},
+	AllNamespacesParam: {
+		description: "List objects across all namespaces",
+		flag:        "--all-namespaces",
+		conflicts:   []string{"-A", "--all-namespaces"},
+		operations:  []string{"get", "describe", "events", "top"},
+	},
+	SelectorParam: {
+		description: "Label selector, e.g. 'app=web' or 'tier in (frontend,backend)'",
+		flag:        "--selector",
+		conflicts:   []string{"-l", "--selector"},
+		operations: []string{"get", "describe", "delete", "label", "annotate", "scale", "rollout", "logs", "top",
+			"cordon", "uncordon", "drain", "taint"},
+		pattern: selectorPattern,
+	},
+	FieldSelectorParam: {
+		description: "Field selector, e.g. 'status.phase=Running' or 'spec.nodeName=node-1'",
+		flag:        "--field-selector",
+		conflicts:   []string{"--field-selector"},
+		operations:  []string{"get", "delete"},
+		pattern:     selectorPattern,
+	},
+	ContainerParam: {
+		description: "Container name in the pod",
+		flag:        "--container",
+		conflicts:   []string{"-c", "--container"},
+		operations:  []string{"logs", "exec", "cp"},
+		pattern:     dnsLabelPattern,
+	},
+	SinceParam: {
+		description: "Only return logs newer than a relative duration like 5s, 2m or 3h",
+		flag:        "--since",
+		conflicts:   []string{"--since", "--since-time"},
+		operations:  []string{"logs"},
+		pattern:     durationPattern,
+	},
+	TailParam: {
+		description: "Number of recent log lines to return",
+		flag:        "--tail",
+		conflicts:   []string{"--tail"},
+		operations:  []string{"logs"},
+	},
+	ContextParam: {
+		description: "Kubeconfig context to run the command in, empty uses the current context",
+		flag:        "--context",
+		pattern:     contextPattern,
+	},
+}
+
+// toolTypedParams lists the typed parameters of each tool in schema order
+var toolTypedParams = map[string][]string{
+	"kubectl_resources":   {NameParam, NamespaceParam, AllNamespacesParam, SelectorParam, FieldSelectorParam, ContextParam},
+	"kubectl_workloads":   {NameParam, NamespaceParam, SelectorParam, ContextParam},
+	"kubectl_metadata":    {NameParam, NamespaceParam, SelectorParam, ContextParam},
+	"kubectl_diagnostics": {NameParam, NamespaceParam, AllNamespacesParam, SelectorParam, ContainerParam, SinceParam, TailParam, ContextParam},
+	"kubectl_cluster":     {ContextParam},
+}
+
+// restrictedArgFlags are flags that override credentials, impersonate other users or redirect
+// commands to another kubeconfig, context or API server. They are rejected in the free-form args,
+// the context is chosen with the context parameter.
+var restrictedArgFlags = []string{
+	"--token", "--as", "--as-group", "--as-uid", "-s", "--server", "--username", "--password",
+	"--insecure-skip-tls-verify", "--client-certificate", "--client-key", "--certificate-authority", "--tls-server-name",
+	"--kubeconfig", "--context", "--cluster", "--user",
+}
+
+// outputFlagValues maps output parameter values to the kubectl -o value they select
+var outputFlagValues = map[string]string{
+	OutputWide: "wide",
+	OutputYAML: "yaml",
+	OutputName: "name",
+}
+
+// argsDescription describes the free-form args parameter of tools with typed parameters
+const argsDescription = "Advanced: additional raw kubectl arguments for cases the typed parameters don't cover. Prefer the typed parameters, flags they set can't be repeated here and credential or impersonation flags are rejected"
+
+// withTypedParams adds the typed parameters of a tool to its definition
+func withTypedParams(toolName string) mcp.ToolOption {
+	var options []mcp.ToolOption
+	for _, name := range toolTypedParams[toolName] {
+		param := typedParams[name]
+		description := param.description
+		if len(param.operations) > 0 {
+			description += fmt.Sprintf(" (%s)", strings.Join(param.operations, ", "))
+		}
+		switch name {
+		case AllNamespacesParam:
+			options = append(options, mcp.WithBoolean(name, mcp.Description(description)))
+		case TailParam:
+			options = append(options, mcp.WithNumber(name, mcp.Description(description), mcp.Min(0)))
+		default:
+			options = append(options, mcp.WithString(name, mcp.Description(description)))
+		}
+	}
+	return func(t *mcp.Tool) {
+		for _, option := range options {
+			option(t)
+		}
+	}
+}
+
+// withArgsParam adds the free-form args parameter to a tool definition, optional for tools with typed parameters
+func withArgsParam(toolName, description string) mcp.ToolOption {
+	if _, ok := toolTypedParams[toolName]; ok {
+		return mcp.WithString("args", mcp.Description(argsDescription))
+	}
+	return mcp.WithString("args", mcp.Required(), mcp.Description(description))
+}
+
+// buildTypedArgs validates the typed parameters of a call and assembles them into kubectl arguments.
+// The name comes first so that flags never end up after a "--" in args.
+func buildTypedArgs(toolName, operation string, params map[string]interface{}) ([]string, error) {
+	var parts []string
+	for _, name := range toolTypedParams[toolName] {
+		value, ok := params[name]
+		if !ok || value == nil {
+			continue
+		}
+		param := typedParams[name]
+
+		var arg string
+		switch v := value.(type) {
+		case bool:
+			if name != AllNamespacesParam {
+				return nil, toolerror.Newf(toolerror.InvalidArguments, "%s must be a string, got bool", name)
+			}
+			if !v {
+				continue
+			}
+		case float64:
+			if name != TailParam {
+				return nil, toolerror.Newf(toolerror.InvalidArguments, "%s must be a string, got number", name)
+			}
+			if v < 0 || v != float64(int64(v)) {
+				return nil, toolerror.Newf(toolerror.InvalidArguments, "%s must be a non-negative integer", name)
+			}
+			arg = fmt.Sprint(int64(v))
+		case string:
+			switch name {
+			case AllNamespacesParam:
+				return nil, toolerror.Newf(toolerror.InvalidArguments, "%s must be a boolean, got string", name)
+			case TailParam:
+				return nil, toolerror.Newf(toolerror.InvalidArguments, "%s must be a number, got string", name)
+			}
+			if v == "" {
+				continue
+			}
+			if param.pattern != nil && !param.pattern.MatchString(v) {
+				return nil, toolerror.Newf(toolerror.InvalidArguments, "invalid %s '%s'", name, v)
+			}
+			arg = v
+		default:
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "%s has an invalid type %T", name, value)
+		}
+
+		if len(param.operations) > 0 && !contains(param.operations, operation) {
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "parameter '%s' is not supported for operation '%s'", name, operation)
+		}
+
+		switch {
+		case param.flag == "":
+			parts = append([]string{quoteArg(arg)}, parts...)
+		case arg == "":
+			parts = append(parts, param.flag)
+		default:
+			parts = append(parts, param.flag+"="+quoteArg(arg))
+		}
+	}
+
+	// kubectl output formats are passed on with -o, the structured formats are rendered by ExecuteStructured
+	if format, _ := params[OutputParam].(string); outputFlagValues[format] != "" {
+		if operation != "get" {
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "%s '%s' is only supported for the get operation", OutputParam, format)
+		}
+		parts = append(parts, "--output="+outputFlagValues[format])
+	}
+	return parts, nil
+}
+
+// validateArgs applies the stricter validation of the free-form args: no control characters,
+// no credential or impersonation flags and no flags also set by typed parameters
+func validateArgs(toolName string, params map[string]interface{}, args string) error {
+	if strings.ContainsAny(args, "\n\r\x00") {
+		return toolerror.Newf(toolerror.InvalidArguments, "args must not contain line breaks or control characters")
+	}
+
+	fields := strings.Fields(args)
+	for _, field := range fields {
+		if field == "--" {
+			break
+		}
+		flag, _, _ := strings.Cut(field, "=")
+		for _, restricted := range restrictedArgFlags {
+			if isFlag(flag, restricted) {
+				return toolerror.Newf(toolerror.InvalidArguments, "flag %s is not allowed in args", restricted)
+			}
+		}
+	}
+
+	conflicts := make(map[string][]string)
+	for _, name := range toolTypedParams[toolName] {
+		if isSet(params[name]) {
+			conflicts[name] = typedParams[name].conflicts
+		}
+	}
+	if format, _ := params[OutputParam].(string); outputFlagValues[format] != "" {
+		conflicts[OutputParam] = []string{"-o", "--output"}
+	}
+
+	for _, field := range fields {
+		if field == "--" {
+			break
+		}
+		flag, _, _ := strings.Cut(field, "=")
+		for name, flags := range conflicts {
+			for _, conflict := range flags {
+				if isFlag(flag, conflict) {
+					return toolerror.Newf(toolerror.InvalidArguments, "%s is set by the '%s' parameter and must not be repeated in args", conflict, name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isFlag checks if an argument is a flag, short flags may carry their value like -nprod
+func isFlag(arg, flag string) bool {
+	return arg == flag || (len(flag) == 2 && strings.HasPrefix(arg, flag) && !strings.HasPrefix(arg, "--"))
+}
+
+// isSet checks if a typed parameter has a value
+func isSet(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	case bool:
+		return v
+	default:
+		return true
+	}
+}
+
+// quoteArg quotes a value so that it is kept as a single argument when the command is split
+func quoteArg(value string) string {
+	if !strings.ContainsAny(value, " \t'\"\\()") {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// contains checks if a string is in a list
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
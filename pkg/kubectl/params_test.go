@@ -0,0 +1,197 @@
+package kubectl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/shlex"
+)
+
+func TestBuildTypedArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		toolName  string
+		operation string
+		params    map[string]interface{}
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "no typed parameters",
+			toolName:  "kubectl_resources",
+			operation: "get",
+			params:    map[string]interface{}{},
+			want:      "",
+		},
+		{
+			name:      "name first then flags",
+			toolName:  "kubectl_resources",
+			operation: "get",
+			params: map[string]interface{}{
+				NamespaceParam: "prod", NameParam: "web", SelectorParam: "tier in (frontend,backend)",
+				FieldSelectorParam: "status.phase=Running", OutputParam: "wide",
+			},
+			want: "web --namespace=prod --selector='tier in (frontend,backend)' --field-selector=status.phase=Running --output=wide",
+		},
+		{
+			name:      "all namespaces",
+			toolName:  "kubectl_resources",
+			operation: "get",
+			params:    map[string]interface{}{AllNamespacesParam: true},
+			want:      "--all-namespaces",
+		},
+		{
+			name:      "all namespaces false",
+			toolName:  "kubectl_resources",
+			operation: "get",
+			params:    map[string]interface{}{AllNamespacesParam: false},
+			want:      "",
+		},
+		{
+			name:      "logs",
+			toolName:  "kubectl_diagnostics",
+			operation: "logs",
+			params:    map[string]interface{}{NameParam: "web-0", ContainerParam: "app", SinceParam: "1h30m", TailParam: float64(100)},
+			want:      "web-0 --container=app --since=1h30m --tail=100",
+		},
+		{
+			name:      "context",
+			toolName:  "kubectl_cluster",
+			operation: "api-resources",
+			params:    map[string]interface{}{ContextParam: "arn:aws:eks:us-east-1:123:cluster/prod"},
+			want:      "--context=arn:aws:eks:us-east-1:123:cluster/prod",
+		},
+		{
+			name:      "invalid context",
+			toolName:  "kubectl_resources",
+			operation: "get",
+			params:    map[string]interface{}{ContextParam: "x --kubeconfig=/tmp/config"},
+			wantErr:   "invalid context",
+		},
+		{
+			name:      "structured output is rendered later",
+			toolName:  "kubectl_resources",
+			operation: "get",
+			params:    map[string]interface{}{OutputParam: "json"},
+			want:      "",
+		},
+		{
+			name:      "parameter not supported by operation",
+			toolName:  "kubectl_diagnostics",
+			operation: "events",
+			params:    map[string]interface{}{TailParam: float64(10)},
+			wantErr:   "parameter 'tail' is not supported for operation 'events'",
+		},
+		{
+			name:      "output not supported by operation",
+			toolName:  "kubectl_resources",
+			operation: "describe",
+			params:    map[string]interface{}{OutputParam: "yaml"},
+			wantErr:   "only supported for the get operation",
+		},
+		{
+			name:      "invalid namespace",
+			toolName:  "kubectl_resources",
+			operation: "get",
+			params:    map[string]interface{}{NamespaceParam: "prod; rm -rf /"},
+			wantErr:   "invalid namespace",
+		},
+		{
+			name:      "invalid name",
+			toolName:  "kubectl_resources",
+			operation: "get",
+			params:    map[string]interface{}{NameParam: "--all"},
+			wantErr:   "invalid name",
+		},
+		{
+			name:      "invalid duration",
+			toolName:  "kubectl_diagnostics",
+			operation: "logs",
+			params:    map[string]interface{}{SinceParam: "yesterday"},
+			wantErr:   "invalid since",
+		},
+		{
+			name:      "negative tail",
+			toolName:  "kubectl_diagnostics",
+			operation: "logs",
+			params:    map[string]interface{}{TailParam: float64(-1)},
+			wantErr:   "non-negative integer",
+		},
+		{
+			name:      "wrong type",
+			toolName:  "kubectl_resources",
+			operation: "get",
+			params:    map[string]interface{}{AllNamespacesParam: "true"},
+			wantErr:   "all_namespaces must be a boolean",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := buildTypedArgs(tt.toolName, tt.operation, tt.params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("buildTypedArgs() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildTypedArgs() unexpected error = %v", err)
+			}
+			if got := strings.Join(parts, " "); got != tt.want {
+				t.Errorf("buildTypedArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		args    string
+		wantErr string
+	}{
+		{"plain args", map[string]interface{}{}, "-n prod -l app=web", ""},
+		{"namespace repeated", map[string]interface{}{NamespaceParam: "prod"}, "-n dev", "-n is set by the 'namespace' parameter"},
+		{"attached short flag", map[string]interface{}{NamespaceParam: "prod"}, "-ndev", "-n is set by the 'namespace' parameter"},
+		{"long flag with value", map[string]interface{}{SelectorParam: "app=web"}, "--selector=tier=db", "--selector is set by the 'selector' parameter"},
+		{"output repeated", map[string]interface{}{OutputParam: "yaml"}, "-o json", "-o is set by the 'output' parameter"},
+		{"flags after -- are ignored", map[string]interface{}{NamespaceParam: "prod"}, "web -- ls -n", ""},
+		{"impersonation", map[string]interface{}{}, "--as=system:admin", "flag --as is not allowed"},
+		{"token", map[string]interface{}{}, "--token abc", "flag --token is not allowed"},
+		{"kubeconfig", map[string]interface{}{}, "--kubeconfig=/home/user/.kube/other", "flag --kubeconfig is not allowed"},
+		{"context", map[string]interface{}{}, "--context prod", "flag --context is not allowed"},
+		{"cluster", map[string]interface{}{}, "--cluster=prod", "flag --cluster is not allowed"},
+		{"user", map[string]interface{}{}, "--user admin", "flag --user is not allowed"},
+		{"server", map[string]interface{}{}, "--server=https://evil:6443", "flag --server is not allowed"},
+		{"short server", map[string]interface{}{}, "-s https://evil:6443", "flag -s is not allowed"},
+		{"attached short server", map[string]interface{}{}, "-shttps://evil", "flag -s is not allowed"},
+		{"short server with equals", map[string]interface{}{}, "-s=https://evil", "flag -s is not allowed"},
+		{"line break", map[string]interface{}{}, "-n prod\nget secrets", "line breaks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateArgs("kubectl_resources", tt.params, tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateArgs() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateArgs() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	for _, value := range []string{"app=web", "tier in (a,b)", "it's", `back\slash`} {
+		parts, err := shlex.Split("--selector=" + quoteArg(value))
+		if err != nil || len(parts) != 1 || parts[0] != "--selector="+value {
+			t.Errorf("quoteArg(%q) split into %q, %v", value, parts, err)
+		}
+	}
+}
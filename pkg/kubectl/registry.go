@@ -1,6 +1,7 @@
 package kubectl
 
 import (
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -49,6 +50,7 @@ func RegisterKubectlTools(accessLevel string) []mcp.Tool {
 		{creator: toolCreator(createConfigTool), minAccess: AccessLevelReadOnly, readOnlyMode: true},
 		{creator: toolCreatorSimple(createWorkloadsTool), minAccess: AccessLevelReadWrite},
 		{creator: toolCreatorSimple(createMetadataTool), minAccess: AccessLevelReadWrite},
+		{creator: toolCreatorSimple(createBatchTool), minAccess: AccessLevelReadOnly},
 	}
 
 	// Normalize access level
@@ -90,23 +92,32 @@ func shouldRegisterTool(minAccess, currentAccess string) bool {
 
 // createToolFromRegistration creates a tool from its registration definition
 func createToolFromRegistration(reg toolRegistration, accessLevel string) mcp.Tool {
+	var tool mcp.Tool
 	switch creator := reg.creator.(type) {
 	case toolCreator:
 		// Tools that support read-only mode
 		readOnly := accessLevel == AccessLevelReadOnly && reg.readOnlyMode
-		return creator(readOnly)
+		tool = creator(readOnly)
 	case toolCreatorSimple:
 		// Tools that don't have read-only variants
-		return creator()
+		tool = creator()
 	default:
 		panic("invalid tool creator type")
 	}
+
+	// The readonly access level rejects all operations that modify the cluster
+	if accessLevel == AccessLevelReadOnly {
+		tools.SetAnnotations(&tool, tools.ReadOnlyAnnotations)
+	}
+	return tool
 }
 
 // createResourcesTool creates the main resource management tool
 func createResourcesTool(readOnly bool) mcp.Tool {
 	var description string
 	var operationDesc string
+	var operations []string
+	annotations := tools.ReadOnlyAnnotations
 
 	if readOnly {
 		description = `View Kubernetes resources with read-only operations.
@@ -119,13 +130,14 @@ Common resources: pods, deployments, services, configmaps, secrets, namespaces,
 
 Examples:
 - Get pods: operation='get', resource='pods', args='-n default'
-- Get specific pod: operation='get', resource='pods', args='nginx-pod -n default'
-- Get with selector: operation='get', resource='pods', args='-l app=nginx'
-- Get all namespaces: operation='get', resource='pods', args='--all-namespaces'
-- Describe deployment: operation='describe', resource='deployment', args='myapp -n production'
+- Get specific pod: operation='get', resource='pods', name='nginx-pod', namespace='default'
+- Get with selector: operation='get', resource='pods', selector='app=nginx'
+- Get all namespaces: operation='get', resource='pods', all_namespaces=true
+- Describe deployment: operation='describe', resource='deployment', name='myapp', namespace='production'
 - Describe all pods: operation='describe', resource='pods', args=''
 - Describe with selector: operation='describe', resource='pods', args='-l name=myLabel'`
 		operationDesc = "The operation to perform: get, describe"
+		operations = []string{"get", "describe"}
 	} else {
 		description = `Manage Kubernetes resources with standard CRUD operations.
 
@@ -146,10 +158,10 @@ Common resources: pods, deployments, services, configmaps, secrets, namespaces,
 
 Examples:
 - Get pods: operation='get', resource='pods', args='-n default'
-- Get specific pod: operation='get', resource='pods', args='nginx-pod -n default'
-- Get with selector: operation='get', resource='pods', args='-l app=nginx'
-- Get all namespaces: operation='get', resource='pods', args='--all-namespaces'
-- Describe deployment: operation='describe', resource='deployment', args='myapp -n production'
+- Get specific pod: operation='get', resource='pods', name='nginx-pod', namespace='default'
+- Get with selector: operation='get', resource='pods', selector='app=nginx'
+- Get all namespaces: operation='get', resource='pods', all_namespaces=true
+- Describe deployment: operation='describe', resource='deployment', name='myapp', namespace='production'
 - Describe all pods: operation='describe', resource='pods', args=''
 - Describe with selector: operation='describe', resource='pods', args='-l name=myLabel'
 - Create from file: operation='create', resource='', args='-f deployment.yaml'
@@ -161,6 +173,7 @@ Examples:
 - Patch from file: operation='patch', resource='', args='-f node.json -p \'{"spec":{"unschedulable":true}}\''
 - Patch pod image: operation='patch', resource='pod', args='valid-pod -p \'{"spec":{"containers":[{"name":"app","image":"nginx:1.20"}]}}\''
 - Patch with JSON type: operation='patch', resource='pod', args='valid-pod --type=json -p \'[{"op":"replace","path":"/spec/containers/0/image","value":"nginx:1.20"}]\''
+- Apply inline manifest: operation='apply', resource='', args='-n default', manifest='apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: my-config\ndata:\n  key: value'
 - Replace from file: operation='replace', resource='', args='-f ./updated-pod.json'
 - Force replace: operation='replace', resource='', args='--force -f ./pod.json'
 - Delete service: operation='delete', resource='service', args='myservice -n default'
@@ -176,23 +189,37 @@ Examples:
 - Remove taint: operation='taint', resource='nodes', args='worker-1 dedicated:NoSchedule-'
 - Taint with selector: operation='taint', resource='node', args='-l myLabel=X dedicated=foo:PreferNoSchedule'`
 		operationDesc = "The operation to perform: get, describe, create, delete, apply, patch, replace, cordon, uncordon, drain, taint"
+		operations = []string{"get", "describe", "create", "delete", "apply", "patch", "replace", "cordon", "uncordon", "drain", "taint"}
+		annotations = tools.Annotations{Destructive: true}
 	}
 
-	return mcp.NewTool("kubectl_resources",
+	options := []mcp.ToolOption{
 		mcp.WithDescription(description),
+		tools.WithAnnotations(annotations),
 		mcp.WithString("operation",
 			mcp.Required(),
 			mcp.Description(operationDesc),
+			mcp.Enum(operations...),
 		),
 		mcp.WithString("resource",
 			mcp.Required(),
 			mcp.Description("The resource type (e.g., pods, deployments, services) or empty string '' for file-based operations (create -f, apply -f, patch -f, replace -f, delete -f)"),
 		),
-		mcp.WithString("args",
-			mcp.Required(),
-			mcp.Description("Additional arguments like resource names, namespaces, and flags"),
-		),
-	)
+	}
+	options = append(options, withTypedParams("kubectl_resources"), withArgsParam("kubectl_resources", ""))
+
+	if !readOnly {
+		options = append(options, mcp.WithString("manifest",
+			mcp.Description("Inline YAML or JSON manifest, may contain multiple documents, piped to kubectl on stdin for create/apply/replace. Use resource='' and don't pass -f in args"),
+		))
+	}
+
+	options = append(options, withOutputParam(
+		"Optional result format for get: 'text' (default) returns kubectl's output, 'wide', 'yaml' and 'name' return it in that kubectl output format, 'json' returns structured summaries of the objects with a compact text rendering, 'summary' returns only the compact text rendering. Don't pass -o in args together with output",
+		OutputText, OutputWide, OutputYAML, OutputName, OutputJSON, OutputSummary)...)
+	options = append(options, withVerbosityParams()...)
+	options = append(options, withCacheParam(), tools.WithTimeoutSeconds())
+	return mcp.NewTool("kubectl_resources", options...)
 }
 
 // createWorkloadsTool creates the workload management tool
@@ -223,18 +250,19 @@ Examples:
 
 	return mcp.NewTool("kubectl_workloads",
 		mcp.WithDescription(description),
+		tools.WithAnnotations(tools.Annotations{Destructive: true}),
 		mcp.WithString("operation",
 			mcp.Required(),
 			mcp.Description("The operation to perform: run, expose, scale, autoscale, rollout"),
+			mcp.Enum("run", "expose", "scale", "autoscale", "rollout"),
 		),
 		mcp.WithString("resource",
 			mcp.Required(),
 			mcp.Description("The resource type for expose/scale/autoscale, subcommand for rollout, or empty string '' for run operation"),
 		),
-		mcp.WithString("args",
-			mcp.Required(),
-			mcp.Description("Additional arguments specific to the operation"),
-		),
+		withTypedParams("kubectl_workloads"),
+		withArgsParam("kubectl_workloads", ""),
+		tools.WithTimeoutSeconds(),
 	)
 }
 
@@ -258,18 +286,19 @@ Examples:
 
 	return mcp.NewTool("kubectl_metadata",
 		mcp.WithDescription(description),
+		tools.WithAnnotations(tools.Annotations{Destructive: true, Idempotent: true}),
 		mcp.WithString("operation",
 			mcp.Required(),
 			mcp.Description("The operation to perform: label, annotate, set"),
+			mcp.Enum("label", "annotate", "set"),
 		),
 		mcp.WithString("resource",
 			mcp.Required(),
 			mcp.Description("The resource type to modify"),
 		),
-		mcp.WithString("args",
-			mcp.Required(),
-			mcp.Description("Resource names and metadata changes"),
-		),
+		withTypedParams("kubectl_metadata"),
+		withArgsParam("kubectl_metadata", ""),
+		tools.WithTimeoutSeconds(),
 	)
 }
 
@@ -286,7 +315,7 @@ Available operations:
 
 Examples:
 - Logs for default container: operation='logs', resource='', args='nginx'
-- Logs for specific container: operation='logs', resource='', args='nginx -c ruby-container'
+- Logs for specific container: operation='logs', resource='', name='nginx', container='ruby-container', tail=100
 - Logs with selector: operation='logs', resource='', args='-l app=nginx --all-containers=true'
 - Get events: operation='events', resource='', args='--all-namespaces'
 - Get events namespace: operation='events', resource='', args='-n default'
@@ -298,21 +327,27 @@ Examples:
 - Copy from pod: operation='cp', resource='', args='some-namespace/some-pod:/tmp/foo /tmp/bar'
 - Copy with container: operation='cp', resource='', args='/tmp/foo some-pod:/tmp/bar -c specific-container'`
 
-	return mcp.NewTool("kubectl_diagnostics",
+	// exec runs arbitrary commands in containers
+	options := []mcp.ToolOption{
 		mcp.WithDescription(description),
+		tools.WithAnnotations(tools.Annotations{Destructive: true}),
 		mcp.WithString("operation",
 			mcp.Required(),
 			mcp.Description("The operation to perform: logs, events, top, exec, cp"),
+			mcp.Enum("logs", "events", "top", "exec", "cp"),
 		),
 		mcp.WithString("resource",
 			mcp.Required(),
 			mcp.Description("The resource type: 'node'/'pod' for top, empty string '' for logs/events/exec/cp"),
 		),
-		mcp.WithString("args",
-			mcp.Required(),
-			mcp.Description("Resource names and operation-specific flags"),
-		),
-	)
+		withTypedParams("kubectl_diagnostics"),
+		withArgsParam("kubectl_diagnostics", ""),
+	}
+	options = append(options, withOutputParam(
+		"Optional result format for events: 'text' (default) returns kubectl's output, 'json' returns structured summaries of the events with a compact text rendering, 'summary' returns only the compact text rendering. The other operations return text. Don't pass -o in args together with output",
+		OutputText, OutputJSON, OutputSummary)...)
+	options = append(options, withCacheParam(), tools.WithTimeoutSeconds())
+	return mcp.NewTool("kubectl_diagnostics", options...)
 }
 
 // createClusterTool creates the cluster information tool
@@ -339,18 +374,20 @@ Examples:
 
 	return mcp.NewTool("kubectl_cluster",
 		mcp.WithDescription(description),
+		tools.WithAnnotations(tools.ReadOnlyAnnotations),
 		mcp.WithString("operation",
 			mcp.Required(),
 			mcp.Description("The operation to perform: cluster-info, api-resources, api-versions, explain"),
+			mcp.Enum("cluster-info", "api-resources", "api-versions", "explain"),
 		),
 		mcp.WithString("resource",
 			mcp.Required(),
 			mcp.Description("The resource type for explain operation, or empty string '' for cluster-info/api-resources/api-versions"),
 		),
-		mcp.WithString("args",
-			mcp.Required(),
-			mcp.Description("Additional flags and options"),
-		),
+		withTypedParams("kubectl_cluster"),
+		withArgsParam("kubectl_cluster", "Additional flags and options"),
+		withCacheParam(),
+		tools.WithTimeoutSeconds(),
 	)
 }
 
@@ -358,6 +395,8 @@ Examples:
 func createConfigTool(readOnly bool) mcp.Tool {
 	var description string
 	var operationDesc string
+	var operations []string
+	annotations := tools.ReadOnlyAnnotations
 
 	if readOnly {
 		description = `Work with Kubernetes configurations (read-only).
@@ -377,6 +416,7 @@ Examples:
 - Get current context: operation='config', resource='current-context', args=''
 - List contexts: operation='config', resource='get-contexts', args=''`
 		operationDesc = "The operation to perform: diff, auth, config"
+		operations = []string{"diff", "auth", "config"}
 	} else {
 		description = `Work with Kubernetes configurations.
 
@@ -399,22 +439,32 @@ Examples:
 - List contexts: operation='config', resource='get-contexts', args=''
 - Switch context: operation='config', resource='use-context', args='my-cluster-context'`
 		operationDesc = "The operation to perform: diff, auth, certificate, config"
+		operations = []string{"diff", "auth", "certificate", "config"}
+		annotations = tools.Annotations{Idempotent: true}
 	}
 
 	return mcp.NewTool("kubectl_config",
 		mcp.WithDescription(description),
+		tools.WithAnnotations(annotations),
 		mcp.WithString("operation",
 			mcp.Required(),
 			mcp.Description(operationDesc),
+			mcp.Enum(operations...),
 		),
 		mcp.WithString("resource",
 			mcp.Required(),
 			mcp.Description("Subcommand for auth/certificate/config operations, or empty string '' for diff operation"),
 		),
-		mcp.WithString("args",
-			mcp.Required(),
-			mcp.Description("Operation-specific arguments"),
-		),
+		withArgsParam("kubectl_config", "Operation-specific arguments"),
+		tools.WithTimeoutSeconds(),
+	)
+}
+
+// withCacheParam adds the optional cache control parameter to a tool definition
+func withCacheParam() mcp.ToolOption {
+	return mcp.WithString(CacheParam,
+		mcp.Description("Optional cache control for read-only operations. Set to 'bypass' to skip cached results and fetch fresh data"),
+		mcp.Enum("bypass"),
 	)
 }
 
@@ -427,6 +477,7 @@ func GetKubectlToolNames() []string {
 		"kubectl_diagnostics",
 		"kubectl_cluster",
 		"kubectl_config",
+		BatchToolName,
 	}
 }
 
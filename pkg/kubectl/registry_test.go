@@ -1,8 +1,11 @@
 package kubectl
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func TestRegisterKubectlTools(t *testing.T) {
@@ -10,7 +13,7 @@ func TestRegisterKubectlTools(t *testing.T) {
 	tools := RegisterKubectlTools("admin")
 
 	// Verify we have the expected number of tools
-	expectedCount := 6
+	expectedCount := 7
 	if len(tools) != expectedCount {
 		t.Errorf("Expected %d consolidated tools, got %d", expectedCount, len(tools))
 	}
@@ -122,6 +125,22 @@ func TestConsolidatedToolParameters(t *testing.T) {
 			if tool.Description == "" {
 				t.Error("Tool has empty description")
 			}
+
+			// kubectl_resources and kubectl_diagnostics offer structured output, only kubectl_resources verbosity
+			_, hasOutput := tool.InputSchema.Properties[OutputParam]
+			wantOutput := structuredOperations[tool.Name] != ""
+			if hasOutput != wantOutput {
+				t.Errorf("output parameter present = %v, want %v", hasOutput, wantOutput)
+			}
+			wantVerbosity := tool.Name == "kubectl_resources"
+			if _, hasVerbosity := tool.InputSchema.Properties[VerbosityParam]; hasVerbosity != wantVerbosity {
+				t.Errorf("verbosity parameter present = %v, want %v", hasVerbosity, wantVerbosity)
+			}
+			// kubectl_batch returns the results of its operations as structured content
+			wantSchema := wantOutput || tool.Name == BatchToolName
+			if (tool.RawOutputSchema != nil) != wantSchema {
+				t.Errorf("output schema present = %v, want %v", tool.RawOutputSchema != nil, wantSchema)
+			}
 		})
 	}
 }
@@ -136,6 +155,7 @@ func TestGetKubectlToolNames(t *testing.T) {
 		"kubectl_diagnostics",
 		"kubectl_cluster",
 		"kubectl_config",
+		"kubectl_batch",
 	}
 
 	if len(names) != len(expected) {
@@ -450,3 +470,210 @@ func TestRegisterKubectlTools_DefaultsToReadOnly(t *testing.T) {
 		}
 	}
 }
+
+func TestTypedToolParameters(t *testing.T) {
+	for _, tool := range RegisterKubectlTools("admin") {
+		if tool.Name == BatchToolName {
+			// Batch operations take the parameters of their tools as list items
+			continue
+		}
+		t.Run(tool.Name, func(t *testing.T) {
+			operation, ok := tool.InputSchema.Properties["operation"].(map[string]any)
+			if !ok || operation["enum"] == nil {
+				t.Errorf("operation has no enum: %v", tool.InputSchema.Properties["operation"])
+			}
+
+			typed, hasTyped := toolTypedParams[tool.Name]
+			for _, name := range typed {
+				if _, ok := tool.InputSchema.Properties[name]; !ok {
+					t.Errorf("missing typed parameter %s", name)
+				}
+			}
+			argsRequired := false
+			for _, required := range tool.InputSchema.Required {
+				if required == "args" {
+					argsRequired = true
+				}
+			}
+			if argsRequired == hasTyped {
+				t.Errorf("args required = %v, want %v", argsRequired, !hasTyped)
+			}
+		})
+	}
+}
+
+func TestRegisterKubectlTools_Annotations(t *testing.T) {
+	tests := []struct {
+		accessLevel string
+		toolName    string
+		readOnly    bool
+		destructive bool
+	}{
+		{AccessLevelAdmin, "kubectl_resources", false, true},
+		{AccessLevelAdmin, "kubectl_workloads", false, true},
+		{AccessLevelAdmin, "kubectl_metadata", false, true},
+		{AccessLevelAdmin, "kubectl_diagnostics", false, true},
+		{AccessLevelAdmin, "kubectl_cluster", true, false},
+		{AccessLevelAdmin, "kubectl_config", false, false},
+		// The readonly access level rejects writes, so all its tools are read-only
+		{AccessLevelReadOnly, "kubectl_resources", true, false},
+		{AccessLevelReadOnly, "kubectl_diagnostics", true, false},
+		{AccessLevelReadOnly, "kubectl_config", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accessLevel+"/"+tt.toolName, func(t *testing.T) {
+			tool := findTool(t, RegisterKubectlTools(tt.accessLevel), tt.toolName)
+			annotations := tool.Annotations
+			if annotations.ReadOnlyHint == nil || *annotations.ReadOnlyHint != tt.readOnly {
+				t.Errorf("readOnlyHint = %v, want %v", annotations.ReadOnlyHint, tt.readOnly)
+			}
+			if annotations.DestructiveHint == nil || *annotations.DestructiveHint != tt.destructive {
+				t.Errorf("destructiveHint = %v, want %v", annotations.DestructiveHint, tt.destructive)
+			}
+			if annotations.OpenWorldHint == nil || *annotations.OpenWorldHint {
+				t.Errorf("openWorldHint = %v, want false", annotations.OpenWorldHint)
+			}
+		})
+	}
+}
+
+func TestRegisterSplitKubectlTools(t *testing.T) {
+	tools := RegisterSplitKubectlTools(AccessLevelAdmin)
+
+	tests := []struct {
+		toolName   string
+		readOnly   bool
+		operations []string
+		inDesc     []string
+		notInDesc  []string
+	}{
+		{
+			toolName:   "kubectl_resources",
+			readOnly:   true,
+			operations: []string{"get", "describe"},
+			inDesc:     []string{"use kubectl_resources_write", "operation='get'"},
+			notInDesc:  []string{"operation='delete'", "- drain:"},
+		},
+		{
+			toolName:   "kubectl_resources_write",
+			operations: []string{"create", "delete", "apply", "patch", "replace", "cordon", "uncordon", "drain", "taint"},
+			inDesc:     []string{"operation='delete'", "- drain:"},
+			notInDesc:  []string{"operation='get'", "- describe:"},
+		},
+		{
+			toolName:   "kubectl_workloads",
+			readOnly:   true,
+			operations: []string{"rollout"},
+			inDesc:     []string{"resource='status'", "resource='history'"},
+			notInDesc:  []string{"resource='undo'", "operation='scale'"},
+		},
+		{
+			toolName:   "kubectl_workloads_write",
+			operations: []string{"run", "expose", "scale", "autoscale", "rollout"},
+			inDesc:     []string{"resource='undo'", "operation='scale'"},
+			notInDesc:  []string{"resource='status'"},
+		},
+		{
+			toolName:   "kubectl_diagnostics",
+			readOnly:   true,
+			operations: []string{"logs", "events", "top"},
+			notInDesc:  []string{"operation='exec'"},
+		},
+		{
+			toolName:   "kubectl_diagnostics_write",
+			operations: []string{"exec", "cp"},
+		},
+		{
+			toolName:   "kubectl_config",
+			readOnly:   true,
+			operations: []string{"diff", "auth", "config"},
+			inDesc:     []string{"- get-contexts:"},
+			notInDesc:  []string{"- use-context:", "use-context'", "operation='certificate'"},
+		},
+		{
+			toolName:   "kubectl_config_write",
+			operations: []string{"certificate", "config"},
+			inDesc:     []string{"- use-context:", "operation='certificate'"},
+			notInDesc:  []string{"- get-contexts:", "operation='diff'"},
+		},
+		{toolName: "kubectl_metadata", operations: []string{"label", "annotate", "set"}},
+		{toolName: "kubectl_cluster", readOnly: true, operations: []string{"cluster-info", "api-resources", "api-versions", "explain"}},
+		{toolName: "kubectl_batch", readOnly: true},
+	}
+
+	if len(tools) != len(tests) {
+		t.Errorf("RegisterSplitKubectlTools() returned %d tools, want %d", len(tools), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.toolName, func(t *testing.T) {
+			tool := findTool(t, tools, tt.toolName)
+			if got := *tool.Annotations.ReadOnlyHint; got != tt.readOnly {
+				t.Errorf("readOnlyHint = %v, want %v", got, tt.readOnly)
+			}
+			if got := toolOperations(tool); strings.Join(got, ",") != strings.Join(tt.operations, ",") {
+				t.Errorf("operations = %v, want %v", got, tt.operations)
+			}
+			for _, s := range tt.inDesc {
+				if !strings.Contains(tool.Description, s) {
+					t.Errorf("description doesn't contain %q", s)
+				}
+			}
+			for _, s := range tt.notInDesc {
+				if strings.Contains(tool.Description, s) {
+					t.Errorf("description contains %q", s)
+				}
+			}
+		})
+	}
+
+	// The readonly access level has no write operations to split off
+	readOnlyTools := RegisterSplitKubectlTools(AccessLevelReadOnly)
+	if len(readOnlyTools) != len(RegisterKubectlTools(AccessLevelReadOnly)) {
+		t.Errorf("readonly tools were split: %d tools", len(readOnlyTools))
+	}
+}
+
+// findTool returns the tool with the given name
+func findTool(t *testing.T, tools []mcp.Tool, name string) mcp.Tool {
+	t.Helper()
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool
+		}
+	}
+	t.Fatalf("tool %s not found", name)
+	return mcp.Tool{}
+}
+
+func TestToolForOperation(t *testing.T) {
+	tests := []struct {
+		accessLevel string
+		split       bool
+		tool        string
+		operation   string
+		resource    string
+		want        string
+	}{
+		{accessLevel: "readonly", tool: "kubectl_diagnostics", operation: "logs", want: "kubectl_diagnostics"},
+		{accessLevel: "readonly", tool: "kubectl_diagnostics", operation: "exec"},
+		{accessLevel: "readonly", tool: "kubectl_workloads", operation: "rollout", resource: "status"},
+		{accessLevel: "readwrite", tool: "kubectl_workloads", operation: "rollout", resource: "status", want: "kubectl_workloads"},
+		{accessLevel: "readwrite", tool: "kubectl_workloads", operation: "rollout", resource: "undo", want: "kubectl_workloads"},
+		{accessLevel: "readwrite", split: true, tool: "kubectl_workloads", operation: "rollout", resource: "status", want: "kubectl_workloads"},
+		{accessLevel: "readwrite", split: true, tool: "kubectl_workloads", operation: "rollout", resource: "undo", want: "kubectl_workloads_write"},
+		{accessLevel: "readwrite", split: true, tool: "kubectl_metadata", operation: "label", want: "kubectl_metadata"},
+		{accessLevel: "readwrite", tool: "kubectl_resources", operation: "drain"},
+		{accessLevel: "admin", split: true, tool: "kubectl_resources", operation: "drain", want: "kubectl_resources_write"},
+		{accessLevel: "admin", tool: "kubectl_unknown", operation: "get"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %v %s %s %s", tt.accessLevel, tt.split, tt.tool, tt.operation, tt.resource), func(t *testing.T) {
+			got, ok := ToolForOperation(tt.accessLevel, tt.split, tt.tool, tt.operation, tt.resource)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("ToolForOperation() = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
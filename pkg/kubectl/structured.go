@@ -0,0 +1,355 @@
+package kubectl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// OutputParam is the optional argument selecting how kubectl_resources and kubectl_diagnostics return results
+const OutputParam = "output"
+
+// structuredOperations maps the tools returning structured content to the operation whose objects can be
+// rendered from kubectl's JSON output. The other read operations, such as logs and top, have no JSON output
+// and are returned as text.
+var structuredOperations = map[string]string{
+	"kubectl_resources":   "get",
+	"kubectl_diagnostics": "events",
+}
+
+// Output formats. text, wide, yaml and name return kubectl's output, json and summary are rendered from it.
+const (
+	OutputText    = "text"
+	OutputWide    = "wide"
+	OutputYAML    = "yaml"
+	OutputName    = "name"
+	OutputJSON    = "json"
+	OutputSummary = "summary"
+)
+
+// ResourceOutput is the structured content returned by kubectl_resources and kubectl_diagnostics
+type ResourceOutput struct {
+	// Items summarizes the returned objects for output=json
+	Items []ResourceSummary `json:"items,omitempty" jsonschema:"description=Summaries of the returned objects (output=json)"`
+	// Text is the rendered output for output=text, output=summary and verbosity=table
+	Text string `json:"text,omitempty" jsonschema:"description=Text output (output=text or output=summary or verbosity=table)"`
+}
+
+// ResourceSummary holds the key facts of a Kubernetes object
+type ResourceSummary struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Age       string `json:"age,omitempty"`
+	// Fields holds kind specific facts such as ready and restarts for pods
+	Fields map[string]string `json:"fields,omitempty" jsonschema:"description=Kind specific fields such as ready/restarts/node for pods or type/clusterIP/ports for services"`
+}
+
+// withOutputParam adds the output format parameter and the matching output schema to a tool definition
+func withOutputParam(description string, formats ...string) []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString(OutputParam, mcp.Description(description), mcp.Enum(formats...)),
+		mcp.WithOutputSchema[ResourceOutput](),
+	}
+}
+
+// getOutputFormat returns the output format requested in params, defaulting to text
+func getOutputFormat(params map[string]interface{}) (string, error) {
+	value, ok := params[OutputParam]
+	if !ok || value == nil {
+		return OutputText, nil
+	}
+	format, ok := value.(string)
+	if !ok {
+		return "", toolerror.Newf(toolerror.InvalidArguments, "%s must be a string, got %T", OutputParam, value)
+	}
+	switch format {
+	case "", OutputText:
+		return OutputText, nil
+	case OutputWide, OutputYAML, OutputName, OutputJSON, OutputSummary:
+		return format, nil
+	default:
+		return "", toolerror.Newf(toolerror.InvalidArguments, "invalid %s '%s'. Valid values are: %s", OutputParam, format,
+			strings.Join([]string{OutputText, OutputWide, OutputYAML, OutputName, OutputJSON, OutputSummary}, ", "))
+	}
+}
+
+// hasOutputFlag checks if kubectl arguments already select an output format
+func hasOutputFlag(args string) bool {
+	for _, field := range strings.Fields(args) {
+		if field == "-o" || field == "--output" || strings.HasPrefix(field, "--output=") ||
+			(strings.HasPrefix(field, "-o") && !strings.HasPrefix(field, "--")) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeObjects parses kubectl JSON output, a single object or a list, into its objects
+func decodeObjects(data string) ([]map[string]interface{}, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &obj); err != nil {
+		return nil, err
+	}
+
+	items, ok := obj["items"].([]interface{})
+	if !ok || !strings.HasSuffix(stringField(obj, "kind"), "List") {
+		return []map[string]interface{}{obj}, nil
+	}
+	objects := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if o, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, o)
+		}
+	}
+	return objects, nil
+}
+
+// summarizeObjects parses kubectl JSON output, a single object or a list, into summaries
+func summarizeObjects(data string, now time.Time) ([]ResourceSummary, error) {
+	objects, err := decodeObjects(data)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]ResourceSummary, len(objects))
+	for i, obj := range objects {
+		summaries[i] = summarizeObject(obj, now)
+	}
+	return summaries, nil
+}
+
+// summarizeObject extracts the key facts of an object
+func summarizeObject(obj map[string]interface{}, now time.Time) ResourceSummary {
+	summary := ResourceSummary{
+		Kind:      stringField(obj, "kind"),
+		Name:      stringField(obj, "metadata", "name"),
+		Namespace: stringField(obj, "metadata", "namespace"),
+		Status:    stringField(obj, "status", "phase"),
+		Fields:    make(map[string]string),
+	}
+	if created, err := time.Parse(time.RFC3339, stringField(obj, "metadata", "creationTimestamp")); err == nil {
+		summary.Age = shortDuration(now.Sub(created))
+	}
+
+	switch summary.Kind {
+	case "Pod":
+		summarizePod(obj, &summary)
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		desired := intField(obj, "spec", "replicas")
+		ready := intField(obj, "status", "readyReplicas")
+		summary.Fields["ready"] = fmt.Sprintf("%d/%d", ready, desired)
+		if summary.Kind == "Deployment" {
+			summary.Fields["upToDate"] = fmt.Sprint(intField(obj, "status", "updatedReplicas"))
+			summary.Fields["available"] = fmt.Sprint(intField(obj, "status", "availableReplicas"))
+		}
+		summary.Status = readiness(ready, desired)
+	case "DaemonSet":
+		desired := intField(obj, "status", "desiredNumberScheduled")
+		ready := intField(obj, "status", "numberReady")
+		summary.Fields["ready"] = fmt.Sprintf("%d/%d", ready, desired)
+		summary.Status = readiness(ready, desired)
+	case "Job":
+		summary.Fields["completions"] = fmt.Sprintf("%d/%d", intField(obj, "status", "succeeded"), intField(obj, "spec", "completions"))
+		summary.Status = "Running"
+		for _, condition := range []string{"Complete", "Failed", "Suspended"} {
+			if conditionStatus(obj, condition) == "True" {
+				summary.Status = condition
+			}
+		}
+	case "Service":
+		summary.Fields["type"] = stringField(obj, "spec", "type")
+		summary.Fields["clusterIP"] = stringField(obj, "spec", "clusterIP")
+		var ports []string
+		for _, p := range sliceField(obj, "spec", "ports") {
+			if port, ok := p.(map[string]interface{}); ok {
+				ports = append(ports, fmt.Sprintf("%d/%s", intField(port, "port"), stringField(port, "protocol")))
+			}
+		}
+		summary.Fields["ports"] = strings.Join(ports, ",")
+	case "Event":
+		summary.Status = stringField(obj, "type")
+		if seen, err := time.Parse(time.RFC3339, stringField(obj, "lastTimestamp")); err == nil {
+			summary.Age = shortDuration(now.Sub(seen))
+		}
+		summary.Fields["reason"] = stringField(obj, "reason")
+		if kind := stringField(obj, "involvedObject", "kind"); kind != "" {
+			summary.Fields["object"] = strings.ToLower(kind) + "/" + stringField(obj, "involvedObject", "name")
+		}
+		if count := intField(obj, "count"); count > 1 {
+			summary.Fields["count"] = fmt.Sprint(count)
+		}
+		summary.Fields["message"] = strings.TrimSpace(stringField(obj, "message"))
+	case "Node":
+		summary.Status = "NotReady"
+		if conditionStatus(obj, "Ready") == "True" {
+			summary.Status = "Ready"
+		}
+		if unschedulable, _ := nestedField(obj, "spec", "unschedulable").(bool); unschedulable {
+			summary.Status += ",SchedulingDisabled"
+		}
+		var roles []string
+		for label := range mapField(obj, "metadata", "labels") {
+			if role, found := strings.CutPrefix(label, "node-role.kubernetes.io/"); found && role != "" {
+				roles = append(roles, role)
+			}
+		}
+		sort.Strings(roles)
+		summary.Fields["roles"] = strings.Join(roles, ",")
+		summary.Fields["version"] = stringField(obj, "status", "nodeInfo", "kubeletVersion")
+	default:
+		if summary.Status == "" {
+			if ready := conditionStatus(obj, "Ready"); ready != "" {
+				summary.Status = "Ready=" + ready
+			}
+		}
+	}
+
+	if stringField(obj, "metadata", "deletionTimestamp") != "" {
+		summary.Status = "Terminating"
+	}
+	for key, value := range summary.Fields {
+		if value == "" {
+			delete(summary.Fields, key)
+		}
+	}
+	if len(summary.Fields) == 0 {
+		summary.Fields = nil
+	}
+	return summary
+}
+
+// summarizePod sets the status and fields of a pod like the STATUS, READY and RESTARTS columns of kubectl get
+func summarizePod(obj map[string]interface{}, summary *ResourceSummary) {
+	if reason := stringField(obj, "status", "reason"); reason != "" {
+		summary.Status = reason
+	}
+
+	var ready, restarts int64
+	statuses := sliceField(obj, "status", "containerStatuses")
+	for _, s := range statuses {
+		status, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if isReady, _ := status["ready"].(bool); isReady {
+			ready++
+		}
+		restarts += intField(status, "restartCount")
+		if reason := stringField(status, "state", "waiting", "reason"); reason != "" {
+			summary.Status = reason
+		} else if reason := stringField(status, "state", "terminated", "reason"); reason != "" {
+			summary.Status = reason
+		}
+	}
+
+	containers := int64(len(sliceField(obj, "spec", "containers")))
+	summary.Fields["ready"] = fmt.Sprintf("%d/%d", ready, containers)
+	summary.Fields["restarts"] = fmt.Sprint(restarts)
+	summary.Fields["node"] = stringField(obj, "spec", "nodeName")
+	summary.Fields["ip"] = stringField(obj, "status", "podIP")
+}
+
+// renderSummaries renders summaries as compact text, one line per object
+func renderSummaries(summaries []ResourceSummary) string {
+	if len(summaries) == 0 {
+		return "No resources found\n"
+	}
+
+	var out strings.Builder
+	for _, s := range summaries {
+		name := s.Name
+		if s.Namespace != "" {
+			name = s.Namespace + "/" + s.Name
+		}
+		fmt.Fprintf(&out, "%s %s", strings.ToLower(s.Kind), name)
+		if s.Status != "" {
+			fmt.Fprintf(&out, " %s", s.Status)
+		}
+		if s.Age != "" {
+			fmt.Fprintf(&out, " age=%s", s.Age)
+		}
+		keys := make([]string, 0, len(s.Fields))
+		for key := range s.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&out, " %s=%s", key, s.Fields[key])
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// readiness returns Ready when all desired replicas are ready
+func readiness(ready, desired int64) string {
+	if ready >= desired {
+		return "Ready"
+	}
+	return "NotReady"
+}
+
+// shortDuration formats a duration like the AGE column of kubectl get
+func shortDuration(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "0s"
+	case d < 2*time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < 3*time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// conditionStatus returns the status of a condition, or empty if the object doesn't have it
+func conditionStatus(obj map[string]interface{}, conditionType string) string {
+	for _, c := range sliceField(obj, "status", "conditions") {
+		if condition, ok := c.(map[string]interface{}); ok && stringField(condition, "type") == conditionType {
+			return stringField(condition, "status")
+		}
+	}
+	return ""
+}
+
+// nestedField returns the value at a path of nested maps, or nil if it doesn't exist
+func nestedField(obj map[string]interface{}, path ...string) interface{} {
+	var value interface{} = obj
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+func stringField(obj map[string]interface{}, path ...string) string {
+	value, _ := nestedField(obj, path...).(string)
+	return value
+}
+
+func intField(obj map[string]interface{}, path ...string) int64 {
+	value, _ := nestedField(obj, path...).(float64)
+	return int64(value)
+}
+
+func sliceField(obj map[string]interface{}, path ...string) []interface{} {
+	value, _ := nestedField(obj, path...).([]interface{})
+	return value
+}
+
+func mapField(obj map[string]interface{}, path ...string) map[string]interface{} {
+	value, _ := nestedField(obj, path...).(map[string]interface{})
+	return value
+}
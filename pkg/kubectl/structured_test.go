@@ -0,0 +1,163 @@
+package kubectl
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const podListJSON = `{
+    "apiVersion": "v1",
+    "kind": "List",
+    "items": [
+        {
+            "apiVersion": "v1",
+            "kind": "Pod",
+            "metadata": {"name": "web", "namespace": "default", "creationTimestamp": "2025-01-01T11:55:00Z"},
+            "spec": {"nodeName": "node-1", "containers": [{"name": "app"}, {"name": "sidecar"}]},
+            "status": {
+                "phase": "Running",
+                "podIP": "10.0.0.5",
+                "containerStatuses": [
+                    {"name": "app", "ready": true, "restartCount": 1},
+                    {"name": "sidecar", "ready": false, "restartCount": 4, "state": {"waiting": {"reason": "CrashLoopBackOff"}}}
+                ]
+            }
+        },
+        {
+            "apiVersion": "v1",
+            "kind": "Service",
+            "metadata": {"name": "web", "namespace": "default", "creationTimestamp": "2024-12-25T12:00:00Z"},
+            "spec": {"type": "ClusterIP", "clusterIP": "10.96.0.10", "ports": [{"port": 80, "protocol": "TCP"}]}
+        }
+    ]
+}`
+
+var structuredTestNow = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestSummarizeObjects(t *testing.T) {
+	summaries, err := summarizeObjects(podListJSON, structuredTestNow)
+	if err != nil {
+		t.Fatalf("summarizeObjects() unexpected error = %v", err)
+	}
+
+	want := []ResourceSummary{
+		{
+			Kind: "Pod", Name: "web", Namespace: "default", Status: "CrashLoopBackOff", Age: "5m",
+			Fields: map[string]string{"ready": "1/2", "restarts": "5", "node": "node-1", "ip": "10.0.0.5"},
+		},
+		{
+			Kind: "Service", Name: "web", Namespace: "default", Age: "7d",
+			Fields: map[string]string{"type": "ClusterIP", "clusterIP": "10.96.0.10", "ports": "80/TCP"},
+		},
+	}
+	if !reflect.DeepEqual(summaries, want) {
+		t.Errorf("summarizeObjects() = %+v, want %+v", summaries, want)
+	}
+
+	text := renderSummaries(summaries)
+	wantText := "pod default/web CrashLoopBackOff age=5m ip=10.0.0.5 node=node-1 ready=1/2 restarts=5\n" +
+		"service default/web age=7d clusterIP=10.96.0.10 ports=80/TCP type=ClusterIP\n"
+	if text != wantText {
+		t.Errorf("renderSummaries() = %q, want %q", text, wantText)
+	}
+}
+
+func TestSummarizeObjectKinds(t *testing.T) {
+	tests := []struct {
+		name       string
+		json       string
+		wantStatus string
+		wantFields map[string]string
+	}{
+		{
+			name:       "deployment not ready",
+			json:       `{"kind": "Deployment", "metadata": {"name": "api"}, "spec": {"replicas": 3}, "status": {"readyReplicas": 1, "updatedReplicas": 3, "availableReplicas": 1}}`,
+			wantStatus: "NotReady",
+			wantFields: map[string]string{"ready": "1/3", "upToDate": "3", "available": "1"},
+		},
+		{
+			name:       "cordoned node",
+			json:       `{"kind": "Node", "metadata": {"name": "n1", "labels": {"node-role.kubernetes.io/control-plane": ""}}, "spec": {"unschedulable": true}, "status": {"conditions": [{"type": "Ready", "status": "True"}], "nodeInfo": {"kubeletVersion": "v1.31.0"}}}`,
+			wantStatus: "Ready,SchedulingDisabled",
+			wantFields: map[string]string{"roles": "control-plane", "version": "v1.31.0"},
+		},
+		{
+			name:       "failed job",
+			json:       `{"kind": "Job", "metadata": {"name": "migrate"}, "spec": {"completions": 1}, "status": {"conditions": [{"type": "Failed", "status": "True"}]}}`,
+			wantStatus: "Failed",
+			wantFields: map[string]string{"completions": "0/1"},
+		},
+		{
+			name:       "warning event",
+			json:       `{"kind": "Event", "metadata": {"name": "web.1"}, "involvedObject": {"kind": "Pod", "name": "web"}, "type": "Warning", "reason": "BackOff", "message": "Back-off restarting failed container\n", "count": 5}`,
+			wantStatus: "Warning",
+			wantFields: map[string]string{"reason": "BackOff", "object": "pod/web", "count": "5", "message": "Back-off restarting failed container"},
+		},
+		{
+			name:       "terminating namespace",
+			json:       `{"kind": "Namespace", "metadata": {"name": "old", "deletionTimestamp": "2025-01-01T11:00:00Z"}, "status": {"phase": "Active"}}`,
+			wantStatus: "Terminating",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaries, err := summarizeObjects(tt.json, structuredTestNow)
+			if err != nil || len(summaries) != 1 {
+				t.Fatalf("summarizeObjects() = %v, %v", summaries, err)
+			}
+			if summaries[0].Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", summaries[0].Status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(summaries[0].Fields, tt.wantFields) {
+				t.Errorf("Fields = %v, want %v", summaries[0].Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestGetOutputFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		want    string
+		wantErr bool
+	}{
+		{"default", map[string]interface{}{}, OutputText, false},
+		{"empty", map[string]interface{}{OutputParam: ""}, OutputText, false},
+		{"json", map[string]interface{}{OutputParam: "json"}, OutputJSON, false},
+		{"summary", map[string]interface{}{OutputParam: "summary"}, OutputSummary, false},
+		{"kubectl format", map[string]interface{}{OutputParam: "yaml"}, OutputYAML, false},
+		{"invalid", map[string]interface{}{OutputParam: "xml"}, "", true},
+		{"not a string", map[string]interface{}{OutputParam: 1.0}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getOutputFormat(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getOutputFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getOutputFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasOutputFlag(t *testing.T) {
+	tests := map[string]bool{
+		"-n default":             false,
+		"-o wide":                true,
+		"-owide":                 true,
+		"--output=yaml":          true,
+		"--output json":          true,
+		"-l app=web --overwrite": false,
+	}
+	for args, want := range tests {
+		if got := hasOutputFlag(args); got != want {
+			t.Errorf("hasOutputFlag(%q) = %v, want %v", args, got, want)
+		}
+	}
+}
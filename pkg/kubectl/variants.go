@@ -0,0 +1,170 @@
+package kubectl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// readOperations lists the operations of each tool that don't modify the cluster. Operations taking a
+// subcommand as resource list their read-only subcommands, nil means all uses of the operation are read-only.
+var readOperations = map[string]map[string][]string{
+	"kubectl_resources":   {"get": nil, "describe": nil},
+	"kubectl_workloads":   {"rollout": {"status", "history"}},
+	"kubectl_diagnostics": {"logs": nil, "events": nil, "top": nil},
+	"kubectl_cluster":     {"cluster-info": nil, "api-resources": nil, "api-versions": nil, "explain": nil},
+	"kubectl_config":      {"diff": nil, "auth": nil, "config": {"current-context", "get-contexts"}},
+}
+
+var (
+	// operationBulletPattern matches the operation list entries of tool descriptions
+	operationBulletPattern = regexp.MustCompile(`^- ([a-z-]+): `)
+	// examplePattern matches the examples of tool descriptions
+	examplePattern = regexp.MustCompile(`operation='([^']*)', resource='([^']*)'`)
+)
+
+// isReadOperation checks if an operation of a tool doesn't modify the cluster
+func isReadOperation(toolName, operation, resource string) bool {
+	subcommands, ok := readOperations[toolName][operation]
+	return ok && (subcommands == nil || contains(subcommands, resource))
+}
+
+// isWriteOperation checks if an operation of a tool modifies the cluster, at least for some subcommands
+func isWriteOperation(toolName, operation string) bool {
+	subcommands, ok := readOperations[toolName][operation]
+	return !ok || subcommands != nil
+}
+
+// RegisterSplitKubectlTools returns kubectl tools filtered by access level, with the tools that mix read and
+// write operations split into a read-only variant keeping the tool name and a write variant named <tool>_write.
+// Tools registered for the readonly access level can't modify the cluster and aren't split.
+func RegisterSplitKubectlTools(accessLevel string) []mcp.Tool {
+	if !isValidAccessLevel(accessLevel) || accessLevel == AccessLevelReadOnly {
+		return RegisterKubectlTools(accessLevel)
+	}
+
+	var result []mcp.Tool
+	for _, tool := range RegisterKubectlTools(accessLevel) {
+		var readOps, writeOps []string
+		for _, operation := range toolOperations(tool) {
+			if hasKey(readOperations[tool.Name], operation) {
+				readOps = append(readOps, operation)
+			}
+			if isWriteOperation(tool.Name, operation) {
+				writeOps = append(writeOps, operation)
+			}
+		}
+		if len(readOps) == 0 || len(writeOps) == 0 {
+			result = append(result, tool)
+			continue
+		}
+
+		// restrictTool copies the schema properties it changes, so the variants don't share state
+		read := tool
+		restrictTool(&read, readOps, func(operation, resource string) bool {
+			return isReadOperation(tool.Name, operation, resource)
+		})
+		read.Description = fmt.Sprintf("Read-only variant, use %s for operations that modify the cluster.\n\n%s",
+			tools.WriteVariantName(tool.Name), read.Description)
+		tools.SetAnnotations(&read, tools.ReadOnlyAnnotations)
+
+		write := tool
+		write.Name = tools.WriteVariantName(tool.Name)
+		restrictTool(&write, writeOps, func(operation, resource string) bool {
+			return !isReadOperation(tool.Name, operation, resource)
+		})
+		write.Description = fmt.Sprintf("Write variant of %s for operations that modify the cluster, use %s for read-only operations.\n\n%s",
+			tool.Name, tool.Name, write.Description)
+
+		result = append(result, read, write)
+	}
+	return result
+}
+
+// restrictTool limits the operation enum of a tool to the given operations and drops the operation
+// list entries and examples of other operations and subcommands from its description
+func restrictTool(tool *mcp.Tool, operations []string, allowed func(operation, resource string) bool) {
+	all := toolOperations(*tool)
+	properties := make(map[string]any, len(tool.InputSchema.Properties))
+	for name, property := range tool.InputSchema.Properties {
+		properties[name] = property
+	}
+	operation := make(map[string]any)
+	for key, value := range properties["operation"].(map[string]any) {
+		operation[key] = value
+	}
+	operation["enum"] = operations
+	operation["description"] = "The operation to perform: " + strings.Join(operations, ", ")
+	properties["operation"] = operation
+	tool.InputSchema.Properties = properties
+
+	// Entries under a heading like "Config operations:" are subcommands of that operation
+	var lines []string
+	var section string
+	for _, line := range strings.Split(tool.Description, "\n") {
+		if heading, ok := strings.CutSuffix(line, " operations:"); ok {
+			section = strings.ToLower(heading)
+		}
+		if match := operationBulletPattern.FindStringSubmatch(line); match != nil {
+			if contains(all, section) && !allowed(section, match[1]) {
+				continue
+			}
+			if contains(all, match[1]) && !contains(operations, match[1]) {
+				continue
+			}
+		}
+		if match := examplePattern.FindStringSubmatch(line); match != nil && !allowed(match[1], match[2]) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	tool.Description = strings.Join(lines, "\n")
+}
+
+// toolOperations returns the operation enum of a tool definition
+func toolOperations(tool mcp.Tool) []string {
+	operation, _ := tool.InputSchema.Properties["operation"].(map[string]any)
+	operations, _ := operation["enum"].([]string)
+	return operations
+}
+
+func hasKey(m map[string][]string, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// ToolForOperation returns the name of the registered kubectl tool running an operation at an access level,
+// and false if the operation isn't available. With split tools, operations modifying the cluster run on the
+// write variant of the tool.
+func ToolForOperation(accessLevel string, splitTools bool, toolName, operation, resource string) (string, bool) {
+	command, err := MapOperationToCommand(toolName, operation, resource)
+	if err != nil || command == "" {
+		return "", false
+	}
+	var executor KubectlToolExecutor
+	if executor.checkAccessLevel(command, &config.ConfigData{AccessLevel: accessLevel}) != nil {
+		return "", false
+	}
+
+	registered := RegisterKubectlTools(accessLevel)
+	if splitTools {
+		registered = RegisterSplitKubectlTools(accessLevel)
+	}
+	name := toolName
+	if splitTools && !isReadOperation(toolName, operation, resource) {
+		name = tools.WriteVariantName(toolName)
+	}
+	// Tools that aren't split run both read and write operations
+	for _, candidate := range []string{name, toolName} {
+		for _, tool := range registered {
+			if tool.Name == candidate && contains(toolOperations(tool), operation) {
+				return tool.Name, true
+			}
+		}
+	}
+	return "", false
+}
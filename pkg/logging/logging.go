@@ -0,0 +1,154 @@
+// Package logging logs structured server events to stderr and forwards them to sinks, like the MCP sessions
+// of the server. Secrets in messages and attributes are redacted before they are written anywhere.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"sync"
+)
+
+// SessionKey is the attribute holding the session that caused an event. Events of a session are only
+// forwarded to it, events without a session are forwarded to all sessions.
+const SessionKey = "session"
+
+// Record is an event forwarded to sinks
+type Record struct {
+	Level   slog.Level
+	Message string
+	// SessionID is the session that caused the event, empty for server events
+	SessionID string
+	// Attrs holds the attributes of the event without the session
+	Attrs map[string]any
+}
+
+// Sink receives every logged event, regardless of the level of the stderr output
+type Sink func(record Record)
+
+// secretPatterns match secrets in command lines and outputs, the first group is kept
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[^\s"']+`),
+	regexp.MustCompile(`(?i)(--(?:token|password|client-key-data|client-certificate-data)[=\s]+)[^\s"']+`),
+	regexp.MustCompile(`(?i)(--from-literal[=\s]+[^=\s]+=)[^\s"']+`),
+	regexp.MustCompile(`(?i)([\w.-]*(?:password|passwd|secret|token|apikey|api-key|api_key)\s*[=:]\s*"?)[^\s",]+`),
+}
+
+// Redact replaces secrets in a text, like bearer tokens, passwords and literal values of secrets
+func Redact(text string) string {
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllString(text, "${1}[REDACTED]")
+	}
+	return text
+}
+
+var (
+	mu     sync.RWMutex
+	sinks  []Sink
+	logger = slog.New(&handler{output: newOutput(os.Stderr, slog.LevelInfo)})
+)
+
+// Logger returns the logger of the server
+func Logger() *slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return logger
+}
+
+// ForSession returns the logger of the events of a session, empty for server events
+func ForSession(sessionID string) *slog.Logger {
+	if sessionID == "" {
+		return Logger()
+	}
+	return Logger().With(slog.String(SessionKey, sessionID))
+}
+
+// AddSink registers a sink receiving every event
+func AddSink(sink Sink) {
+	mu.Lock()
+	defer mu.Unlock()
+	sinks = append(sinks, sink)
+}
+
+// SetOutput writes events at or above a level to w instead of stderr, and removes all sinks. Tests use it
+// to capture events.
+func SetOutput(w io.Writer, level slog.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	sinks = nil
+	logger = slog.New(&handler{output: newOutput(w, level)})
+}
+
+// newOutput creates the text handler writing events
+func newOutput(w io.Writer, level slog.Level) slog.Handler {
+	return slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})
+}
+
+// handler redacts events, writes them to its output and forwards them to the sinks
+type handler struct {
+	output slog.Handler
+	attrs  []slog.Attr
+}
+
+// Enabled implements slog.Handler, sinks filter events themselves so every level is enabled
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+// Handle implements slog.Handler
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	record := Record{Level: r.Level, Message: redacted.Message, Attrs: make(map[string]any)}
+	add := func(attr slog.Attr) bool {
+		attr.Value = attr.Value.Resolve()
+		if attr.Key == SessionKey {
+			record.SessionID = attr.Value.String()
+		} else if attr.Value.Kind() == slog.KindString || attr.Value.Kind() == slog.KindAny {
+			// Errors and other values are redacted in their text form
+			attr.Value = slog.StringValue(Redact(attr.Value.String()))
+		}
+		redacted.AddAttrs(attr)
+		if attr.Key != SessionKey {
+			record.Attrs[attr.Key] = value(attr.Value)
+		}
+		return true
+	}
+	for _, attr := range h.attrs {
+		add(attr)
+	}
+	r.Attrs(add)
+
+	var err error
+	if h.output.Enabled(ctx, r.Level) {
+		err = h.output.Handle(ctx, redacted)
+	}
+	mu.RLock()
+	forward := sinks
+	mu.RUnlock()
+	for _, sink := range forward {
+		sink(record)
+	}
+	return err
+}
+
+// value converts an attribute value for sinks, durations and times are passed as text
+func value(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindDuration, slog.KindTime:
+		return v.String()
+	default:
+		return v.Any()
+	}
+}
+
+// WithAttrs implements slog.Handler
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{output: h.output, attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+// WithGroup implements slog.Handler, groups are not used by the server and are flattened
+func (h *handler) WithGroup(name string) slog.Handler {
+	return h
+}
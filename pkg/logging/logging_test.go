@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "bearer token", text: "Authorization: Bearer abc.def", want: "Authorization: Bearer [REDACTED]"},
+		{name: "token flag", text: "kubectl get pods --token=abc -n x", want: "kubectl get pods --token=[REDACTED] -n x"},
+		{name: "password flag", text: "helm repo add r url --password s3cret", want: "helm repo add r url --password [REDACTED]"},
+		{name: "literal", text: "kubectl create secret generic db --from-literal=user=admin", want: "kubectl create secret generic db --from-literal=user=[REDACTED]"},
+		{name: "helm values", text: "helm install db chart --set auth.password=pw,replicas=2", want: "helm install db chart --set auth.password=[REDACTED],replicas=2"},
+		{name: "yaml", text: `token: "abc"`, want: `token: "[REDACTED]"`},
+		{name: "nothing to redact", text: "kubectl get pods -n shop", want: "kubectl get pods -n shop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.text); got != tt.want {
+				t.Errorf("Redact() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSinks(t *testing.T) {
+	var output bytes.Buffer
+	defer SetOutput(os.Stderr, slog.LevelInfo)
+	SetOutput(&output, slog.LevelInfo)
+
+	var records []Record
+	AddSink(func(record Record) { records = append(records, record) })
+
+	ForSession("s1").Debug("command started", "command", "kubectl get pods --token=abc")
+	Logger().Warn("telemetry export failed", "error", errors.New("password=x"), "delay", time.Second)
+
+	if len(records) != 2 {
+		t.Fatalf("sinks received %d records, want 2", len(records))
+	}
+	first := records[0]
+	if first.SessionID != "s1" || first.Level != slog.LevelDebug || first.Attrs["command"] != "kubectl get pods --token=[REDACTED]" {
+		t.Errorf("Unexpected session record %+v", first)
+	}
+	if _, ok := first.Attrs[SessionKey]; ok {
+		t.Errorf("session is passed as attribute: %+v", first.Attrs)
+	}
+	second := records[1]
+	if second.SessionID != "" || second.Attrs["error"] != "password=[REDACTED]" || second.Attrs["delay"] != "1s" {
+		t.Errorf("Unexpected server record %+v", second)
+	}
+
+	// The output only has events at its level, redacted
+	text := output.String()
+	if strings.Contains(text, "command started") || !strings.Contains(text, "telemetry export failed") || strings.Contains(text, "password=x") {
+		t.Errorf("Unexpected output %q", text)
+	}
+}
@@ -0,0 +1,182 @@
+package native
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/shlex"
+)
+
+// flagSpec describes a kubectl flag supported by the native backend
+type flagSpec struct {
+	// name is the long flag name without dashes
+	name     string
+	hasValue bool
+}
+
+// flagSpecs maps the supported short and long kubectl flags to their spec
+var flagSpecs = map[string]flagSpec{
+	"-n":                 {"namespace", true},
+	"--namespace":        {"namespace", true},
+	"-A":                 {"all-namespaces", false},
+	"--all-namespaces":   {"all-namespaces", false},
+	"-l":                 {"selector", true},
+	"--selector":         {"selector", true},
+	"--field-selector":   {"field-selector", true},
+	"-o":                 {"output", true},
+	"--output":           {"output", true},
+	"-p":                 {"patch", true},
+	"--patch":            {"patch", true},
+	"--type":             {"type", true},
+	"--replicas":         {"replicas", true},
+	"-f":                 {"filename", true},
+	"--filename":         {"filename", true},
+	"--overwrite":        {"overwrite", false},
+	"--all":              {"all", false},
+	"--ignore-not-found": {"ignore-not-found", false},
+	"--for":              {"for", true},
+	"--types":            {"types", true},
+}
+
+// operationFlags lists the flags each natively implemented operation accepts.
+// Calls with any other flag are executed with the kubectl binary instead.
+var operationFlags = map[string][]string{
+	"get":      {"namespace", "all-namespaces", "selector", "field-selector", "output"},
+	"describe": {"namespace", "all-namespaces", "selector"},
+	"delete":   {"namespace", "selector", "all", "ignore-not-found"},
+	"apply":    {"namespace", "filename"},
+	"patch":    {"namespace", "patch", "type"},
+	"scale":    {"namespace", "replicas"},
+	"label":    {"namespace", "selector", "all", "overwrite"},
+	"events":   {"namespace", "all-namespaces", "for", "types", "output"},
+}
+
+// commandArgs holds parsed kubectl arguments
+type commandArgs struct {
+	positional []string
+	// flags maps long flag names to their value, "true" for boolean flags
+	flags map[string]string
+	// filenames collects every -f/--filename value in order
+	filenames []string
+}
+
+// errUnsupported is returned when arguments use features the native backend doesn't implement
+type errUnsupported struct {
+	reason string
+}
+
+func (e *errUnsupported) Error() string {
+	return "not supported by the native backend: " + e.reason
+}
+
+// parseArgs parses kubectl arguments for an operation, rejecting flags the operation doesn't support natively
+func parseArgs(operation, args string) (*commandArgs, error) {
+	allowed, ok := operationFlags[operation]
+	if !ok {
+		return nil, &errUnsupported{reason: "operation " + operation}
+	}
+
+	parts, err := shlex.Split(args)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &commandArgs{flags: make(map[string]string)}
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+		if !strings.HasPrefix(part, "-") || part == "-" {
+			parsed.positional = append(parsed.positional, part)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(part, "=")
+		spec, ok := flagSpecs[name]
+		if !ok || !contains(allowed, spec.name) {
+			return nil, &errUnsupported{reason: "flag " + name}
+		}
+
+		if spec.hasValue && !hasValue {
+			if i+1 >= len(parts) {
+				return nil, fmt.Errorf("flag needs an argument: %s", name)
+			}
+			value = parts[i+1]
+			i++
+		} else if !spec.hasValue && !hasValue {
+			value = "true"
+		}
+
+		if spec.name == "filename" {
+			parsed.filenames = append(parsed.filenames, value)
+		}
+		parsed.flags[spec.name] = value
+	}
+
+	return parsed, nil
+}
+
+// flag returns the value of a flag, or empty if it was not given
+func (a *commandArgs) flag(name string) string {
+	return a.flags[name]
+}
+
+// boolFlag checks if a boolean flag is set to true
+func (a *commandArgs) boolFlag(name string) bool {
+	return a.flags[name] == "true"
+}
+
+// contains checks if a string is in a list
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// target is a resource type and an optional object name
+type target struct {
+	resource string
+	name     string
+}
+
+// resolveTargets combines the resource parameter and positional arguments into targets.
+// Names may be given as "type/name" or after a resource type, like kubectl does.
+func resolveTargets(resource string, positional []string) ([]target, error) {
+	if strings.Contains(resource, ",") {
+		return nil, &errUnsupported{reason: "multiple resource types"}
+	}
+
+	if resource == "" {
+		if len(positional) == 0 {
+			return nil, fmt.Errorf("you must specify the type of resource")
+		}
+		if !strings.Contains(positional[0], "/") {
+			resource, positional = positional[0], positional[1:]
+			if strings.Contains(resource, ",") {
+				return nil, &errUnsupported{reason: "multiple resource types"}
+			}
+		}
+	}
+
+	var targets []target
+	for _, arg := range positional {
+		if typ, name, found := strings.Cut(arg, "/"); found {
+			if resource != "" && len(targets) == 0 && typ != "" {
+				// "type/name" arguments can't be mixed with a separate resource type
+				return nil, fmt.Errorf("there is no need to specify a resource type as a separate argument when passing arguments in resource/name form")
+			}
+			targets = append(targets, target{resource: typ, name: name})
+			continue
+		}
+		if resource == "" {
+			return nil, fmt.Errorf("arguments in resource/name form must have a single resource and name")
+		}
+		targets = append(targets, target{resource: resource, name: arg})
+	}
+
+	if len(targets) == 0 {
+		targets = append(targets, target{resource: resource})
+	}
+	return targets, nil
+}
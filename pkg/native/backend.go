@@ -0,0 +1,189 @@
+// Package native implements kubectl tool operations with client-go instead of the kubectl binary.
+package native
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/command"
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/version"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// FieldManager is the field manager name used for server-side apply
+const FieldManager = "mcp-kubernetes"
+
+// supportedOperations lists the natively implemented operations per tool
+var supportedOperations = map[string][]string{
+	"kubectl_resources":   {"get", "describe", "delete", "apply", "patch"},
+	"kubectl_workloads":   {"scale"},
+	"kubectl_metadata":    {"label"},
+	"kubectl_diagnostics": {"events"},
+}
+
+// Backend runs kubectl tool operations with the dynamic client
+type Backend struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+	now    func() time.Time
+	// namespace is used by commands without a namespace, like the namespace of the kubeconfig context for kubectl
+	namespace string
+	// operations lists the implemented operations per tool
+	operations map[string][]string
+}
+
+var (
+	_ kubectl.Backend       = (*Backend)(nil)
+	_ kubectl.ScopeResolver = (*Backend)(nil)
+)
+
+// NewBackend creates a native backend for the cluster of a kubeconfig file.
+// An empty path uses the default kubeconfig loading rules.
+func NewBackend(kubeconfig string) (*Backend, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loadingRules.ExplicitPath = kubeconfig
+	}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{})
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	restConfig.UserAgent = version.GetUserAgent()
+
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+
+	cached := memory.NewMemCacheClient(discoveryClient)
+	mapper := restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cached), cached, func(string) {})
+	backend := NewBackendWithClients(client, mapper)
+	backend.namespace = namespace
+	return backend, nil
+}
+
+// NewBackendWithClients creates a native backend from existing clients, commands without a namespace use
+// the default namespace
+func NewBackendWithClients(client dynamic.Interface, mapper meta.RESTMapper) *Backend {
+	return &Backend{
+		client:     client,
+		mapper:     mapper,
+		now:        time.Now,
+		namespace:  "default",
+		operations: supportedOperations,
+	}
+}
+
+// Supports checks if the request only uses operations and flags implemented natively
+func (b *Backend) Supports(req kubectl.BackendRequest) bool {
+	if !contains(b.operations[req.ToolName], req.Operation) {
+		return false
+	}
+
+	args, err := parseArgs(req.Operation, req.Args)
+	if err != nil {
+		var unsupported *errUnsupported
+		return !errors.As(err, &unsupported)
+	}
+
+	switch req.Operation {
+	case "events":
+		output := args.flag("output")
+		return len(args.positional) == 0 && (output == "" || output == "json" || output == "yaml")
+	case "apply":
+		// Only manifests given inline or as local files are applied natively
+		for _, filename := range args.filenames {
+			if filename == "-" && req.Manifest == "" || strings.Contains(filename, "://") {
+				return false
+			}
+		}
+		return len(args.filenames) > 0 && len(args.positional) == 0
+	}
+
+	positional := args.positional
+	if req.Operation == "label" {
+		positional, _ = splitLabelArgs(positional)
+	}
+	var unsupported *errUnsupported
+	if _, err := resolveTargets(req.Resource, positional); errors.As(err, &unsupported) {
+		return false
+	}
+	return req.Operation != "get" || isSupportedOutput(args.flag("output"))
+}
+
+// Execute runs a supported request against the cluster
+func (b *Backend) Execute(req kubectl.BackendRequest) (string, error) {
+	args, err := parseArgs(req.Operation, req.Args)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	var output string
+	switch req.Operation {
+	case "get":
+		output, err = b.get(ctx, req, args)
+	case "describe":
+		output, err = b.describe(ctx, req, args)
+	case "delete":
+		output, err = b.delete(ctx, req, args)
+	case "apply":
+		output, err = b.apply(ctx, req, args)
+	case "patch":
+		output, err = b.patch(ctx, req, args)
+	case "scale":
+		output, err = b.scale(ctx, req, args)
+	case "label":
+		output, err = b.label(ctx, req, args)
+	case "events":
+		output, err = b.events(ctx, args)
+	default:
+		return "", fmt.Errorf("operation %s is not supported by the native backend", req.Operation)
+	}
+
+	fullCommand := strings.TrimSpace(fmt.Sprintf("kubectl %s %s %s", req.Operation, req.Resource, req.Args))
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "", &command.TimeoutError{Command: fullCommand, Timeout: time.Duration(req.Timeout) * time.Second}
+	}
+	if errors.Is(err, context.Canceled) || ctx.Err() == context.Canceled {
+		return "", fmt.Errorf("%w: %s", command.ErrCancelled, fullCommand)
+	}
+	var typedErr *toolerror.Error
+	if errors.As(err, &typedErr) {
+		// Typed errors are reported as errors, after the output of the objects that were already processed
+		return "", &toolerror.Error{Code: typedErr.Code, Message: output + typedErr.Message, Hint: typedErr.Hint}
+	}
+	if err != nil {
+		// Like the kubectl backend, failures are returned as output
+		return output + errorOutput(err), nil
+	}
+	return output, nil
+}
@@ -0,0 +1,465 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/command"
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var testNow = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestBackend creates a backend on the fake dynamic client seeded with objects
+func newTestBackend(t *testing.T, objects ...runtime.Object) *Backend {
+	t.Helper()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	namespaced := []schema.GroupVersionKind{
+		{Version: "v1", Kind: "Pod"},
+		{Version: "v1", Kind: "Service"},
+		{Version: "v1", Kind: "ConfigMap"},
+		{Version: "v1", Kind: "Event"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}
+	for _, gvk := range namespaced {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "pods"}:                       "PodList",
+		{Version: "v1", Resource: "services"}:                   "ServiceList",
+		{Version: "v1", Resource: "configmaps"}:                 "ConfigMapList",
+		{Version: "v1", Resource: "events"}:                     "EventList",
+		{Version: "v1", Resource: "namespaces"}:                 "NamespaceList",
+		{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+
+	// The fake tracker applies with a strategic merge patch, which unstructured objects don't
+	// support, so server-side apply of existing objects is approximated with a replace
+	client.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		existing, err := client.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		obj.SetUID(existing.(*unstructured.Unstructured).GetUID())
+		return true, obj, client.Tracker().Update(patch.GetResource(), obj, patch.GetNamespace())
+	})
+
+	backend := NewBackendWithClients(client, mapper)
+	backend.now = func() time.Time { return testNow }
+	return backend
+}
+
+// newObject creates an unstructured object created five minutes before testNow
+func newObject(apiVersion, kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	for key, value := range fields {
+		obj.Object[key] = value
+	}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetUID(types.UID("uid-" + name))
+	obj.SetCreationTimestamp(metav1.NewTime(testNow.Add(-5 * time.Minute)))
+	return obj
+}
+
+func newPod(namespace, name string) *unstructured.Unstructured {
+	return newObject("v1", "Pod", namespace, name, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "app", "image": "nginx"}},
+		},
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"containerStatuses": []interface{}{
+				map[string]interface{}{"name": "app", "ready": true, "restartCount": int64(2)},
+			},
+		},
+	})
+}
+
+func newDeployment(namespace, name string, replicas int64) *unstructured.Unstructured {
+	return newObject("apps/v1", "Deployment", namespace, name, map[string]interface{}{
+		"spec":   map[string]interface{}{"replicas": replicas},
+		"status": map[string]interface{}{"readyReplicas": replicas, "updatedReplicas": replicas, "availableReplicas": replicas},
+	})
+}
+
+func execute(t *testing.T, b *Backend, req kubectl.BackendRequest) string {
+	t.Helper()
+	if !b.Supports(req) {
+		t.Fatalf("Supports(%+v) = false, want true", req)
+	}
+	output, err := b.Execute(req)
+	if err != nil {
+		t.Fatalf("Execute(%+v) unexpected error = %v", req, err)
+	}
+	return output
+}
+
+func TestSupports(t *testing.T) {
+	b := newTestBackend(t)
+	tests := []struct {
+		name string
+		req  kubectl.BackendRequest
+		want bool
+	}{
+		{"get", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Args: "-n prod -o wide"}, true},
+		{"get with type/name", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Args: "deployment/nginx -o yaml"}, true},
+		{"get with jsonpath", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Args: "-o jsonpath={.items}"}, false},
+		{"get with context", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Args: "--context other"}, false},
+		{"get multiple types", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods,services"}, false},
+		{"apply file", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "apply", Args: "-f app.yaml"}, true},
+		{"apply inline", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "apply", Args: "-f -", Manifest: "kind: Pod"}, true},
+		{"apply url", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "apply", Args: "-f https://example.com/app.yaml"}, false},
+		{"apply kustomize", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "apply", Args: "-k ./overlay"}, false},
+		{"create", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "create", Args: "-f app.yaml"}, false},
+		{"scale", kubectl.BackendRequest{ToolName: "kubectl_workloads", Operation: "scale", Resource: "deployment", Args: "nginx --replicas=3"}, true},
+		{"label", kubectl.BackendRequest{ToolName: "kubectl_metadata", Operation: "label", Resource: "pods", Args: "nginx tier=web --overwrite"}, true},
+		{"annotate", kubectl.BackendRequest{ToolName: "kubectl_metadata", Operation: "annotate", Resource: "pods", Args: "nginx a=b"}, false},
+		{"logs", kubectl.BackendRequest{ToolName: "kubectl_diagnostics", Operation: "logs", Args: "nginx"}, false},
+		{"events json", kubectl.BackendRequest{ToolName: "kubectl_diagnostics", Operation: "events", Args: "-n prod -o json"}, true},
+		{"events wide", kubectl.BackendRequest{ToolName: "kubectl_diagnostics", Operation: "events", Args: "-o wide"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Supports(tt.req); got != tt.want {
+				t.Errorf("Supports() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTable(t *testing.T) {
+	b := newTestBackend(t, newPod("default", "web"), newPod("prod", "api"), newDeployment("default", "nginx", 2))
+
+	output := execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods"})
+	want := "NAME   READY   STATUS    RESTARTS   AGE\n" +
+		"web    1/1     Running   2          5m\n"
+	if output != want {
+		t.Errorf("get pods output =\n%s\nwant\n%s", output, want)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Args: "-A"})
+	if !strings.HasPrefix(output, "NAMESPACE   NAME") || !strings.Contains(output, "prod        api") {
+		t.Errorf("get pods -A output =\n%s", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "deployments"})
+	if !strings.Contains(output, "READY   UP-TO-DATE   AVAILABLE") || !strings.Contains(output, "nginx   2/2") {
+		t.Errorf("get deployments output =\n%s", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Args: "-n empty"})
+	if output != "No resources found in empty namespace.\n" {
+		t.Errorf("get pods in empty namespace output = %q", output)
+	}
+}
+
+func TestGetOutputFormats(t *testing.T) {
+	b := newTestBackend(t, newPod("default", "web"), newDeployment("default", "nginx", 2))
+
+	output := execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Args: "deployment/nginx -o json"})
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &obj); err != nil {
+		t.Fatalf("get -o json returned invalid JSON: %v", err)
+	}
+	if obj["kind"] != "Deployment" {
+		t.Errorf("get deployment/nginx -o json kind = %v, want Deployment", obj["kind"])
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Args: "-o yaml"})
+	if !strings.HasPrefix(output, "apiVersion: v1\nitems:\n") || !strings.Contains(output, "kind: List") {
+		t.Errorf("get pods -o yaml output =\n%s", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "deployments", Args: "-o name"})
+	if output != "deployment.apps/nginx\n" {
+		t.Errorf("get deployments -o name output = %q", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Args: "missing"})
+	if output != "Error from server (NotFound): pods \"missing\" not found\n" {
+		t.Errorf("get missing pod output = %q", output)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	event := newObject("v1", "Event", "default", "web.1", map[string]interface{}{
+		"involvedObject": map[string]interface{}{"kind": "Pod", "name": "web", "uid": "uid-web"},
+		"type":           "Warning",
+		"reason":         "BackOff",
+		"message":        "Back-off restarting failed container",
+		"source":         map[string]interface{}{"component": "kubelet"},
+		"lastTimestamp":  testNow.Add(-time.Minute).Format(time.RFC3339),
+	})
+	pod := newPod("default", "web")
+	pod.SetLabels(map[string]string{"app": "web"})
+	b := newTestBackend(t, pod, event)
+
+	output := execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "describe", Resource: "pod", Args: "web"})
+	for _, want := range []string{
+		"Name:         web\n",
+		"Namespace:    default\n",
+		"Labels:       app=web\n",
+		"Annotations:  <none>\n",
+		"Kind:         Pod\n",
+		"Restart Count:",
+		"Events:\n",
+		"Warning  BackOff  60s   kubelet  Back-off restarting failed container",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("describe output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	b := newTestBackend(t, newPod("default", "web"), newPod("default", "api"))
+
+	output := execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "delete", Resource: "pod", Args: "web"})
+	if output != "pod \"web\" deleted\n" {
+		t.Errorf("delete output = %q", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "delete", Resource: "pod", Args: "web"})
+	if !strings.Contains(output, "(NotFound)") {
+		t.Errorf("delete missing pod output = %q, want NotFound error", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "delete", Resource: "pod", Args: "web --ignore-not-found"})
+	if output != "" {
+		t.Errorf("delete --ignore-not-found output = %q, want empty", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "delete", Resource: "pods"})
+	if !strings.Contains(output, "no name was specified") {
+		t.Errorf("delete without name output = %q", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Args: "-o name"})
+	if output != "pod/api\n" {
+		t.Errorf("remaining pods = %q, want pod/api", output)
+	}
+}
+
+func TestApply(t *testing.T) {
+	b := newTestBackend(t)
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: fast
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: prod
+spec:
+  replicas: 1
+`
+	req := kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "apply", Args: "-f -", Manifest: manifest}
+	output := execute(t, b, req)
+	want := "configmap/settings created\ndeployment.apps/nginx created\n"
+	if output != want {
+		t.Errorf("apply output = %q, want %q", output, want)
+	}
+
+	output = execute(t, b, req)
+	if !strings.Contains(output, "configmap/settings ") || strings.Contains(output, "created") {
+		t.Errorf("second apply output = %q, want existing objects", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "apply", Args: "-f - -n other", Manifest: manifest})
+	if !strings.Contains(output, "does not match the namespace") {
+		t.Errorf("apply with conflicting namespace output = %q", output)
+	}
+}
+
+func TestApplyConflict(t *testing.T) {
+	b := newTestBackend(t, newDeployment("default", "nginx", 1))
+	client := b.client.(*dynamicfake.FakeDynamicClient)
+	client.PrependReactor("patch", "deployments", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if patch := action.(clienttesting.PatchAction); patch.GetPatchType() == types.ApplyPatchType {
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "nginx",
+				errors.New(`Apply failed with 1 conflict: conflict with "kubectl-edit": .spec.replicas`))
+		}
+		return false, nil, nil
+	})
+
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: 2
+`
+	req := kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "apply", Args: "-f -", Manifest: manifest}
+	_, err := b.Execute(req)
+	if toolerror.CodeOf(err) != toolerror.Conflict {
+		t.Fatalf("Execute() error = %v, want a Conflict error", err)
+	}
+	if !strings.Contains(err.Error(), "configmap/settings created") || !strings.Contains(err.Error(), "kubectl-edit") {
+		t.Errorf("Execute() error = %q, want the applied objects and the conflict", err.Error())
+	}
+}
+
+func TestContextNamespace(t *testing.T) {
+	b := newTestBackend(t, newPod("default", "web"), newPod("team", "api"))
+	b.namespace = "team"
+
+	output := execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Args: "-o name"})
+	if output != "pod/api\n" {
+		t.Errorf("get without namespace = %q, want the pods of the context namespace", output)
+	}
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Args: "-o name -n default"})
+	if output != "pod/web\n" {
+		t.Errorf("get with namespace = %q, want pod/web", output)
+	}
+}
+
+func TestCancelledContext(t *testing.T) {
+	// The API server only answers once the request is abandoned, so the call returns by the tool call context
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer server.Close()
+	client, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	b := NewBackendWithClients(client, mapper)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = b.Execute(kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Timeout: 30, Context: ctx})
+	if !errors.Is(err, command.ErrCancelled) {
+		t.Errorf("Execute() error = %v, want a cancelled error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Execute() returned after %v, want it to stop when the context is cancelled", elapsed)
+	}
+
+	_, err = b.Execute(kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods", Timeout: 30, Context: ctx})
+	if !errors.Is(err, command.ErrCancelled) {
+		t.Errorf("Execute() with a cancelled context error = %v, want a cancelled error", err)
+	}
+}
+
+func TestPatchScaleLabel(t *testing.T) {
+	b := newTestBackend(t, newDeployment("default", "nginx", 1), newPod("default", "web"))
+
+	output := execute(t, b, kubectl.BackendRequest{
+		ToolName: "kubectl_resources", Operation: "patch", Resource: "deployment",
+		Args: `nginx --type merge -p '{"metadata":{"annotations":{"owner":"team"}}}'`,
+	})
+	if output != "deployment.apps/nginx patched\n" {
+		t.Errorf("patch output = %q", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_workloads", Operation: "scale", Resource: "deployment", Args: "nginx --replicas=3"})
+	if output != "deployment.apps/nginx scaled\n" {
+		t.Errorf("scale output = %q", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Args: "deployment/nginx -o json"})
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &obj); err != nil {
+		t.Fatalf("get -o json returned invalid JSON: %v", err)
+	}
+	u := unstructured.Unstructured{Object: obj}
+	if replicas, _, _ := unstructured.NestedFloat64(obj, "spec", "replicas"); replicas != 3 {
+		t.Errorf("replicas = %v, want 3", replicas)
+	}
+	if u.GetAnnotations()["owner"] != "team" {
+		t.Errorf("annotations = %v, want owner=team", u.GetAnnotations())
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_metadata", Operation: "label", Resource: "pods", Args: "web tier=web"})
+	if output != "pod/web labeled\n" {
+		t.Errorf("label output = %q", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_metadata", Operation: "label", Resource: "pods", Args: "web tier=api"})
+	if !strings.Contains(output, "'tier' already has a value (web), and --overwrite is false") {
+		t.Errorf("label without overwrite output = %q", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_metadata", Operation: "label", Resource: "pods", Args: "web tier-"})
+	if output != "pod/web unlabeled\n" {
+		t.Errorf("label removal output = %q", output)
+	}
+}
+
+func TestSmartLabel(t *testing.T) {
+	tests := map[string]string{
+		"restartCount":      "Restart Count",
+		"podIPs":            "Pod IPs",
+		"containerID":       "Container ID",
+		"apiVersion":        "API Version",
+		"creationTimestamp": "Creation Timestamp",
+	}
+	for field, want := range tests {
+		if got := smartLabel(field); got != want {
+			t.Errorf("smartLabel(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
+
+func TestKindScope(t *testing.T) {
+	backend := newTestBackend(t)
+
+	if namespaced, err := backend.KindScope("apps/v1", "Deployment"); err != nil || !namespaced {
+		t.Errorf("KindScope(Deployment) = %v, %v, want namespaced", namespaced, err)
+	}
+	if namespaced, err := backend.KindScope("v1", "Namespace"); err != nil || namespaced {
+		t.Errorf("KindScope(Namespace) = %v, %v, want cluster-scoped", namespaced, err)
+	}
+	if _, err := backend.KindScope("example.com/v1", "Widget"); err == nil {
+		t.Error("Expected error for unknown kind")
+	}
+}
@@ -0,0 +1,255 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// eventsResource is the core events resource listed for describe
+var eventsResource = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+
+// describedFields are printed in the describe header and skipped in the content
+var describedFields = map[string]bool{
+	"apiVersion": true,
+	"kind":       true,
+}
+
+// describedMetadataFields are printed in the describe header and skipped in the metadata
+var describedMetadataFields = map[string]bool{
+	"name":          true,
+	"namespace":     true,
+	"labels":        true,
+	"annotations":   true,
+	"managedFields": true,
+}
+
+// acronyms are field name words printed in upper case by describe
+var acronyms = map[string]bool{
+	"api": true, "cidr": true, "cpu": true, "dns": true, "http": true, "https": true, "id": true,
+	"ip": true, "tcp": true, "tls": true, "udp": true, "uid": true, "uri": true, "url": true,
+}
+
+// describe implements kubectl describe with a generic describer like kubectl uses for custom resources
+func (b *Backend) describe(ctx context.Context, req kubectl.BackendRequest, args *commandArgs) (string, error) {
+	targets, err := resolveTargets(req.Resource, args.positional)
+	if err != nil {
+		return "", err
+	}
+
+	namespace := b.namespaceFor(args)
+	if args.boolFlag("all-namespaces") {
+		namespace = ""
+	}
+
+	objects, fetchErr := b.fetch(ctx, targets, namespace, listOptions(args))
+	if fetchErr != nil && !isNotFound(fetchErr) {
+		return "", fetchErr
+	}
+	if len(objects) == 0 && fetchErr == nil {
+		if namespace != "" {
+			return fmt.Sprintf("No resources found in %s namespace.\n", namespace), nil
+		}
+		return "No resources found\n", nil
+	}
+
+	var out strings.Builder
+	for i, ro := range objects {
+		if i > 0 {
+			out.WriteString("\n\n")
+		}
+		b.describeObject(ctx, &out, ro.object)
+	}
+	return out.String(), fetchErr
+}
+
+// describeObject prints the fields of an object followed by its events
+func (b *Backend) describeObject(ctx context.Context, out io.Writer, obj *unstructured.Unstructured) {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Name:\t%s\n", obj.GetName())
+	if obj.GetNamespace() != "" {
+		fmt.Fprintf(w, "Namespace:\t%s\n", obj.GetNamespace())
+	}
+	printMap(w, "Labels", obj.GetLabels())
+	printMap(w, "Annotations", obj.GetAnnotations())
+	fmt.Fprintf(w, "API Version:\t%s\n", obj.GetAPIVersion())
+	fmt.Fprintf(w, "Kind:\t%s\n", obj.GetKind())
+
+	content := make(map[string]interface{}, len(obj.Object))
+	for key, value := range obj.Object {
+		if describedFields[key] {
+			continue
+		}
+		if key == "metadata" {
+			metadata, _ := value.(map[string]interface{})
+			filtered := make(map[string]interface{}, len(metadata))
+			for field, v := range metadata {
+				if !describedMetadataFields[field] {
+					filtered[field] = v
+				}
+			}
+			value = filtered
+		}
+		content[key] = value
+	}
+	printContent(w, content, 0)
+
+	b.printEvents(ctx, w, obj)
+	_ = w.Flush()
+}
+
+// printMap prints labels or annotations, one key=value pair per line
+func printMap(w io.Writer, title string, values map[string]string) {
+	if len(values) == 0 {
+		fmt.Fprintf(w, "%s:\t<none>\n", title)
+		return
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		if i == 0 {
+			fmt.Fprintf(w, "%s:\t%s=%s\n", title, key, values[key])
+		} else {
+			fmt.Fprintf(w, "\t%s=%s\n", key, values[key])
+		}
+	}
+}
+
+// printContent prints nested object fields with humanized names and two spaces of indentation per level
+func printContent(w io.Writer, content map[string]interface{}, level int) {
+	indent := strings.Repeat("  ", level)
+	keys := make([]string, 0, len(content))
+	for key := range content {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		label := smartLabel(key)
+		switch value := content[key].(type) {
+		case map[string]interface{}:
+			if len(value) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "%s%s:\n", indent, label)
+			printContent(w, value, level+1)
+		case []interface{}:
+			if len(value) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "%s%s:\n", indent, label)
+			for _, item := range value {
+				if m, ok := item.(map[string]interface{}); ok {
+					printContent(w, m, level+1)
+				} else {
+					fmt.Fprintf(w, "%s  %v\n", indent, item)
+				}
+			}
+		default:
+			fmt.Fprintf(w, "%s%s:\t%v\n", indent, label, value)
+		}
+	}
+}
+
+// smartLabel turns a camel case field name into words, e.g. "podIPs" into "Pod IPs"
+func smartLabel(field string) string {
+	var words []string
+	var current []rune
+	runes := []rune(field)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && !unicode.IsUpper(runes[i-1]) && len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		switch {
+		case acronyms[lower]:
+			words[i] = strings.ToUpper(word)
+		case strings.HasSuffix(lower, "s") && acronyms[strings.TrimSuffix(lower, "s")]:
+			words[i] = strings.ToUpper(word[:len(word)-1]) + "s"
+		default:
+			r := []rune(word)
+			r[0] = unicode.ToUpper(r[0])
+			words[i] = string(r)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// printEvents prints the events of an object, like kubectl describe does for every resource
+func (b *Backend) printEvents(ctx context.Context, w io.Writer, obj *unstructured.Unstructured) {
+	uid := string(obj.GetUID())
+	if uid == "" {
+		return
+	}
+
+	list, err := b.client.Resource(eventsResource).Namespace(obj.GetNamespace()).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.uid=" + uid,
+	})
+	if err != nil {
+		// Like kubectl, objects are described even when their events can't be listed
+		return
+	}
+
+	var events []unstructured.Unstructured
+	for _, event := range list.Items {
+		// Field selectors are not supported by every client, so filter again
+		if involved, _, _ := unstructured.NestedString(event.Object, "involvedObject", "uid"); involved == uid {
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		fmt.Fprintf(w, "Events:\t<none>\n")
+		return
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTimestamp(&events[i]) < eventTimestamp(&events[j])
+	})
+
+	fmt.Fprintf(w, "Events:\n")
+	fmt.Fprintf(w, "  Type\tReason\tAge\tFrom\tMessage\n")
+	fmt.Fprintf(w, "  ----\t------\t----\t----\t-------\n")
+	for i := range events {
+		event := &events[i]
+		eventType, _, _ := unstructured.NestedString(event.Object, "type")
+		reason, _, _ := unstructured.NestedString(event.Object, "reason")
+		message, _, _ := unstructured.NestedString(event.Object, "message")
+		from, _, _ := unstructured.NestedString(event.Object, "source", "component")
+		if from == "" {
+			from, _, _ = unstructured.NestedString(event.Object, "reportingComponent")
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", eventType, reason, b.translateTimestamp(eventTimestamp(event)), from, strings.TrimSpace(message))
+	}
+}
+
+// eventTimestamp returns the last time an event occurred
+func eventTimestamp(event *unstructured.Unstructured) string {
+	for _, field := range []string{"lastTimestamp", "eventTime", "firstTimestamp"} {
+		if ts, _, _ := unstructured.NestedString(event.Object, field); ts != "" {
+			return ts
+		}
+	}
+	ts, _, _ := unstructured.NestedString(event.Object, "metadata", "creationTimestamp")
+	return ts
+}
@@ -0,0 +1,97 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// events implements kubectl events
+func (b *Backend) events(ctx context.Context, args *commandArgs) (string, error) {
+	allNamespaces := args.boolFlag("all-namespaces")
+	namespace := b.namespaceFor(args)
+	if allNamespaces {
+		namespace = ""
+	}
+
+	// --for limits events to a single object given as type/name
+	var forKind, forName string
+	if forObject := args.flag("for"); forObject != "" {
+		resource, name, found := strings.Cut(forObject, "/")
+		if !found || name == "" {
+			return "", fmt.Errorf("--for must be in resource/name form")
+		}
+		mapping, err := b.mappingFor(resource)
+		if err != nil {
+			return "", err
+		}
+		forKind, forName = mapping.GroupVersionKind.Kind, name
+	}
+
+	var types []string
+	if args.flag("types") != "" {
+		for _, t := range strings.Split(args.flag("types"), ",") {
+			types = append(types, strings.ToLower(strings.TrimSpace(t)))
+		}
+	}
+
+	list, err := b.client.Resource(eventsResource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	var events []unstructured.Unstructured
+	for _, event := range list.Items {
+		kind, _, _ := unstructured.NestedString(event.Object, "involvedObject", "kind")
+		name, _, _ := unstructured.NestedString(event.Object, "involvedObject", "name")
+		eventType, _, _ := unstructured.NestedString(event.Object, "type")
+		if forKind != "" && (kind != forKind || name != forName) {
+			continue
+		}
+		if len(types) > 0 && !contains(types, strings.ToLower(eventType)) {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTimestamp(&events[i]) < eventTimestamp(&events[j])
+	})
+	if output := args.flag("output"); output != "" {
+		return marshalList(events, output)
+	}
+
+	if len(events) == 0 {
+		if allNamespaces {
+			return "No events found.\n", nil
+		}
+		return fmt.Sprintf("No events found in %s namespace.\n", namespace), nil
+	}
+
+	var out strings.Builder
+	w := tabwriter.NewWriter(&out, 6, 4, 3, ' ', 0)
+	if allNamespaces {
+		fmt.Fprint(w, "NAMESPACE\t")
+	}
+	fmt.Fprintln(w, "LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE")
+	for i := range events {
+		event := &events[i]
+		eventType, _, _ := unstructured.NestedString(event.Object, "type")
+		reason, _, _ := unstructured.NestedString(event.Object, "reason")
+		message, _, _ := unstructured.NestedString(event.Object, "message")
+		kind, _, _ := unstructured.NestedString(event.Object, "involvedObject", "kind")
+		name, _, _ := unstructured.NestedString(event.Object, "involvedObject", "name")
+		if allNamespaces {
+			fmt.Fprintf(w, "%s\t", event.GetNamespace())
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%s\n", b.translateTimestamp(eventTimestamp(event)), eventType, reason,
+			strings.ToLower(kind), name, strings.TrimSpace(message))
+	}
+	_ = w.Flush()
+	return out.String(), nil
+}
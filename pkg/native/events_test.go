@@ -0,0 +1,92 @@
+package native
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newEvent(namespace, name, kind, object, eventType, reason string, ago time.Duration) *unstructured.Unstructured {
+	return newObject("v1", "Event", namespace, name, map[string]interface{}{
+		"involvedObject": map[string]interface{}{"kind": kind, "name": object},
+		"type":           eventType,
+		"reason":         reason,
+		"message":        reason + " " + object,
+		"lastTimestamp":  testNow.Add(-ago).Format(time.RFC3339),
+	})
+}
+
+func TestEvents(t *testing.T) {
+	b := newTestBackend(t,
+		newEvent("default", "web.1", "Pod", "web", "Warning", "BackOff", time.Minute),
+		newEvent("default", "web.2", "Pod", "web", "Normal", "Pulled", 2*time.Minute),
+		newEvent("default", "api.1", "Deployment", "api", "Normal", "ScalingReplicaSet", 3*time.Minute),
+		newEvent("prod", "db.1", "Pod", "db", "Warning", "Unhealthy", 4*time.Minute),
+	)
+
+	tests := []struct {
+		name string
+		args string
+		want []string
+		skip []string
+	}{
+		{
+			name: "namespace sorted by time",
+			want: []string{"LAST SEEN   TYPE      REASON              OBJECT           MESSAGE\n3m          Normal    ScalingReplicaSet   deployment/api   ScalingReplicaSet api\n"},
+			skip: []string{"db"},
+		},
+		{
+			name: "all namespaces",
+			args: "-A",
+			want: []string{"NAMESPACE", "prod        4m", "Unhealthy"},
+		},
+		{
+			name: "for object",
+			args: "--for pod/web",
+			want: []string{"BackOff", "Pulled"},
+			skip: []string{"ScalingReplicaSet"},
+		},
+		{
+			name: "types",
+			args: "--types=warning",
+			want: []string{"BackOff"},
+			skip: []string{"Pulled", "ScalingReplicaSet"},
+		},
+		{
+			name: "no events",
+			args: "-n staging",
+			want: []string{"No events found in staging namespace.\n"},
+		},
+		{
+			name: "json",
+			args: "--types=warning -o json",
+			want: []string{`"kind": "List"`, `"reason": "BackOff"`},
+			skip: []string{"Pulled", "LAST SEEN"},
+		},
+		{
+			name: "no events json",
+			args: "-n staging -o json",
+			want: []string{`"items": []`},
+			skip: []string{"No events found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_diagnostics", Operation: "events", Args: tt.args})
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("events output missing %q:\n%s", want, output)
+				}
+			}
+			for _, skip := range tt.skip {
+				if strings.Contains(output, skip) {
+					t.Errorf("events output contains %q:\n%s", skip, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,570 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// patchTypes maps the kubectl --type values to patch types
+var patchTypes = map[string]types.PatchType{
+	"strategic": types.StrategicMergePatchType,
+	"merge":     types.MergePatchType,
+	"json":      types.JSONPatchType,
+}
+
+// resourceObject is a fetched object and the mapping of its resource
+type resourceObject struct {
+	mapping *meta.RESTMapping
+	object  *unstructured.Unstructured
+}
+
+// mappingFor resolves a resource argument like "pods", "deploy" or "deployments.apps" to its REST mapping
+func (b *Backend) mappingFor(resource string) (*meta.RESTMapping, error) {
+	fullySpecified, groupResource := schema.ParseResourceArg(strings.ToLower(resource))
+	var gvk schema.GroupVersionKind
+	if fullySpecified != nil {
+		gvk, _ = b.mapper.KindFor(*fullySpecified)
+	}
+	if gvk.Empty() {
+		var err error
+		gvk, err = b.mapper.KindFor(groupResource.WithVersion(""))
+		if err != nil {
+			return nil, fmt.Errorf("the server doesn't have a resource type %q", resource)
+		}
+	}
+	return b.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// KindScope implements kubectl.ScopeResolver with the discovery information of the cluster
+func (b *Backend) KindScope(apiVersion, kind string) (bool, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return false, err
+	}
+	mapping, err := b.mapper.RESTMapping(schema.GroupKind{Group: gv.Group, Kind: kind}, gv.Version)
+	if err != nil {
+		return false, err
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+// resourceClient returns the client for a resource, namespaced resources use the given namespace
+func (b *Backend) resourceClient(mapping *meta.RESTMapping, namespace string) dynamic.ResourceInterface {
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return b.client.Resource(mapping.Resource).Namespace(namespace)
+	}
+	return b.client.Resource(mapping.Resource)
+}
+
+// namespaceFor returns the namespace of the command, the namespace of the kubeconfig context when none is given
+func (b *Backend) namespaceFor(args *commandArgs) string {
+	if namespace := args.flag("namespace"); namespace != "" {
+		return namespace
+	}
+	return b.namespace
+}
+
+// listOptions builds list options from the selector flags
+func listOptions(args *commandArgs) metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: args.flag("selector"),
+		FieldSelector: args.flag("field-selector"),
+	}
+}
+
+// fetch gets the named objects of the targets, or lists them when no name is given
+func (b *Backend) fetch(ctx context.Context, targets []target, namespace string, opts metav1.ListOptions) ([]resourceObject, error) {
+	var objects []resourceObject
+	var errs []error
+	for _, t := range targets {
+		mapping, err := b.mappingFor(t.resource)
+		if err != nil {
+			return objects, err
+		}
+		client := b.resourceClient(mapping, namespace)
+
+		if t.name != "" {
+			obj, err := client.Get(ctx, t.name, metav1.GetOptions{})
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			objects = append(objects, resourceObject{mapping: mapping, object: obj})
+			continue
+		}
+
+		list, err := client.List(ctx, opts)
+		if err != nil {
+			return objects, err
+		}
+		for i := range list.Items {
+			objects = append(objects, resourceObject{mapping: mapping, object: &list.Items[i]})
+		}
+	}
+	return objects, errors.Join(errs...)
+}
+
+// get implements kubectl get
+func (b *Backend) get(ctx context.Context, req kubectl.BackendRequest, args *commandArgs) (string, error) {
+	targets, err := resolveTargets(req.Resource, args.positional)
+	if err != nil {
+		return "", err
+	}
+
+	allNamespaces := args.boolFlag("all-namespaces")
+	namespace := b.namespaceFor(args)
+	if allNamespaces {
+		namespace = ""
+	}
+
+	objects, fetchErr := b.fetch(ctx, targets, namespace, listOptions(args))
+	// Objects that were found are printed before errors for missing ones
+	if fetchErr != nil && !isNotFound(fetchErr) {
+		return "", fetchErr
+	}
+
+	// Empty results of cluster-scoped resources don't mention a namespace
+	printNamespace := namespace
+	if mapping, err := b.mappingFor(targets[0].resource); err == nil && mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		printNamespace = ""
+	}
+
+	// A single named object is printed as itself, everything else as a list
+	single := len(targets) == 1 && targets[0].name != ""
+	output, err := b.printObjects(objects, printOptions{
+		output:        args.flag("output"),
+		allNamespaces: allNamespaces,
+		namespace:     printNamespace,
+		single:        single,
+		// Named lookups report errors instead of an empty result
+		named: targets[0].name != "",
+	})
+	if err != nil {
+		return "", err
+	}
+	return output, fetchErr
+}
+
+// delete implements kubectl delete
+func (b *Backend) delete(ctx context.Context, req kubectl.BackendRequest, args *commandArgs) (string, error) {
+	targets, err := resolveTargets(req.Resource, args.positional)
+	if err != nil {
+		return "", err
+	}
+	if targets[0].name == "" && args.flag("selector") == "" && !args.boolFlag("all") {
+		return "", fmt.Errorf("resource(s) were provided, but no name was specified")
+	}
+
+	objects, err := b.fetch(ctx, targets, b.namespaceFor(args), listOptions(args))
+	if err != nil && args.boolFlag("ignore-not-found") && isNotFound(err) {
+		err = nil
+	}
+	if len(objects) == 0 {
+		if err != nil || targets[0].name != "" {
+			return "", err
+		}
+		return "No resources found\n", nil
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	var out strings.Builder
+	for _, ro := range objects {
+		client := b.resourceClient(ro.mapping, ro.object.GetNamespace())
+		deleteErr := client.Delete(ctx, ro.object.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if deleteErr != nil {
+			if args.boolFlag("ignore-not-found") && apierrors.IsNotFound(deleteErr) {
+				continue
+			}
+			return out.String(), deleteErr
+		}
+		fmt.Fprintf(&out, "%s %q deleted\n", resourceName(ro.mapping), ro.object.GetName())
+	}
+	return out.String(), err
+}
+
+// apply implements kubectl apply with server-side apply
+func (b *Backend) apply(ctx context.Context, req kubectl.BackendRequest, args *commandArgs) (string, error) {
+	var objects []*unstructured.Unstructured
+	for _, filename := range args.filenames {
+		var docs []*unstructured.Unstructured
+		var err error
+		if filename == "-" {
+			docs, err = decodeObjects(strings.NewReader(req.Manifest))
+		} else {
+			docs, err = readObjects(resolveFile(req.Dir, filename))
+		}
+		if err != nil {
+			return "", err
+		}
+		objects = append(objects, docs...)
+	}
+	if len(objects) == 0 {
+		return "", fmt.Errorf("no objects passed to apply")
+	}
+
+	namespaceFlag := args.flag("namespace")
+	var out strings.Builder
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		mapping, err := b.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return out.String(), fmt.Errorf("resource mapping not found for name: %q namespace: %q: no matches for kind %q in version %q",
+				obj.GetName(), obj.GetNamespace(), gvk.Kind, gvk.GroupVersion().String())
+		}
+
+		namespace := ""
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			namespace = obj.GetNamespace()
+			if namespace != "" && namespaceFlag != "" && namespace != namespaceFlag {
+				return out.String(), fmt.Errorf("the namespace from the provided object %q does not match the namespace %q. You must pass '--namespace=%s' to perform this operation",
+					namespace, namespaceFlag, namespace)
+			}
+			if namespace == "" {
+				namespace = b.namespaceFor(args)
+			}
+			obj.SetNamespace(namespace)
+		}
+
+		client := b.resourceClient(mapping, namespace)
+		existing, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		created := apierrors.IsNotFound(err)
+		if err != nil && !created {
+			return out.String(), err
+		}
+
+		// New objects are created, existing ones are updated with server-side apply
+		if created {
+			if _, err := client.Create(ctx, obj, metav1.CreateOptions{FieldManager: FieldManager}); err != nil {
+				return out.String(), err
+			}
+			fmt.Fprintf(&out, "%s/%s created\n", resourceName(mapping), obj.GetName())
+			continue
+		}
+
+		// Fields owned by other managers, e.g. changed with kubectl edit, are not taken over
+		applied, err := client.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: FieldManager})
+		if apierrors.IsConflict(err) {
+			return out.String(), &toolerror.Error{
+				Code:    toolerror.Conflict,
+				Message: fmt.Sprintf("%s/%s: %v", resourceName(mapping), obj.GetName(), err),
+				Hint:    "Leave the conflicting fields out of the manifest, or change them with the tool that manages them.",
+			}
+		}
+		if err != nil {
+			return out.String(), err
+		}
+
+		result := "configured"
+		if isUnchanged(existing, applied) {
+			result = "unchanged"
+		}
+		fmt.Fprintf(&out, "%s/%s %s\n", resourceName(mapping), obj.GetName(), result)
+	}
+	return out.String(), nil
+}
+
+// patch implements kubectl patch
+func (b *Backend) patch(ctx context.Context, req kubectl.BackendRequest, args *commandArgs) (string, error) {
+	patch := args.flag("patch")
+	if patch == "" {
+		return "", fmt.Errorf("must specify -p to patch")
+	}
+
+	patchTypeName := args.flag("type")
+	if patchTypeName == "" {
+		patchTypeName = "strategic"
+	}
+	patchType, ok := patchTypes[patchTypeName]
+	if !ok {
+		return "", fmt.Errorf("--type must be one of [json merge strategic], not %q", patchTypeName)
+	}
+
+	return b.modify(ctx, req, args, args.positional, func(ro resourceObject) ([]byte, types.PatchType, string, error) {
+		return []byte(patch), patchType, "patched", nil
+	})
+}
+
+// scale implements kubectl scale by patching the replicas of the resource
+func (b *Backend) scale(ctx context.Context, req kubectl.BackendRequest, args *commandArgs) (string, error) {
+	replicasFlag := args.flag("replicas")
+	if replicasFlag == "" {
+		return "", fmt.Errorf("required flag(s) \"replicas\" not set")
+	}
+	replicas, err := strconv.Atoi(replicasFlag)
+	if err != nil || replicas < 0 {
+		return "", fmt.Errorf("the --replicas=COUNT flag is required, and COUNT must be greater than or equal to 0")
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": replicas},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return b.modify(ctx, req, args, args.positional, func(ro resourceObject) ([]byte, types.PatchType, string, error) {
+		if _, found, _ := unstructured.NestedFieldNoCopy(ro.object.Object, "spec", "replicas"); !found {
+			return nil, "", "", fmt.Errorf("%s/%s is not scalable", resourceName(ro.mapping), ro.object.GetName())
+		}
+		return patch, types.MergePatchType, "scaled", nil
+	})
+}
+
+// label implements kubectl label
+func (b *Backend) label(ctx context.Context, req kubectl.BackendRequest, args *commandArgs) (string, error) {
+	names, labelArgs := splitLabelArgs(args.positional)
+	if len(labelArgs) == 0 {
+		return "", fmt.Errorf("at least one label update is required")
+	}
+
+	set := make(map[string]string)
+	var remove []string
+	for _, arg := range labelArgs {
+		if key, ok := strings.CutSuffix(arg, "-"); ok && !strings.Contains(arg, "=") {
+			remove = append(remove, key)
+			continue
+		}
+		key, value, _ := strings.Cut(arg, "=")
+		if key == "" {
+			return "", fmt.Errorf("invalid label spec: %s", arg)
+		}
+		set[key] = value
+	}
+
+	overwrite := args.boolFlag("overwrite")
+	return b.modify(ctx, req, args, names, func(ro resourceObject) ([]byte, types.PatchType, string, error) {
+		current := ro.object.GetLabels()
+		labels := make(map[string]interface{})
+		for key, value := range set {
+			if old, exists := current[key]; exists && old != value && !overwrite {
+				return nil, "", "", fmt.Errorf("'%s' already has a value (%s), and --overwrite is false", key, old)
+			}
+			if old, exists := current[key]; !exists || old != value {
+				labels[key] = value
+			}
+		}
+		for _, key := range remove {
+			if _, exists := current[key]; exists {
+				labels[key] = nil
+			}
+		}
+		if len(labels) == 0 {
+			return nil, "", "not labeled", nil
+		}
+
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": labels},
+		})
+		if len(set) == 0 {
+			return patch, types.MergePatchType, "unlabeled", err
+		}
+		return patch, types.MergePatchType, "labeled", err
+	})
+}
+
+// patchFunc builds the patch for an object and the verb reported when it is applied.
+// A nil patch reports the verb without changing the object.
+type patchFunc func(ro resourceObject) ([]byte, types.PatchType, string, error)
+
+// modify patches the objects selected by names or selector flags and reports the result per object
+func (b *Backend) modify(ctx context.Context, req kubectl.BackendRequest, args *commandArgs, positional []string, build patchFunc) (string, error) {
+	targets, err := resolveTargets(req.Resource, positional)
+	if err != nil {
+		return "", err
+	}
+	if targets[0].name == "" && args.flag("selector") == "" && !args.boolFlag("all") {
+		return "", fmt.Errorf("resource(s) were provided, but no name was specified")
+	}
+
+	objects, err := b.fetch(ctx, targets, b.namespaceFor(args), listOptions(args))
+	if err != nil {
+		return "", err
+	}
+	if len(objects) == 0 {
+		return "No resources found\n", nil
+	}
+
+	var out strings.Builder
+	for _, ro := range objects {
+		patch, patchType, verb, err := build(ro)
+		if err != nil {
+			return out.String(), err
+		}
+		name := resourceName(ro.mapping) + "/" + ro.object.GetName()
+		if patch == nil {
+			fmt.Fprintf(&out, "%s %s\n", name, verb)
+			continue
+		}
+
+		client := b.resourceClient(ro.mapping, ro.object.GetNamespace())
+		patched, err := client.Patch(ctx, ro.object.GetName(), patchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+		if err != nil {
+			return out.String(), err
+		}
+		if verb == "patched" && isUnchanged(ro.object, patched) {
+			verb = "patched (no change)"
+		}
+		fmt.Fprintf(&out, "%s %s\n", name, verb)
+	}
+	return out.String(), nil
+}
+
+// splitLabelArgs separates object names from label updates like "key=value" and "key-"
+func splitLabelArgs(positional []string) ([]string, []string) {
+	var names, labels []string
+	for i, arg := range positional {
+		// The first argument is a resource type or name, never a label
+		if i > 0 && (strings.Contains(arg, "=") || strings.HasSuffix(arg, "-")) {
+			labels = append(labels, arg)
+			continue
+		}
+		names = append(names, arg)
+	}
+	return names, labels
+}
+
+// isUnchanged checks if an update left the resource version of an object unchanged
+func isUnchanged(before, after *unstructured.Unstructured) bool {
+	return before.GetResourceVersion() != "" && before.GetResourceVersion() == after.GetResourceVersion()
+}
+
+// isNotFound checks if all errors are not found errors
+func isNotFound(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			if !apierrors.IsNotFound(e) {
+				return false
+			}
+		}
+		return true
+	}
+	return apierrors.IsNotFound(err)
+}
+
+// resourceName returns the resource name kubectl prints for a mapping, e.g. "pod" or "deployment.apps"
+func resourceName(mapping *meta.RESTMapping) string {
+	name := strings.ToLower(mapping.GroupVersionKind.Kind)
+	if group := mapping.GroupVersionKind.Group; group != "" {
+		name += "." + group
+	}
+	return name
+}
+
+// resolveFile resolves a relative file name against the command directory
+func resolveFile(dir, filename string) string {
+	if dir == "" || filepath.IsAbs(filename) {
+		return filename
+	}
+	return filepath.Join(dir, filename)
+}
+
+// readObjects reads the objects of a manifest file, or of the manifest files of a directory
+func readObjects(path string) ([]*unstructured.Unstructured, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("the path %q does not exist", path)
+	}
+
+	paths := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		paths = nil
+		for _, entry := range entries {
+			switch filepath.Ext(entry.Name()) {
+			case ".json", ".yaml", ".yml":
+				if !entry.IsDir() {
+					paths = append(paths, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, p := range paths {
+		// #nosec G304: paths are validated against the workspace before execution
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		docs, err := decodeObjects(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", p, err)
+		}
+		objects = append(objects, docs...)
+	}
+	return objects, nil
+}
+
+// decodeObjects decodes the objects of a multi-document YAML or JSON manifest, expanding lists
+func decodeObjects(r io.Reader) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	var objects []*unstructured.Unstructured
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if len(doc) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: doc}
+		if !obj.IsList() {
+			objects = append(objects, obj)
+			continue
+		}
+		list, err := obj.ToList()
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	}
+	return objects, nil
+}
+
+// errorOutput formats an error the way kubectl prints it
+func errorOutput(err error) string {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var out strings.Builder
+		for _, e := range joined.Unwrap() {
+			out.WriteString(errorOutput(e))
+		}
+		return out.String()
+	}
+
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		if reason := status.Status().Reason; reason != "" && reason != metav1.StatusReasonUnknown {
+			return fmt.Sprintf("Error from server (%s): %s\n", reason, err.Error())
+		}
+		return fmt.Sprintf("Error from server: %s\n", err.Error())
+	}
+	return "error: " + err.Error() + "\n"
+}
@@ -0,0 +1,415 @@
+package native
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/yaml"
+)
+
+// supportedOutputs lists the -o formats printed natively
+var supportedOutputs = []string{"", "wide", "json", "yaml", "name"}
+
+// isSupportedOutput checks if an output format is printed natively
+func isSupportedOutput(output string) bool {
+	return contains(supportedOutputs, output)
+}
+
+// printOptions controls how fetched objects are printed
+type printOptions struct {
+	output        string
+	allNamespaces bool
+	namespace     string
+	// single prints the object itself instead of a list in JSON and YAML
+	single bool
+	// named suppresses the "No resources found" message for lookups by name
+	named bool
+}
+
+// column is a table column and how to compute its value
+type column struct {
+	header string
+	value  func(obj *unstructured.Unstructured) string
+}
+
+// printObjects prints objects in the requested kubectl output format
+func (b *Backend) printObjects(objects []resourceObject, opts printOptions) (string, error) {
+	switch opts.output {
+	case "json", "yaml":
+		if opts.single && len(objects) == 0 {
+			return "", nil
+		}
+		if opts.single && len(objects) == 1 {
+			return marshalOutput(objects[0].object.Object, opts.output)
+		}
+		items := make([]unstructured.Unstructured, 0, len(objects))
+		for _, ro := range objects {
+			items = append(items, *ro.object)
+		}
+		return marshalList(items, opts.output)
+	case "name":
+		var out strings.Builder
+		for _, ro := range objects {
+			fmt.Fprintf(&out, "%s/%s\n", resourceName(ro.mapping), ro.object.GetName())
+		}
+		return out.String(), nil
+	}
+
+	if len(objects) == 0 {
+		if opts.named {
+			return "", nil
+		}
+		if opts.namespace != "" && !opts.allNamespaces {
+			return fmt.Sprintf("No resources found in %s namespace.\n", opts.namespace), nil
+		}
+		return "No resources found\n", nil
+	}
+	return b.printTables(objects, opts), nil
+}
+
+// printTables prints objects as tables, one per consecutive group of objects of the same kind
+func (b *Backend) printTables(objects []resourceObject, opts printOptions) string {
+	var out strings.Builder
+	for start := 0; start < len(objects); {
+		kind := objects[start].mapping.GroupVersionKind.GroupKind()
+		end := start
+		for end < len(objects) && objects[end].mapping.GroupVersionKind.GroupKind() == kind {
+			end++
+		}
+		if start > 0 {
+			out.WriteString("\n")
+		}
+		// Tables of different kinds prefix names with the resource like kubectl does
+		prefix := start > 0 || end < len(objects)
+		b.printTable(&out, objects[start:end], opts, prefix)
+		start = end
+	}
+	return out.String()
+}
+
+// printTable prints objects of one kind as a table
+func (b *Backend) printTable(out *strings.Builder, objects []resourceObject, opts printOptions, prefix bool) {
+	columns := b.columnsFor(objects[0].mapping.GroupVersionKind.Kind, opts.output == "wide")
+
+	w := tabwriter.NewWriter(out, 6, 4, 3, ' ', 0)
+	headers := make([]string, 0, len(columns)+2)
+	if opts.allNamespaces {
+		headers = append(headers, "NAMESPACE")
+	}
+	headers = append(headers, "NAME")
+	for _, c := range columns {
+		headers = append(headers, c.header)
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+
+	for _, ro := range objects {
+		row := make([]string, 0, len(headers))
+		if opts.allNamespaces {
+			row = append(row, ro.object.GetNamespace())
+		}
+		name := ro.object.GetName()
+		if prefix {
+			name = resourceName(ro.mapping) + "/" + name
+		}
+		row = append(row, name)
+		for _, c := range columns {
+			row = append(row, c.value(ro.object))
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	_ = w.Flush()
+}
+
+// columnsFor returns the table columns printed after NAME for a kind
+func (b *Backend) columnsFor(kind string, wide bool) []column {
+	age := column{header: "AGE", value: b.age}
+
+	switch kind {
+	case "Pod":
+		columns := []column{
+			{"READY", podReady},
+			{"STATUS", podStatus},
+			{"RESTARTS", podRestarts},
+			age,
+		}
+		if wide {
+			columns = append(columns,
+				column{"IP", stringField("status", "podIP")},
+				column{"NODE", stringField("spec", "nodeName")},
+			)
+		}
+		return columns
+	case "Deployment":
+		return []column{
+			{"READY", func(obj *unstructured.Unstructured) string {
+				return fmt.Sprintf("%d/%d", int64Field(obj, "status", "readyReplicas"), int64Field(obj, "spec", "replicas"))
+			}},
+			{"UP-TO-DATE", intField("status", "updatedReplicas")},
+			{"AVAILABLE", intField("status", "availableReplicas")},
+			age,
+		}
+	case "ReplicaSet":
+		return []column{
+			{"DESIRED", intField("spec", "replicas")},
+			{"CURRENT", intField("status", "replicas")},
+			{"READY", intField("status", "readyReplicas")},
+			age,
+		}
+	case "StatefulSet":
+		return []column{
+			{"READY", func(obj *unstructured.Unstructured) string {
+				return fmt.Sprintf("%d/%d", int64Field(obj, "status", "readyReplicas"), int64Field(obj, "spec", "replicas"))
+			}},
+			age,
+		}
+	case "Service":
+		return []column{
+			{"TYPE", stringField("spec", "type")},
+			{"CLUSTER-IP", stringField("spec", "clusterIP")},
+			{"EXTERNAL-IP", serviceExternalIP},
+			{"PORT(S)", servicePorts},
+			age,
+		}
+	case "Namespace":
+		return []column{{"STATUS", stringField("status", "phase")}, age}
+	case "Node":
+		return []column{
+			{"STATUS", nodeStatus},
+			{"ROLES", nodeRoles},
+			age,
+			{"VERSION", stringField("status", "nodeInfo", "kubeletVersion")},
+		}
+	case "ConfigMap":
+		return []column{{"DATA", dataCount}, age}
+	case "Secret":
+		return []column{{"TYPE", stringField("type")}, {"DATA", dataCount}, age}
+	default:
+		return []column{age}
+	}
+}
+
+// age returns the human readable age of an object
+func (b *Backend) age(obj *unstructured.Unstructured) string {
+	created := obj.GetCreationTimestamp()
+	if created.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(b.now().Sub(created.Time))
+}
+
+// translateTimestamp returns the human readable age of a timestamp string
+func (b *Backend) translateTimestamp(timestamp string) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(b.now().Sub(t))
+}
+
+// stringField returns a column value function for a nested string field
+func stringField(fields ...string) func(*unstructured.Unstructured) string {
+	return func(obj *unstructured.Unstructured) string {
+		value, found, _ := unstructured.NestedString(obj.Object, fields...)
+		if !found || value == "" {
+			return "<none>"
+		}
+		return value
+	}
+}
+
+// intField returns a column value function for a nested integer field
+func intField(fields ...string) func(*unstructured.Unstructured) string {
+	return func(obj *unstructured.Unstructured) string {
+		return strconv.FormatInt(int64Field(obj, fields...), 10)
+	}
+}
+
+// int64Field returns a nested integer field, zero if it is missing
+func int64Field(obj *unstructured.Unstructured, fields ...string) int64 {
+	value, _, _ := unstructured.NestedFieldNoCopy(obj.Object, fields...)
+	switch v := value.(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	case int:
+		return int64(v)
+	}
+	return 0
+}
+
+// nestedMaps returns a nested list of objects
+func nestedMaps(obj map[string]interface{}, fields ...string) []map[string]interface{} {
+	items, _, _ := unstructured.NestedSlice(obj, fields...)
+	maps := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
+
+// podReady returns the number of ready containers of a pod
+func podReady(obj *unstructured.Unstructured) string {
+	containers := nestedMaps(obj.Object, "spec", "containers")
+	ready := 0
+	for _, status := range nestedMaps(obj.Object, "status", "containerStatuses") {
+		if r, _, _ := unstructured.NestedBool(status, "ready"); r {
+			ready++
+		}
+	}
+	return fmt.Sprintf("%d/%d", ready, len(containers))
+}
+
+// podStatus returns the status of a pod, preferring container waiting and termination reasons
+func podStatus(obj *unstructured.Unstructured) string {
+	if obj.GetDeletionTimestamp() != nil {
+		return "Terminating"
+	}
+	reason, _, _ := unstructured.NestedString(obj.Object, "status", "reason")
+	if reason == "" {
+		reason, _, _ = unstructured.NestedString(obj.Object, "status", "phase")
+	}
+	for _, status := range nestedMaps(obj.Object, "status", "containerStatuses") {
+		if waiting, _, _ := unstructured.NestedString(status, "state", "waiting", "reason"); waiting != "" {
+			return waiting
+		}
+		if terminated, _, _ := unstructured.NestedString(status, "state", "terminated", "reason"); terminated != "" {
+			reason = terminated
+		}
+	}
+	if reason == "" {
+		return "Unknown"
+	}
+	return reason
+}
+
+// podRestarts returns the total number of container restarts of a pod
+func podRestarts(obj *unstructured.Unstructured) string {
+	var restarts int64
+	for _, status := range nestedMaps(obj.Object, "status", "containerStatuses") {
+		count, _, _ := unstructured.NestedInt64(status, "restartCount")
+		restarts += count
+	}
+	return strconv.FormatInt(restarts, 10)
+}
+
+// serviceExternalIP returns the external addresses of a service
+func serviceExternalIP(obj *unstructured.Unstructured) string {
+	var addresses []string
+	externalIPs, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "externalIPs")
+	addresses = append(addresses, externalIPs...)
+	for _, ingress := range nestedMaps(obj.Object, "status", "loadBalancer", "ingress") {
+		if ip, _, _ := unstructured.NestedString(ingress, "ip"); ip != "" {
+			addresses = append(addresses, ip)
+		} else if hostname, _, _ := unstructured.NestedString(ingress, "hostname"); hostname != "" {
+			addresses = append(addresses, hostname)
+		}
+	}
+	if len(addresses) > 0 {
+		return strings.Join(addresses, ",")
+	}
+
+	serviceType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+	switch serviceType {
+	case "LoadBalancer":
+		return "<pending>"
+	case "ExternalName":
+		name, _, _ := unstructured.NestedString(obj.Object, "spec", "externalName")
+		return name
+	}
+	return "<none>"
+}
+
+// servicePorts returns the ports of a service like "80:30080/TCP"
+func servicePorts(obj *unstructured.Unstructured) string {
+	var ports []string
+	for _, port := range nestedMaps(obj.Object, "spec", "ports") {
+		number, _, _ := unstructured.NestedInt64(port, "port")
+		protocol, _, _ := unstructured.NestedString(port, "protocol")
+		if protocol == "" {
+			protocol = "TCP"
+		}
+		if nodePort, _, _ := unstructured.NestedInt64(port, "nodePort"); nodePort > 0 {
+			ports = append(ports, fmt.Sprintf("%d:%d/%s", number, nodePort, protocol))
+		} else {
+			ports = append(ports, fmt.Sprintf("%d/%s", number, protocol))
+		}
+	}
+	if len(ports) == 0 {
+		return "<none>"
+	}
+	return strings.Join(ports, ",")
+}
+
+// nodeStatus returns the readiness of a node
+func nodeStatus(obj *unstructured.Unstructured) string {
+	status := "Unknown"
+	for _, condition := range nestedMaps(obj.Object, "status", "conditions") {
+		if t, _, _ := unstructured.NestedString(condition, "type"); t == "Ready" {
+			if s, _, _ := unstructured.NestedString(condition, "status"); s == "True" {
+				status = "Ready"
+			} else {
+				status = "NotReady"
+			}
+		}
+	}
+	if unschedulable, _, _ := unstructured.NestedBool(obj.Object, "spec", "unschedulable"); unschedulable {
+		status += ",SchedulingDisabled"
+	}
+	return status
+}
+
+// nodeRoles returns the roles of a node from its node-role labels
+func nodeRoles(obj *unstructured.Unstructured) string {
+	var roles []string
+	for key := range obj.GetLabels() {
+		if role, ok := strings.CutPrefix(key, "node-role.kubernetes.io/"); ok && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return "<none>"
+	}
+	sort.Strings(roles)
+	return strings.Join(roles, ",")
+}
+
+// dataCount returns the number of data entries of a config map or secret
+func dataCount(obj *unstructured.Unstructured) string {
+	data, _, _ := unstructured.NestedMap(obj.Object, "data")
+	binaryData, _, _ := unstructured.NestedMap(obj.Object, "binaryData")
+	return strconv.Itoa(len(data) + len(binaryData))
+}
+
+// marshalList prints objects as a v1 List in JSON or YAML, like kubectl does for multiple objects
+func marshalList(objects []unstructured.Unstructured, output string) (string, error) {
+	items := make([]interface{}, 0, len(objects))
+	for _, object := range objects {
+		items = append(items, object.Object)
+	}
+	return marshalOutput(map[string]interface{}{
+		"apiVersion": "v1",
+		"items":      items,
+		"kind":       "List",
+		"metadata":   map[string]interface{}{"resourceVersion": ""},
+	}, output)
+}
+
+// marshalOutput prints data in JSON or YAML
+func marshalOutput(data interface{}, output string) (string, error) {
+	if output == "yaml" {
+		out, err := yaml.Marshal(data)
+		return string(out), err
+	}
+	out, err := json.MarshalIndent(data, "", "    ")
+	return string(out) + "\n", err
+}
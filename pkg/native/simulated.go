@@ -0,0 +1,187 @@
+package native
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// simulatedOperations lists the operations answered by the simulated cluster
+var simulatedOperations = map[string][]string{
+	"kubectl_resources":   {"get", "describe", "delete"},
+	"kubectl_workloads":   {"scale"},
+	"kubectl_metadata":    {"label"},
+	"kubectl_diagnostics": {"events"},
+}
+
+// builtinKinds are served by the simulated cluster even when no manifest contains them
+var builtinKinds = []schema.GroupVersionKind{
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "Event"},
+	{Version: "v1", Kind: "Namespace"},
+	{Version: "v1", Kind: "Node"},
+	{Version: "v1", Kind: "PersistentVolume"},
+	{Version: "v1", Kind: "PersistentVolumeClaim"},
+	{Version: "v1", Kind: "Pod"},
+	{Version: "v1", Kind: "Secret"},
+	{Version: "v1", Kind: "Service"},
+	{Version: "v1", Kind: "ServiceAccount"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "apps", Version: "v1", Kind: "ReplicaSet"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "batch", Version: "v1", Kind: "CronJob"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"},
+	{Group: "storage.k8s.io", Version: "v1", Kind: "StorageClass"},
+}
+
+// builtinNamespaces exist in every simulated cluster
+var builtinNamespaces = []string{"default", "kube-system"}
+
+// NewSimulatedBackend creates a backend answering from an in-memory cluster loaded with the
+// manifests of a file or directory. An empty path starts an empty cluster.
+func NewSimulatedBackend(path string) (*Backend, error) {
+	var objects []*unstructured.Unstructured
+	if path != "" {
+		var err error
+		objects, err = loadManifests(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Register the built-in kinds and every kind found in the manifests
+	mapper := meta.NewDefaultRESTMapper(nil)
+	listKinds := make(map[schema.GroupVersionResource]string)
+	registered := make(map[schema.GroupVersionKind]bool)
+	register := func(gvk schema.GroupVersionKind) {
+		if registered[gvk] {
+			return
+		}
+		registered[gvk] = true
+		scope := meta.RESTScopeNamespace
+		if security.IsClusterScopedKind(gvk.Kind) {
+			scope = meta.RESTScopeRoot
+		}
+		mapper.Add(gvk, scope)
+		plural, _ := meta.UnsafeGuessKindToResource(gvk)
+		listKinds[plural] = gvk.Kind + "List"
+	}
+	for _, gvk := range builtinKinds {
+		register(gvk)
+	}
+
+	now := metav1.NewTime(time.Now())
+	namespaces := make(map[string]bool)
+	seeded := make([]runtime.Object, 0, len(objects))
+	index := make(map[string]int)
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		register(gvk)
+		if security.IsClusterScopedKind(gvk.Kind) {
+			obj.SetNamespace("")
+		} else if obj.GetNamespace() == "" {
+			obj.SetNamespace("default")
+		}
+		if gvk.Kind == "Namespace" {
+			namespaces[obj.GetName()] = true
+		}
+
+		// Later manifests of the same object replace earlier ones, like applying them in order
+		key := gvk.String() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+		if i, exists := index[key]; exists {
+			seeded[i] = obj
+			continue
+		}
+		index[key] = len(seeded)
+		seeded = append(seeded, obj)
+	}
+
+	// Namespaces referenced by objects exist even without a manifest
+	for _, obj := range objects {
+		if ns := obj.GetNamespace(); ns != "" && !namespaces[ns] {
+			namespaces[ns] = true
+			seeded = append(seeded, newNamespace(ns))
+		}
+	}
+	for _, ns := range builtinNamespaces {
+		if !namespaces[ns] {
+			namespaces[ns] = true
+			seeded = append(seeded, newNamespace(ns))
+		}
+	}
+
+	for _, obj := range seeded {
+		u := obj.(*unstructured.Unstructured)
+		u.SetUID(uuid.NewUUID())
+		u.SetResourceVersion("1")
+		u.SetCreationTimestamp(now)
+		if u.GetKind() == "Namespace" {
+			if _, found, _ := unstructured.NestedString(u.Object, "status", "phase"); !found {
+				_ = unstructured.SetNestedField(u.Object, "Active", "status", "phase")
+			}
+		}
+	}
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, seeded...)
+	backend := NewBackendWithClients(client, mapper)
+	backend.operations = simulatedOperations
+	return backend, nil
+}
+
+// newNamespace creates a namespace object
+func newNamespace(name string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	return ns
+}
+
+// loadManifests reads the objects of a manifest file, or of all manifest files below a directory
+func loadManifests(path string) ([]*unstructured.Unstructured, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read simulated cluster data: %w", err)
+	}
+	if !info.IsDir() {
+		return readObjects(path)
+	}
+
+	var objects []*unstructured.Unstructured
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch filepath.Ext(p) {
+		case ".json", ".yaml", ".yml":
+			docs, err := readObjects(p)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, docs...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
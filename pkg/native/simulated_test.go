@@ -0,0 +1,92 @@
+package native
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+)
+
+const simulatedTestData = "../../example/test_data/comprehensive-dns-test-env"
+
+func TestNewSimulatedBackend(t *testing.T) {
+	b, err := NewSimulatedBackend(simulatedTestData)
+	if err != nil {
+		t.Fatalf("NewSimulatedBackend() unexpected error = %v", err)
+	}
+
+	output := execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "namespaces"})
+	for _, ns := range []string{"default", "kube-system", "app-backend", "dns-test", "secure-ns"} {
+		if !strings.Contains(output, ns) {
+			t.Errorf("get namespaces output missing %s:\n%s", ns, output)
+		}
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "deployments", Args: "-A"})
+	for _, name := range []string{"backend-app", "failing-worker", "secure-app", "web-app"} {
+		if !strings.Contains(output, name) {
+			t.Errorf("get deployments output missing %s:\n%s", name, output)
+		}
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "networkpolicies", Args: "-n app-backend -o name"})
+	if output != "networkpolicy.networking.k8s.io/restrict-dns-access\n" {
+		t.Errorf("get networkpolicies = %q", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_workloads", Operation: "scale", Resource: "deployment", Args: "web-app --replicas=5 -n dns-test"})
+	if output != "deployment.apps/web-app scaled\n" {
+		t.Errorf("scale = %q", output)
+	}
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "deployment", Args: "web-app -n dns-test -o yaml"})
+	if !strings.Contains(output, "replicas: 5") {
+		t.Errorf("scaled deployment missing replicas: 5:\n%s", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_metadata", Operation: "label", Resource: "service", Args: "web-service tier=frontend -n dns-test"})
+	if output != "service/web-service labeled\n" {
+		t.Errorf("label = %q", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "delete", Resource: "deployment", Args: "failing-worker -n app-backend"})
+	if output != "deployment.apps \"failing-worker\" deleted\n" {
+		t.Errorf("delete = %q", output)
+	}
+
+	output = execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_diagnostics", Operation: "events"})
+	if output != "No events found in default namespace.\n" {
+		t.Errorf("events = %q", output)
+	}
+}
+
+func TestSimulatedSupports(t *testing.T) {
+	b, err := NewSimulatedBackend("")
+	if err != nil {
+		t.Fatalf("NewSimulatedBackend() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  kubectl.BackendRequest
+		want bool
+	}{
+		{"get", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "get", Resource: "pods"}, true},
+		{"events", kubectl.BackendRequest{ToolName: "kubectl_diagnostics", Operation: "events", Args: "-A"}, true},
+		{"apply", kubectl.BackendRequest{ToolName: "kubectl_resources", Operation: "apply", Args: "-f app.yaml"}, false},
+		{"logs", kubectl.BackendRequest{ToolName: "kubectl_diagnostics", Operation: "logs", Args: "nginx"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Supports(tt.req); got != tt.want {
+				t.Errorf("Supports() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSimulatedBackendMissingPath(t *testing.T) {
+	if _, err := NewSimulatedBackend("does-not-exist"); err == nil {
+		t.Error("Expected error for a missing manifest path")
+	}
+}
@@ -0,0 +1,65 @@
+package native
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// Watch calls changed with the namespace of every object of a kind that is added, modified or deleted, until
+// the context is done or the watch ends. The current state of the objects is not reported. Namespaced kinds
+// are watched in the default namespace when no namespace is given, like kubectl get.
+func (b *Backend) Watch(ctx context.Context, namespace, kind, name string, changed func(namespace string)) error {
+	mapping, err := b.mappingFor(kind)
+	if err != nil {
+		return err
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	client := b.resourceClient(mapping, namespace)
+
+	opts := metav1.ListOptions{}
+	if name != "" {
+		opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+	}
+	// Start watching at the version of the current state, which would otherwise be reported as added objects
+	list, err := client.List(ctx, opts)
+	if err != nil {
+		return err
+	}
+	opts.ResourceVersion = list.GetResourceVersion()
+	watcher, err := client.Watch(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return errors.New("watch closed by the server")
+			}
+			switch event.Type {
+			case watch.Error:
+				return apierrors.FromObject(event.Object)
+			case watch.Bookmark:
+				continue
+			}
+			obj, ok := event.Object.(*unstructured.Unstructured)
+			// Field selectors are not supported by every client, names are checked again
+			if !ok || (name != "" && obj.GetName() != name) {
+				continue
+			}
+			changed(obj.GetNamespace())
+		}
+	}
+}
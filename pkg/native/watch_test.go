@@ -0,0 +1,58 @@
+package native
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+)
+
+func TestWatch(t *testing.T) {
+	b, err := NewSimulatedBackend(simulatedTestData)
+	if err != nil {
+		t.Fatalf("NewSimulatedBackend() unexpected error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Watch(ctx, "dns-test", "deployments", "web-app", func(namespace string) { changes <- namespace })
+	}()
+
+	// Changes of other objects are not reported, the watch may not have started yet so changes are retried
+	deadline := time.After(5 * time.Second)
+	for replicas := 2; ; replicas++ {
+		execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_workloads", Operation: "scale", Resource: "deployment", Args: "backend-app --replicas=2 -n app-backend"})
+		execute(t, b, kubectl.BackendRequest{ToolName: "kubectl_workloads", Operation: "scale", Resource: "deployment", Args: "web-app --replicas=" + strconv.Itoa(replicas) + " -n dns-test"})
+		select {
+		case namespace := <-changes:
+			if namespace != "dns-test" {
+				t.Fatalf("changed namespace = %q, want dns-test", namespace)
+			}
+			cancel()
+			if err := <-done; err != context.Canceled {
+				t.Errorf("Watch() error = %v, want context canceled", err)
+			}
+			return
+		case err := <-done:
+			t.Fatalf("Watch() unexpected error = %v", err)
+		case <-deadline:
+			t.Fatal("Watch() didn't report the change")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func TestWatchUnknownKind(t *testing.T) {
+	b, err := NewSimulatedBackend("")
+	if err != nil {
+		t.Fatalf("NewSimulatedBackend() unexpected error = %v", err)
+	}
+	if err := b.Watch(context.Background(), "", "widgets", "", func(string) {}); err == nil {
+		t.Error("Watch() expected error for an unknown kind")
+	}
+}
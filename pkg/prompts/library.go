@@ -0,0 +1,152 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolFunc returns the name of the registered tool running an operation of a tool, and false if the
+// operation isn't available
+type ToolFunc func(toolName, operation, resource string) (string, bool)
+
+// Library renders prompt definitions for the tools available on the server
+type Library struct {
+	definitions []Definition
+	accessLevel string
+	tool        ToolFunc
+}
+
+// templateData is passed to message templates
+type templateData struct {
+	// Args holds the given arguments, optional arguments that were not given hold their default
+	Args        map[string]string
+	AccessLevel string
+}
+
+// NewLibrary creates a prompt library resolving tool references with a tool function
+func NewLibrary(definitions []Definition, accessLevel string, tool ToolFunc) *Library {
+	return &Library{definitions: definitions, accessLevel: accessLevel, tool: tool}
+}
+
+// Prompts returns the prompts of the library with their handlers
+func (l *Library) Prompts() []server.ServerPrompt {
+	prompts := make([]server.ServerPrompt, 0, len(l.definitions))
+	for _, definition := range l.definitions {
+		opts := []mcp.PromptOption{mcp.WithPromptDescription(definition.Description)}
+		for _, argument := range definition.Arguments {
+			description := argument.Description
+			if argument.Default != "" {
+				description = fmt.Sprintf("%s (default %s)", description, argument.Default)
+			}
+			argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(description)}
+			if argument.Required {
+				argOpts = append(argOpts, mcp.RequiredArgument())
+			}
+			opts = append(opts, mcp.WithArgument(argument.Name, argOpts...))
+		}
+
+		definition := definition
+		prompts = append(prompts, server.ServerPrompt{
+			Prompt: mcp.NewPrompt(definition.Name, opts...),
+			Handler: func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				return l.render(definition, req.Params.Arguments)
+			},
+		})
+	}
+	return prompts
+}
+
+// Render renders the messages of a prompt with arguments
+func (l *Library) Render(name string, args map[string]string) (*mcp.GetPromptResult, error) {
+	for _, definition := range l.definitions {
+		if definition.Name == name {
+			return l.render(definition, args)
+		}
+	}
+	return nil, toolerror.Newf(toolerror.NotFound, "prompt %s not found", name)
+}
+
+// render renders the messages of a definition with arguments
+func (l *Library) render(definition Definition, args map[string]string) (*mcp.GetPromptResult, error) {
+	data := templateData{Args: make(map[string]string), AccessLevel: l.accessLevel}
+	for name, value := range args {
+		data.Args[name] = value
+	}
+	for _, argument := range definition.Arguments {
+		if strings.TrimSpace(data.Args[argument.Name]) != "" {
+			continue
+		}
+		if argument.Required {
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "missing required argument: %s", argument.Name)
+		}
+		data.Args[argument.Name] = argument.Default
+	}
+
+	messages := make([]mcp.PromptMessage, 0, len(definition.Messages))
+	for i, message := range definition.Messages {
+		tmpl, err := parseTemplate(definition.Name, message.Text)
+		if err != nil {
+			return nil, err
+		}
+		var text strings.Builder
+		if err := tmpl.Funcs(templateFuncs(l.tool)).Execute(&text, data); err != nil {
+			return nil, fmt.Errorf("failed to render message %d of prompt %s: %w", i+1, definition.Name, err)
+		}
+		messages = append(messages, mcp.NewPromptMessage(mcp.Role(message.Role), mcp.NewTextContent(strings.TrimSpace(text.String()))))
+	}
+	return mcp.NewGetPromptResult(definition.Description, messages), nil
+}
+
+// templateFuncs returns the functions of message templates:
+//   - has checks if an operation is available: {{if has "kubectl_workloads" "rollout" "undo"}}
+//   - tool returns the tool running an operation: {{tool "kubectl_diagnostics" "logs"}}
+//   - call describes a tool call: {{call "kubectl_resources" "get" "pods" "-n shop"}}
+//
+// The resource and args of has, tool and call are optional. tool and call fail for unavailable operations,
+// so templates check operations that depend on the access level with has.
+func templateFuncs(resolve ToolFunc) template.FuncMap {
+	lookup := func(toolName, operation string, rest []string) (string, error) {
+		resource := ""
+		if len(rest) > 0 {
+			resource = rest[0]
+		}
+		if resolve == nil {
+			return toolName, nil
+		}
+		name, ok := resolve(toolName, operation, resource)
+		if !ok {
+			return "", fmt.Errorf("operation %s of %s is not available, check it with has", operation, toolName)
+		}
+		return name, nil
+	}
+
+	return template.FuncMap{
+		"has": func(toolName, operation string, rest ...string) bool {
+			_, err := lookup(toolName, operation, rest)
+			return err == nil
+		},
+		"tool": func(toolName, operation string, rest ...string) (string, error) {
+			return lookup(toolName, operation, rest)
+		},
+		"call": func(toolName, operation string, rest ...string) (string, error) {
+			name, err := lookup(toolName, operation, rest)
+			if err != nil {
+				return "", err
+			}
+			resource, args := "", ""
+			if len(rest) > 0 {
+				resource = rest[0]
+			}
+			if len(rest) > 1 {
+				args = strings.Join(rest[1:], " ")
+			}
+			return fmt.Sprintf("%s(operation='%s', resource='%s', args='%s')", name, operation, resource, args), nil
+		},
+	}
+}
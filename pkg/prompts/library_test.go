@@ -0,0 +1,189 @@
+package prompts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newTestLibrary creates a library of the built-in prompts for the kubectl tools of an access level
+func newTestLibrary(t *testing.T, accessLevel string, split bool) *Library {
+	t.Helper()
+	definitions, err := Builtin()
+	if err != nil {
+		t.Fatalf("Builtin() unexpected error = %v", err)
+	}
+	return NewLibrary(definitions, accessLevel, func(toolName, operation, resource string) (string, bool) {
+		return kubectl.ToolForOperation(accessLevel, split, toolName, operation, resource)
+	})
+}
+
+// promptText joins the texts of the messages of a prompt result
+func promptText(result *mcp.GetPromptResult) string {
+	var texts []string
+	for _, message := range result.Messages {
+		texts = append(texts, string(message.Role)+": "+message.Content.(mcp.TextContent).Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name        string
+		accessLevel string
+		split       bool
+		prompt      string
+		args        map[string]string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "crashloop read-only",
+			accessLevel: "readonly",
+			prompt:      "troubleshoot-crashloop",
+			args:        map[string]string{"namespace": "shop", "pod": "web-1"},
+			contains: []string{
+				"user: The pod web-1 in namespace shop is in CrashLoopBackOff",
+				"kubectl_diagnostics(operation='logs', resource='', args='web-1 -n shop --previous --tail=100')",
+				"The server is readonly",
+				"assistant: I'll start by describing the pod web-1",
+			},
+			notContains: []string{"kubectl_resources_write", "after confirmation"},
+		},
+		{
+			name:        "crashloop split read-write",
+			accessLevel: "readwrite",
+			split:       true,
+			prompt:      "troubleshoot-crashloop",
+			args:        map[string]string{"namespace": "shop", "pod": "web-1"},
+			contains:    []string{"only apply it with kubectl_resources_write after confirmation"},
+			notContains: []string{"The server is"},
+		},
+		{
+			name:        "rollout read-only uses the deployment status",
+			accessLevel: "readonly",
+			prompt:      "review-rollout",
+			args:        map[string]string{"namespace": "shop", "deployment": "web"},
+			contains:    []string{"conditions of kubectl_resources(operation='get', resource='deployment', args='web -n shop -o yaml')"},
+			notContains: []string{"kubectl_workloads"},
+		},
+		{
+			name:        "rollout split read-write",
+			accessLevel: "readwrite",
+			split:       true,
+			prompt:      "review-rollout",
+			args:        map[string]string{"namespace": "shop", "deployment": "web"},
+			contains: []string{
+				"kubectl_workloads(operation='rollout', resource='status', args='deployment/web -n shop --timeout=10s')",
+				"kubectl_workloads_write(operation='rollout', resource='undo', args='deployment/web -n shop')",
+			},
+		},
+		{
+			name:        "dns defaults",
+			accessLevel: "readonly",
+			prompt:      "dns-resolution-failure",
+			args:        map[string]string{"namespace": "shop"},
+			contains:    []string{"Pods in namespace shop fail to resolve kubernetes.default.svc.cluster.local"},
+			notContains: []string{"like", "exec"},
+		},
+		{
+			name:        "dns with pod",
+			accessLevel: "readwrite",
+			prompt:      "dns-resolution-failure",
+			args:        map[string]string{"namespace": "shop", "pod": "web-1", "hostname": "db.shop"},
+			contains: []string{
+				"Pods in namespace shop, like web-1, fail to resolve db.shop",
+				"kubectl_diagnostics(operation='exec', resource='', args='web-1 -n shop -- nslookup db.shop')",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := newTestLibrary(t, tt.accessLevel, tt.split).Render(tt.prompt, tt.args)
+			if err != nil {
+				t.Fatalf("Render() unexpected error = %v", err)
+			}
+			text := promptText(result)
+			for _, s := range tt.contains {
+				if !strings.Contains(text, s) {
+					t.Errorf("prompt doesn't contain %q:\n%s", s, text)
+				}
+			}
+			for _, s := range tt.notContains {
+				if strings.Contains(text, s) {
+					t.Errorf("prompt contains %q:\n%s", s, text)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderBuiltinAtAllAccessLevels(t *testing.T) {
+	args := map[string]string{"namespace": "ns", "pod": "p", "service": "s", "deployment": "d"}
+	for _, accessLevel := range []string{"readonly", "readwrite", "admin"} {
+		for _, split := range []bool{false, true} {
+			library := newTestLibrary(t, accessLevel, split)
+			for _, prompt := range library.Prompts() {
+				req := mcp.GetPromptRequest{}
+				req.Params.Name = prompt.Prompt.Name
+				req.Params.Arguments = args
+				if _, err := prompt.Handler(context.Background(), req); err != nil {
+					t.Errorf("%s at %s (split %v): unexpected error = %v", prompt.Prompt.Name, accessLevel, split, err)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	library := newTestLibrary(t, "readonly", false)
+
+	_, err := library.Render("troubleshoot-crashloop", map[string]string{"namespace": "shop"})
+	if toolerror.CodeOf(err) != toolerror.InvalidArguments || !strings.Contains(err.Error(), "pod") {
+		t.Errorf("Render() error = %v, want missing argument", err)
+	}
+
+	_, err = library.Render("missing", nil)
+	if toolerror.CodeOf(err) != toolerror.NotFound {
+		t.Errorf("Render() error = %v, want not found", err)
+	}
+
+	// Unavailable operations fail unless templates check them with has
+	library = NewLibrary([]Definition{{
+		Name:     "drain",
+		Messages: []Message{{Role: RoleUser, Text: `{{call "kubectl_resources" "drain" "" "node-1"}}`}},
+	}}, "readonly", func(toolName, operation, resource string) (string, bool) {
+		return kubectl.ToolForOperation("readonly", false, toolName, operation, resource)
+	})
+	_, err = library.Render("drain", nil)
+	if err == nil || !strings.Contains(err.Error(), "operation drain of kubectl_resources is not available") {
+		t.Errorf("Render() error = %v, want unavailable operation", err)
+	}
+}
+
+func TestPromptArguments(t *testing.T) {
+	for _, prompt := range newTestLibrary(t, "readonly", false).Prompts() {
+		if prompt.Prompt.Name != "dns-resolution-failure" {
+			continue
+		}
+		required := map[string]bool{}
+		descriptions := map[string]string{}
+		for _, argument := range prompt.Prompt.Arguments {
+			required[argument.Name] = argument.Required
+			descriptions[argument.Name] = argument.Description
+		}
+		if !required["namespace"] || required["pod"] || required["hostname"] {
+			t.Errorf("Unexpected arguments %+v", prompt.Prompt.Arguments)
+		}
+		if !strings.HasSuffix(descriptions["hostname"], "(default kubernetes.default.svc.cluster.local)") {
+			t.Errorf("hostname description = %q, want default", descriptions["hostname"])
+		}
+		return
+	}
+	t.Error("dns-resolution-failure prompt not found")
+}
@@ -0,0 +1,157 @@
+// Package prompts provides MCP prompts guiding clients through common troubleshooting workflows. Prompts are
+// defined in YAML files, the built-in prompts are embedded and teams can load their own from a directory.
+package prompts
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+//go:embed builtin/*.yaml
+var builtinFS embed.FS
+
+// Roles of prompt messages
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// namePattern matches prompt and argument names
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Definition is a prompt loaded from a YAML file
+type Definition struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Arguments   []Argument `json:"arguments,omitempty"`
+	// Messages are Go templates rendered with the arguments and the available tools
+	Messages []Message `json:"messages"`
+}
+
+// Argument is an argument of a prompt
+type Argument struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required,omitempty"`
+	// Default is used when an optional argument is not given
+	Default string `json:"default,omitempty"`
+}
+
+// Message is a message template of a prompt
+type Message struct {
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+// Builtin returns the built-in prompt definitions
+func Builtin() ([]Definition, error) {
+	return loadFS(builtinFS, "builtin")
+}
+
+// Load reads the prompt definitions of the .yaml and .yml files in a directory
+func Load(dir string) ([]Definition, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompts directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("prompts directory %s is not a directory", dir)
+	}
+	return loadFS(os.DirFS(dir), ".")
+}
+
+// Merge adds custom definitions to the built-in ones, custom definitions replace built-in prompts of the same name
+func Merge(builtin, custom []Definition) []Definition {
+	byName := make(map[string]Definition, len(builtin)+len(custom))
+	for _, definition := range builtin {
+		byName[definition.Name] = definition
+	}
+	for _, definition := range custom {
+		byName[definition.Name] = definition
+	}
+
+	merged := make([]Definition, 0, len(byName))
+	for _, definition := range byName {
+		merged = append(merged, definition)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name < merged[j].Name })
+	return merged
+}
+
+// loadFS reads the prompt definitions of the YAML files in a directory of a file system
+func loadFS(fsys fs.FS, dir string) ([]Definition, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompts directory: %w", err)
+	}
+
+	var definitions []Definition
+	files := make(map[string]string)
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt %s: %w", entry.Name(), err)
+		}
+		var definition Definition
+		if err := yaml.UnmarshalStrict(data, &definition); err != nil {
+			return nil, fmt.Errorf("failed to parse prompt %s: %w", entry.Name(), err)
+		}
+		if err := definition.validate(); err != nil {
+			return nil, fmt.Errorf("invalid prompt %s: %w", entry.Name(), err)
+		}
+		if other, ok := files[definition.Name]; ok {
+			return nil, fmt.Errorf("prompt %s is defined in %s and %s", definition.Name, other, entry.Name())
+		}
+		files[definition.Name] = entry.Name()
+		definitions = append(definitions, definition)
+	}
+	return definitions, nil
+}
+
+// validate checks the names, roles and templates of a definition
+func (d Definition) validate() error {
+	if !namePattern.MatchString(d.Name) {
+		return fmt.Errorf("invalid name '%s', names use lowercase letters, digits, '-' and '_'", d.Name)
+	}
+	seen := make(map[string]bool)
+	for _, argument := range d.Arguments {
+		if !namePattern.MatchString(argument.Name) {
+			return fmt.Errorf("invalid argument name '%s'", argument.Name)
+		}
+		if seen[argument.Name] {
+			return fmt.Errorf("argument %s is defined twice", argument.Name)
+		}
+		seen[argument.Name] = true
+	}
+
+	if len(d.Messages) == 0 {
+		return fmt.Errorf("at least one message is required")
+	}
+	for i, message := range d.Messages {
+		if message.Role != RoleUser && message.Role != RoleAssistant {
+			return fmt.Errorf("message %d: invalid role '%s'. Valid values are: %s", i+1, message.Role, strings.Join([]string{RoleUser, RoleAssistant}, ", "))
+		}
+		if _, err := parseTemplate(d.Name, message.Text); err != nil {
+			return fmt.Errorf("message %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// parseTemplate parses a message template, the functions are replaced when rendering
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=zero").Funcs(templateFuncs(nil)).Parse(text)
+}
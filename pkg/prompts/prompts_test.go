@@ -0,0 +1,133 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltin(t *testing.T) {
+	definitions, err := Builtin()
+	if err != nil {
+		t.Fatalf("Builtin() unexpected error = %v", err)
+	}
+
+	names := map[string]bool{}
+	for _, definition := range definitions {
+		names[definition.Name] = true
+	}
+	for _, name := range []string{"troubleshoot-crashloop", "pod-pending", "service-no-endpoints", "review-rollout", "dns-resolution-failure"} {
+		if !names[name] {
+			t.Errorf("Builtin() is missing prompt %s, got %v", name, names)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		wantNames   []string
+		expectError string
+	}{
+		{
+			name: "yaml files",
+			files: map[string]string{
+				"a.yaml":    "name: check-node\ndescription: Check a node\narguments:\n- name: node\n  required: true\nmessages:\n- role: user\n  text: Check {{.Args.node}}\n",
+				"b.yml":     "name: check-quota\nmessages:\n- role: user\n  text: Check quotas\n",
+				"README.md": "not a prompt",
+			},
+			wantNames: []string{"check-node", "check-quota"},
+		},
+		{
+			name:        "unknown field",
+			files:       map[string]string{"a.yaml": "name: a\nmesages: []\n"},
+			expectError: "unknown field",
+		},
+		{
+			name:        "invalid name",
+			files:       map[string]string{"a.yaml": "name: Check Node\nmessages:\n- role: user\n  text: x\n"},
+			expectError: "invalid name 'Check Node'",
+		},
+		{
+			name:        "no messages",
+			files:       map[string]string{"a.yaml": "name: a\n"},
+			expectError: "at least one message is required",
+		},
+		{
+			name:        "invalid role",
+			files:       map[string]string{"a.yaml": "name: a\nmessages:\n- role: system\n  text: x\n"},
+			expectError: "invalid role 'system'",
+		},
+		{
+			name:        "invalid template",
+			files:       map[string]string{"a.yaml": "name: a\nmessages:\n- role: user\n  text: '{{.Args.x'\n"},
+			expectError: "message 1",
+		},
+		{
+			name:        "unknown template function",
+			files:       map[string]string{"a.yaml": "name: a\nmessages:\n- role: user\n  text: '{{kubectl \"get\"}}'\n"},
+			expectError: `function "kubectl" not defined`,
+		},
+		{
+			name:        "duplicate argument",
+			files:       map[string]string{"a.yaml": "name: a\narguments:\n- name: x\n- name: x\nmessages:\n- role: user\n  text: x\n"},
+			expectError: "argument x is defined twice",
+		},
+		{
+			name: "duplicate prompt",
+			files: map[string]string{
+				"a.yaml": "name: a\nmessages:\n- role: user\n  text: x\n",
+				"b.yaml": "name: a\nmessages:\n- role: user\n  text: y\n",
+			},
+			expectError: "prompt a is defined in a.yaml and b.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			definitions, err := Load(dir)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Load() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() unexpected error = %v", err)
+			}
+			var names []string
+			for _, definition := range definitions {
+				names = append(names, definition.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("Load() names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Load() expected error for a missing directory")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	builtin := []Definition{{Name: "b", Description: "builtin"}, {Name: "a", Description: "builtin"}}
+	custom := []Definition{{Name: "b", Description: "custom"}, {Name: "c", Description: "custom"}}
+
+	var got []string
+	for _, definition := range Merge(builtin, custom) {
+		got = append(got, definition.Name+":"+definition.Description)
+	}
+	if want := "a:builtin,b:custom,c:custom"; strings.Join(got, ",") != want {
+		t.Errorf("Merge() = %v, want %s", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// RedactedValue replaces the values of secrets
+const RedactedValue = "<redacted>"
+
+// lastAppliedAnnotation holds the previously applied manifest, including secret values
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// redact removes secret values and noise from kubectl's JSON output of an object or a list, and renders
+// it in the requested format
+func redact(output, format string) (string, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &object); err != nil {
+		return "", fmt.Errorf("failed to parse kubectl output: %w", err)
+	}
+
+	if items, ok := object["items"].([]interface{}); ok {
+		for _, item := range items {
+			if item, ok := item.(map[string]interface{}); ok {
+				redactObject(item)
+			}
+		}
+	} else {
+		redactObject(object)
+	}
+
+	data, err := json.MarshalIndent(object, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if format == FormatJSON {
+		return string(data) + "\n", nil
+	}
+	data, err = yaml.JSONToYAML(data)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// redactObject replaces the values of secrets and drops managed fields and the last applied configuration
+func redactObject(object map[string]interface{}) {
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, lastAppliedAnnotation)
+			if len(annotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+	}
+
+	if object["kind"] != "Secret" {
+		return
+	}
+	for _, field := range []string{"data", "stringData"} {
+		if values, ok := object[field].(map[string]interface{}); ok {
+			for key := range values {
+				values[key] = RedactedValue
+			}
+		}
+	}
+}
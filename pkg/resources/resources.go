@@ -0,0 +1,131 @@
+// Package resources exposes cluster objects as MCP resources, so clients can attach them as context
+// without a tool call.
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Provider reads cluster objects with the kubectl tool executor, so resources go through the same
+// access level, namespace and backend handling as tool calls
+type Provider struct {
+	executor tools.CommandExecutor
+	cfg      *config.ConfigData
+}
+
+// NewProvider creates a resource provider running kubectl commands with an executor
+func NewProvider(executor tools.CommandExecutor, cfg *config.ConfigData) *Provider {
+	return &Provider{executor: executor, cfg: cfg}
+}
+
+// Resources returns the static resources of the cluster
+func (p *Provider) Resources() []server.ServerResource {
+	return []server.ServerResource{
+		{
+			Resource: mcp.NewResource(ClusterInfoURI, "Cluster info",
+				mcp.WithResourceDescription("Addresses of the control plane and cluster services of the current context"),
+				mcp.WithMIMEType("text/plain"),
+			),
+			Handler: p.commandHandler("cluster-info"),
+		},
+		{
+			Resource: mcp.NewResource(APIResourcesURI, "API resources",
+				mcp.WithResourceDescription("Resource types supported by the API server, with their short names, API group and scope"),
+				mcp.WithMIMEType("text/plain"),
+			),
+			Handler: p.commandHandler("api-resources"),
+		},
+	}
+}
+
+// Templates returns the resource templates of cluster objects and lists of objects
+func (p *Provider) Templates() []server.ServerResourceTemplate {
+	placeholders := fmt.Sprintf("Use %s as context for the current context and as namespace for cluster-scoped objects. "+
+		"Objects are returned as YAML, or as JSON with ?format=json. Secret values are redacted.", Placeholder)
+	return []server.ServerResourceTemplate{
+		{
+			Template: mcp.NewResourceTemplate(ObjectTemplate, "Kubernetes object",
+				mcp.WithTemplateDescription("A Kubernetes object by kind and name, for example k8s://_/default/deployments/web. "+placeholders),
+				mcp.WithTemplateMIMEType("application/yaml"),
+			),
+			Handler: p.ReadObject,
+		},
+		{
+			Template: mcp.NewResourceTemplate(ListTemplate, "Kubernetes objects",
+				mcp.WithTemplateDescription("All objects of a kind in a namespace, for example k8s://_/default/pods. "+placeholders),
+				mcp.WithTemplateMIMEType("application/yaml"),
+			),
+			Handler: p.ReadObject,
+		},
+	}
+}
+
+// ReadObject reads the object or list of objects of a k8s:// URI
+func (p *Provider) ReadObject(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	ref, err := ParseURI(req.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	text, err := p.Get(sessionIDOf(ctx), ref)
+	if err != nil {
+		return nil, err
+	}
+
+	mimeType := "application/yaml"
+	if ref.Format == FormatJSON {
+		mimeType = "application/json"
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: req.Params.URI, MIMEType: mimeType, Text: text},
+	}, nil
+}
+
+// Get returns the referenced objects in the format of the reference, with secret values redacted. The events
+// of the command are logged for the session reading them.
+func (p *Provider) Get(sessionID string, ref Ref) (string, error) {
+	params := ref.params()
+	if sessionID != "" {
+		params[tools.SessionIDParam] = sessionID
+	}
+	output, err := p.executor.Execute(params, p.cfg)
+	if err != nil {
+		return "", err
+	}
+	return redact(output, ref.Format)
+}
+
+// commandHandler returns a handler for a static resource returning the output of a kubectl_cluster operation
+func (p *Provider) commandHandler(operation string) server.ResourceHandlerFunc {
+	return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		params := map[string]interface{}{
+			"_tool_name": "kubectl_cluster",
+			"operation":  operation,
+			"resource":   "",
+			"args":       "",
+		}
+		if sessionID := sessionIDOf(ctx); sessionID != "" {
+			params[tools.SessionIDParam] = sessionID
+		}
+		output, err := p.executor.Execute(params, p.cfg)
+		if err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: req.Params.URI, MIMEType: "text/plain", Text: output},
+		}, nil
+	}
+}
+
+// sessionIDOf returns the session of a resource read, empty if it isn't known
+func sessionIDOf(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
@@ -0,0 +1,197 @@
+package resources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	"github.com/Azure/mcp-kubernetes/pkg/native"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const testManifests = `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: shop
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: '{"data":{"password":"c2VjcmV0"}}'
+data:
+  password: c2VjcmV0
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: shop
+data:
+  mode: fast
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: internal
+  namespace: kube-system
+data:
+  mode: slow
+`
+
+// newTestProvider creates a provider reading from a simulated cluster, restricted to the shop namespace
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cluster.yaml")
+	if err := os.WriteFile(path, []byte(testManifests), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	backend, err := native.NewSimulatedBackend(path)
+	if err != nil {
+		t.Fatalf("NewSimulatedBackend() unexpected error = %v", err)
+	}
+
+	securityConfig := &security.SecurityConfig{AccessLevel: security.AccessLevelReadOnly}
+	securityConfig.SetAllowedNamespaces("shop")
+	cfg := &config.ConfigData{AccessLevel: "readonly", Timeout: 30, SecurityConfig: securityConfig}
+	return NewProvider(kubectl.NewKubectlToolExecutorWithBackend(backend, false), cfg)
+}
+
+// readResource reads a resource URI and returns its only contents
+func readResource(t *testing.T, p *Provider, uri string) (mcp.TextResourceContents, error) {
+	t.Helper()
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = uri
+	contents, err := p.ReadObject(context.Background(), req)
+	if err != nil {
+		return mcp.TextResourceContents{}, err
+	}
+	if len(contents) != 1 {
+		t.Fatalf("Expected one resource contents, got %d", len(contents))
+	}
+	return contents[0].(mcp.TextResourceContents), nil
+}
+
+func TestReadObject(t *testing.T) {
+	p := newTestProvider(t)
+
+	tests := []struct {
+		name         string
+		uri          string
+		wantMIMEType string
+		contains     []string
+		notContains  []string
+		expectError  string
+	}{
+		{
+			name:         "object as yaml",
+			uri:          "k8s://_/shop/configmaps/settings",
+			wantMIMEType: "application/yaml",
+			contains:     []string{"kind: ConfigMap", "name: settings", "mode: fast"},
+		},
+		{
+			name:         "list as json",
+			uri:          "k8s://_/shop/configmaps?format=json",
+			wantMIMEType: "application/json",
+			contains:     []string{`"items": [`, `"name": "settings"`},
+			notContains:  []string{"internal"},
+		},
+		{
+			name:         "secret values are redacted",
+			uri:          "k8s://_/shop/secrets/db",
+			wantMIMEType: "application/yaml",
+			contains:     []string{"password: <redacted>"},
+			notContains:  []string{"c2VjcmV0", "last-applied-configuration"},
+		},
+		{
+			name:         "secrets in lists are redacted",
+			uri:          "k8s://_/shop/secrets?format=json",
+			wantMIMEType: "application/json",
+			contains:     []string{`"password": "\u003credacted\u003e"`},
+			notContains:  []string{"c2VjcmV0"},
+		},
+		{name: "namespace not allowed", uri: "k8s://_/kube-system/configmaps/internal", expectError: "kube-system"},
+		{name: "object not found", uri: "k8s://_/shop/configmaps/missing", expectError: "NotFound"},
+		{name: "invalid uri", uri: "k8s://_/shop", expectError: "invalid resource URI"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents, err := readResource(t, p, tt.uri)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("ReadObject() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadObject() unexpected error = %v", err)
+			}
+			if contents.URI != tt.uri || contents.MIMEType != tt.wantMIMEType {
+				t.Errorf("contents uri = %q, mime type = %q", contents.URI, contents.MIMEType)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(contents.Text, s) {
+					t.Errorf("contents don't contain %q:\n%s", s, contents.Text)
+				}
+			}
+			for _, s := range tt.notContains {
+				if strings.Contains(contents.Text, s) {
+					t.Errorf("contents contain %q:\n%s", s, contents.Text)
+				}
+			}
+		})
+	}
+}
+
+// sessionExecutor records the session of the last command
+type sessionExecutor struct {
+	tools.CommandExecutor
+	session string
+}
+
+// Execute implements tools.CommandExecutor
+func (e *sessionExecutor) Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	e.session = tools.SessionID(params)
+	return e.CommandExecutor.Execute(params, cfg)
+}
+
+func TestReadObjectSession(t *testing.T) {
+	p := newTestProvider(t)
+	executor := &sessionExecutor{CommandExecutor: p.executor}
+	p.executor = executor
+
+	ctx := server.NewMCPServer("test", "1.0").WithContext(context.Background(), server.NewInProcessSession("s1", nil))
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = "k8s://_/shop/configmaps/settings"
+	if _, err := p.ReadObject(ctx, req); err != nil {
+		t.Fatalf("ReadObject() unexpected error = %v", err)
+	}
+	if executor.session != "s1" {
+		t.Errorf("executor session = %q, want s1", executor.session)
+	}
+}
+
+func TestProviderRegistrations(t *testing.T) {
+	p := newTestProvider(t)
+
+	uris := map[string]bool{}
+	for _, resource := range p.Resources() {
+		uris[resource.Resource.URI] = resource.Handler != nil
+	}
+	if !uris[ClusterInfoURI] || !uris[APIResourcesURI] {
+		t.Errorf("Expected static cluster resources, got %v", uris)
+	}
+
+	templates := map[string]bool{}
+	for _, template := range p.Templates() {
+		templates[template.Template.URITemplate.Raw()] = template.Handler != nil
+	}
+	if !templates[ObjectTemplate] || !templates[ListTemplate] {
+		t.Errorf("Expected object and list templates, got %v", templates)
+	}
+}
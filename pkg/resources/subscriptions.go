@@ -0,0 +1,180 @@
+package resources
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+)
+
+// MaxSubscriptions is the maximum number of resource subscriptions of a session
+const MaxSubscriptions = 20
+
+// Default timings of subscriptions
+const (
+	// DebounceInterval collects changes of a resource into a single notification
+	DebounceInterval = time.Second
+	// RetryInterval is the delay before a failed watch is restarted
+	RetryInterval = 5 * time.Second
+)
+
+// Watcher watches the objects of a reference
+type Watcher interface {
+	// Watch calls changed with the namespace of every added, modified or deleted object, empty for
+	// cluster-scoped objects, until the context is done or the watch fails
+	Watch(ctx context.Context, ref Ref, changed func(namespace string)) error
+}
+
+// Notifier tells a session that the resource of a URI changed
+type Notifier func(sessionID, uri string) error
+
+// Subscriptions watches the resources subscribed by sessions and notifies them about changes
+type Subscriptions struct {
+	watcher  Watcher
+	cfg      *config.ConfigData
+	notify   Notifier
+	debounce time.Duration
+	retry    time.Duration
+
+	mu sync.Mutex
+	// sessions holds the subscriptions of each session by URI
+	sessions map[string]map[string]*subscription
+}
+
+// subscription is a running watch of a subscribed resource
+type subscription struct {
+	cancel context.CancelFunc
+	// timer delivers the pending notification, nil if none is pending
+	timer *time.Timer
+}
+
+// NewSubscriptions creates a subscription manager watching resources with a watcher
+func NewSubscriptions(watcher Watcher, cfg *config.ConfigData, notify Notifier) *Subscriptions {
+	return &Subscriptions{
+		watcher:  watcher,
+		cfg:      cfg,
+		notify:   notify,
+		debounce: DebounceInterval,
+		retry:    RetryInterval,
+		sessions: make(map[string]map[string]*subscription),
+	}
+}
+
+// Subscribe starts watching the resource of a URI for a session. Subscribing to a resource twice is a no-op.
+func (s *Subscriptions) Subscribe(sessionID, uri string) error {
+	ref, err := ParseURI(uri)
+	if err != nil {
+		return err
+	}
+	if ref.Namespace != Placeholder && !s.namespaceAllowed(ref.Namespace) {
+		return toolerror.Newf(toolerror.PolicyDenied, "namespace %s is not allowed by security policy", ref.Namespace)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscriptions := s.sessions[sessionID]
+	if _, ok := subscriptions[uri]; ok {
+		return nil
+	}
+	if len(subscriptions) >= MaxSubscriptions {
+		return toolerror.Newf(toolerror.InvalidArguments, "a session can subscribe to at most %d resources, unsubscribe from resources that are no longer needed", MaxSubscriptions)
+	}
+	if subscriptions == nil {
+		subscriptions = make(map[string]*subscription)
+		s.sessions[sessionID] = subscriptions
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &subscription{cancel: cancel}
+	subscriptions[uri] = sub
+	go s.watch(ctx, sessionID, uri, ref, sub)
+	return nil
+}
+
+// Unsubscribe stops watching the resource of a URI for a session
+func (s *Subscriptions) Unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sub, ok := s.sessions[sessionID][uri]; ok {
+		sub.stop()
+		delete(s.sessions[sessionID], uri)
+	}
+}
+
+// CloseSession stops all watches of a session that ended
+func (s *Subscriptions) CloseSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range s.sessions[sessionID] {
+		sub.stop()
+	}
+	delete(s.sessions, sessionID)
+}
+
+// Count returns the number of subscriptions of a session
+func (s *Subscriptions) Count(sessionID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions[sessionID])
+}
+
+// watch runs the watch of a subscription and restarts it when it fails, until the subscription ends
+func (s *Subscriptions) watch(ctx context.Context, sessionID, uri string, ref Ref, sub *subscription) {
+	for {
+		err := s.watcher.Watch(ctx, ref, func(namespace string) {
+			s.changed(sessionID, uri, namespace, sub)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		logging.ForSession(sessionID).Warn("watch ended, restarting", "uri", uri, "retry", s.retry, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.retry):
+		}
+	}
+}
+
+// changed schedules a notification for a change of a subscribed resource. The namespace policy is checked
+// on every event, so changes in namespaces that are not allowed are never reported.
+func (s *Subscriptions) changed(sessionID, uri, namespace string, sub *subscription) {
+	if namespace != "" && !s.namespaceAllowed(namespace) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sub.timer != nil || s.sessions[sessionID][uri] != sub {
+		return
+	}
+	sub.timer = time.AfterFunc(s.debounce, func() {
+		s.mu.Lock()
+		active := s.sessions[sessionID][uri] == sub
+		sub.timer = nil
+		s.mu.Unlock()
+		if !active {
+			return
+		}
+		if err := s.notify(sessionID, uri); err != nil {
+			logging.ForSession(sessionID).Warn("failed to notify session about a resource update", "uri", uri, "error", err)
+		}
+	})
+}
+
+// namespaceAllowed checks a namespace against the current security policy
+func (s *Subscriptions) namespaceAllowed(namespace string) bool {
+	return s.cfg.SecurityConfig == nil || s.cfg.SecurityConfig.IsNamespaceAllowed(namespace)
+}
+
+// stop ends the watch and drops the pending notification of a subscription
+func (sub *subscription) stop() {
+	sub.cancel()
+	if sub.timer != nil {
+		sub.timer.Stop()
+		sub.timer = nil
+	}
+}
@@ -0,0 +1,240 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/native"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+)
+
+// fakeWatcher records running watches and lets tests report changes
+type fakeWatcher struct {
+	mu      sync.Mutex
+	changed map[string]func(namespace string)
+}
+
+func (w *fakeWatcher) Watch(ctx context.Context, ref Ref, changed func(namespace string)) error {
+	w.mu.Lock()
+	w.changed[ref.URI()] = changed
+	w.mu.Unlock()
+	<-ctx.Done()
+	w.mu.Lock()
+	delete(w.changed, ref.URI())
+	w.mu.Unlock()
+	return ctx.Err()
+}
+
+// change reports a change of a watched URI, it waits for the watch to start
+func (w *fakeWatcher) change(t *testing.T, uri, namespace string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		w.mu.Lock()
+		changed := w.changed[uri]
+		w.mu.Unlock()
+		if changed != nil {
+			changed(namespace)
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("%s is not watched", uri)
+}
+
+// watching returns the number of running watches
+func (w *fakeWatcher) watching() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.changed)
+}
+
+// newTestSubscriptions creates subscriptions restricted to the shop namespace, recording notifications
+func newTestSubscriptions() (*Subscriptions, *fakeWatcher, chan string) {
+	securityConfig := &security.SecurityConfig{AccessLevel: security.AccessLevelReadOnly}
+	securityConfig.SetAllowedNamespaces("shop")
+	cfg := &config.ConfigData{SecurityConfig: securityConfig}
+
+	watcher := &fakeWatcher{changed: make(map[string]func(string))}
+	notifications := make(chan string, 10)
+	s := NewSubscriptions(watcher, cfg, func(sessionID, uri string) error {
+		notifications <- sessionID + " " + uri
+		return nil
+	})
+	s.debounce = 20 * time.Millisecond
+	return s, watcher, notifications
+}
+
+func TestSubscribe(t *testing.T) {
+	tests := []struct {
+		name        string
+		uri         string
+		expectError toolerror.Code
+	}{
+		{name: "object", uri: "k8s://_/shop/configmaps/settings"},
+		{name: "list across namespaces", uri: "k8s://_/_/configmaps"},
+		{name: "namespace not allowed", uri: "k8s://_/kube-system/configmaps", expectError: toolerror.PolicyDenied},
+		{name: "invalid uri", uri: "k8s://cluster-info", expectError: toolerror.InvalidArguments},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, watcher, _ := newTestSubscriptions()
+			defer s.CloseSession("a")
+
+			err := s.Subscribe("a", tt.uri)
+			if tt.expectError != "" {
+				if toolerror.CodeOf(err) != tt.expectError {
+					t.Errorf("Subscribe() error = %v, want %s", err, tt.expectError)
+				}
+				if s.Count("a") != 0 {
+					t.Errorf("Count() = %d after rejected subscription", s.Count("a"))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Subscribe() unexpected error = %v", err)
+			}
+			watcher.change(t, tt.uri, "shop")
+		})
+	}
+}
+
+func TestSubscriptionNotifications(t *testing.T) {
+	s, watcher, notifications := newTestSubscriptions()
+	uri := "k8s://_/_/configmaps"
+	if err := s.Subscribe("a", uri); err != nil {
+		t.Fatalf("Subscribe() unexpected error = %v", err)
+	}
+	// Subscribing again keeps the existing watch
+	if err := s.Subscribe("a", uri); err != nil || s.Count("a") != 1 {
+		t.Fatalf("Subscribe() again error = %v, count = %d", err, s.Count("a"))
+	}
+
+	// A burst of changes is debounced into a single notification
+	for i := 0; i < 5; i++ {
+		watcher.change(t, uri, "shop")
+	}
+	if got := <-notifications; got != "a "+uri {
+		t.Errorf("notification = %q", got)
+	}
+	select {
+	case got := <-notifications:
+		t.Errorf("unexpected second notification %q", got)
+	case <-time.After(60 * time.Millisecond):
+	}
+
+	// Changes in namespaces that are not allowed are dropped
+	watcher.change(t, uri, "kube-system")
+	select {
+	case got := <-notifications:
+		t.Errorf("unexpected notification %q for a denied namespace", got)
+	case <-time.After(60 * time.Millisecond):
+	}
+
+	// Pending notifications are dropped when unsubscribing
+	watcher.change(t, uri, "")
+	s.Unsubscribe("a", uri)
+	select {
+	case got := <-notifications:
+		t.Errorf("unexpected notification %q after unsubscribing", got)
+	case <-time.After(60 * time.Millisecond):
+	}
+	waitFor(t, func() bool { return watcher.watching() == 0 })
+}
+
+func TestSubscriptionLimitAndCleanup(t *testing.T) {
+	s, watcher, _ := newTestSubscriptions()
+	for i := 0; i < MaxSubscriptions; i++ {
+		if err := s.Subscribe("a", fmt.Sprintf("k8s://_/shop/configmaps/cm-%d", i)); err != nil {
+			t.Fatalf("Subscribe() unexpected error = %v", err)
+		}
+	}
+	err := s.Subscribe("a", "k8s://_/shop/configmaps/one-too-many")
+	if err == nil || !strings.Contains(err.Error(), "at most") {
+		t.Errorf("Subscribe() error = %v, want limit error", err)
+	}
+	// The limit applies per session
+	if err := s.Subscribe("b", "k8s://_/shop/configmaps/settings"); err != nil {
+		t.Errorf("Subscribe() for another session unexpected error = %v", err)
+	}
+	waitFor(t, func() bool { return watcher.watching() == MaxSubscriptions+1 })
+
+	s.CloseSession("a")
+	if s.Count("a") != 0 || s.Count("b") != 1 {
+		t.Errorf("Count() after closing session = %d, %d", s.Count("a"), s.Count("b"))
+	}
+	waitFor(t, func() bool { return watcher.watching() == 1 })
+	s.CloseSession("b")
+	waitFor(t, func() bool { return watcher.watching() == 0 })
+}
+
+func TestRefWatchArgs(t *testing.T) {
+	ref := Ref{Context: "prod", Namespace: "shop", Kind: "pods", Name: "web"}
+	want := "get pods web --namespace shop --context prod --watch-only --output-watch-events -o json"
+	if got := strings.Join(ref.watchArgs(), " "); got != want {
+		t.Errorf("watchArgs() = %q, want %q", got, want)
+	}
+
+	ref = Ref{Context: Placeholder, Namespace: Placeholder, Kind: "nodes"}
+	want = "get nodes --watch-only --output-watch-events -o json"
+	if got := strings.Join(ref.watchArgs(), " "); got != want {
+		t.Errorf("watchArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeEvents(t *testing.T) {
+	output := `{"type":"MODIFIED","object":{"kind":"Pod","metadata":{"name":"web","namespace":"shop"}}}
+{"type":"BOOKMARK","object":{"kind":"Pod","metadata":{}}}
+{"type":"ADDED","object":{"kind":"Node","metadata":{"name":"node-1"}}}
+`
+	var namespaces []string
+	if err := decodeEvents(strings.NewReader(output), func(namespace string) { namespaces = append(namespaces, namespace) }); err != nil {
+		t.Fatalf("decodeEvents() unexpected error = %v", err)
+	}
+	if strings.Join(namespaces, ",") != "shop," {
+		t.Errorf("changed namespaces = %q", namespaces)
+	}
+
+	if err := decodeEvents(strings.NewReader("error: the server doesn't have a resource type"), func(string) {}); err == nil {
+		t.Error("decodeEvents() expected error for invalid output")
+	}
+}
+
+func TestNewWatcher(t *testing.T) {
+	backend, err := native.NewSimulatedBackend("")
+	if err != nil {
+		t.Fatalf("NewSimulatedBackend() unexpected error = %v", err)
+	}
+	watcher := NewWatcher(backend, false, &config.ConfigData{})
+
+	// The current context is watched by the backend until the context ends
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := watcher.Watch(ctx, Ref{Context: Placeholder, Namespace: "default", Kind: "configmaps"}, func(string) {}); err != context.DeadlineExceeded {
+		t.Errorf("Watch() error = %v, want deadline exceeded", err)
+	}
+
+	// Other contexts need kubectl, which the simulated backend never runs
+	err = watcher.Watch(context.Background(), Ref{Context: "prod", Namespace: "default", Kind: "configmaps"}, func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Watch() error = %v, want unsupported context", err)
+	}
+}
+
+// waitFor polls a condition until it holds
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	for i := 0; i < 200; i++ {
+		if condition() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("condition not met")
+}
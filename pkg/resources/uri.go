@@ -0,0 +1,136 @@
+package resources
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// URI templates of cluster objects
+const (
+	ObjectTemplate = "k8s://{context}/{namespace}/{kind}/{name}{?format}"
+	ListTemplate   = "k8s://{context}/{namespace}/{kind}{?format}"
+)
+
+// Static resources of the cluster
+const (
+	ClusterInfoURI  = "k8s://cluster-info"
+	APIResourcesURI = "k8s://api-resources"
+)
+
+// Placeholder selects the current context when used as context, and cluster-scoped objects when used as namespace
+const Placeholder = "_"
+
+// Formats of object resources
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+var (
+	// contextPattern matches kubeconfig context names, which may look like user@cluster or an ARN
+	contextPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._@:/-]*$`)
+	// kindPattern matches resource types like pods, deployments.apps or deploy
+	kindPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.-]*$`)
+
+	objectTemplate = mcp.NewResourceTemplate(ObjectTemplate, "object").URITemplate
+	listTemplate   = mcp.NewResourceTemplate(ListTemplate, "list").URITemplate
+)
+
+// Ref identifies a cluster object or a list of objects by its resource URI
+type Ref struct {
+	Context   string
+	Namespace string
+	Kind      string
+	// Name is empty for lists
+	Name   string
+	Format string
+}
+
+// ParseURI parses an object or list URI and validates its parts
+func ParseURI(uri string) (Ref, error) {
+	var values map[string]string
+	switch {
+	case objectTemplate.Regexp().MatchString(uri):
+		values = matchValues(objectTemplate, uri)
+	case listTemplate.Regexp().MatchString(uri):
+		values = matchValues(listTemplate, uri)
+	default:
+		return Ref{}, toolerror.Newf(toolerror.InvalidArguments, "invalid resource URI '%s', expected %s or %s", uri, ObjectTemplate, ListTemplate)
+	}
+	return newRef(values)
+}
+
+// newRef creates a reference from the values of a URI template and validates them
+func newRef(values map[string]string) (Ref, error) {
+	ref := Ref{
+		Context:   values["context"],
+		Namespace: values["namespace"],
+		Kind:      values["kind"],
+		Name:      values["name"],
+		Format:    values["format"],
+	}
+	if ref.Format == "" {
+		ref.Format = FormatYAML
+	}
+
+	switch {
+	case !contextPattern.MatchString(ref.Context):
+		return Ref{}, toolerror.Newf(toolerror.InvalidArguments, "invalid context '%s', use %s for the current context", ref.Context, Placeholder)
+	case ref.Namespace == "":
+		return Ref{}, toolerror.Newf(toolerror.InvalidArguments, "namespace is required, use %s for cluster-scoped objects", Placeholder)
+	case !kindPattern.MatchString(ref.Kind):
+		return Ref{}, toolerror.Newf(toolerror.InvalidArguments, "invalid kind '%s'", ref.Kind)
+	case ref.Format != FormatYAML && ref.Format != FormatJSON:
+		return Ref{}, toolerror.Newf(toolerror.InvalidArguments, "invalid format '%s'. Valid values are: %s, %s", ref.Format, FormatYAML, FormatJSON)
+	}
+	return ref, nil
+}
+
+// URI returns the resource URI of the reference
+func (r Ref) URI() string {
+	uri := fmt.Sprintf("k8s://%s/%s/%s", r.Context, r.Namespace, r.Kind)
+	if r.Name != "" {
+		uri += "/" + r.Name
+	}
+	if r.Format != "" && r.Format != FormatYAML {
+		uri += "?format=" + r.Format
+	}
+	return uri
+}
+
+// IsList checks if the reference is a list of objects
+func (r Ref) IsList() bool {
+	return r.Name == ""
+}
+
+// params returns the kubectl_resources arguments getting the referenced objects as JSON
+func (r Ref) params() map[string]interface{} {
+	params := map[string]interface{}{
+		"_tool_name": "kubectl_resources",
+		"operation":  "get",
+		"resource":   r.Kind,
+		"args":       "-o json",
+	}
+	if r.Context != Placeholder {
+		params["context"] = r.Context
+	}
+	if r.Namespace != Placeholder {
+		params["namespace"] = r.Namespace
+	}
+	if r.Name != "" {
+		params["name"] = r.Name
+	}
+	return params
+}
+
+// matchValues returns the variables of a URI matching a template
+func matchValues(template *mcp.URITemplate, uri string) map[string]string {
+	values := make(map[string]string)
+	for name, value := range template.Match(uri) {
+		values[name] = value.String()
+	}
+	return values
+}
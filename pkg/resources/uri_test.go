@@ -0,0 +1,71 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		uri         string
+		want        Ref
+		expectError string
+	}{
+		{uri: "k8s://_/default/pods/web", want: Ref{Context: "_", Namespace: "default", Kind: "pods", Name: "web", Format: FormatYAML}},
+		{uri: "k8s://prod/shop/deployments.apps/web?format=json", want: Ref{Context: "prod", Namespace: "shop", Kind: "deployments.apps", Name: "web", Format: FormatJSON}},
+		{uri: "k8s://_/_/nodes", want: Ref{Context: "_", Namespace: "_", Kind: "nodes", Format: FormatYAML}},
+		{uri: "k8s://admin%40prod/kube-system/configmaps", want: Ref{Context: "admin@prod", Namespace: "kube-system", Kind: "configmaps", Format: FormatYAML}},
+		{uri: "k8s://_/default", expectError: "invalid resource URI"},
+		{uri: "https://_/default/pods", expectError: "invalid resource URI"},
+		{uri: "k8s://_/default/pods/web/extra", expectError: "invalid resource URI"},
+		{uri: "k8s:///default/pods", expectError: "invalid context ''"},
+		{uri: "k8s://-x/default/pods", expectError: "invalid context '-x'"},
+		{uri: "k8s://_//pods", expectError: "namespace is required"},
+		{uri: "k8s://_/default/pods%20--token", expectError: "invalid kind"},
+		{uri: "k8s://_/default/pods?format=xml", expectError: "invalid format 'xml'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			ref, err := ParseURI(tt.uri)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("ParseURI() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseURI() unexpected error = %v", err)
+			}
+			if ref != tt.want {
+				t.Errorf("ParseURI() = %+v, want %+v", ref, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefURI(t *testing.T) {
+	for _, uri := range []string{"k8s://_/default/pods/web", "k8s://prod/_/nodes?format=json"} {
+		ref, err := ParseURI(uri)
+		if err != nil {
+			t.Fatalf("ParseURI(%q) unexpected error = %v", uri, err)
+		}
+		if ref.URI() != uri {
+			t.Errorf("URI() = %q, want %q", ref.URI(), uri)
+		}
+	}
+}
+
+func TestRefParams(t *testing.T) {
+	ref := Ref{Context: "prod", Namespace: "shop", Kind: "pods", Name: "web", Format: FormatYAML}
+	params := ref.params()
+	if params["args"] != "-o json" || params["context"] != "prod" || params["namespace"] != "shop" || params["name"] != "web" {
+		t.Errorf("Unexpected params %v", params)
+	}
+
+	// The placeholders select the current context and cluster scope
+	params = Ref{Context: Placeholder, Namespace: Placeholder, Kind: "nodes"}.params()
+	if params["args"] != "-o json" || params["context"] != nil || params["namespace"] != nil || params["name"] != nil {
+		t.Errorf("Unexpected params %v", params)
+	}
+}
@@ -0,0 +1,123 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+)
+
+// ObjectWatcher watches objects of the current context, it is implemented by the native backend.
+// An empty namespace selects cluster-scoped objects.
+type ObjectWatcher interface {
+	Watch(ctx context.Context, namespace, kind, name string, changed func(namespace string)) error
+}
+
+// NewWatcher returns a watcher using the watcher of a backend for the current context. Other contexts, or all
+// references when there is no backend watcher, are watched with kubectl unless fallback is disabled.
+func NewWatcher(backend ObjectWatcher, fallback bool, cfg *config.ConfigData) Watcher {
+	return &backendWatcher{backend: backend, fallback: fallback, kubectl: &kubectlWatcher{cfg: cfg}}
+}
+
+// backendWatcher dispatches watches to the backend or to kubectl
+type backendWatcher struct {
+	backend  ObjectWatcher
+	fallback bool
+	kubectl  *kubectlWatcher
+}
+
+// Watch implements Watcher
+func (w *backendWatcher) Watch(ctx context.Context, ref Ref, changed func(namespace string)) error {
+	if w.backend != nil && ref.Context == Placeholder {
+		namespace := ref.Namespace
+		if namespace == Placeholder {
+			namespace = ""
+		}
+		return w.backend.Watch(ctx, namespace, ref.Kind, ref.Name, changed)
+	}
+	if !w.fallback {
+		return fmt.Errorf("watching context %s is not supported by the configured backend", ref.Context)
+	}
+	return w.kubectl.Watch(ctx, ref, changed)
+}
+
+// kubectlWatcher watches objects by streaming the events of kubectl get --watch
+type kubectlWatcher struct {
+	cfg *config.ConfigData
+}
+
+// watchEvent is an event printed by kubectl get --output-watch-events -o json
+type watchEvent struct {
+	Type   string `json:"type"`
+	Object struct {
+		Metadata struct {
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	} `json:"object"`
+}
+
+// Watch implements Watcher
+func (w *kubectlWatcher) Watch(ctx context.Context, ref Ref, changed func(namespace string)) error {
+	cmd := exec.CommandContext(ctx, "kubectl", ref.watchArgs()...)
+	cmd.Env = w.cfg.CommandEnv(security.CommandTypeKubectl)
+	// Watches are shared by the sessions subscribed to an object and read no local files
+	cmd.Dir = w.cfg.CommandDir("")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start kubectl: %w", err)
+	}
+
+	decodeErr := decodeEvents(stdout, changed)
+	waitErr := cmd.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if waitErr != nil {
+		return fmt.Errorf("%w: %s", waitErr, strings.TrimSpace(stderr.String()))
+	}
+	return decodeErr
+}
+
+// decodeEvents reads watch events from kubectl's output until it ends
+func decodeEvents(r io.Reader, changed func(namespace string)) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var event watchEvent
+		if err := decoder.Decode(&event); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to parse watch event: %w", err)
+		}
+		if event.Type != "BOOKMARK" {
+			changed(event.Object.Metadata.Namespace)
+		}
+	}
+}
+
+// watchArgs returns the kubectl arguments watching the referenced objects, skipping their current state
+func (r Ref) watchArgs() []string {
+	args := []string{"get", r.Kind}
+	if r.Name != "" {
+		args = append(args, r.Name)
+	}
+	if r.Namespace != Placeholder {
+		args = append(args, "--namespace", r.Namespace)
+	}
+	if r.Context != Placeholder {
+		args = append(args, "--context", r.Context)
+	}
+	return append(args, "--watch-only", "--output-watch-events", "-o", "json")
+}
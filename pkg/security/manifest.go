@@ -0,0 +1,98 @@
+package security
+
+import (
+	"fmt"
+)
+
+// ManifestObject identifies a single object in a manifest
+type ManifestObject struct {
+	APIVersion string
+	Kind       string
+	Name       string
+	Namespace  string
+}
+
+// clusterScopedKinds lists well-known kinds that are not namespaced
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"CertificateSigningRequest":      true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CSIDriver":                      true,
+	"CustomResourceDefinition":       true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// IsClusterScopedKind checks if a kind is a well-known cluster-scoped kind
+func IsClusterScopedKind(kind string) bool {
+	return clusterScopedKinds[kind]
+}
+
+// ScopeResolver reports whether objects of a kind are namespaced, usually by asking the API server
+// with discovery. It returns an error when the scope of the kind can't be determined.
+type ScopeResolver func(apiVersion, kind string) (namespaced bool, err error)
+
+// ValidateManifestObjects validates every object of a manifest against the namespace
+// restrictions. Objects without a namespace use the namespace of the command, or "default".
+// When namespaces are restricted, the scope of kinds that are not well-known cluster-scoped
+// kinds is resolved with resolve, and kinds whose scope is unknown are denied.
+func (v *Validator) ValidateManifestObjects(objects []ManifestObject, command string, resolve ScopeResolver) error {
+	defaultNamespace := v.extractNamespaceFromCommand(command)
+	if defaultNamespace == "" || defaultNamespace == "*" {
+		defaultNamespace = "default"
+	}
+
+	restricted := len(v.secConfig.allowedNamespaces) > 0 || len(v.secConfig.allowedNamespacesRe) > 0
+
+	for i, obj := range objects {
+		if obj.Kind == "" {
+			return &ValidationError{Message: fmt.Sprintf("Error: Manifest document %d has no kind", i+1)}
+		}
+
+		clusterScoped := IsClusterScopedKind(obj.Kind)
+		if restricted && !clusterScoped {
+			if resolve == nil {
+				return &ValidationError{
+					Message: fmt.Sprintf("Error: Scope of kind '%s' can't be determined and is denied by namespace restrictions in security configuration", obj.Kind),
+				}
+			}
+			namespaced, err := resolve(obj.APIVersion, obj.Kind)
+			if err != nil {
+				return &ValidationError{
+					Message: fmt.Sprintf("Error: Scope of kind '%s' can't be determined and is denied by namespace restrictions in security configuration: %v", obj.Kind, err),
+				}
+			}
+			clusterScoped = !namespaced
+		}
+
+		if clusterScoped {
+			// Cluster-scoped objects can't be confined to the allowed namespaces
+			if restricted {
+				return &ValidationError{
+					Message: fmt.Sprintf("Error: Cluster-scoped kind '%s' is denied by namespace restrictions in security configuration", obj.Kind),
+				}
+			}
+			continue
+		}
+
+		namespace := obj.Namespace
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+		if !v.secConfig.IsNamespaceAllowed(namespace) {
+			return &ValidationError{
+				Message: fmt.Sprintf("Error: Access to namespace '%s' for %s '%s' is denied by security configuration", namespace, obj.Kind, obj.Name),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package security
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateManifestObjects(t *testing.T) {
+	tests := []struct {
+		name              string
+		allowedNamespaces string
+		objects           []ManifestObject
+		command           string
+		shouldErr         bool
+		errContains       string
+	}{
+		{"No restrictions", "", []ManifestObject{{Kind: "ConfigMap", Name: "cm", Namespace: "prod"}}, "apply -f -", false, ""},
+		{"No restrictions cluster-scoped", "", []ManifestObject{{Kind: "Namespace", Name: "ns"}}, "apply -f -", false, ""},
+		{"Allowed namespace", "dev", []ManifestObject{{Kind: "ConfigMap", Name: "cm", Namespace: "dev"}}, "apply -f -", false, ""},
+		{"Namespace from command", "dev", []ManifestObject{{Kind: "ConfigMap", Name: "cm"}}, "apply -f - -n dev", false, ""},
+		{"Default namespace denied", "dev", []ManifestObject{{Kind: "ConfigMap", Name: "cm"}}, "apply -f -", true, "namespace 'default'"},
+		{"One denied document", "dev", []ManifestObject{
+			{Kind: "ConfigMap", Name: "cm", Namespace: "dev"},
+			{Kind: "Secret", Name: "s", Namespace: "kube-system"},
+		}, "apply -f -", true, "namespace 'kube-system'"},
+		{"Cluster-scoped denied", "dev", []ManifestObject{{Kind: "ClusterRoleBinding", Name: "crb"}}, "apply -f -", true, "Cluster-scoped kind"},
+		{"Missing kind", "", []ManifestObject{{Name: "cm"}}, "apply -f -", true, "has no kind"},
+		{"Namespaced custom kind", "dev", []ManifestObject{{APIVersion: "example.com/v1", Kind: "Widget", Name: "w"}}, "apply -f - -n dev", false, ""},
+		{"Cluster-scoped custom kind", "dev", []ManifestObject{{APIVersion: "example.com/v1", Kind: "ClusterWidget", Name: "w"}}, "apply -f - -n dev", true, "Cluster-scoped kind 'ClusterWidget'"},
+		{"Unknown custom kind", "dev", []ManifestObject{{APIVersion: "example.com/v1", Kind: "Gadget", Name: "g"}}, "apply -f - -n dev", true, "Scope of kind 'Gadget' can't be determined"},
+		{"Unknown kind without restrictions", "", []ManifestObject{{APIVersion: "example.com/v1", Kind: "Gadget", Name: "g"}}, "apply -f -", false, ""},
+	}
+	resolve := func(apiVersion, kind string) (bool, error) {
+		switch {
+		case kind == "ConfigMap" || kind == "Secret" || kind == "Widget":
+			return true, nil
+		case kind == "ClusterWidget":
+			return false, nil
+		}
+		return false, errors.New("kind not found")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secConfig := NewSecurityConfig()
+			secConfig.SetAllowedNamespaces(tt.allowedNamespaces)
+			validator := NewValidator(secConfig)
+
+			err := validator.ValidateManifestObjects(tt.objects, tt.command, resolve)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				} else if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error to contain %q, got %q", tt.errContains, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateManifestObjectsWithoutResolver(t *testing.T) {
+	secConfig := NewSecurityConfig()
+	secConfig.SetAllowedNamespaces("dev")
+	validator := NewValidator(secConfig)
+
+	err := validator.ValidateManifestObjects([]ManifestObject{{APIVersion: "v1", Kind: "ConfigMap", Name: "cm"}}, "apply -f - -n dev", nil)
+	if err == nil || !strings.Contains(err.Error(), "can't be determined") {
+		t.Errorf("ValidateManifestObjects() error = %v, want unknown scope", err)
+	}
+}
@@ -0,0 +1,163 @@
+package security
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SessionRoots holds the root directories advertised by the clients of sessions. Local file paths of the
+// commands of a session with roots must resolve into one of them, confined to the workspace if one is set.
+type SessionRoots struct {
+	mu       sync.RWMutex
+	sessions map[string][]string
+	// pending holds the sessions whose clients advertised roots that weren't listed yet
+	pending map[string]bool
+}
+
+// NewSessionRoots creates an empty set of session roots
+func NewSessionRoots() *SessionRoots {
+	return &SessionRoots{sessions: make(map[string][]string), pending: make(map[string]bool)}
+}
+
+// MarkPending denies local file paths of a session until its roots are set, for clients that advertise roots
+func (r *SessionRoots) MarkPending(sessionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sessions[sessionID]; !ok {
+		r.sessions[sessionID] = []string{}
+		r.pending[sessionID] = true
+	}
+}
+
+// Pending checks if the roots of a session are awaited
+func (r *SessionRoots) Pending(sessionID string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pending[sessionID]
+}
+
+// Set replaces the roots of a session with the directories of file URIs. URIs that are not local
+// directories of the server are skipped and returned as errors, a session without any directory can't
+// access local files at all.
+func (r *SessionRoots) Set(sessionID string, uris []string) ([]string, []error) {
+	dirs := []string{}
+	var errs []error
+	for _, uri := range uris {
+		dir, err := rootDir(uri)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[sessionID] = dirs
+	delete(r.pending, sessionID)
+	return dirs, errs
+}
+
+// Get returns the roots of a session, and false if its client didn't advertise any
+func (r *SessionRoots) Get(sessionID string) ([]string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	dirs, ok := r.sessions[sessionID]
+	return dirs, ok
+}
+
+// get returns the roots of a session like Get, and false for nil session roots
+func (r *SessionRoots) get(sessionID string) ([]string, bool) {
+	if r == nil || sessionID == "" {
+		return nil, false
+	}
+	return r.Get(sessionID)
+}
+
+// Remove forgets the roots of a session
+func (r *SessionRoots) Remove(sessionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, sessionID)
+	delete(r.pending, sessionID)
+}
+
+// rootDir resolves the directory of a file URI
+func rootDir(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || u.Path == "" {
+		return "", fmt.Errorf("root %s is not a file URI", uri)
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(filepath.FromSlash(u.Path)))
+	if err != nil {
+		return "", fmt.Errorf("root %s is not accessible: %w", uri, err)
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", fmt.Errorf("root %s is not accessible: %w", uri, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("root %s is not a directory", uri)
+	}
+	return resolved, nil
+}
+
+// SetSessionRoots sets the roots that narrow the sandbox of sessions whose clients advertise roots
+func (s *SecurityConfig) SetSessionRoots(roots *SessionRoots) {
+	s.sessionRoots = roots
+}
+
+// SandboxDirs returns the directories that local file paths of the commands of a session must resolve into:
+// the roots of its client, none while they are pending, or the workspace if the client doesn't advertise
+// roots. Roots are confined to the workspace if one is set. It returns false if the sandbox is disabled.
+func (s *SecurityConfig) SandboxDirs(sessionID string) ([]string, bool) {
+	if dirs, ok := s.sessionRoots.get(sessionID); ok {
+		if s.workspace != "" {
+			dirs = confineDirs(dirs, s.workspace)
+		}
+		return dirs, true
+	}
+	if s.workspace != "" {
+		return []string{s.workspace}, true
+	}
+	return nil, false
+}
+
+// confineDirs limits directories to the workspace: directories inside it are kept, directories containing
+// it are narrowed to the workspace itself and any other directory is dropped
+func confineDirs(dirs []string, workspace string) []string {
+	confined := []string{}
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		switch {
+		case isWithin(workspace, dir):
+		case isWithin(dir, workspace):
+			dir = workspace
+		default:
+			continue
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			confined = append(confined, dir)
+		}
+	}
+	return confined
+}
+
+// isWithin checks if a resolved path is the directory or inside it
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ForSession returns a validator checking local file paths against the sandbox of a session
+func (v *Validator) ForSession(sessionID string) *Validator {
+	return &Validator{secConfig: v.secConfig, sessionID: sessionID}
+}
@@ -22,6 +22,12 @@ type SecurityConfig struct {
 	allowedNamespaces []string
 	// allowedNamespacesRe is a list of compiled regex patterns for namespace matching
 	allowedNamespacesRe []*regexp.Regexp
+	// workspace is the resolved directory that local file paths must stay in, empty disables the sandbox
+	workspace string
+	// allowedURLHosts is a list of hosts allowed for URL file sources when the sandbox is enabled
+	allowedURLHosts []string
+	// sessionRoots holds the roots of clients, which replace the workspace for their sessions
+	sessionRoots *SessionRoots
 }
 
 // NewSecurityConfig creates a new SecurityConfig instance
@@ -74,6 +80,12 @@ func (s *SecurityConfig) SetAllowedNamespaces(namespaces string) {
 	}
 }
 
+// AllowedNamespaces returns the literal names of the allowed namespaces, and whether namespaces are also
+// allowed by patterns
+func (s *SecurityConfig) AllowedNamespaces() ([]string, bool) {
+	return append([]string(nil), s.allowedNamespaces...), len(s.allowedNamespacesRe) > 0
+}
+
 // IsNamespaceAllowed checks if a namespace is allowed to be accessed
 func (s *SecurityConfig) IsNamespaceAllowed(namespace string) bool {
 	// If no restrictions are defined, allow all namespaces
@@ -3,6 +3,8 @@ package security
 import (
 	"regexp"
 	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
 )
 
 // Command type constants
@@ -49,11 +51,29 @@ var (
 	HubbleReadOperations = []string{
 		"status", "version", "help", "observe", "status", "list", "config",
 	}
+
+	// KubectlReadSubcommands defines subcommands of kubectl write operations that don't modify state
+	KubectlReadSubcommands = []string{
+		"rollout status", "rollout history",
+	}
+
+	// HelmWriteSubcommands defines subcommands of helm read operations that modify local state
+	HelmWriteSubcommands = []string{
+		"repo add", "repo remove", "repo rm", "repo update", "repo up", "repo index",
+	}
+
+	// CiliumWriteSubcommands defines subcommands of cilium read operations that modify the cluster
+	CiliumWriteSubcommands = []string{
+		"connectivity test", "config set", "config delete", "hubble enable", "hubble disable",
+		"policy import", "policy delete", "endpoint config",
+	}
 )
 
 // Validator handles validation of commands against security configuration
 type Validator struct {
 	secConfig *SecurityConfig
+	// sessionID is the session of the validated commands, which selects the sandbox of local file paths
+	sessionID string
 }
 
 // NewValidator creates a new Validator instance with the given security configuration
@@ -72,6 +92,11 @@ func (e *ValidationError) Error() string {
 	return e.Message
 }
 
+// ErrorCode reports validation errors as policy denials
+func (e *ValidationError) ErrorCode() toolerror.Code {
+	return toolerror.PolicyDenied
+}
+
 // getReadOperationsList returns the appropriate list of read operations based on command type
 func (v *Validator) getReadOperationsList(commandType string) []string {
 	switch commandType {
@@ -144,9 +169,48 @@ func (v *Validator) ValidateCommand(command, commandType string) error {
 		return err
 	}
 
+	// Check local file paths and URL sources against the workspace sandbox
+	if err := v.validateFilePaths(command, commandType); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// IsReadOnlyCommand checks if a command only reads state and is safe to repeat
+func (v *Validator) IsReadOnlyCommand(command, commandType string) bool {
+	operation := v.extractOperationFromCommand(command, commandType)
+	if operation == "config" && v.isConfigWriteOperation(command) {
+		return false
+	}
+	subcommand := ExtractSubcommand(command, commandType)
+	if v.isOperationInList(subcommand, v.getWriteSubcommandsList(commandType)) {
+		return false
+	}
+	return v.isOperationInList(operation, v.getReadOperationsList(commandType)) ||
+		v.isOperationInList(subcommand, v.getReadSubcommandsList(commandType))
+}
+
+// getReadSubcommandsList returns the subcommands of write operations that don't modify state, as "operation subcommand"
+func (v *Validator) getReadSubcommandsList(commandType string) []string {
+	if commandType == CommandTypeKubectl {
+		return KubectlReadSubcommands
+	}
+	return []string{}
+}
+
+// getWriteSubcommandsList returns the subcommands of read operations that modify state, as "operation subcommand"
+func (v *Validator) getWriteSubcommandsList(commandType string) []string {
+	switch commandType {
+	case CommandTypeHelm:
+		return HelmWriteSubcommands
+	case CommandTypeCilium:
+		return CiliumWriteSubcommands
+	default:
+		return []string{}
+	}
+}
+
 // validateAccessLevel validates if a command is allowed based on the configured access level
 func (v *Validator) validateAccessLevel(command, commandType string) error {
 	readOperations := v.getReadOperationsList(commandType)
@@ -161,7 +225,8 @@ func (v *Validator) validateAccessLevel(command, commandType string) error {
 		if operation == "config" && v.isConfigWriteOperation(command) {
 			return &ValidationError{Message: "Error: Cannot execute config write operations in read-only mode"}
 		}
-		if !v.isOperationInList(operation, readOperations) {
+		// The same classification decides retries and caching, so a command allowed here is safe to repeat
+		if !v.IsReadOnlyCommand(command, commandType) {
 			return &ValidationError{Message: "Error: Cannot execute write or admin operations in read-only mode"}
 		}
 	case AccessLevelReadWrite:
@@ -227,6 +292,11 @@ func (v *Validator) isOperationInList(operation string, allowedOperations []stri
 
 // extractOperationFromCommand extracts the operation from a command
 func (v *Validator) extractOperationFromCommand(command, commandType string) string {
+	return ExtractOperation(command, commandType)
+}
+
+// ExtractOperation returns the first non-flag word of a command, skipping the command name itself
+func ExtractOperation(command, commandType string) string {
 	cmdParts := strings.Fields(command)
 	var operation string
 
@@ -243,20 +313,37 @@ func (v *Validator) extractOperationFromCommand(command, commandType string) str
 	return operation
 }
 
-// isConfigWriteOperation checks if a config command is a write operation
-func (v *Validator) isConfigWriteOperation(command string) bool {
-	// Extract config subcommand
-	cmdParts := strings.Fields(command)
-	if len(cmdParts) < 2 || cmdParts[0] != "config" {
-		return false
+// ExtractSubcommand returns the first two non-flag words of a command as "operation subcommand",
+// skipping the command name itself
+func ExtractSubcommand(command, commandType string) string {
+	var words []string
+	for _, part := range strings.Fields(command) {
+		if strings.HasPrefix(part, "-") || (len(words) == 0 && part == commandType) {
+			continue
+		}
+		words = append(words, part)
+		if len(words) == 2 {
+			break
+		}
 	}
+	return strings.Join(words, " ")
+}
+
+// kubectlConfigWriteSubcommands lists the kubectl config subcommands that modify the kubeconfig
+var kubectlConfigWriteSubcommands = []string{
+	"use-context", "use", "set-context", "set-cluster", "set-credentials", "delete-context", "delete-cluster",
+	"delete-user", "rename-context", "set", "unset",
+}
 
-	writeSubcommands := []string{
-		"use-context",
+// isConfigWriteOperation checks if a config command is a write operation, with or without the
+// kubectl command name
+func (v *Validator) isConfigWriteOperation(command string) bool {
+	operation, subcommand, found := strings.Cut(ExtractSubcommand(command, CommandTypeKubectl), " ")
+	if !found || operation != "config" {
+		return false
 	}
 
-	subcommand := cmdParts[1]
-	for _, writeOp := range writeSubcommands {
+	for _, writeOp := range kubectlConfigWriteSubcommands {
 		if subcommand == writeOp {
 			return true
 		}
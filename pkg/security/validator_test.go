@@ -38,6 +38,14 @@ func TestValidatorAccessLevels(t *testing.T) {
 		{"ReadOnly - config current-context", AccessLevelReadOnly, "config current-context", false, ""},
 		{"ReadOnly - config get-contexts", AccessLevelReadOnly, "config get-contexts", false, ""},
 		{"ReadOnly - config use-context", AccessLevelReadOnly, "config use-context mycontext", true, "config write operations in read-only mode"},
+		{"ReadOnly - kubectl config use-context", AccessLevelReadOnly, "kubectl config use-context mycontext", true, "config write operations in read-only mode"},
+		{"ReadOnly - config set-context", AccessLevelReadOnly, "kubectl config set-context --current --namespace=prod", true, "config write operations in read-only mode"},
+		{"ReadOnly - config set-credentials", AccessLevelReadOnly, "config set-credentials admin --token=secret", true, "config write operations in read-only mode"},
+		{"ReadOnly - config unset", AccessLevelReadOnly, "config unset contexts.prod", true, "config write operations in read-only mode"},
+		{"ReadOnly - kubectl config view", AccessLevelReadOnly, "kubectl config view --minify", false, ""},
+		{"ReadOnly - rollout status", AccessLevelReadOnly, "kubectl rollout status deployment/web -n shop", false, ""},
+		{"ReadOnly - rollout history", AccessLevelReadOnly, "rollout history deployment/web", false, ""},
+		{"ReadOnly - rollout undo", AccessLevelReadOnly, "kubectl rollout undo deployment/web", true, "write or admin operations in read-only mode"},
 
 		{"ReadWrite - config current-context", AccessLevelReadWrite, "config current-context", false, ""},
 		{"ReadWrite - config get-contexts", AccessLevelReadWrite, "config get-contexts", false, ""},
@@ -67,6 +75,42 @@ func TestValidatorAccessLevels(t *testing.T) {
 	}
 }
 
+func TestValidatorReadOnlySubcommands(t *testing.T) {
+	tests := []struct {
+		command     string
+		commandType string
+		shouldErr   bool
+	}{
+		{"helm repo list", CommandTypeHelm, false},
+		{"helm repo add bitnami https://charts.bitnami.com/bitnami", CommandTypeHelm, true},
+		{"helm repo update", CommandTypeHelm, true},
+		{"helm repo remove bitnami", CommandTypeHelm, true},
+		{"cilium connectivity test", CommandTypeCilium, true},
+		{"cilium config set enable-l7-proxy false", CommandTypeCilium, true},
+		{"cilium hubble enable", CommandTypeCilium, true},
+		{"cilium status", CommandTypeCilium, false},
+		{"cilium config view", CommandTypeCilium, false},
+	}
+
+	secConfig := NewSecurityConfig()
+	secConfig.AccessLevel = AccessLevelReadOnly
+	validator := NewValidator(secConfig)
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			err := validator.ValidateCommand(tt.command, tt.commandType)
+			if tt.shouldErr && (err == nil || !strings.Contains(err.Error(), "read-only mode")) {
+				t.Errorf("ValidateCommand() error = %v, want read-only denial", err)
+			} else if !tt.shouldErr && err != nil {
+				t.Errorf("ValidateCommand() unexpected error = %v", err)
+			}
+			// Access checks and retries use the same classification
+			if readOnly := validator.IsReadOnlyCommand(tt.command, tt.commandType); readOnly == tt.shouldErr {
+				t.Errorf("IsReadOnlyCommand() = %v, want %v", readOnly, !tt.shouldErr)
+			}
+		})
+	}
+}
+
 func TestValidatorNamespaceRestriction(t *testing.T) {
 	secConfig := NewSecurityConfig()
 	secConfig.SetAllowedNamespaces("allowed-ns,another-ns")
@@ -149,7 +193,7 @@ func TestReadOperationsValidation(t *testing.T) {
 		{"cilium status", CommandTypeCilium, false},
 		{"cilium endpoint list", CommandTypeCilium, false}, // "endpoint" is in CiliumReadOperations
 		{"cilium install", CommandTypeCilium, true},
-		{"cilium hubble enable", CommandTypeCilium, false},
+		{"cilium hubble enable", CommandTypeCilium, true}, // "hubble enable" is in CiliumWriteSubcommands
 		{"hubble status", CommandTypeHubble, false},
 		{"hubble observe", CommandTypeHubble, false},
 		{"hubble list nodes", CommandTypeHubble, false},
@@ -210,3 +254,46 @@ func TestValidateCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestIsReadOnlyCommand(t *testing.T) {
+	validator := NewValidator(NewSecurityConfig())
+
+	tests := []struct {
+		command     string
+		commandType string
+		want        bool
+	}{
+		{"kubectl get pods -n default", CommandTypeKubectl, true},
+		{"api-resources", CommandTypeKubectl, true},
+		{"config get-contexts", CommandTypeKubectl, true},
+		{"kubectl rollout status deployment/web", CommandTypeKubectl, true},
+		{"kubectl rollout restart deployment/web", CommandTypeKubectl, false},
+		{"config use-context prod", CommandTypeKubectl, false},
+		{"kubectl config use-context prod", CommandTypeKubectl, false},
+		{"kubectl config set-context --current --namespace=prod", CommandTypeKubectl, false},
+		{"kubectl config set-cluster prod --server=https://prod", CommandTypeKubectl, false},
+		{"kubectl config set-credentials admin --token=secret", CommandTypeKubectl, false},
+		{"kubectl config delete-context prod", CommandTypeKubectl, false},
+		{"kubectl config rename-context prod production", CommandTypeKubectl, false},
+		{"kubectl config set users.admin.token secret", CommandTypeKubectl, false},
+		{"kubectl config unset contexts.prod", CommandTypeKubectl, false},
+		{"kubectl config get-contexts", CommandTypeKubectl, true},
+		{"delete pod nginx", CommandTypeKubectl, false},
+		{"apply -f -", CommandTypeKubectl, false},
+		{"exec nginx -- ls", CommandTypeKubectl, false},
+		{"helm list -A", CommandTypeHelm, true},
+		{"helm upgrade app ./chart", CommandTypeHelm, false},
+		{"helm repo list", CommandTypeHelm, true},
+		{"helm repo add bitnami https://charts.bitnami.com/bitnami", CommandTypeHelm, false},
+		{"cilium status --wait", CommandTypeCilium, true},
+		{"cilium connectivity test", CommandTypeCilium, false},
+		{"cilium hubble enable --ui", CommandTypeCilium, false},
+		{"hubble observe --follow", CommandTypeHubble, true},
+	}
+
+	for _, tt := range tests {
+		if got := validator.IsReadOnlyCommand(tt.command, tt.commandType); got != tt.want {
+			t.Errorf("IsReadOnlyCommand(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package security
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/shlex"
+)
+
+// kubectlPathFlags lists kubectl flags whose values are local file paths. create --raw and replace --raw
+// read the request body from the path of -f. --template takes a template or the path of a template file,
+// inline templates resolve inside the sandbox like relative paths.
+var kubectlPathFlags = []string{
+	"-f", "--filename", "-k", "--kustomize", "--kubeconfig", "--from-env-file", "--patch-file",
+	"--certificate-authority", "--client-certificate", "--client-key", "--template", "--cache-dir",
+}
+
+// kubectlOutputFileFormats lists the kubectl output formats that read a template file, given as
+// -o <format>=<path>
+var kubectlOutputFileFormats = []string{"go-template-file", "jsonpath-file", "templatefile"}
+
+// kubectlKeyedPathFlags lists kubectl flags whose values are comma-separated paths or key=path pairs
+var kubectlKeyedPathFlags = []string{"--from-file"}
+
+// helmPathFlags lists helm flags whose values are local file paths
+var helmPathFlags = []string{
+	"-f", "--values", "--kubeconfig", "--cert-file", "--key-file", "--ca-file", "--keyring", "--kube-ca-file",
+	"--repository-config", "--registry-config", "--repository-cache",
+}
+
+// helmKeyedPathFlags lists helm flags whose values are comma-separated key=path pairs
+var helmKeyedPathFlags = []string{"--set-file"}
+
+// helmChartCommands lists the helm commands whose positional arguments may be local chart paths
+var helmChartCommands = map[string]bool{
+	"install": true, "upgrade": true, "template": true, "lint": true, "package": true, "show": true,
+	"inspect": true, "dependency": true, "dep": true, "verify": true, "push": true,
+}
+
+// kubectlValueFlags lists kubectl flags that take a separate value, used to find positional arguments
+var kubectlValueFlags = []string{
+	"-c", "--container", "-n", "--namespace", "--retries", "--context", "--cluster",
+	"--user", "-s", "--server", "--request-timeout", "--token", "--kubeconfig",
+}
+
+// helmValueFlags lists helm flags that take a separate value, used to find positional arguments
+var helmValueFlags = []string{
+	"-n", "--namespace", "--kube-context", "--kubeconfig", "--kube-apiserver", "--kube-token", "--kube-as-user",
+	"--kube-as-group", "--kube-ca-file", "--version", "-f", "--values", "--set", "--set-string", "--set-file",
+	"--set-json", "--set-literal", "--repo", "--username", "--password", "--timeout", "-o", "--output",
+	"--description", "--post-renderer", "--post-renderer-args", "--ca-file", "--cert-file", "--key-file",
+	"--keyring", "--registry-config", "--repository-cache", "--repository-config", "-d", "--destination",
+	"--app-version", "--max-history", "--history-max", "--revision", "--name-template", "-l", "--labels",
+	"--burst-limit", "--max", "--show-only", "-s",
+}
+
+// SetWorkspace sets the workspace directory that local file paths must resolve into.
+// An empty directory disables the workspace sandbox.
+func (s *SecurityConfig) SetWorkspace(dir string) error {
+	if dir == "" {
+		s.workspace = ""
+		return nil
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid workspace directory %s: %w", dir, err)
+	}
+	resolved, err := filepath.EvalSymlinks(absDir)
+	if err != nil {
+		return fmt.Errorf("invalid workspace directory %s: %w", dir, err)
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return fmt.Errorf("invalid workspace directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid workspace directory %s: not a directory", dir)
+	}
+
+	s.workspace = resolved
+	return nil
+}
+
+// Workspace returns the resolved workspace directory, or empty if the sandbox is disabled
+func (s *SecurityConfig) Workspace() string {
+	return s.workspace
+}
+
+// SetAllowedURLHosts sets the comma-separated list of hosts allowed for URL file sources
+func (s *SecurityConfig) SetAllowedURLHosts(hosts string) {
+	s.allowedURLHosts = []string{}
+	for _, host := range strings.Split(hosts, ",") {
+		host = strings.TrimSpace(host)
+		if host != "" {
+			s.allowedURLHosts = append(s.allowedURLHosts, strings.ToLower(host))
+		}
+	}
+}
+
+// isURLHostAllowed checks if a URL host is in the allowlist
+func (s *SecurityConfig) isURLHostAllowed(host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range s.allowedURLHosts {
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// validateFilePaths validates that local file paths in a command resolve inside the
+// sandbox of the session and that URL sources are allowlisted
+func (v *Validator) validateFilePaths(command, commandType string) error {
+	dirs, enabled := v.secConfig.SandboxDirs(v.sessionID)
+	if !enabled {
+		return nil
+	}
+
+	args, err := shlex.Split(command)
+	if err != nil {
+		return &ValidationError{Message: "Error: Cannot parse command arguments: " + err.Error()}
+	}
+	if len(args) > 0 && args[0] == commandType {
+		args = args[1:]
+	}
+
+	for _, path := range extractLocalPaths(args, commandType) {
+		if err := v.validatePath(path, dirs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractLocalPaths returns the values of file path flags, the template files of kubectl output formats,
+// the local positional arguments of kubectl cp and kubectl kustomize, and the local chart paths of helm commands
+func extractLocalPaths(args []string, commandType string) []string {
+	var pathFlags, keyedPathFlags []string
+	switch commandType {
+	case CommandTypeKubectl:
+		pathFlags, keyedPathFlags = kubectlPathFlags, kubectlKeyedPathFlags
+	case CommandTypeHelm:
+		pathFlags, keyedPathFlags = helmPathFlags, helmKeyedPathFlags
+	default:
+		return nil
+	}
+
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		paths = append(paths, flagValues(args, i, pathFlags)...)
+
+		// Keyed flags take paths or key=path pairs, e.g. --from-file=config=/etc/app.conf
+		for _, value := range flagValues(args, i, keyedPathFlags) {
+			for _, item := range strings.Split(value, ",") {
+				if _, path, found := strings.Cut(item, "="); found {
+					item = path
+				}
+				paths = append(paths, item)
+			}
+		}
+
+		// Template output formats read files, e.g. -o jsonpath-file=/etc/template
+		if commandType == CommandTypeKubectl {
+			for _, value := range flagValues(args, i, []string{"-o", "--output"}) {
+				format, path, found := strings.Cut(value, "=")
+				for _, fileFormat := range kubectlOutputFileFormats {
+					if found && format == fileFormat {
+						paths = append(paths, path)
+					}
+				}
+			}
+		}
+	}
+
+	switch commandType {
+	case CommandTypeKubectl:
+		positional := positionalArgs(args, kubectlValueFlags)
+		if len(positional) > 0 {
+			switch positional[0] {
+			case "cp":
+				// Remote paths have the form [namespace/]pod:path
+				for _, arg := range positional[1:] {
+					if !strings.Contains(arg, ":") {
+						paths = append(paths, arg)
+					}
+				}
+			case "kustomize":
+				paths = append(paths, positional[1:]...)
+			}
+		}
+	case CommandTypeHelm:
+		// Charts are local paths unless they are remote references such as oci:// URLs. Release and
+		// repository names resolve inside the sandbox like relative paths, so they are checked as well.
+		positional := positionalArgs(args, helmValueFlags)
+		if len(positional) > 0 && helmChartCommands[positional[0]] {
+			for _, arg := range positional[1:] {
+				if !strings.Contains(arg, "://") {
+					paths = append(paths, arg)
+				}
+			}
+		}
+	}
+
+	return paths
+}
+
+// flagValues returns the values of the argument at index i if it's one of flags, taking the value from
+// the next argument, after = or attached to a short flag
+func flagValues(args []string, i int, flags []string) []string {
+	arg := args[i]
+	var values []string
+	for _, flag := range flags {
+		switch {
+		case arg == flag && i+1 < len(args):
+			values = append(values, args[i+1])
+		case strings.HasPrefix(arg, flag+"="):
+			values = append(values, strings.TrimPrefix(arg, flag+"="))
+		case len(flag) == 2 && strings.HasPrefix(arg, flag) && len(arg) > 2 && !strings.HasPrefix(arg, "--"):
+			// Short flag with attached value, e.g. -fdeploy.yaml
+			values = append(values, arg[2:])
+		}
+	}
+	return values
+}
+
+// positionalArgs returns the arguments that are neither flags nor values of valueFlags
+func positionalArgs(args []string, valueFlags []string) []string {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, "-") {
+			for _, flag := range valueFlags {
+				if arg == flag {
+					i++
+					break
+				}
+			}
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	return positional
+}
+
+// validatePath checks a single local path or URL against the sandbox directories. Relative paths resolve
+// against the first directory, which is the working directory of commands.
+func (v *Validator) validatePath(path string, dirs []string) error {
+	// stdin is always allowed
+	if path == "-" || path == "" {
+		return nil
+	}
+
+	if strings.Contains(path, "://") {
+		u, err := url.Parse(path)
+		if err != nil {
+			return &ValidationError{Message: "Error: Invalid URL '" + path + "'"}
+		}
+		if !v.secConfig.isURLHostAllowed(u.Hostname()) {
+			return &ValidationError{
+				Message: "Error: URL source '" + path + "' is denied by security configuration, host '" + u.Hostname() + "' is not allowed",
+			}
+		}
+		return nil
+	}
+
+	sandbox := "the workspace directory"
+	if _, ok := v.secConfig.sessionRoots.get(v.sessionID); ok {
+		sandbox = "the roots of the client"
+	}
+	if len(dirs) == 0 {
+		reason := "the client has no local roots"
+		if v.secConfig.sessionRoots.Pending(v.sessionID) {
+			reason = "the roots of the client have not been received yet"
+		}
+		return &ValidationError{
+			Message: "Error: Path '" + path + "' is outside " + sandbox + " and is denied by security configuration, " + reason,
+		}
+	}
+
+	resolved, err := resolvePath(dirs[0], path)
+	if err != nil {
+		return &ValidationError{Message: "Error: Cannot resolve path '" + path + "': " + err.Error()}
+	}
+
+	for _, dir := range dirs {
+		if isWithin(dir, resolved) {
+			return nil
+		}
+	}
+	return &ValidationError{
+		Message: "Error: Path '" + path + "' is outside " + sandbox + " and is denied by security configuration",
+	}
+}
+
+// resolvePath resolves a path relative to a directory, following symlinks of the
+// longest existing prefix so paths that don't exist yet can still be checked
+func resolvePath(dir, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+
+	existing := path
+	var rest []string
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{resolved}, rest...)...), nil
+}
@@ -0,0 +1,242 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateFilePaths(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(workspace, "deploy.yaml"), []byte("kind: Pod"), 0600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(workspace, "escape")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	secConfig := NewSecurityConfig()
+	secConfig.AccessLevel = AccessLevelAdmin
+	if err := secConfig.SetWorkspace(workspace); err != nil {
+		t.Fatalf("SetWorkspace() unexpected error: %v", err)
+	}
+	secConfig.SetAllowedURLHosts("raw.githubusercontent.com")
+	validator := NewValidator(secConfig)
+
+	tests := []struct {
+		name        string
+		command     string
+		commandType string
+		shouldErr   bool
+		errContains string
+	}{
+		{"Relative file", "apply -f deploy.yaml", CommandTypeKubectl, false, ""},
+		{"Absolute file in workspace", "apply -f " + filepath.Join(workspace, "deploy.yaml"), CommandTypeKubectl, false, ""},
+		{"New file in workspace", "apply -f manifests/new.yaml", CommandTypeKubectl, false, ""},
+		{"Stdin", "apply -f -", CommandTypeKubectl, false, ""},
+		{"Absolute file outside", "apply -f /etc/passwd", CommandTypeKubectl, true, "outside the workspace"},
+		{"Parent traversal", "apply -f ../deploy.yaml", CommandTypeKubectl, true, "outside the workspace"},
+		{"Long flag with equals", "apply --filename=/etc/passwd", CommandTypeKubectl, true, "outside the workspace"},
+		{"Attached short flag", "apply -f/etc/passwd", CommandTypeKubectl, true, "outside the workspace"},
+		{"Symlink escape", "apply -f escape/secret.yaml", CommandTypeKubectl, true, "outside the workspace"},
+		{"Kustomize flag", "apply -k /srv/overlay", CommandTypeKubectl, true, "outside the workspace"},
+		{"Kustomize command", "kustomize /srv/overlay", CommandTypeKubectl, true, "outside the workspace"},
+		{"Kubeconfig flag", "get pods --kubeconfig /root/.kube/config", CommandTypeKubectl, true, "outside the workspace"},
+		{"Copy from pod inside", "cp default/pod:/tmp/log ./log", CommandTypeKubectl, false, ""},
+		{"Copy from pod outside", "cp pod:/tmp/config /home/mcp/.kube/config", CommandTypeKubectl, true, "outside the workspace"},
+		{"Copy with container flag", "cp /etc/shadow pod:/tmp/x -c app", CommandTypeKubectl, true, "outside the workspace"},
+		{"Allowed URL", "apply -f https://raw.githubusercontent.com/org/repo/main/pod.yaml", CommandTypeKubectl, false, ""},
+		{"Denied URL", "apply -f http://169.254.169.254/latest/meta-data", CommandTypeKubectl, true, "is not allowed"},
+		{"Helm values inside", "helm install app ./chart --values values.yaml", CommandTypeHelm, false, ""},
+		{"Helm values outside", "helm install app ./chart -f /etc/values.yaml", CommandTypeHelm, true, "outside the workspace"},
+		{"Helm set-file outside", "helm install app ./chart --set-file key=/etc/passwd", CommandTypeHelm, true, "outside the workspace"},
+		{"From file inside", "create configmap app --from-file=app.conf --from-file=key=deploy.yaml", CommandTypeKubectl, false, ""},
+		{"From file outside", "create secret generic x --from-file=/etc/shadow", CommandTypeKubectl, true, "outside the workspace"},
+		{"From file key outside", "create secret generic x --from-file shadow=/etc/shadow", CommandTypeKubectl, true, "outside the workspace"},
+		{"From file list outside", "create configmap x --from-file=deploy.yaml,/etc/hosts", CommandTypeKubectl, true, "outside the workspace"},
+		{"From env file outside", "create configmap x --from-env-file=/proc/self/environ", CommandTypeKubectl, true, "outside the workspace"},
+		{"Patch file outside", "patch deployment web --patch-file /etc/patch.yaml", CommandTypeKubectl, true, "outside the workspace"},
+		{"Raw source outside", "create --raw /api/v1/namespaces/default/configmaps -f /etc/shadow", CommandTypeKubectl, true, "outside the workspace"},
+		{"Raw source inside", "replace --raw /api/v1/namespaces/default/configmaps/x -f deploy.yaml", CommandTypeKubectl, false, ""},
+		{"Client key outside", "get pods --client-key=/root/.kube/key.pem", CommandTypeKubectl, true, "outside the workspace"},
+		{"Helm local chart inside", "helm upgrade app ./chart --wait", CommandTypeHelm, false, ""},
+		{"Helm repository chart", "helm install app bitnami/nginx -n web", CommandTypeHelm, false, ""},
+		{"Helm OCI chart", "helm install app oci://registry.example.com/charts/app --version 1.0.0", CommandTypeHelm, false, ""},
+		{"Helm local chart outside", "helm install app /etc/charts/app", CommandTypeHelm, true, "outside the workspace"},
+		{"Helm template chart outside", "helm template -n web app ../chart", CommandTypeHelm, true, "outside the workspace"},
+		{"Helm set-file list outside", "helm install app ./chart --set-file a=values.yaml,b=/etc/passwd", CommandTypeHelm, true, "outside the workspace"},
+		{"Helm cert file outside", "helm install app ./chart --repo https://charts.example.com --cert-file /etc/ssl/client.pem", CommandTypeHelm, true, "outside the workspace"},
+		{"Helm key file outside", "helm pull app --key-file=/etc/ssl/client.key", CommandTypeHelm, true, "outside the workspace"},
+		{"Helm CA file outside", "helm repo add charts https://charts.example.com --ca-file /etc/ssl/ca.pem", CommandTypeHelm, true, "outside the workspace"},
+		{"Helm keyring outside", "helm verify ./chart.tgz --keyring /root/.gnupg/pubring.gpg", CommandTypeHelm, true, "outside the workspace"},
+		{"Go template file outside", "get pods -o go-template-file=/etc/passwd", CommandTypeKubectl, true, "outside the workspace"},
+		{"JSONPath file outside", "get pods -o jsonpath-file=/etc/shadow", CommandTypeKubectl, true, "outside the workspace"},
+		{"Template file format outside", "get pods --output=templatefile=/etc/passwd", CommandTypeKubectl, true, "outside the workspace"},
+		{"Attached template file outside", "get pods -ojsonpath-file=/etc/shadow", CommandTypeKubectl, true, "outside the workspace"},
+		{"JSONPath file inside", "get pods -o jsonpath-file=templates/names.txt", CommandTypeKubectl, false, ""},
+		{"Inline JSONPath", "get pods -o jsonpath={.items[*].metadata.name}", CommandTypeKubectl, false, ""},
+		{"Template flag outside", "get pods -o go-template --template=/etc/passwd", CommandTypeKubectl, true, "outside the workspace"},
+		{"Inline template", "get pods -o go-template --template '{{range .items}}{{.metadata.name}}{{end}}'", CommandTypeKubectl, false, ""},
+		{"Cache dir outside", "get pods --cache-dir=/etc", CommandTypeKubectl, true, "outside the workspace"},
+		{"Helm repository config outside", "helm repo add x https://a --repository-config /root/.kube/config", CommandTypeHelm, true, "outside the workspace"},
+		{"Helm repository config equals outside", "helm repo add x https://a --repository-config=/root/.kube/config", CommandTypeHelm, true, "outside the workspace"},
+		{"Helm registry config outside", "helm pull oci://registry.example.com/app --registry-config /root/.docker/config.json", CommandTypeHelm, true, "outside the workspace"},
+		{"Helm repository cache outside", "helm repo update --repository-cache=/etc", CommandTypeHelm, true, "outside the workspace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.validateFilePaths(tt.command, tt.commandType)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				} else if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error to contain %q, got %q", tt.errContains, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateFilePathsWithoutWorkspace(t *testing.T) {
+	validator := NewValidator(NewSecurityConfig())
+
+	if err := validator.validateFilePaths("apply -f /etc/passwd", CommandTypeKubectl); err != nil {
+		t.Errorf("Expected no error when the workspace sandbox is disabled, got: %v", err)
+	}
+}
+
+func TestSetWorkspace(t *testing.T) {
+	secConfig := NewSecurityConfig()
+
+	if err := secConfig.SetWorkspace(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for missing workspace directory")
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := secConfig.SetWorkspace(file); err == nil {
+		t.Error("Expected error for workspace that is not a directory")
+	}
+}
+
+func TestValidateFilePathsWithRoots(t *testing.T) {
+	workspace, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to resolve workspace: %v", err)
+	}
+	first := filepath.Join(workspace, "first")
+	second := filepath.Join(workspace, "second")
+	for _, dir := range []string{first, second} {
+		if err := os.Mkdir(dir, 0700); err != nil {
+			t.Fatalf("Failed to create root: %v", err)
+		}
+	}
+	outside, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to resolve directory: %v", err)
+	}
+	file := filepath.Join(first, "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	secConfig := NewSecurityConfig()
+	secConfig.AccessLevel = AccessLevelAdmin
+	if err := secConfig.SetWorkspace(workspace); err != nil {
+		t.Fatalf("SetWorkspace() unexpected error: %v", err)
+	}
+	roots := NewSessionRoots()
+	secConfig.SetSessionRoots(roots)
+
+	dirs, errs := roots.Set("s1", []string{"file://" + first, "file://" + second, "https://example.com/repo", "file://" + file})
+	if len(dirs) != 2 || len(errs) != 2 {
+		t.Fatalf("Set() = %v, %v, want 2 roots and 2 errors", dirs, errs)
+	}
+	roots.Set("empty", nil)
+	roots.Set("outside", []string{"file://" + outside})
+	roots.Set("filesystem", []string{"file:///", "file://" + second})
+	roots.MarkPending("pending")
+	// Sessions that already sent roots keep them
+	roots.MarkPending("s1")
+
+	tests := []struct {
+		name        string
+		sessionID   string
+		command     string
+		shouldErr   bool
+		errContains string
+	}{
+		{"Relative file in first root", "s1", "apply -f deploy.yaml", false, ""},
+		{"File in second root", "s1", "apply -f " + filepath.Join(second, "deploy.yaml"), false, ""},
+		{"Helm values in root", "s1", "helm install app ./chart --values " + filepath.Join(second, "values.yaml"), false, ""},
+		{"Workspace of session with roots", "s1", "apply -f " + filepath.Join(workspace, "deploy.yaml"), true, "outside the roots of the client"},
+		{"Copy outside roots", "s1", "cp pod:/tmp/log /tmp/log", true, "outside the roots of the client"},
+		{"Session without usable roots", "empty", "apply -f deploy.yaml", true, "the client has no local roots"},
+		{"Root outside workspace is dropped", "outside", "apply -f " + filepath.Join(outside, "deploy.yaml"), true, "the client has no local roots"},
+		{"Filesystem root is narrowed to workspace", "filesystem", "apply -f " + filepath.Join(workspace, "deploy.yaml"), false, ""},
+		{"Filesystem root outside workspace", "filesystem", "apply -f /etc/passwd", true, "outside the roots of the client"},
+		{"Pending roots deny local paths", "pending", "apply -f " + filepath.Join(workspace, "deploy.yaml"), true, "have not been received yet"},
+		{"Pending roots allow stdin", "pending", "apply -f -", false, ""},
+		{"Session without roots uses workspace", "s2", "apply -f " + filepath.Join(workspace, "deploy.yaml"), false, ""},
+		{"Server without session uses workspace", "", "apply -f " + filepath.Join(outside, "deploy.yaml"), true, "outside the workspace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commandType := CommandTypeKubectl
+			if strings.HasPrefix(tt.command, "helm ") {
+				commandType = CommandTypeHelm
+			}
+			err := NewValidator(secConfig).ForSession(tt.sessionID).validateFilePaths(tt.command, commandType)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				} else if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error to contain %q, got %q", tt.errContains, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+
+	if dirs, _ := secConfig.SandboxDirs("filesystem"); len(dirs) != 2 || dirs[0] != workspace || dirs[1] != second {
+		t.Errorf("SandboxDirs() = %v, want the workspace and the second root", dirs)
+	}
+
+	roots.Remove("s1")
+	if dirs, ok := secConfig.SandboxDirs("s1"); !ok || len(dirs) != 1 || dirs[0] != workspace {
+		t.Errorf("SandboxDirs() after Remove() = %v, %v, want the workspace", dirs, ok)
+	}
+
+	roots.Set("pending", []string{"file://" + first})
+	if roots.Pending("pending") {
+		t.Error("Pending() = true after Set()")
+	}
+}
+
+func TestValidateFilePathsPendingRootsWithoutWorkspace(t *testing.T) {
+	secConfig := NewSecurityConfig()
+	secConfig.AccessLevel = AccessLevelAdmin
+	roots := NewSessionRoots()
+	secConfig.SetSessionRoots(roots)
+	validator := NewValidator(secConfig)
+
+	// Without a workspace, only sessions whose clients advertise roots are sandboxed
+	if err := validator.ForSession("s1").validateFilePaths("apply -f /etc/passwd", CommandTypeKubectl); err != nil {
+		t.Errorf("validateFilePaths() unexpected error = %v", err)
+	}
+	roots.MarkPending("s1")
+	err := validator.ForSession("s1").validateFilePaths("apply -f /etc/passwd", CommandTypeKubectl)
+	if err == nil || !strings.Contains(err.Error(), "have not been received yet") {
+		t.Errorf("validateFilePaths() error = %v, want denial while roots are pending", err)
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// errNoStream is returned for requests to sessions that have no stream to send them on
+var errNoStream = errors.New("the session has no open stream for server requests")
+
+// clientRequests sends requests of the server to the clients of sessions, like roots/list, and delivers
+// their responses. Requests are written to the stream of a session, which is stdout for stdio and the
+// event stream of GET requests for streamable HTTP.
+type clientRequests struct {
+	mu     sync.Mutex
+	nextID int64
+	// pending holds the requests waiting for a response by request ID
+	pending map[string]pendingRequest
+	// streams write a message to the client of a session
+	streams map[string]func(message []byte) error
+}
+
+// pendingRequest is a request waiting for the response of a client
+type pendingRequest struct {
+	sessionID string
+	response  chan rpcMessage
+}
+
+// rpcResponseError is the error of a JSON-RPC response
+type rpcResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// newClientRequests creates client requests without any streams
+func newClientRequests() *clientRequests {
+	return &clientRequests{
+		pending: make(map[string]pendingRequest),
+		streams: make(map[string]func(message []byte) error),
+	}
+}
+
+// attach sets the stream of a session
+func (c *clientRequests) attach(sessionID string, write func(message []byte) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streams[sessionID] = write
+}
+
+// detach removes the stream of a session and fails its pending requests
+func (c *clientRequests) detach(sessionID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.streams, sessionID)
+	for id, pending := range c.pending {
+		if pending.sessionID == sessionID {
+			close(pending.response)
+			delete(c.pending, id)
+		}
+	}
+}
+
+// connected reports whether requests can be sent to a session
+func (c *clientRequests) connected(sessionID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.streams[sessionID] != nil
+}
+
+// request sends a request to the client of a session and decodes the result of its response into result
+func (c *clientRequests) request(ctx context.Context, sessionID, method string, params any, result any) error {
+	c.mu.Lock()
+	write := c.streams[sessionID]
+	if write == nil {
+		c.mu.Unlock()
+		return errNoStream
+	}
+	c.nextID++
+	id := mcp.NewRequestId(fmt.Sprintf("mcp-kubernetes-%d", c.nextID))
+	response := make(chan rpcMessage, 1)
+	c.pending[id.String()] = pendingRequest{sessionID: sessionID, response: response}
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id.String())
+		c.mu.Unlock()
+	}()
+
+	message, err := json.Marshal(mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Request: mcp.Request{Method: method},
+		Params:  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+	if err := write(message); err != nil {
+		return fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%s request was not answered: %w", method, ctx.Err())
+	case msg, ok := <-response:
+		if !ok {
+			return fmt.Errorf("%s request was not answered: %w", method, errNoStream)
+		}
+		if msg.Error != nil {
+			return fmt.Errorf("client failed %s request: %s (code %d)", method, msg.Error.Message, msg.Error.Code)
+		}
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		return nil
+	}
+}
+
+// resolve delivers a response of a client, it returns false if the message doesn't answer a pending request
+func (c *clientRequests) resolve(msg rpcMessage) bool {
+	if msg.Method != "" || msg.ID == nil || (msg.Result == nil && msg.Error == nil) {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pending, ok := c.pending[msg.ID.String()]
+	if !ok {
+		return false
+	}
+	delete(c.pending, msg.ID.String())
+	pending.response <- msg
+	return true
+}
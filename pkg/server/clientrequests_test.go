@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// requestID returns the ID of a request sent to a client
+func requestID(t *testing.T, message []byte) *mcp.RequestId {
+	t.Helper()
+	var msg rpcMessage
+	if err := json.Unmarshal(message, &msg); err != nil || msg.ID == nil {
+		t.Fatalf("invalid request %q: %v", message, err)
+	}
+	return msg.ID
+}
+
+func TestClientRequests(t *testing.T) {
+	c := newClientRequests()
+	var result mcp.ListRootsResult
+	if err := c.request(context.Background(), "s1", methodRootsList, nil, &result); !errors.Is(err, errNoStream) {
+		t.Errorf("request() without stream error = %v, want %v", err, errNoStream)
+	}
+
+	sent := make(chan []byte, 1)
+	c.attach("s1", func(message []byte) error {
+		sent <- message
+		return nil
+	})
+	if !c.connected("s1") || c.connected("s2") {
+		t.Errorf("connected() is wrong for s1 or s2")
+	}
+
+	tests := []struct {
+		name     string
+		response func(id *mcp.RequestId) rpcMessage
+		wantErr  string
+		wantURI  string
+	}{
+		{
+			name: "result",
+			response: func(id *mcp.RequestId) rpcMessage {
+				return rpcMessage{ID: id, Result: json.RawMessage(`{"roots":[{"uri":"file:///src"}]}`)}
+			},
+			wantURI: "file:///src",
+		},
+		{
+			name: "error",
+			response: func(id *mcp.RequestId) rpcMessage {
+				return rpcMessage{ID: id, Error: &rpcResponseError{Code: mcp.METHOD_NOT_FOUND, Message: "not supported"}}
+			},
+			wantErr: "client failed roots/list request: not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result mcp.ListRootsResult
+			done := make(chan error, 1)
+			go func() { done <- c.request(context.Background(), "s1", methodRootsList, nil, &result) }()
+
+			message := <-sent
+			if !strings.Contains(string(message), `"method":"roots/list"`) {
+				t.Errorf("sent request = %q", message)
+			}
+			if !c.resolve(tt.response(requestID(t, message))) {
+				t.Fatal("resolve() = false for a pending request")
+			}
+			err := <-done
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("request() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || len(result.Roots) != 1 || result.Roots[0].URI != tt.wantURI {
+				t.Errorf("request() = %+v, %v", result, err)
+			}
+		})
+	}
+
+	// Responses to unknown requests are passed on, like the sampling responses of the MCP server
+	id := mcp.NewRequestId(int64(1))
+	if c.resolve(rpcMessage{ID: &id, Result: json.RawMessage(`{}`)}) {
+		t.Error("resolve() = true for an unknown request")
+	}
+
+	// Detaching fails pending requests, expired requests fail with the context
+	done := make(chan error, 1)
+	go func() { done <- c.request(context.Background(), "s1", methodRootsList, nil, &result) }()
+	<-sent
+	c.detach("s1")
+	if err := <-done; !errors.Is(err, errNoStream) {
+		t.Errorf("request() after detach() error = %v, want %v", err, errNoStream)
+	}
+	c.attach("s1", func(message []byte) error { return nil })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.request(ctx, "s1", methodRootsList, nil, &result); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("request() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Azure/mcp-kubernetes/pkg/completion"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// methodCompletionComplete completes arguments of prompts and resource templates, the MCP server doesn't
+// implement it
+const methodCompletionComplete = "completion/complete"
+
+// registerCompletions answers completion requests by listing cluster objects with an executor, and advertises
+// the completions capability
+func (s *Service) registerCompletions(executor tools.CommandExecutor) {
+	completer := completion.NewCompleter(executor, s.cfg)
+	s.extensions.handle(methodCompletionComplete, func(ctx context.Context, sessionID string, params json.RawMessage) (any, error) {
+		var req completion.Request
+		if err := json.Unmarshal(params, &req); err != nil {
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "invalid completion parameters: %v", err)
+		}
+		return completer.Complete(sessionID, req)
+	})
+	s.extensions.advertise("completions", struct{}{})
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// methodElicitationCreate asks the user of a client for input
+const methodElicitationCreate = "elicitation/create"
+
+// elicitationTimeout is how long the server waits for the user to choose
+const elicitationTimeout = 5 * time.Minute
+
+// elicitationChoice is the property of the requested schema holding the choice of the user
+const elicitationChoice = "choice"
+
+// elicit asks the user of the client of a session to choose one of several values. The request is sent on
+// the stream of the session like sampling requests, since it's made while a tool call is handled.
+func (s *Service) elicit(sessionID, message string, choices []string) (string, error) {
+	if !s.extensions.clientCapability(sessionID, "elicitation") || !s.extensions.clients.connected(sessionID) {
+		return "", tools.ErrElicitationUnavailable
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), elicitationTimeout)
+	defer cancel()
+
+	params := map[string]any{
+		"message": message,
+		"requestedSchema": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				elicitationChoice: map[string]any{
+					"type":  "string",
+					"title": "Choice",
+					"enum":  choices,
+				},
+			},
+			"required": []string{elicitationChoice},
+		},
+	}
+	var result struct {
+		Action  string            `json:"action"`
+		Content map[string]string `json:"content"`
+	}
+	if err := s.extensions.clients.request(ctx, sessionID, methodElicitationCreate, params, &result); err != nil {
+		return "", err
+	}
+	switch result.Action {
+	case "accept":
+		return result.Content[elicitationChoice], nil
+	case "decline", "cancel":
+		return "", tools.ErrElicitationDeclined
+	default:
+		return "", fmt.Errorf("the client returned the unknown elicitation action %q", result.Action)
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+func TestElicit(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities string
+		response     string
+		want         string
+		wantErr      error
+	}{
+		{
+			name:         "accepted",
+			capabilities: `{"elicitation":{}}`,
+			response:     `{"action":"accept","content":{"choice":"sidecar"}}`,
+			want:         "sidecar",
+		},
+		{
+			name:         "declined",
+			capabilities: `{"elicitation":{}}`,
+			response:     `{"action":"decline"}`,
+			wantErr:      tools.ErrElicitationDeclined,
+		},
+		{
+			name:         "cancelled",
+			capabilities: `{"elicitation":{}}`,
+			response:     `{"action":"cancel"}`,
+			wantErr:      tools.ErrElicitationDeclined,
+		},
+		{
+			name:         "client without elicitation",
+			capabilities: `{"sampling":{}}`,
+			wantErr:      tools.ErrElicitationUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{cfg: config.NewConfig(), extensions: newExtensions()}
+			s.extensions.recordClientCapabilities("s1", []byte(`{"params":{"capabilities":`+tt.capabilities+`}}`))
+			var requests []string
+			s.extensions.clients.attach("s1", func(message []byte) error {
+				requests = append(requests, string(message))
+				var msg rpcMessage
+				_ = json.Unmarshal(message, &msg)
+				go s.extensions.clients.resolve(rpcMessage{ID: msg.ID, Result: json.RawMessage(tt.response)})
+				return nil
+			})
+
+			got, err := s.elicit("s1", "Which container should logs use?", []string{"app", "sidecar"})
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("elicit() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+			if errors.Is(err, tools.ErrElicitationUnavailable) {
+				if len(requests) != 0 {
+					t.Errorf("sent requests to a client without elicitation: %v", requests)
+				}
+			} else if len(requests) != 1 || !strings.Contains(requests[0], `"method":"elicitation/create"`) ||
+				!strings.Contains(requests[0], `"enum":["app","sidecar"]`) {
+				t.Errorf("Unexpected requests %v", requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,479 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stdioSessionID is the ID of the only session of the stdio transport
+const stdioSessionID = "stdio"
+
+// methodNotificationInitialized is sent by clients when the initialization of a session is complete
+const methodNotificationInitialized = "notifications/initialized"
+
+// methodHandler answers a request of a session
+type methodHandler func(ctx context.Context, sessionID string, params json.RawMessage) (any, error)
+
+// extensions answers requests of methods that the MCP server doesn't implement, before the transport passes
+// them to the MCP server
+type extensions struct {
+	handlers map[string]methodHandler
+	// closed are called when a session ends
+	closed []func(sessionID string)
+	// ready are called when a session is initialized and when it opens a stream for server requests
+	ready []func(sessionID string)
+	// initialized are called when the client capabilities of a session are known, before its client can
+	// send requests
+	initialized []func(sessionID string)
+	// capabilities are added to the server capabilities of initialize results
+	capabilities map[string]any
+	// clientCapabilities holds the capabilities of the clients of sessions by session ID
+	clientCapabilities sync.Map
+	// clients sends requests to the clients of sessions
+	clients *clientRequests
+	// sessions tracks the sessions of the streamable HTTP transport
+	sessions *httpSessions
+}
+
+// rpcMessage is the part of a JSON-RPC message needed to route it
+type rpcMessage struct {
+	ID     *mcp.RequestId  `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	// Result and Error are set for responses of clients to requests of the server
+	Result json.RawMessage   `json:"result,omitempty"`
+	Error  *rpcResponseError `json:"error,omitempty"`
+}
+
+// newExtensions creates extensions without any methods
+func newExtensions() *extensions {
+	e := &extensions{
+		handlers:     make(map[string]methodHandler),
+		capabilities: make(map[string]any),
+		clients:      newClientRequests(),
+	}
+	e.sessions = newHTTPSessions(httpSessionIdleTimeout, e.sessionClosed)
+	return e
+}
+
+// advertise adds a capability to the initialize results of the MCP server
+func (e *extensions) advertise(name string, value any) {
+	e.capabilities[name] = value
+}
+
+// handle registers the handler of a method
+func (e *extensions) handle(method string, handler methodHandler) {
+	e.handlers[method] = handler
+}
+
+// onSessionClosed registers a function called when a session ends
+func (e *extensions) onSessionClosed(fn func(sessionID string)) {
+	e.closed = append(e.closed, fn)
+}
+
+// onSessionReady registers a function called when a session is initialized and when it opens a stream for
+// server requests, sessions may not be connected yet when it's called
+func (e *extensions) onSessionReady(fn func(sessionID string)) {
+	e.ready = append(e.ready, fn)
+}
+
+// onInitialize registers a function called when the client capabilities of a session are recorded, before
+// the initialize result reaches the client
+func (e *extensions) onInitialize(fn func(sessionID string)) {
+	e.initialized = append(e.initialized, fn)
+}
+
+// sessionReady tells the extensions that a session can receive server requests
+func (e *extensions) sessionReady(sessionID string) {
+	for _, fn := range e.ready {
+		fn(sessionID)
+	}
+}
+
+// sessionClosed tells the extensions that a session ended
+func (e *extensions) sessionClosed(sessionID string) {
+	e.clientCapabilities.Delete(sessionID)
+	for _, fn := range e.closed {
+		fn(sessionID)
+	}
+}
+
+// recordClientCapabilities stores the client capabilities of an initialize request of a session
+func (e *extensions) recordClientCapabilities(sessionID string, message []byte) {
+	var request struct {
+		Params struct {
+			Capabilities map[string]json.RawMessage `json:"capabilities"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil {
+		return
+	}
+	e.clientCapabilities.Store(sessionID, request.Params.Capabilities)
+	for _, fn := range e.initialized {
+		fn(sessionID)
+	}
+}
+
+// clientCapability reports whether the client of a session advertised a capability
+func (e *extensions) clientCapability(sessionID, name string) bool {
+	capabilities, ok := e.clientCapabilities.Load(sessionID)
+	if !ok {
+		return false
+	}
+	_, ok = capabilities.(map[string]json.RawMessage)[name]
+	return ok
+}
+
+// intercept answers a message of a session if it's a request of an extension method, and delivers responses
+// to requests of the server. The response is nil for notifications and responses, messages of other methods
+// are not handled.
+func (e *extensions) intercept(ctx context.Context, sessionID string, message []byte) (response []byte, handled bool) {
+	call, handled := e.route(sessionID, message)
+	if call == nil {
+		return nil, handled
+	}
+	return call(ctx), true
+}
+
+// route delivers responses to requests of the server and returns the call of the extension handler of a
+// message, which returns the response of the handler. The call is nil for responses and messages that are
+// not handled.
+func (e *extensions) route(sessionID string, message []byte) (call func(ctx context.Context) []byte, handled bool) {
+	var msg rpcMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, false
+	}
+	if e.clients.resolve(msg) {
+		return nil, true
+	}
+	if msg.Method == methodNotificationInitialized {
+		// The MCP server handles the notification as well
+		e.sessionReady(sessionID)
+		return nil, false
+	}
+	handler, ok := e.handlers[msg.Method]
+	if !ok {
+		return nil, false
+	}
+
+	return func(ctx context.Context) []byte {
+		result, err := handler(ctx, sessionID, msg.Params)
+		if msg.ID == nil {
+			return nil
+		}
+		var reply any = mcp.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: *msg.ID, Result: result}
+		if err != nil {
+			reply = rpcError(*msg.ID, err)
+		}
+		response, err := json.Marshal(reply)
+		if err != nil {
+			response, _ = json.Marshal(mcp.NewJSONRPCError(*msg.ID, mcp.INTERNAL_ERROR, err.Error(), nil))
+		}
+		return response
+	}, true
+}
+
+// initializeID returns the ID of an initialize request, false for other messages
+func initializeID(message []byte) (string, bool) {
+	var msg rpcMessage
+	if err := json.Unmarshal(message, &msg); err != nil || msg.Method != string(mcp.MethodInitialize) || msg.ID == nil {
+		return "", false
+	}
+	return msg.ID.String(), true
+}
+
+// addCapabilities adds the advertised capabilities to the result of an initialize response. Responses that
+// can't be decoded, like errors, are returned unchanged.
+func (e *extensions) addCapabilities(response []byte) []byte {
+	if len(e.capabilities) == 0 {
+		return response
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(response, &msg); err != nil || msg["result"] == nil {
+		return response
+	}
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(msg["result"], &result); err != nil {
+		return response
+	}
+	capabilities := make(map[string]any)
+	if raw, ok := result["capabilities"]; ok {
+		if err := json.Unmarshal(raw, &capabilities); err != nil {
+			return response
+		}
+	}
+	for name, value := range e.capabilities {
+		capabilities[name] = value
+	}
+
+	var err error
+	if result["capabilities"], err = json.Marshal(capabilities); err != nil {
+		return response
+	}
+	if msg["result"], err = json.Marshal(result); err != nil {
+		return response
+	}
+	rewritten, err := json.Marshal(msg)
+	if err != nil {
+		return response
+	}
+	return rewritten
+}
+
+// rpcError converts a handler error to a JSON-RPC error, errors with a code are invalid requests and carry the
+// code and hint as data
+func rpcError(id mcp.RequestId, err error) mcp.JSONRPCError {
+	code := toolerror.CodeOf(err)
+	if code == "" {
+		return mcp.NewJSONRPCError(id, mcp.INTERNAL_ERROR, err.Error(), nil)
+	}
+	return mcp.NewJSONRPCError(id, mcp.INVALID_PARAMS, err.Error(), map[string]any{
+		tools.ErrorCodeMetaKey: code,
+		tools.HintMetaKey:      toolerror.HintOf(err),
+	})
+}
+
+// listenStdio serves a stdio server, answering extension requests before they reach it. Responses of both are
+// written to stdout one line at a time.
+func (e *extensions) listenStdio(ctx context.Context, stdio *server.StdioServer, stdin io.Reader, stdout io.Writer) error {
+	// Responses to initialize requests advertise the capabilities of the extensions
+	var pendingMu sync.Mutex
+	pending := make(map[string]bool)
+	out := &lockedWriter{w: stdout, rewrite: func(p []byte) []byte {
+		pendingMu.Lock()
+		defer pendingMu.Unlock()
+		if len(pending) == 0 {
+			return p
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal(p, &msg); err != nil || msg.ID == nil || msg.Method != "" || !pending[msg.ID.String()] {
+			return p
+		}
+		delete(pending, msg.ID.String())
+		return append(e.addCapabilities(bytes.TrimSpace(p)), '\n')
+	}}
+	e.clients.attach(stdioSessionID, func(message []byte) error {
+		_, err := out.Write(append(message, '\n'))
+		return err
+	})
+	defer e.clients.detach(stdioSessionID)
+
+	// Responses of extension handlers are written before returning
+	var calls sync.WaitGroup
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(stdin)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				if call, handled := e.route(stdioSessionID, line); handled {
+					// Handlers may run commands, so they must not hold up the messages that follow
+					if call != nil {
+						calls.Add(1)
+						go func() {
+							defer calls.Done()
+							if response := call(ctx); response != nil {
+								_, _ = out.Write(append(response, '\n'))
+							}
+						}()
+					}
+				} else {
+					if id, ok := initializeID(line); ok {
+						e.recordClientCapabilities(stdioSessionID, line)
+						pendingMu.Lock()
+						pending[id] = true
+						pendingMu.Unlock()
+					}
+					if _, err := pipeWriter.Write(line); err != nil {
+						return
+					}
+				}
+			}
+			if err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	defer e.sessionClosed(stdioSessionID)
+	defer calls.Wait()
+	return stdio.Listen(ctx, pipeReader, out)
+}
+
+// httpHandler answers extension requests of streamable HTTP sessions and passes everything else to next.
+// Only sessions issued by e.sessions reach the extensions, requests of other sessions are left to next, which
+// validates them with the same session ID manager. The capabilities of the extensions are added to initialize
+// results, and the event streams of GET requests carry the requests of the server to the client of the session.
+func (e *extensions) httpHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Header.Get(server.HeaderKeySessionID)
+		switch {
+		case r.Method == http.MethodGet && sessionID != "":
+			if !e.sessions.streamOpened(sessionID) {
+				http.Error(w, "Session terminated", http.StatusNotFound)
+				return
+			}
+			defer e.sessions.streamClosed(sessionID)
+			stream := &eventStream{ResponseWriter: w, opened: func(stream *eventStream) {
+				e.clients.attach(sessionID, stream.send)
+				e.sessionReady(sessionID)
+			}}
+			defer e.clients.detach(sessionID)
+			defer stream.close()
+			next.ServeHTTP(stream, r)
+			return
+		case r.Method == http.MethodPost && sessionID == "":
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "failed to read request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			if _, ok := initializeID(body); ok {
+				recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+				next.ServeHTTP(recorder, r)
+				if id := w.Header().Get(server.HeaderKeySessionID); id != "" {
+					e.recordClientCapabilities(id, body)
+				}
+				response := recorder.body.Bytes()
+				if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+					response = e.addCapabilities(response)
+				}
+				w.Header().Del("Content-Length")
+				w.WriteHeader(recorder.status)
+				_, _ = w.Write(response)
+				return
+			}
+		case r.Method == http.MethodPost && e.sessions.touch(sessionID):
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "failed to read request body", http.StatusBadRequest)
+				return
+			}
+			if response, handled := e.intercept(r.Context(), sessionID, body); handled {
+				if response == nil {
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set(server.HeaderKeySessionID, sessionID)
+				_, _ = w.Write(response)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// responseRecorder buffers a response so it can be rewritten
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+// Write implements http.ResponseWriter
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	return r.body.Write(p)
+}
+
+// eventStream serializes the writes of the MCP server to the event stream of a GET request with the events
+// of server requests
+type eventStream struct {
+	http.ResponseWriter
+	// opened is called when the MCP server starts the stream
+	opened func(stream *eventStream)
+
+	mu   sync.Mutex
+	open bool
+}
+
+// WriteHeader implements http.ResponseWriter
+func (s *eventStream) WriteHeader(status int) {
+	s.mu.Lock()
+	s.ResponseWriter.WriteHeader(status)
+	s.open = status == http.StatusOK
+	open := s.open
+	s.mu.Unlock()
+	if open {
+		s.opened(s)
+	}
+}
+
+// Write implements http.ResponseWriter
+func (s *eventStream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher
+func (s *eventStream) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// send writes a message as an event, until the stream is closed
+func (s *eventStream) send(message []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.open {
+		return errNoStream
+	}
+	if _, err := fmt.Fprintf(s.ResponseWriter, "event: message\ndata: %s\n\n", message); err != nil {
+		return err
+	}
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// close stops sending events, the response writer can't be used after the request was served
+func (s *eventStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.open = false
+}
+
+// lockedWriter serializes writes of complete messages, optionally rewriting them
+type lockedWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	rewrite func([]byte) []byte
+}
+
+// Write implements io.Writer
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rewrite != nil {
+		if _, err := l.w.Write(l.rewrite(p)); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return l.w.Write(p)
+}
@@ -0,0 +1,371 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newTestExtensions creates extensions answering an echo method and recording closed sessions
+func newTestExtensions(closed *[]string) *extensions {
+	e := newExtensions()
+	e.handle("test/echo", func(ctx context.Context, sessionID string, params json.RawMessage) (any, error) {
+		var p struct {
+			Fail string `json:"fail"`
+		}
+		_ = json.Unmarshal(params, &p)
+		switch p.Fail {
+		case "coded":
+			return nil, toolerror.New(toolerror.PolicyDenied, "denied")
+		case "plain":
+			return nil, errors.New("broken")
+		}
+		return map[string]string{"session": sessionID}, nil
+	})
+	e.onSessionClosed(func(sessionID string) { *closed = append(*closed, sessionID) })
+	e.advertise("completions", struct{}{})
+	return e
+}
+
+func TestIntercept(t *testing.T) {
+	e := newTestExtensions(&[]string{})
+
+	tests := []struct {
+		name        string
+		message     string
+		wantHandled bool
+		want        string
+	}{
+		{name: "request", message: `{"jsonrpc":"2.0","id":1,"method":"test/echo"}`, wantHandled: true, want: `{"jsonrpc":"2.0","id":1,"result":{"session":"s1"}}`},
+		{name: "string id", message: `{"jsonrpc":"2.0","id":"a","method":"test/echo"}`, wantHandled: true, want: `{"jsonrpc":"2.0","id":"a","result":{"session":"s1"}}`},
+		{name: "notification", message: `{"jsonrpc":"2.0","method":"test/echo"}`, wantHandled: true},
+		{
+			name:        "coded error",
+			message:     `{"jsonrpc":"2.0","id":2,"method":"test/echo","params":{"fail":"coded"}}`,
+			wantHandled: true,
+			want:        `{"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"denied","data":{"errorCode":"PolicyDenied","hint":"` + toolerror.HintOf(toolerror.New(toolerror.PolicyDenied, "")) + `"}}}`,
+		},
+		{name: "plain error", message: `{"jsonrpc":"2.0","id":3,"method":"test/echo","params":{"fail":"plain"}}`, wantHandled: true, want: `{"jsonrpc":"2.0","id":3,"error":{"code":-32603,"message":"broken"}}`},
+		{name: "other method", message: `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`},
+		{name: "response", message: `{"jsonrpc":"2.0","id":5,"result":{}}`},
+		{name: "invalid json", message: `{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, handled := e.intercept(context.Background(), "s1", []byte(tt.message))
+			if handled != tt.wantHandled {
+				t.Fatalf("intercept() handled = %v, want %v", handled, tt.wantHandled)
+			}
+			if string(response) != tt.want {
+				t.Errorf("intercept() response = %s, want %s", response, tt.want)
+			}
+		})
+	}
+}
+
+func TestListenStdio(t *testing.T) {
+	var closed []string
+	e := newTestExtensions(&closed)
+	mcpServer := server.NewMCPServer("test", "1.0")
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"test/echo"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+	}, "\n") + "\n"
+	var output strings.Builder
+	if err := e.listenStdio(context.Background(), server.NewStdioServer(mcpServer), strings.NewReader(input), &output); err != nil {
+		t.Fatalf("listenStdio() unexpected error = %v", err)
+	}
+
+	responses := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("invalid output line %q: %v", line, err)
+		}
+		responses[string(msg.ID)] = string(msg.Result)
+	}
+	if !strings.Contains(responses["1"], `"completions":{}`) || responses["2"] != `{"session":"stdio"}` || responses["3"] != "{}" {
+		t.Errorf("Unexpected responses %v", responses)
+	}
+	if len(closed) != 1 || closed[0] != stdioSessionID {
+		t.Errorf("closed sessions = %v, want stdio", closed)
+	}
+}
+
+func TestListenStdioSlowHandler(t *testing.T) {
+	e := newTestExtensions(&[]string{})
+	release := make(chan struct{})
+	e.handle("test/slow", func(ctx context.Context, sessionID string, params json.RawMessage) (any, error) {
+		<-release
+		return map[string]string{}, nil
+	})
+
+	stdinReader, stdin := io.Pipe()
+	stdoutReader, stdout := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- e.listenStdio(context.Background(), server.NewStdioServer(server.NewMCPServer("test", "1.0")), stdinReader, stdout)
+	}()
+	output := bufio.NewScanner(stdoutReader)
+	next := func() string {
+		if !output.Scan() {
+			t.Fatal("no output")
+		}
+		var msg struct {
+			ID json.RawMessage `json:"id"`
+		}
+		_ = json.Unmarshal(output.Bytes(), &msg)
+		return string(msg.ID)
+	}
+
+	_, _ = io.WriteString(stdin, `{"jsonrpc":"2.0","id":1,"method":"test/slow"}`+"\n")
+	_, _ = io.WriteString(stdin, `{"jsonrpc":"2.0","id":2,"method":"test/echo"}`+"\n")
+	if id := next(); id != "2" {
+		t.Errorf("first response id = %s, want 2 while the slow handler runs", id)
+	}
+	close(release)
+	if id := next(); id != "1" {
+		t.Errorf("second response id = %s, want 1", id)
+	}
+
+	_ = stdin.Close()
+	if err := <-done; err != nil {
+		t.Errorf("listenStdio() unexpected error = %v", err)
+	}
+}
+
+func TestAddCapabilities(t *testing.T) {
+	e := newTestExtensions(&[]string{})
+
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{
+			name:     "merged",
+			response: `{"jsonrpc":"2.0","id":1,"result":{"capabilities":{"tools":{}},"protocolVersion":"2025-03-26"}}`,
+			want:     `{"id":1,"jsonrpc":"2.0","result":{"capabilities":{"completions":{},"tools":{}},"protocolVersion":"2025-03-26"}}`,
+		},
+		{
+			name:     "no capabilities",
+			response: `{"jsonrpc":"2.0","id":1,"result":{}}`,
+			want:     `{"id":1,"jsonrpc":"2.0","result":{"capabilities":{"completions":{}}}}`,
+		},
+		{
+			name:     "error",
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"x"}}`,
+			want:     `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"x"}}`,
+		},
+		{name: "invalid json", response: `{`, want: `{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(e.addCapabilities([]byte(tt.response))); got != tt.want {
+				t.Errorf("addCapabilities() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	var closed []string
+	e := newTestExtensions(&closed)
+	var passed []string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		passed = append(passed, r.Method+" "+string(body))
+		if strings.Contains(string(body), `"initialize"`) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Length", "64")
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"capabilities":{}}}`))
+		}
+	})
+	handler := e.httpHandler(next)
+
+	request := func(method, sessionID, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/mcp", strings.NewReader(body))
+		if sessionID != "" {
+			r.Header.Set(server.HeaderKeySessionID, sessionID)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	s1 := e.sessions.Generate()
+	w := request(http.MethodPost, s1, `{"jsonrpc":"2.0","id":1,"method":"test/echo"}`)
+	if w.Body.String() != `{"jsonrpc":"2.0","id":1,"result":{"session":"`+s1+`"}}` || w.Header().Get(server.HeaderKeySessionID) != s1 {
+		t.Errorf("echo response = %q, headers %v", w.Body.String(), w.Header())
+	}
+	if w := request(http.MethodPost, s1, `{"jsonrpc":"2.0","method":"test/echo"}`); w.Code != http.StatusAccepted {
+		t.Errorf("notification status = %d, want %d", w.Code, http.StatusAccepted)
+	}
+
+	// Other methods and requests without a session or of sessions the server didn't issue are passed on
+	// with their body
+	request(http.MethodPost, s1, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	request(http.MethodPost, "", `{"jsonrpc":"2.0","id":3,"method":"test/echo"}`)
+	request(http.MethodPost, "made-up", `{"jsonrpc":"2.0","id":4,"method":"test/echo"}`)
+	w = request(http.MethodPost, "", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`)
+	if w.Body.String() != `{"id":1,"jsonrpc":"2.0","result":{"capabilities":{"completions":{}}}}` || w.Header().Get("Content-Length") != "" {
+		t.Errorf("initialize response = %q, headers %v", w.Body.String(), w.Header())
+	}
+	request(http.MethodDelete, "made-up", "")
+	want := []string{
+		`POST {"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`POST {"jsonrpc":"2.0","id":3,"method":"test/echo"}`,
+		`POST {"jsonrpc":"2.0","id":4,"method":"test/echo"}`,
+		`POST {"jsonrpc":"2.0","id":1,"method":"initialize"}`,
+		"DELETE ",
+	}
+	if strings.Join(passed, "\n") != strings.Join(want, "\n") {
+		t.Errorf("passed requests = %q, want %q", passed, want)
+	}
+
+	// Event streams of unknown sessions are refused
+	if w := request(http.MethodGet, "made-up", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET status of unknown session = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if len(closed) != 0 {
+		t.Errorf("closed sessions = %v, want none", closed)
+	}
+}
+
+func TestHTTPHandlerWithStreamableServer(t *testing.T) {
+	var closed []string
+	e := newTestExtensions(&closed)
+	mcpServer := server.NewMCPServer("test", "1.0")
+	httpServer := httptest.NewServer(e.httpHandler(server.NewStreamableHTTPServer(mcpServer, server.WithSessionIdManager(e.sessions))))
+	defer httpServer.Close()
+
+	post := func(sessionID, body string) *http.Response {
+		r, _ := http.NewRequest(http.MethodPost, httpServer.URL, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			r.Header.Set(server.HeaderKeySessionID, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	sessionID := resp.Header.Get(server.HeaderKeySessionID)
+	if !e.sessions.touch(sessionID) {
+		t.Fatalf("session %q of initialize response was not issued by the extensions", sessionID)
+	}
+	if resp := post(sessionID, `{"jsonrpc":"2.0","id":2,"method":"test/echo"}`); resp.StatusCode != http.StatusOK {
+		t.Errorf("echo status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if resp := post("mcp-session-00000000-0000-0000-0000-000000000000", `{"jsonrpc":"2.0","id":3,"method":"test/echo"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status of made-up session = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+
+	r, _ := http.NewRequest(http.MethodDelete, httpServer.URL, nil)
+	r.Header.Set(server.HeaderKeySessionID, sessionID)
+	if resp, err := http.DefaultClient.Do(r); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("DELETE = %v, %v", resp, err)
+	}
+	if len(closed) != 1 || closed[0] != sessionID {
+		t.Errorf("closed sessions = %v, want %s", closed, sessionID)
+	}
+	if resp := post(sessionID, `{"jsonrpc":"2.0","id":4,"method":"test/echo"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status of deleted session = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestHTTPEventStream(t *testing.T) {
+	e := newTestExtensions(&[]string{})
+	ready := make(chan string, 1)
+	e.onSessionReady(func(sessionID string) { ready <- sessionID })
+	// next streams events like the MCP server until the client disconnects
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	httpServer := httptest.NewServer(e.httpHandler(next))
+	defer httpServer.Close()
+
+	s1 := e.sessions.Generate()
+	ctx, cancel := context.WithCancel(context.Background())
+	r, _ := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL, nil)
+	r.Header.Set(server.HeaderKeySessionID, s1)
+	stream, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer stream.Body.Close()
+	if sessionID := <-ready; sessionID != s1 {
+		t.Errorf("ready session = %q, want %s", sessionID, s1)
+	}
+
+	var result mcp.ListRootsResult
+	done := make(chan error, 1)
+	go func() { done <- e.clients.request(context.Background(), s1, methodRootsList, nil, &result) }()
+
+	// The request is sent as event of the stream, and answered with a POST
+	events := bufio.NewScanner(stream.Body)
+	var message string
+	for events.Scan() {
+		if data, ok := strings.CutPrefix(events.Text(), "data: "); ok {
+			message = data
+			break
+		}
+	}
+	var msg rpcMessage
+	if err := json.Unmarshal([]byte(message), &msg); err != nil || msg.Method != methodRootsList {
+		t.Fatalf("unexpected event %q: %v", message, err)
+	}
+	id, _ := json.Marshal(msg.ID)
+	response, _ := http.NewRequest(http.MethodPost, httpServer.URL, strings.NewReader(`{"jsonrpc":"2.0","id":`+string(id)+`,"result":{"roots":[{"uri":"file:///src"}]}}`))
+	response.Header.Set(server.HeaderKeySessionID, s1)
+	answer, err := http.DefaultClient.Do(response)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	answer.Body.Close()
+	if answer.StatusCode != http.StatusAccepted {
+		t.Errorf("response status = %d, want %d", answer.StatusCode, http.StatusAccepted)
+	}
+	if err := <-done; err != nil || len(result.Roots) != 1 {
+		t.Errorf("request() = %+v, %v", result, err)
+	}
+
+	// Closing the stream detaches it
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for e.clients.connected(s1) {
+		if time.Now().After(deadline) {
+			t.Fatal("stream of s1 is still attached")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
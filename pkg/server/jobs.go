@@ -0,0 +1,39 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Azure/mcp-kubernetes/pkg/jobs"
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+)
+
+// registerJobs registers the job tools and the middleware running long calls as background jobs, if jobs are
+// enabled. Jobs of closed sessions keep running until a new session of the client adopts them.
+func (s *Service) registerJobs() error {
+	if !s.cfg.BackgroundJobs {
+		return nil
+	}
+	dir := s.cfg.JobsDir
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			logging.Logger().Warn("keeping background jobs in memory only, the user cache directory is unknown", "error", err)
+		} else {
+			dir = filepath.Join(cacheDir, "mcp-kubernetes", "jobs")
+		}
+	}
+	manager, err := jobs.NewManager(dir)
+	if err != nil {
+		return err
+	}
+	s.extensions.onSessionClosed(manager.CloseSession)
+
+	executor := jobs.NewExecutor(manager)
+	for _, tool := range jobs.RegisterJobTools() {
+		s.addTool(tool, tools.Chain(tools.NewHandler(executor, s.cfg), tools.InjectToolName(tool.Name)))
+	}
+	s.jobs = manager.Middleware()
+	return nil
+}
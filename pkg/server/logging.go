@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// loggerName is the logger of the log messages sent to clients
+const loggerName = "mcp-kubernetes"
+
+// sessionLogs forwards log events to sessions as notifications/message, at the level each session set with
+// logging/setLevel
+type sessionLogs struct {
+	send func(sessionID string, notification mcp.LoggingMessageNotification) error
+	// sessions holds the IDs of the registered sessions, which receive the events of the server
+	sessions sync.Map
+}
+
+// registerLogging tracks the sessions of the MCP server with hooks and forwards log events to them
+func (s *Service) registerLogging(hooks *server.Hooks) {
+	logs := &sessionLogs{send: func(sessionID string, notification mcp.LoggingMessageNotification) error {
+		return s.mcpServer.SendLogMessageToSpecificClient(sessionID, notification)
+	}}
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		logs.sessions.Store(session.SessionID(), true)
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		logs.sessions.Delete(session.SessionID())
+	})
+	logging.AddSink(logs.forward)
+}
+
+// forward sends an event to its session, or to all sessions if it's an event of the server. Sessions that
+// can't receive it are skipped, logging their errors would log again.
+func (l *sessionLogs) forward(record logging.Record) {
+	data := map[string]any{"message": record.Message}
+	for key, value := range record.Attrs {
+		data[key] = value
+	}
+	notification := mcp.NewLoggingMessageNotification(loggingLevel(record.Level), loggerName, data)
+
+	if record.SessionID != "" {
+		_ = l.send(record.SessionID, notification)
+		return
+	}
+	l.sessions.Range(func(sessionID, _ any) bool {
+		_ = l.send(sessionID.(string), notification)
+		return true
+	})
+}
+
+// loggingLevel converts the level of an event to an MCP logging level
+func loggingLevel(level slog.Level) mcp.LoggingLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return mcp.LoggingLevelDebug
+	case level < slog.LevelWarn:
+		return mcp.LoggingLevelInfo
+	case level < slog.LevelError:
+		return mcp.LoggingLevelWarning
+	default:
+		return mcp.LoggingLevelError
+	}
+}
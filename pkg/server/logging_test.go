@@ -0,0 +1,49 @@
+package server
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSessionLogs(t *testing.T) {
+	var sent []string
+	logs := &sessionLogs{send: func(sessionID string, notification mcp.LoggingMessageNotification) error {
+		data := notification.Params.Data.(map[string]any)
+		sent = append(sent, sessionID+" "+string(notification.Params.Level)+" "+data["message"].(string)+" "+data["command"].(string))
+		return nil
+	}}
+	logs.sessions.Store("s1", true)
+	logs.sessions.Store("s2", true)
+
+	logs.forward(logging.Record{Level: slog.LevelWarn, Message: "retrying", SessionID: "s1", Attrs: map[string]any{"command": "kubectl get pods"}})
+	logs.forward(logging.Record{Level: slog.LevelError, Message: "export failed", Attrs: map[string]any{"command": ""}})
+	sort.Strings(sent)
+
+	want := []string{"s1 error export failed ", "s1 warning retrying kubectl get pods", "s2 error export failed "}
+	if strings.Join(sent, "\n") != strings.Join(want, "\n") {
+		t.Errorf("sent notifications = %q, want %q", sent, want)
+	}
+}
+
+func TestLoggingLevel(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  mcp.LoggingLevel
+	}{
+		{slog.LevelDebug, mcp.LoggingLevelDebug},
+		{slog.LevelInfo, mcp.LoggingLevelInfo},
+		{slog.LevelWarn, mcp.LoggingLevelWarning},
+		{slog.LevelError, mcp.LoggingLevelError},
+		{slog.LevelError + 4, mcp.LoggingLevelError},
+	}
+	for _, tt := range tests {
+		if got := loggingLevel(tt.level); got != tt.want {
+			t.Errorf("loggingLevel(%s) = %s, want %s", tt.level, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Methods of client roots, which the MCP server doesn't implement
+const (
+	methodRootsList                = "roots/list"
+	methodNotificationRootsChanged = "notifications/roots/list_changed"
+)
+
+// rootsTimeout is how long the server waits for the roots of a client
+const rootsTimeout = 10 * time.Second
+
+// registerRoots lists the roots of clients that advertise the roots capability when their sessions start and
+// when their roots change. Local file paths of the commands of those sessions must resolve into their roots,
+// confined to the workspace if one is set, and are denied until the first list of roots is received.
+func (s *Service) registerRoots() {
+	if s.cfg.SecurityConfig == nil {
+		return
+	}
+	roots := security.NewSessionRoots()
+	s.cfg.SecurityConfig.SetSessionRoots(roots)
+
+	s.extensions.onInitialize(func(sessionID string) {
+		if s.extensions.clientCapability(sessionID, "roots") {
+			roots.MarkPending(sessionID)
+		}
+	})
+
+	refresh := func(sessionID string) {
+		if !s.extensions.clientCapability(sessionID, "roots") || !s.extensions.clients.connected(sessionID) {
+			return
+		}
+		go s.listRoots(roots, sessionID)
+	}
+	s.extensions.onSessionReady(refresh)
+	s.extensions.handle(methodNotificationRootsChanged, func(ctx context.Context, sessionID string, params json.RawMessage) (any, error) {
+		refresh(sessionID)
+		return nil, nil
+	})
+	s.extensions.onSessionClosed(roots.Remove)
+}
+
+// listRoots requests the roots of the client of a session and sets them. The previous roots stay in effect if
+// the client doesn't answer, local file paths stay denied if it never answered.
+func (s *Service) listRoots(roots *security.SessionRoots, sessionID string) {
+	logger := logging.ForSession(sessionID)
+	ctx, cancel := context.WithTimeout(context.Background(), rootsTimeout)
+	defer cancel()
+
+	var result mcp.ListRootsResult
+	if err := s.extensions.clients.request(ctx, sessionID, methodRootsList, nil, &result); err != nil {
+		logger.Warn("listing client roots failed", "error", err)
+		return
+	}
+	uris := make([]string, 0, len(result.Roots))
+	for _, root := range result.Roots {
+		uris = append(uris, root.URI)
+	}
+	_, errs := roots.Set(sessionID, uris)
+	for _, err := range errs {
+		logger.Warn("ignoring client root", "error", err)
+	}
+	// Roots are confined to the workspace, which may drop some of them
+	dirs, _ := s.cfg.SecurityConfig.SandboxDirs(sessionID)
+	logger.Info("local file paths are restricted to client roots", "roots", strings.Join(dirs, ","))
+}
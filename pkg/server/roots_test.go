@@ -0,0 +1,118 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/security"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestRoots(t *testing.T) {
+	// Roots are confined to the workspace
+	workspace, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve workspace: %v", err)
+	}
+	first := filepath.Join(workspace, "first")
+	second := filepath.Join(workspace, "second")
+	for _, dir := range []string{first, second} {
+		if err := os.Mkdir(dir, 0700); err != nil {
+			t.Fatalf("failed to create root: %v", err)
+		}
+	}
+	secConfig := security.NewSecurityConfig()
+	if err := secConfig.SetWorkspace(workspace); err != nil {
+		t.Fatalf("SetWorkspace() unexpected error: %v", err)
+	}
+	s := &Service{cfg: &config.ConfigData{SecurityConfig: secConfig}, extensions: newExtensions()}
+	s.registerRoots()
+
+	stdinReader, stdin := io.Pipe()
+	stdoutReader, stdout := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = s.extensions.listenStdio(ctx, server.NewStdioServer(server.NewMCPServer("test", "1.0")), stdinReader, stdout)
+	}()
+	output := bufio.NewScanner(stdoutReader)
+	send := func(message string) {
+		if _, err := io.WriteString(stdin, message+"\n"); err != nil {
+			t.Fatalf("failed to write %q: %v", message, err)
+		}
+	}
+	// answerRoots reads the next roots/list request and answers it with the roots of dirs
+	answerRoots := func(dirs ...string) {
+		for output.Scan() {
+			var msg rpcMessage
+			if err := json.Unmarshal(output.Bytes(), &msg); err != nil || msg.Method != methodRootsList {
+				continue
+			}
+			id, _ := json.Marshal(msg.ID)
+			var roots []string
+			for _, dir := range dirs {
+				roots = append(roots, fmt.Sprintf(`{"uri":"file://%s"}`, dir))
+			}
+			send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"roots":[%s]}}`, id, strings.Join(roots, ",")))
+			return
+		}
+		t.Fatal("no roots/list request")
+	}
+	// waitForSandbox waits until the sandbox of the stdio session is dirs
+	waitForSandbox := func(dirs ...string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			got, _ := secConfig.SandboxDirs(stdioSessionID)
+			if strings.Join(got, ",") == strings.Join(dirs, ",") {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("SandboxDirs() = %v, want %v", got, dirs)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitForSandbox(workspace)
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"roots":{"listChanged":true}},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	// Local paths are denied until the client sent its roots
+	waitForSandbox()
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	answerRoots(first)
+	waitForSandbox(first)
+
+	send(`{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}`)
+	answerRoots(first, second)
+	waitForSandbox(first, second)
+
+	_ = stdin.Close()
+	waitForSandbox(workspace)
+}
+
+func TestRootsWithoutCapability(t *testing.T) {
+	s := &Service{cfg: &config.ConfigData{SecurityConfig: security.NewSecurityConfig()}, extensions: newExtensions()}
+	s.registerRoots()
+	var sent []string
+	s.extensions.clients.attach("s1", func(message []byte) error {
+		sent = append(sent, string(message))
+		return nil
+	})
+	s.extensions.recordClientCapabilities("s1", []byte(`{"params":{"capabilities":{"sampling":{}}}}`))
+
+	s.extensions.sessionReady("s1")
+	if _, handled := s.extensions.intercept(context.Background(), "s1", []byte(`{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}`)); !handled {
+		t.Error("roots/list_changed notification was not handled")
+	}
+	if len(sent) != 0 {
+		t.Errorf("sent requests to a client without roots: %v", sent)
+	}
+}
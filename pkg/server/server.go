@@ -1,16 +1,27 @@
 package server
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/Azure/mcp-kubernetes/pkg/cilium"
 	"github.com/Azure/mcp-kubernetes/pkg/config"
 	"github.com/Azure/mcp-kubernetes/pkg/helm"
 	"github.com/Azure/mcp-kubernetes/pkg/hubble"
+	"github.com/Azure/mcp-kubernetes/pkg/jobs"
 	"github.com/Azure/mcp-kubernetes/pkg/kubectl"
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/native"
+	"github.com/Azure/mcp-kubernetes/pkg/prompts"
+	"github.com/Azure/mcp-kubernetes/pkg/resources"
 	"github.com/Azure/mcp-kubernetes/pkg/tools"
 	"github.com/Azure/mcp-kubernetes/pkg/version"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
@@ -18,86 +29,229 @@ import (
 type Service struct {
 	cfg       *config.ConfigData
 	mcpServer *server.MCPServer
+	// extensions answer the MCP methods that mcpServer doesn't implement
+	extensions *extensions
+	// middlewares registered with Use
+	middlewares []tools.Middleware
+	// toolNames holds the names of the registered tools
+	toolNames map[string]bool
+	// jobs runs long calls of the tools supporting jobs in the background, nil if jobs are disabled
+	jobs tools.Middleware
 }
 
 // NewService creates a new MCP Kubernetes service
 func NewService(cfg *config.ConfigData) *Service {
 	return &Service{
-		cfg: cfg,
+		cfg:       cfg,
+		toolNames: make(map[string]bool),
 	}
 }
 
+// Use registers middlewares wrapping the handlers of all tools. They run in the given order, after the
+// built-in recovery, logging, tracing and metrics middlewares and before argument validation. Use must be
+// called before Initialize. tools.Timeout is not built in, since commands already run with the timeouts of
+// their operations, and can be registered here to also bound whole tool calls.
+func (s *Service) Use(middlewares ...tools.Middleware) {
+	s.middlewares = append(s.middlewares, middlewares...)
+}
+
 // Initialize initializes the service
 func (s *Service) Initialize() error {
 	// Initialize configuration
 
-	// Create MCP server
+	// Create MCP server, forwarding log events to its sessions
+	hooks := &server.Hooks{}
+	s.registerLogging(hooks)
+	// The SSE transport doesn't pass requests to the extensions, so its sessions can't subscribe to
+	// resources. They end when the client disconnects.
+	sse := s.cfg.Transport == "sse"
+	if sse {
+		hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+			s.extensions.sessionClosed(session.SessionID())
+		})
+	}
 	s.mcpServer = server.NewMCPServer(
 		"MCP Kubernetes",
 		version.GetVersion(),
-		server.WithResourceCapabilities(true, true),
+		server.WithResourceCapabilities(!sse, true),
+		server.WithPromptCapabilities(false),
 		server.WithLogging(),
 		server.WithRecovery(),
+		server.WithHooks(hooks),
 	)
+	s.extensions = newExtensions()
+	s.registerRoots()
+	if err := s.registerJobs(); err != nil {
+		return err
+	}
 
 	// Register individual kubectl commands based on permission level
-	s.registerKubectlCommands()
+	if err := s.registerKubectlCommands(); err != nil {
+		return err
+	}
 
 	// Register additional tools
 	if s.cfg.AdditionalTools["helm"] {
-		helmTool := helm.RegisterHelm()
-		s.mcpServer.AddTool(helmTool, tools.CreateToolHandler(helm.NewExecutor(), s.cfg))
+		if s.cfg.SplitTools {
+			s.addTool(helm.RegisterHelmReadOnly(), tools.NewHandler(helm.NewReadOnlyExecutor(), s.cfg))
+			s.addTool(helm.RegisterHelmWrite(), tools.NewHandler(helm.NewExecutor(), s.cfg))
+		} else {
+			s.addTool(helm.RegisterHelm(), tools.NewHandler(helm.NewExecutor(), s.cfg))
+		}
 	}
 
 	if s.cfg.AdditionalTools["cilium"] {
-		ciliumTool := cilium.RegisterCilium()
-		s.mcpServer.AddTool(ciliumTool, tools.CreateToolHandler(cilium.NewExecutor(), s.cfg))
+		if s.cfg.SplitTools {
+			s.addTool(cilium.RegisterCiliumReadOnly(), tools.NewHandler(cilium.NewReadOnlyExecutor(), s.cfg))
+			s.addTool(cilium.RegisterCiliumWrite(), tools.NewHandler(cilium.NewExecutor(), s.cfg))
+		} else {
+			s.addTool(cilium.RegisterCilium(), tools.NewHandler(cilium.NewExecutor(), s.cfg))
+		}
 	}
 
 	if s.cfg.AdditionalTools["hubble"] {
-		hubbleTool := hubble.RegisterHubble()
-		s.mcpServer.AddTool(hubbleTool, tools.CreateToolHandler(hubble.NewExecutor(), s.cfg))
+		s.addTool(hubble.RegisterHubble(), tools.NewHandler(hubble.NewExecutor(), s.cfg))
 	}
 
-	return nil
+	return s.registerPrompts()
 }
 
 // Run starts the service with the specified transport
 func (s *Service) Run() error {
-	log.Println("MCP Kubernetes version:", version.GetVersion())
+	logging.Logger().Info("MCP Kubernetes started", "version", version.GetVersion())
 
 	// Start the server
 	switch s.cfg.Transport {
 	case "stdio":
-		log.Println("Listening for requests on STDIO...")
-		return server.ServeStdio(s.mcpServer)
+		logging.Logger().Info("listening for requests on STDIO")
+		return s.serveStdio()
 	case "sse":
 		sse := server.NewSSEServer(s.mcpServer)
 		addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
-		log.Printf("SSE server listening on %s", addr)
+		logging.Logger().Info("SSE server listening", "address", addr)
 		return sse.Start(addr)
 	case "streamable-http":
-		streamableServer := server.NewStreamableHTTPServer(s.mcpServer)
+		go s.extensions.sessions.run(context.Background())
+		mux := http.NewServeMux()
+		mux.Handle("/mcp", s.extensions.httpHandler(server.NewStreamableHTTPServer(s.mcpServer, server.WithSessionIdManager(s.extensions.sessions))))
 		addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
-		log.Printf("Streamable HTTP server listening on %s", addr)
-		return streamableServer.Start(addr)
+		logging.Logger().Info("streamable HTTP server listening", "address", addr)
+		return (&http.Server{Addr: addr, Handler: mux}).ListenAndServe()
 	default:
 		return fmt.Errorf("invalid transport type: %s (must be 'stdio', 'sse' or 'streamable-http')", s.cfg.Transport)
 	}
 }
 
+// serveStdio serves the MCP server on stdin and stdout until they are closed or the process is stopped
+func (s *Service) serveStdio() error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+	return s.extensions.listenStdio(ctx, server.NewStdioServer(s.mcpServer), os.Stdin, os.Stdout)
+}
+
 // registerKubectlCommands registers kubectl tools based on access level
-func (s *Service) registerKubectlCommands() {
-	// Get kubectl tools filtered by access level
+func (s *Service) registerKubectlCommands() error {
+	// Get kubectl tools filtered by access level, split into read and write variants if requested
 	kubectlTools := kubectl.RegisterKubectlTools(s.cfg.AccessLevel)
+	if s.cfg.SplitTools {
+		kubectlTools = kubectl.RegisterSplitKubectlTools(s.cfg.AccessLevel)
+	}
 
-	// Create a kubectl executor
+	// Create a kubectl executor, the native backend runs supported operations with client-go
 	kubectlExecutor := kubectl.NewKubectlToolExecutor()
+	// Subscriptions watch with the native backend, and with kubectl unless the cluster is simulated
+	var watcher resources.ObjectWatcher
+	fallback := true
+	switch s.cfg.Backend {
+	case kubectl.BackendNative:
+		backend, err := native.NewBackend(s.cfg.Kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to create native backend: %w", err)
+		}
+		kubectlExecutor = kubectl.NewKubectlToolExecutorWithBackend(backend, true)
+		watcher = backend
+	case kubectl.BackendSimulated:
+		// The simulated cluster answers everything itself and never runs kubectl
+		backend, err := native.NewSimulatedBackend(s.cfg.SimulatedData)
+		if err != nil {
+			return fmt.Errorf("failed to create simulated backend: %w", err)
+		}
+		kubectlExecutor = kubectl.NewKubectlToolExecutorWithBackend(backend, false)
+		watcher, fallback = backend, false
+	}
+
+	// Users resolve ambiguous calls when their client supports elicitation
+	kubectlExecutor.SetElicitor(s.elicit)
+	// helm and cilium commands may change the objects kubectl results were cached for
+	s.cfg.ClusterChanged = kubectlExecutor.InvalidateCache
 
-	// Register each kubectl tool
+	// Register each kubectl tool, large outputs are summarized if enabled
+	summaries := s.registerSummaries()
 	for _, tool := range kubectlTools {
 		// Create a handler that injects the tool name into params
-		handler := tools.CreateToolHandlerWithName(kubectlExecutor, s.cfg, tool.Name)
-		s.mcpServer.AddTool(tool, handler)
+		handler := tools.Chain(tools.NewHandler(kubectlExecutor, s.cfg), tools.InjectToolName(tool.Name))
+		if summaries != nil {
+			handler = summaries(handler)
+		}
+		s.addTool(tool, handler)
+	}
+
+	// Expose cluster objects as resources, read with the same executor
+	provider := resources.NewProvider(kubectlExecutor, s.cfg)
+	s.mcpServer.AddResources(provider.Resources()...)
+	s.mcpServer.AddResourceTemplates(provider.Templates()...)
+	s.registerSubscriptions(watcher, fallback)
+	s.registerCompletions(kubectlExecutor)
+
+	return nil
+}
+
+// registerPrompts registers the built-in prompts and the prompts of the prompts directory, referencing the
+// tools registered for the access level
+func (s *Service) registerPrompts() error {
+	definitions, err := prompts.Builtin()
+	if err != nil {
+		return fmt.Errorf("failed to load built-in prompts: %w", err)
+	}
+	if s.cfg.PromptsDir != "" {
+		custom, err := prompts.Load(s.cfg.PromptsDir)
+		if err != nil {
+			return err
+		}
+		definitions = prompts.Merge(definitions, custom)
+	}
+
+	library := prompts.NewLibrary(definitions, s.cfg.AccessLevel, func(toolName, operation, resource string) (string, bool) {
+		if strings.HasPrefix(toolName, "kubectl_") {
+			return kubectl.ToolForOperation(s.cfg.AccessLevel, s.cfg.SplitTools, toolName, operation, resource)
+		}
+		// Operations of other tools are not checked, only their registration
+		for _, name := range []string{toolName, tools.WriteVariantName(toolName)} {
+			if s.toolNames[name] {
+				return name, true
+			}
+		}
+		return "", false
+	})
+	s.mcpServer.AddPrompts(library.Prompts()...)
+	return nil
+}
+
+// addTool registers a tool with its handler wrapped in the middleware chain. Tools supporting jobs get the
+// background parameter when jobs are enabled.
+func (s *Service) addTool(tool mcp.Tool, handler tools.Handler) {
+	if s.jobs != nil && jobs.Supports(tool.Name) {
+		jobs.WithBackground(tool.Name)(&tool)
+		handler = s.jobs(handler)
+	}
+	middlewares := []tools.Middleware{
+		tools.Recovery(),
+		tools.Logging(),
+		tools.Tracing(s.cfg.TelemetryService),
+		tools.Metrics(s.cfg.TelemetryService),
 	}
+	middlewares = append(middlewares, s.middlewares...)
+	middlewares = append(middlewares, tools.ValidateArguments(tool))
+	s.mcpServer.AddTool(tool, tools.Chain(handler, middlewares...))
+	s.toolNames[tool.Name] = true
 }
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestAddToolLogging(t *testing.T) {
+	var logs bytes.Buffer
+	defer logging.SetOutput(os.Stderr, slog.LevelInfo)
+	logging.SetOutput(&logs, slog.LevelInfo)
+
+	s := &Service{cfg: &config.ConfigData{}, mcpServer: server.NewMCPServer("test", "1.0"), toolNames: make(map[string]bool)}
+	tool := mcp.NewTool("kubectl_resources", mcp.WithString("operation"))
+	s.addTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	s.mcpServer.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"kubectl_resources","arguments":{"operation":"get"}}}`))
+	if line := logs.String(); !strings.Contains(line, "tool call completed") || !strings.Contains(line, "tool=kubectl_resources operation=get") {
+		t.Errorf("tool calls must be logged, got %q", line)
+	}
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// httpSessionIdleTimeout is how long a streamable HTTP session without an open event stream may stay idle
+// before it's ended
+const httpSessionIdleTimeout = 30 * time.Minute
+
+// httpSessionIDPrefix starts the IDs of streamable HTTP sessions
+const httpSessionIDPrefix = "mcp-session-"
+
+// errUnknownSession is returned when validating a session ID the server didn't issue
+var errUnknownSession = errors.New("unknown session id")
+
+// httpSessions issues and tracks the session IDs of the streamable HTTP transport. It's the session ID
+// manager of the MCP server, so the MCP server and the extensions accept the same sessions. Sessions end when
+// they are deleted, or when they stay idle without an open event stream for longer than idleTimeout.
+type httpSessions struct {
+	idleTimeout time.Duration
+	// closed is called when a session ends
+	closed func(sessionID string)
+	// now returns the current time
+	now func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// httpSession is the activity of a streamable HTTP session
+type httpSession struct {
+	lastSeen time.Time
+	// streams is the number of open event streams of the session
+	streams int
+}
+
+// newHTTPSessions creates a session ID manager without sessions
+func newHTTPSessions(idleTimeout time.Duration, closed func(sessionID string)) *httpSessions {
+	return &httpSessions{
+		idleTimeout: idleTimeout,
+		closed:      closed,
+		now:         time.Now,
+		sessions:    make(map[string]*httpSession),
+	}
+}
+
+// Generate implements server.SessionIdManager, it issues the ID of a new session
+func (s *httpSessions) Generate() string {
+	sessionID := httpSessionIDPrefix + uuid.New().String()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[sessionID] = &httpSession{lastSeen: s.now()}
+	return sessionID
+}
+
+// Validate implements server.SessionIdManager. IDs of sessions that ended or were never issued are reported
+// as terminated, so clients start a new session.
+func (s *httpSessions) Validate(sessionID string) (isTerminated bool, err error) {
+	if sessionID == "" {
+		return false, errUnknownSession
+	}
+	return !s.touch(sessionID), nil
+}
+
+// Terminate implements server.SessionIdManager, it ends a session deleted by its client
+func (s *httpSessions) Terminate(sessionID string) (isNotAllowed bool, err error) {
+	s.mu.Lock()
+	_, ok := s.sessions[sessionID]
+	delete(s.sessions, sessionID)
+	s.mu.Unlock()
+	if ok {
+		s.closed(sessionID)
+	}
+	return false, nil
+}
+
+// touch records activity of a session, it reports whether the session exists
+func (s *httpSessions) touch(sessionID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[sessionID]
+	if ok {
+		session.lastSeen = s.now()
+	}
+	return ok
+}
+
+// streamOpened records that a session opened an event stream, it reports whether the session exists
+func (s *httpSessions) streamOpened(sessionID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[sessionID]
+	if ok {
+		session.streams++
+		session.lastSeen = s.now()
+	}
+	return ok
+}
+
+// streamClosed records that an event stream of a session was closed
+func (s *httpSessions) streamClosed(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session, ok := s.sessions[sessionID]; ok {
+		session.streams--
+		session.lastSeen = s.now()
+	}
+}
+
+// expire ends the sessions that were idle for longer than the idle timeout
+func (s *httpSessions) expire() {
+	deadline := s.now().Add(-s.idleTimeout)
+	var expired []string
+	s.mu.Lock()
+	for sessionID, session := range s.sessions {
+		if session.streams == 0 && session.lastSeen.Before(deadline) {
+			expired = append(expired, sessionID)
+			delete(s.sessions, sessionID)
+		}
+	}
+	s.mu.Unlock()
+	for _, sessionID := range expired {
+		s.closed(sessionID)
+	}
+}
+
+// run expires idle sessions periodically until ctx is cancelled
+func (s *httpSessions) run(ctx context.Context) {
+	ticker := time.NewTicker(s.idleTimeout / 10)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expire()
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHTTPSessions(t *testing.T) {
+	var closed []string
+	sessions := newHTTPSessions(time.Minute, func(sessionID string) { closed = append(closed, sessionID) })
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	sessions.now = func() time.Time { return now }
+
+	idle := sessions.Generate()
+	streaming := sessions.Generate()
+	active := sessions.Generate()
+	if idle == streaming {
+		t.Fatalf("Generate() returned %q twice", idle)
+	}
+	if terminated, err := sessions.Validate(idle); terminated || err != nil {
+		t.Errorf("Validate(issued) = %v, %v, want valid", terminated, err)
+	}
+	if terminated, err := sessions.Validate("made-up"); !terminated || err != nil {
+		t.Errorf("Validate(made-up) = %v, %v, want terminated", terminated, err)
+	}
+	if _, err := sessions.Validate(""); err == nil {
+		t.Error("Expected error validating an empty session ID")
+	}
+	if sessions.streamOpened("made-up") {
+		t.Error("Expected stream of made-up session to be refused")
+	}
+
+	// Sessions with an open stream or recent requests survive the idle timeout
+	sessions.streamOpened(streaming)
+	now = now.Add(50 * time.Second)
+	sessions.touch(active)
+	now = now.Add(20 * time.Second)
+	sessions.expire()
+	if len(closed) != 1 || closed[0] != idle {
+		t.Fatalf("closed sessions = %v, want %s", closed, idle)
+	}
+	if terminated, _ := sessions.Validate(idle); !terminated {
+		t.Error("Expected expired session to be terminated")
+	}
+
+	sessions.streamClosed(streaming)
+	now = now.Add(2 * time.Minute)
+	sessions.expire()
+	if len(closed) != 3 {
+		t.Errorf("closed sessions = %v, want all", closed)
+	}
+
+	// Terminating an unknown session doesn't close anything
+	if notAllowed, err := sessions.Terminate("made-up"); notAllowed || err != nil || len(closed) != 3 {
+		t.Errorf("Terminate(made-up) = %v, %v, closed %v", notAllowed, err, closed)
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Azure/mcp-kubernetes/pkg/resources"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Methods of resource subscriptions, which the MCP server doesn't implement
+const (
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+)
+
+// registerSubscriptions answers resource subscriptions by watching the subscribed objects. Watches of the
+// current context use the backend watcher if there is one, other watches run kubectl unless fallback is disabled.
+func (s *Service) registerSubscriptions(backend resources.ObjectWatcher, fallback bool) {
+	subscriptions := resources.NewSubscriptions(resources.NewWatcher(backend, fallback, s.cfg), s.cfg, s.notifyResourceUpdated)
+
+	s.extensions.handle(methodResourcesSubscribe, func(ctx context.Context, sessionID string, params json.RawMessage) (any, error) {
+		var p mcp.SubscribeParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "invalid subscribe parameters: %v", err)
+		}
+		if err := subscriptions.Subscribe(sessionID, p.URI); err != nil {
+			return nil, err
+		}
+		return mcp.EmptyResult{}, nil
+	})
+	s.extensions.handle(methodResourcesUnsubscribe, func(ctx context.Context, sessionID string, params json.RawMessage) (any, error) {
+		var p mcp.UnsubscribeParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, toolerror.Newf(toolerror.InvalidArguments, "invalid unsubscribe parameters: %v", err)
+		}
+		subscriptions.Unsubscribe(sessionID, p.URI)
+		return mcp.EmptyResult{}, nil
+	})
+	s.extensions.onSessionClosed(subscriptions.CloseSession)
+}
+
+// notifyResourceUpdated tells a session that a subscribed resource changed
+func (s *Service) notifyResourceUpdated(sessionID, uri string) error {
+	return s.mcpServer.SendNotificationToSpecificClient(sessionID, string(mcp.MethodNotificationResourceUpdated), map[string]any{"uri": uri})
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/summarize"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// methodSamplingCreateMessage requests a message from the model of a client
+const methodSamplingCreateMessage = "sampling/createMessage"
+
+// registerSummaries registers the raw_output tool and returns the middleware summarizing the large outputs of
+// kubectl tools, nil if summaries are disabled
+func (s *Service) registerSummaries() tools.Middleware {
+	if !s.cfg.SummarizeOutputs {
+		return nil
+	}
+	store := summarize.NewStore()
+	s.extensions.onSessionClosed(store.CloseSession)
+	s.addTool(summarize.RegisterRawOutput(), tools.NewHandler(summarize.NewRawOutputExecutor(store), s.cfg))
+	return summarize.NewSummarizer(s.cfg.SummarizeThreshold, s.sample, store).Middleware()
+}
+
+// sample requests a message from the model of the client of a session. The request is sent on the stream of
+// the session, since the MCP server can't send requests while it handles a tool call of a streamable HTTP
+// session.
+func (s *Service) sample(ctx context.Context, sessionID string, params mcp.CreateMessageParams) (summarize.Sample, error) {
+	if !s.extensions.clientCapability(sessionID, "sampling") || !s.extensions.clients.connected(sessionID) {
+		return summarize.Sample{}, summarize.ErrSamplingUnavailable
+	}
+	var result struct {
+		Content struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Model string `json:"model"`
+	}
+	if err := s.extensions.clients.request(ctx, sessionID, methodSamplingCreateMessage, params, &result); err != nil {
+		return summarize.Sample{}, err
+	}
+	if result.Content.Type != "text" {
+		return summarize.Sample{}, fmt.Errorf("the client returned %q content instead of text", result.Content.Type)
+	}
+	return summarize.Sample{Text: result.Content.Text, Model: result.Model}, nil
+}
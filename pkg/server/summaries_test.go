@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/summarize"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSample(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities string
+		response     string
+		want         summarize.Sample
+		wantErr      string
+	}{
+		{
+			name:         "text summary",
+			capabilities: `{"sampling":{}}`,
+			response:     `{"role":"assistant","content":{"type":"text","text":"2 errors"},"model":"test-model"}`,
+			want:         summarize.Sample{Text: "2 errors", Model: "test-model"},
+		},
+		{
+			name:         "image content",
+			capabilities: `{"sampling":{}}`,
+			response:     `{"role":"assistant","content":{"type":"image","data":"","mimeType":"image/png"},"model":"test-model"}`,
+			wantErr:      `returned "image" content`,
+		},
+		{
+			name:         "client without sampling",
+			capabilities: `{"roots":{}}`,
+			wantErr:      summarize.ErrSamplingUnavailable.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{cfg: config.NewConfig(), extensions: newExtensions()}
+			s.extensions.recordClientCapabilities("s1", []byte(`{"params":{"capabilities":`+tt.capabilities+`}}`))
+			var requests []string
+			s.extensions.clients.attach("s1", func(message []byte) error {
+				requests = append(requests, string(message))
+				var msg rpcMessage
+				_ = json.Unmarshal(message, &msg)
+				go s.extensions.clients.resolve(rpcMessage{ID: msg.ID, Result: json.RawMessage(tt.response)})
+				return nil
+			})
+
+			got, err := s.sample(context.Background(), "s1", mcp.CreateMessageParams{MaxTokens: 10})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("sample() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil || got != tt.want {
+				t.Errorf("sample() = %+v, %v, want %+v", got, err, tt.want)
+			}
+			if errors.Is(err, summarize.ErrSamplingUnavailable) {
+				if len(requests) != 0 {
+					t.Errorf("sent requests to a client without sampling: %v", requests)
+				}
+			} else if len(requests) != 1 || !strings.Contains(requests[0], `"method":"sampling/createMessage"`) {
+				t.Errorf("Unexpected requests %v", requests)
+			}
+		})
+	}
+}
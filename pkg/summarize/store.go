@@ -0,0 +1,100 @@
+package summarize
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+)
+
+// Retention limits of raw outputs, the oldest outputs are dropped first
+const (
+	// MaxOutputs is the maximum number of raw outputs kept
+	MaxOutputs = 32
+	// MaxStoredBytes is the maximum total size of the raw outputs kept
+	MaxStoredBytes = 64 << 20
+	// OutputTTL is how long a raw output is kept
+	OutputTTL = 30 * time.Minute
+)
+
+// Store keeps the raw outputs of summarized tool calls for the sessions that made them
+type Store struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	nextID  int
+	outputs map[string]storedOutput
+	// handles holds the handles of the outputs, oldest first
+	handles []string
+	size    int
+}
+
+// storedOutput is a raw output and the session allowed to read it
+type storedOutput struct {
+	sessionID string
+	text      string
+	expires   time.Time
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{now: time.Now, outputs: make(map[string]storedOutput)}
+}
+
+// Put keeps the raw output of a session and returns its handle
+func (s *Store) Put(sessionID, text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+
+	s.nextID++
+	handle := fmt.Sprintf("output-%d", s.nextID)
+	s.outputs[handle] = storedOutput{sessionID: sessionID, text: text, expires: s.now().Add(OutputTTL)}
+	s.handles = append(s.handles, handle)
+	s.size += len(text)
+	// The new output is kept even if it exceeds the size limit on its own
+	for len(s.handles) > 1 && (len(s.handles) > MaxOutputs || s.size > MaxStoredBytes) {
+		s.remove(0)
+	}
+	return handle
+}
+
+// Get returns a raw output of a session. Outputs of other sessions are reported as not found.
+func (s *Store) Get(sessionID, handle string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+
+	output, ok := s.outputs[handle]
+	if !ok || output.sessionID != sessionID {
+		return "", toolerror.Newf(toolerror.NotFound, "raw output %s doesn't exist or has expired", handle)
+	}
+	return output.text, nil
+}
+
+// CloseSession drops the raw outputs of a session
+func (s *Store) CloseSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.handles) - 1; i >= 0; i-- {
+		if s.outputs[s.handles[i]].sessionID == sessionID {
+			s.remove(i)
+		}
+	}
+}
+
+// expire drops the outputs past their TTL
+func (s *Store) expire() {
+	now := s.now()
+	for len(s.handles) > 0 && !now.Before(s.outputs[s.handles[0]].expires) {
+		s.remove(0)
+	}
+}
+
+// remove drops the output at an index of handles
+func (s *Store) remove(i int) {
+	handle := s.handles[i]
+	s.size -= len(s.outputs[handle].text)
+	delete(s.outputs, handle)
+	s.handles = append(s.handles[:i], s.handles[i+1:]...)
+}
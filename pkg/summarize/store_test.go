@@ -0,0 +1,78 @@
+package summarize
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Now()
+	store := NewStore()
+	store.now = func() time.Time { return now }
+
+	first := store.Put("s1", "first")
+	second := store.Put("s2", "second")
+	if first == second {
+		t.Fatalf("Put() returned handle %s twice", first)
+	}
+
+	tests := []struct {
+		name      string
+		sessionID string
+		handle    string
+		want      string
+	}{
+		{name: "own output", sessionID: "s1", handle: first, want: "first"},
+		{name: "output of another session", sessionID: "s1", handle: second},
+		{name: "unknown handle", sessionID: "s1", handle: "output-99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Get(tt.sessionID, tt.handle)
+			if tt.want == "" {
+				if toolerror.CodeOf(err) != toolerror.NotFound {
+					t.Errorf("Get() error = %v, want NotFound", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Get() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	store.CloseSession("s2")
+	if _, err := store.Get("s2", second); err == nil {
+		t.Error("Get() found an output of a closed session")
+	}
+	now = now.Add(OutputTTL)
+	if _, err := store.Get("s1", first); err == nil {
+		t.Error("Get() found an expired output")
+	}
+}
+
+func TestStoreLimits(t *testing.T) {
+	store := NewStore()
+	var handles []string
+	for i := 0; i < MaxOutputs+2; i++ {
+		handles = append(handles, store.Put("s1", "output"))
+	}
+	if _, err := store.Get("s1", handles[1]); err == nil {
+		t.Error("Get() found an output beyond MaxOutputs")
+	}
+	if _, err := store.Get("s1", handles[2]); err != nil {
+		t.Errorf("Get() unexpected error = %v", err)
+	}
+
+	// Large outputs evict older ones, but are kept on their own
+	large := store.Put("s1", strings.Repeat("x", MaxStoredBytes))
+	if _, err := store.Get("s1", handles[len(handles)-1]); err == nil {
+		t.Error("Get() found an output beyond MaxStoredBytes")
+	}
+	if _, err := store.Get("s1", large); err != nil {
+		t.Errorf("Get() unexpected error = %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+// Package summarize shortens large describe and log outputs of tool calls. The client's model summarizes
+// them through sampling when the client supports it, otherwise they are truncated deterministically. Raw
+// outputs are kept for the session that made the call, so the model can read them when the summary isn't
+// enough.
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultThreshold is the size in bytes above which outputs are summarized
+const DefaultThreshold = 16 * 1024
+
+// Limits of sampling requests
+const (
+	// MaxSampledBytes is the maximum size of the output sent to the client's model, larger outputs are
+	// truncated first
+	MaxSampledBytes = 64 * 1024
+	// MaxSummaryTokens is the maximum length of a summary
+	MaxSummaryTokens = 1024
+	// SamplingTimeout is how long the server waits for a summary, including the user's approval
+	SamplingTimeout = 2 * time.Minute
+)
+
+// maxErrorLines is the maximum number of distinct error lines listed by truncated outputs
+const maxErrorLines = 20
+
+// Methods of shortening outputs, reported in the _meta of results
+const (
+	MethodSampling   = "sampling"
+	MethodTruncation = "truncation"
+)
+
+// ErrSamplingUnavailable is returned by samplers for clients that don't support sampling
+var ErrSamplingUnavailable = errors.New("the client doesn't support sampling")
+
+// Sample is a message written by the client's model
+type Sample struct {
+	Text string
+	// Model is the name of the model that wrote the message
+	Model string
+}
+
+// Sampler requests a message from the model of the client of a session with sampling/createMessage
+type Sampler func(ctx context.Context, sessionID string, params mcp.CreateMessageParams) (Sample, error)
+
+var (
+	// timestampPattern matches the timestamp prefix of log lines, which is ignored when counting repeated lines
+	timestampPattern = regexp.MustCompile(`^\[?\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?\]?\s*`)
+	// errorPattern matches lines reporting errors
+	errorPattern = regexp.MustCompile(`(?i)\b(error|err|exception|panic|fatal|failed|failure|crash\w*|back-off|oomkilled|unhealthy)\b`)
+)
+
+// task describes how the output of a kind of tool call is summarized
+type task struct {
+	systemPrompt string
+	// headShare is the share of truncated outputs taken from their beginning, the rest is taken from their end
+	headShare float64
+}
+
+var (
+	describeTask = task{
+		systemPrompt: "You summarize the output of kubectl describe for an agent troubleshooting a Kubernetes cluster. " +
+			"Start with problems: failing conditions, waiting or terminated containers, restarts, warning events and " +
+			"misconfigurations, quoting their messages verbatim. Then give the key facts, such as status, images, " +
+			"resource requests and limits, node and owner, and count repeated events instead of listing them. " +
+			"Be concise and don't add anything that isn't in the output.",
+		headShare: 0.5,
+	}
+	logsTask = task{
+		systemPrompt: "You summarize container logs for an agent troubleshooting a Kubernetes cluster. " +
+			"List errors, exceptions and panics first, quoting the first occurrence of each verbatim with its timestamp, " +
+			"and say how often each one repeats. Then summarize warnings and the remaining activity, giving counts of " +
+			"repeated lines instead of repeating them, and say when the logs start and end. " +
+			"Be concise and don't add anything that isn't in the logs.",
+		headShare: 0.25,
+	}
+)
+
+// taskOf returns the task of a tool call, false if its output isn't summarized
+func taskOf(req mcp.CallToolRequest) (task, bool) {
+	args, _ := req.Params.Arguments.(map[string]interface{})
+	operation, _ := args["operation"].(string)
+	switch toolName, _ := tools.BaseToolName(req.Params.Name); {
+	case toolName == "kubectl_resources" && operation == "describe":
+		return describeTask, true
+	case toolName == "kubectl_diagnostics" && operation == "logs":
+		return logsTask, true
+	}
+	return task{}, false
+}
+
+// Summarizer shortens the outputs of tool calls above a threshold
+type Summarizer struct {
+	threshold int
+	sample    Sampler
+	store     *Store
+}
+
+// NewSummarizer creates a summarizer of outputs larger than threshold bytes. A nil sampler always truncates.
+func NewSummarizer(threshold int, sample Sampler, store *Store) *Summarizer {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	return &Summarizer{threshold: threshold, sample: sample, store: store}
+}
+
+// Middleware shortens the large describe and log outputs of the wrapped handler
+func (s *Summarizer) Middleware() tools.Middleware {
+	return func(next tools.Handler) tools.Handler {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, req)
+			task, ok := taskOf(req)
+			if err != nil || !ok || result == nil || result.IsError || len(result.Content) != 1 {
+				return result, err
+			}
+			content, ok := result.Content[0].(mcp.TextContent)
+			if !ok || len(content.Text) <= s.threshold {
+				return result, nil
+			}
+			var sessionID string
+			if session := server.ClientSessionFromContext(ctx); session != nil {
+				sessionID = session.SessionID()
+			}
+			return s.shorten(ctx, sessionID, req, task, result, content.Text), nil
+		}
+	}
+}
+
+// shorten replaces the output of a result with its summary, or with a truncated output if the client's model
+// can't summarize it
+func (s *Summarizer) shorten(ctx context.Context, sessionID string, req mcp.CallToolRequest, task task, result *mcp.CallToolResult, output string) *mcp.CallToolResult {
+	handle := s.store.Put(sessionID, output)
+	reference := fmt.Sprintf("Read the raw output with the %s tool and handle %q.", RawOutputToolName, handle)
+
+	method := MethodTruncation
+	text := fmt.Sprintf("%s\n\n[Output shortened from %d bytes. %s]", truncate(output, s.threshold, task.headShare), len(output), reference)
+	if sample, err := s.summarize(ctx, sessionID, req, task, output); err == nil {
+		method = MethodSampling
+		text = fmt.Sprintf("%s\n\n[Summary of %d bytes of output written by %s. %s]", sample.Text, len(output), sample.Model, reference)
+	} else {
+		logging.ForSession(sessionID).Debug("truncating output, summarizing it with sampling failed", "tool", req.Params.Name, "error", err)
+	}
+
+	shortened := mcp.NewToolResultText(text)
+	meta := map[string]any{}
+	if result.Meta != nil {
+		for key, value := range result.Meta.AdditionalFields {
+			meta[key] = value
+		}
+	}
+	meta["originalBytes"] = len(output)
+	meta["renderedBytes"] = len(text)
+	meta["rawOutput"] = handle
+	meta["summary"] = method
+	shortened.Meta = mcp.NewMetaFromMap(meta)
+	return shortened
+}
+
+// summarize requests a summary of an output from the client's model
+func (s *Summarizer) summarize(ctx context.Context, sessionID string, req mcp.CallToolRequest, task task, output string) (Sample, error) {
+	if s.sample == nil {
+		return Sample{}, ErrSamplingUnavailable
+	}
+	ctx, cancel := context.WithTimeout(ctx, SamplingTimeout)
+	defer cancel()
+
+	prompt := fmt.Sprintf("Summarize the output of %s (%d bytes, %d lines):\n\n%s",
+		describeCall(req), len(output), strings.Count(strings.TrimRight(output, "\n"), "\n")+1, truncate(output, MaxSampledBytes, task.headShare))
+	sample, err := s.sample(ctx, sessionID, mcp.CreateMessageParams{
+		Messages: []mcp.SamplingMessage{{
+			Role:    mcp.RoleUser,
+			Content: mcp.NewTextContent(prompt),
+		}},
+		SystemPrompt: task.systemPrompt,
+		MaxTokens:    MaxSummaryTokens,
+		ModelPreferences: &mcp.ModelPreferences{
+			SpeedPriority:        0.8,
+			IntelligencePriority: 0.5,
+		},
+	})
+	if err != nil {
+		return Sample{}, err
+	}
+	if strings.TrimSpace(sample.Text) == "" {
+		return Sample{}, errors.New("the client returned an empty summary")
+	}
+	return sample, nil
+}
+
+// describeCall describes a tool call for the summary prompt, like kubectl_resources describe pods web
+func describeCall(req mcp.CallToolRequest) string {
+	args, _ := req.Params.Arguments.(map[string]interface{})
+	parts := []string{req.Params.Name}
+	for _, name := range []string{"operation", "resource", "name", "args", "namespace", "container", "selector"} {
+		if value, ok := args[name].(string); ok && value != "" {
+			if name == "namespace" || name == "container" || name == "selector" {
+				value = name + "=" + value
+			}
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// truncate shortens an output to about limit bytes. Runs of repeated lines are collapsed with their count,
+// distinct error lines are listed first, and the rest of the limit is shared by the beginning and the end
+// of the output.
+func truncate(output string, limit int, headShare float64) string {
+	lines := condense(strings.Split(strings.TrimRight(output, "\n"), "\n"))
+	if size(lines) <= limit {
+		return strings.Join(lines, "\n")
+	}
+
+	var parts []string
+	if errorLines := errorDigest(lines); len(errorLines) > 0 {
+		digest := "Error lines (count, first occurrence):\n" + strings.Join(errorLines, "\n")
+		parts = append(parts, digest)
+		limit -= len(digest) + 2
+	}
+
+	headBudget := int(float64(limit) * headShare)
+	head := 0
+	for used := 0; head < len(lines) && used+len(lines[head])+1 <= headBudget; head++ {
+		used += len(lines[head]) + 1
+	}
+	tail := len(lines)
+	for used := 0; tail > head && used+len(lines[tail-1])+1 <= limit-headBudget; tail-- {
+		used += len(lines[tail-1]) + 1
+	}
+	omitted := lines[head:tail]
+	excerpt := append(append([]string{}, lines[:head]...), fmt.Sprintf("... %d lines (%d bytes) omitted ...", len(omitted), size(omitted)))
+	parts = append(parts, strings.Join(append(excerpt, lines[tail:]...), "\n"))
+	return strings.Join(parts, "\n\n")
+}
+
+// condense collapses runs of lines that only differ in their timestamp into their first line and a count
+func condense(lines []string) []string {
+	var condensed []string
+	for i := 0; i < len(lines); {
+		j := i + 1
+		for j < len(lines) && normalize(lines[j]) == normalize(lines[i]) {
+			j++
+		}
+		line := lines[i]
+		if j-i > 1 {
+			line += fmt.Sprintf(" [repeated %d times]", j-i)
+		}
+		condensed = append(condensed, line)
+		i = j
+	}
+	return condensed
+}
+
+// errorDigest lists the distinct error lines of an output with their counts, most frequent first
+func errorDigest(lines []string) []string {
+	counts := make(map[string]int)
+	first := make(map[string]string)
+	var order []string
+	for _, line := range lines {
+		if !errorPattern.MatchString(line) {
+			continue
+		}
+		line, repeated := splitRepeated(line)
+		key := normalize(line)
+		if _, ok := first[key]; !ok {
+			first[key] = line
+			order = append(order, key)
+		}
+		counts[key] += repeated
+	}
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+
+	var digest []string
+	for i, key := range order {
+		if i == maxErrorLines {
+			digest = append(digest, fmt.Sprintf("... %d more distinct error lines", len(order)-maxErrorLines))
+			break
+		}
+		digest = append(digest, fmt.Sprintf("%dx %s", counts[key], first[key]))
+	}
+	return digest
+}
+
+// splitRepeated splits the count of a condensed line from the line
+func splitRepeated(line string) (string, int) {
+	if i := strings.LastIndex(line, " [repeated "); i >= 0 {
+		var count int
+		if _, err := fmt.Sscanf(line[i:], " [repeated %d times]", &count); err == nil {
+			return line[:i], count
+		}
+	}
+	return line, 1
+}
+
+// normalize strips the timestamp of a log line
+func normalize(line string) string {
+	return timestampPattern.ReplaceAllString(line, "")
+}
+
+// size returns the size of lines joined by newlines
+func size(lines []string) int {
+	total := 0
+	for _, line := range lines {
+		total += len(line) + 1
+	}
+	return total
+}
@@ -0,0 +1,236 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// logOutput returns logs with a repeated error between distinct lines
+func logOutput(lines int) string {
+	var b strings.Builder
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&b, "2025-01-02T10:00:%02d.000Z request %d served\n", i%60, i)
+		if i%10 == 0 {
+			fmt.Fprintf(&b, "2025-01-02T10:00:%02d.000Z ERROR connection refused\n", i%60)
+			fmt.Fprintf(&b, "2025-01-02T10:00:%02d.500Z ERROR connection refused\n", i%60)
+		}
+	}
+	return b.String()
+}
+
+// callTool calls a handler returning output through the summarizer in a session
+func callTool(t *testing.T, summarizer *Summarizer, name string, args map[string]any, output string) *mcp.CallToolResult {
+	t.Helper()
+	handler := summarizer.Middleware()(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(output), nil
+	})
+	ctx := server.NewMCPServer("test", "1.0").WithContext(context.Background(), server.NewInProcessSession("s1", nil))
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	result, err := handler(ctx, req)
+	if err != nil {
+		t.Fatalf("handler() unexpected error = %v", err)
+	}
+	return result
+}
+
+func TestMiddleware(t *testing.T) {
+	logs := logOutput(2000)
+	var prompts []mcp.CreateMessageParams
+	sampler := func(ctx context.Context, sessionID string, params mcp.CreateMessageParams) (Sample, error) {
+		if sessionID != "s1" {
+			t.Errorf("sampled for session %q, want s1", sessionID)
+		}
+		prompts = append(prompts, params)
+		return Sample{Text: "2 errors: connection refused", Model: "test-model"}, nil
+	}
+	declined := func(ctx context.Context, sessionID string, params mcp.CreateMessageParams) (Sample, error) {
+		return Sample{}, errors.New("client failed sampling/createMessage request: user rejected sampling request (code -1)")
+	}
+
+	tests := []struct {
+		name       string
+		sampler    Sampler
+		tool       string
+		args       map[string]any
+		output     string
+		wantMethod string
+		wantText   string
+	}{
+		{
+			name:       "logs summarized with sampling",
+			sampler:    sampler,
+			tool:       "kubectl_diagnostics",
+			args:       map[string]any{"operation": "logs", "name": "web", "namespace": "shop"},
+			output:     logs,
+			wantMethod: MethodSampling,
+			wantText:   "2 errors: connection refused\n\n[Summary of",
+		},
+		{
+			name:       "describe of write variant",
+			sampler:    sampler,
+			tool:       "kubectl_resources_write",
+			args:       map[string]any{"operation": "describe", "resource": "pods"},
+			output:     logs,
+			wantMethod: MethodSampling,
+			wantText:   "written by test-model",
+		},
+		{
+			name:       "declined sampling is truncated",
+			sampler:    declined,
+			tool:       "kubectl_diagnostics",
+			args:       map[string]any{"operation": "logs"},
+			output:     logs,
+			wantMethod: MethodTruncation,
+			wantText:   "Error lines (count, first occurrence):\n400x 2025-01-02T10:00:00.000Z ERROR connection refused",
+		},
+		{
+			name:       "no sampler",
+			tool:       "kubectl_diagnostics",
+			args:       map[string]any{"operation": "logs"},
+			output:     logs,
+			wantMethod: MethodTruncation,
+			wantText:   "lines (",
+		},
+		{
+			name:    "small output",
+			sampler: sampler,
+			tool:    "kubectl_diagnostics",
+			args:    map[string]any{"operation": "logs"},
+			output:  "ready",
+		},
+		{
+			name:    "other operation",
+			sampler: sampler,
+			tool:    "kubectl_resources",
+			args:    map[string]any{"operation": "get"},
+			output:  logs,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore()
+			result := callTool(t, NewSummarizer(1024, tt.sampler, store), tt.tool, tt.args, tt.output)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.wantMethod == "" {
+				if text != tt.output || result.Meta != nil {
+					t.Errorf("output was changed to %q", text)
+				}
+				return
+			}
+
+			meta := result.Meta.AdditionalFields
+			if meta["summary"] != tt.wantMethod || meta["originalBytes"] != len(tt.output) || meta["renderedBytes"] != len(text) {
+				t.Errorf("Unexpected meta %v", meta)
+			}
+			if !strings.Contains(text, tt.wantText) || !strings.Contains(text, fmt.Sprintf("handle %q", meta["rawOutput"])) {
+				t.Errorf("Unexpected text %q", text)
+			}
+			if tt.wantMethod == MethodTruncation && len(text) > 1400 {
+				t.Errorf("truncated text has %d bytes, want about 1024", len(text))
+			}
+			raw, err := store.Get("s1", meta["rawOutput"].(string))
+			if err != nil || raw != tt.output {
+				t.Errorf("raw output was not stored: %v", err)
+			}
+		})
+	}
+
+	// Prompts are task-aware and limited in size
+	if len(prompts) != 2 || !strings.Contains(prompts[0].SystemPrompt, "container logs") || !strings.Contains(prompts[1].SystemPrompt, "kubectl describe") {
+		t.Fatalf("Unexpected sampling requests %+v", prompts)
+	}
+	prompt := prompts[0].Messages[0].Content.(mcp.TextContent).Text
+	if !strings.HasPrefix(prompt, "Summarize the output of kubectl_diagnostics logs web namespace=shop") || len(prompt) > MaxSampledBytes+1024 {
+		t.Errorf("Unexpected prompt of %d bytes: %.200s", len(prompt), prompt)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		limit     int
+		headShare float64
+		want      string
+	}{
+		{
+			name:   "repeated lines are counted",
+			output: "start\n2025-01-02T10:00:00Z retry\n2025-01-02T10:00:01Z retry\n2025-01-02T10:00:02Z retry\nend\n",
+			limit:  100,
+			want:   "start\n2025-01-02T10:00:00Z retry [repeated 3 times]\nend",
+		},
+		{
+			name:      "head and tail",
+			output:    "line 1\nline 2\nline 3\nline 4\nline 5\nline 6\nline 7\nline 8\n",
+			limit:     28,
+			headShare: 0.25,
+			want:      "line 1\n... 4 lines (28 bytes) omitted ...\nline 6\nline 7\nline 8",
+		},
+		{
+			name:      "errors first",
+			output:    "line 1\nError: a\nline 3\nline 4\nError: a\nline 6\nline 7\nline 8\nline 9\nline 10\nline 11\nline 12\n",
+			limit:     70,
+			headShare: 0.5,
+			want:      "Error lines (count, first occurrence):\n2x Error: a\n\nline 1\n... 10 lines (76 bytes) omitted ...\nline 12",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.output, tt.limit, tt.headShare); got != tt.want {
+				t.Errorf("truncate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawOutputExecutor(t *testing.T) {
+	store := NewStore()
+	handle := store.Put("s1", "one\ntwo\nthree\nfour\n")
+	executor := NewRawOutputExecutor(store)
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		want    string
+		wantErr string
+	}{
+		{name: "whole output", params: map[string]interface{}{}, want: "one\ntwo\nthree\nfour"},
+		{name: "page", params: map[string]interface{}{"offset": float64(1), "limit": float64(2)}, want: "two\nthree\n\n[Lines 2-3 of 4. Call again with offset=3 for more.]"},
+		{name: "last page", params: map[string]interface{}{"offset": float64(3)}, want: "four\n\n[Lines 4-4 of 4.]"},
+		{name: "pattern", params: map[string]interface{}{"pattern": "^t"}, want: "two\nthree"},
+		{name: "no match", params: map[string]interface{}{"pattern": "five"}, want: "No lines of the raw output match the pattern"},
+		{name: "invalid pattern", params: map[string]interface{}{"pattern": "("}, wantErr: "invalid pattern"},
+		{name: "other session", params: map[string]interface{}{tools.SessionIDParam: "s2"}, wantErr: "doesn't exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params["handle"] = handle
+			if _, ok := tt.params[tools.SessionIDParam]; !ok {
+				tt.params[tools.SessionIDParam] = "s1"
+			}
+			got, err := executor.Execute(tt.params, config.NewConfig())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Execute() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Execute() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package summarize
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/Azure/mcp-kubernetes/pkg/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RawOutputToolName is the name of the tool reading raw outputs
+const RawOutputToolName = "raw_output"
+
+// DefaultRawOutputLines is the number of lines returned by a raw_output call without limit
+const DefaultRawOutputLines = 500
+
+// RegisterRawOutput registers the tool reading the raw outputs of summarized tool calls
+func RegisterRawOutput() mcp.Tool {
+	return mcp.NewTool(RawOutputToolName,
+		mcp.WithDescription("Read the raw output of a describe or logs call whose result was summarized or truncated. "+
+			"Outputs are kept for a limited time and only for the session that made the call"),
+		tools.WithAnnotations(tools.ReadOnlyAnnotations),
+		mcp.WithString("handle",
+			mcp.Required(),
+			mcp.Description("Handle of the raw output, as given in the summarized result"),
+		),
+		mcp.WithString("pattern",
+			mcp.Description("Optional regular expression, only lines matching it are returned"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of lines to skip, after filtering by pattern"),
+			mcp.Min(0),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of lines to return, default %d", DefaultRawOutputLines)),
+			mcp.Min(1),
+		),
+	)
+}
+
+// RawOutputExecutor reads raw outputs from a store
+type RawOutputExecutor struct {
+	store *Store
+}
+
+// NewRawOutputExecutor creates an executor reading raw outputs from a store
+func NewRawOutputExecutor(store *Store) *RawOutputExecutor {
+	return &RawOutputExecutor{store: store}
+}
+
+// Execute returns a range of the lines of a raw output of the calling session
+func (e *RawOutputExecutor) Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	handle, _ := params["handle"].(string)
+	if handle == "" {
+		return "", toolerror.New(toolerror.InvalidArguments, "handle is required")
+	}
+	offset, err := intParam(params, "offset", 0)
+	if err != nil {
+		return "", err
+	}
+	limit, err := intParam(params, "limit", DefaultRawOutputLines)
+	if err != nil {
+		return "", err
+	}
+	var pattern *regexp.Regexp
+	if expr, _ := params["pattern"].(string); expr != "" {
+		if pattern, err = regexp.Compile(expr); err != nil {
+			return "", toolerror.Newf(toolerror.InvalidArguments, "invalid pattern: %v", err)
+		}
+	}
+
+	output, err := e.store.Get(tools.SessionID(params), handle)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if pattern != nil {
+		var matching []string
+		for _, line := range lines {
+			if pattern.MatchString(line) {
+				matching = append(matching, line)
+			}
+		}
+		lines = matching
+	}
+
+	total := len(lines)
+	if pattern != nil && total == 0 {
+		return "No lines of the raw output match the pattern", nil
+	}
+	if offset > total {
+		offset = total
+	}
+	end := min(offset+limit, total)
+	text := strings.Join(lines[offset:end], "\n")
+	if offset > 0 || end < total {
+		text += fmt.Sprintf("\n\n[Lines %d-%d of %d.", offset+1, end, total)
+		if end < total {
+			text += fmt.Sprintf(" Call again with offset=%d for more.", end)
+		}
+		text += "]"
+	}
+	return text, nil
+}
+
+// intParam returns a non-negative number argument, or a default if it isn't given
+func intParam(params map[string]interface{}, name string, defaultValue int) (int, error) {
+	value, ok := params[name]
+	if !ok || value == nil {
+		return defaultValue, nil
+	}
+	number, ok := value.(float64)
+	if !ok || number < 0 {
+		return 0, toolerror.Newf(toolerror.InvalidArguments, "%s must be a non-negative number", name)
+	}
+	return int(number), nil
+}
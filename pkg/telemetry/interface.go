@@ -17,8 +17,9 @@ type TelemetryInterface interface {
 	// StartActivity starts a new telemetry activity (span)
 	StartActivity(ctx context.Context, activityName string) (context.Context, trace.Span)
 
-	// TrackToolInvocation tracks a tool invocation with minimal data
-	TrackToolInvocation(ctx context.Context, toolName string, operation string, success bool)
+	// TrackToolInvocation tracks a tool invocation with minimal data, errorCode is the code of a failed
+	// invocation's typed error, or empty
+	TrackToolInvocation(ctx context.Context, toolName string, operation string, success bool, errorCode string)
 
 	// TrackServiceStartup tracks the MCP server startup
 	TrackServiceStartup(ctx context.Context)
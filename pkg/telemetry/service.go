@@ -3,9 +3,9 @@ package telemetry
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
 	"github.com/microsoft/ApplicationInsights-Go/appinsights"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -74,7 +74,7 @@ func (s *Service) initializeTracing(ctx context.Context) error {
 		otlptracegrpc.WithInsecure(),
 	)
 	if err != nil {
-		log.Printf("Failed to create OTLP gRPC exporter: %v", err)
+		logging.Logger().Warn("failed to create OTLP gRPC exporter", "error", err)
 	} else {
 		exporters = append(exporters, otlpExporter)
 	}
@@ -100,6 +100,11 @@ func (s *Service) initializeTracing(ctx context.Context) error {
 		propagation.Baggage{},
 	))
 
+	// Failures of exporting spans are logged, so clients see them too
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logging.Logger().Warn("telemetry export failed", "error", err)
+	}))
+
 	s.tracer = otel.Tracer(s.config.ServiceName)
 	return nil
 }
@@ -132,7 +137,7 @@ func (s *Service) StartActivity(ctx context.Context, activityName string) (conte
 }
 
 // TrackToolInvocation tracks a tool invocation with minimal data
-func (s *Service) TrackToolInvocation(ctx context.Context, toolName string, operation string, success bool) {
+func (s *Service) TrackToolInvocation(ctx context.Context, toolName string, operation string, success bool, errorCode string) {
 	if !s.isInitialized {
 		return
 	}
@@ -147,6 +152,9 @@ func (s *Service) TrackToolInvocation(ctx context.Context, toolName string, oper
 			attribute.String("tool.operation", operation),
 			attribute.Bool("tool.success", success),
 		)
+		if errorCode != "" {
+			span.SetAttributes(attribute.String("tool.error_code", errorCode))
+		}
 	}
 
 	// Send to Application Insights as a trace
@@ -155,6 +163,9 @@ func (s *Service) TrackToolInvocation(ctx context.Context, toolName string, oper
 		event.Properties["tool.name"] = toolName
 		event.Properties["tool.operation"] = operation
 		event.Properties["tool.success"] = fmt.Sprintf("%v", success)
+		if errorCode != "" {
+			event.Properties["tool.error_code"] = errorCode
+		}
 		s.appInsightsClient.Track(event)
 	}
 }
@@ -73,7 +73,7 @@ func TestServiceTrackToolInvocationNotInitialized(t *testing.T) {
 	ctx := context.Background()
 
 	// Should not panic or error when not initialized
-	service.TrackToolInvocation(ctx, "kubectl", "get", true)
+	service.TrackToolInvocation(ctx, "kubectl", "get", true, "")
 	service.TrackServiceStartup(ctx)
 }
 
@@ -95,7 +95,7 @@ func TestServiceTrackingAfterInitialization(t *testing.T) {
 	}
 
 	// These should not panic after initialization
-	service.TrackToolInvocation(ctx, "kubectl", "get", true)
+	service.TrackToolInvocation(ctx, "kubectl", "get", true, "")
 	service.TrackServiceStartup(ctx)
 }
 
@@ -177,7 +177,7 @@ func TestServiceDisabledTelemetry(t *testing.T) {
 	}
 
 	// All tracking methods should work without error
-	service.TrackToolInvocation(ctx, "kubectl", "get", true)
+	service.TrackToolInvocation(ctx, "kubectl", "get", true, "")
 	service.TrackServiceStartup(ctx)
 
 	newCtx, span := service.StartActivity(ctx, "test-activity")
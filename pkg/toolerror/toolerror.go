@@ -0,0 +1,152 @@
+// Package toolerror defines the typed errors reported by tools, with a machine-readable code and a
+// remediation hint for clients.
+package toolerror
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Code identifies the kind of a tool error
+type Code string
+
+// Error codes
+const (
+	// PolicyDenied means the server's security configuration doesn't allow the command
+	PolicyDenied Code = "PolicyDenied"
+	// InvalidArguments means the tool call arguments are missing, malformed or inconsistent
+	InvalidArguments Code = "InvalidArguments"
+	// Timeout means the command didn't complete within its timeout
+	Timeout Code = "Timeout"
+	// NotFound means the requested object, resource type or release doesn't exist
+	NotFound Code = "NotFound"
+	// Forbidden means the cluster's RBAC denied the request for the configured credentials
+	Forbidden Code = "Forbidden"
+	// Conflict means the object already exists or was modified concurrently
+	Conflict Code = "Conflict"
+	// Unavailable means the API server can't be reached or is overloaded
+	Unavailable Code = "Unavailable"
+)
+
+// hints are the default remediation hints of each code
+var hints = map[Code]string{
+	PolicyDenied:     "The server's security policy (access level, allowed namespaces, workspace or client roots) doesn't allow this command. Use a permitted operation or namespace, or ask the operator to change the server configuration.",
+	InvalidArguments: "Check the tool's input schema and correct the arguments.",
+	Timeout:          "Retry with a larger timeout_seconds, or narrow the query with a namespace or selector.",
+	NotFound:         "Check the name, namespace and resource type, for example by listing the objects first.",
+	Forbidden:        "The configured credentials lack RBAC permissions for this request. Check them with kubectl_config operation='auth' resource='can-i'.",
+	Conflict:         "The object already exists or was changed concurrently. Fetch the latest version and retry.",
+	Unavailable:      "The API server is unreachable or overloaded. Check the cluster connection and retry later.",
+}
+
+// Error is an error with a code and a remediation hint
+type Error struct {
+	Code    Code
+	Message string
+	// Hint overrides the default hint of the code
+	Hint string
+	Err  error
+}
+
+// Coder is implemented by errors that carry a code, like *Error and security validation errors
+type Coder interface {
+	ErrorCode() Code
+}
+
+// New creates an error with a code
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Newf creates an error with a code and a formatted message, %w wraps an error like fmt.Errorf
+func Newf(code Code, format string, args ...interface{}) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Code: code, Message: err.Error(), Err: errors.Unwrap(err)}
+}
+
+// Wrap adds a code to an error, keeping its message
+func Wrap(code Code, err error) *Error {
+	return &Error{Code: code, Message: err.Error(), Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the code of the error
+func (e *Error) ErrorCode() Code {
+	return e.Code
+}
+
+// CodeOf returns the code of an error, or empty if it has none
+func CodeOf(err error) Code {
+	var coder Coder
+	if errors.As(err, &coder) {
+		return coder.ErrorCode()
+	}
+	return ""
+}
+
+// HintOf returns the remediation hint of an error, or empty if it has no code
+func HintOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) && e.Hint != "" {
+		return e.Hint
+	}
+	return hints[CodeOf(err)]
+}
+
+// outputPattern maps the first line of a command's error output to a code
+type outputPattern struct {
+	pattern *regexp.Regexp
+	code    Code
+}
+
+// kubectlPatterns match the errors of kubectl and of the native kubectl backend
+var kubectlPatterns = []outputPattern{
+	{regexp.MustCompile(`^Error from server \(NotFound\)`), NotFound},
+	{regexp.MustCompile(`^Error from server \((Forbidden|Unauthorized)\)`), Forbidden},
+	{regexp.MustCompile(`^Error from server \((Conflict|AlreadyExists)\)`), Conflict},
+	{regexp.MustCompile(`^Error from server \((ServiceUnavailable|TooManyRequests|InternalError)\)`), Unavailable},
+	{regexp.MustCompile(`^Error from server \((Timeout|ServerTimeout)\)`), Timeout},
+	{regexp.MustCompile(`^error: the server doesn't have a resource type`), NotFound},
+	{regexp.MustCompile(`^error: You must be logged in to the server`), Forbidden},
+	{regexp.MustCompile(`^(The connection to the server .* was refused|Unable to connect to the server)`), Unavailable},
+}
+
+// helmPatterns match the errors of helm, which reports every error as "Error: <message>"
+var helmPatterns = []outputPattern{
+	{regexp.MustCompile(`^Error: .*Kubernetes cluster unreachable`), Unavailable},
+	{regexp.MustCompile(`^Error: .*(is forbidden|Unauthorized)`), Forbidden},
+	{regexp.MustCompile(`^Error: .*(cannot re-use a name that is still in use|already exists|another operation .* is in progress)`), Conflict},
+	{regexp.MustCompile(`^Error: .*(timed out waiting for the condition|context deadline exceeded)`), Timeout},
+	{regexp.MustCompile(`^Error: .*not found`), NotFound},
+}
+
+// FromKubectlOutput classifies the output of a failed kubectl command, which kubectl tools return as their
+// output. It returns nil if the output isn't a known error.
+func FromKubectlOutput(output string) *Error {
+	return fromOutput(kubectlPatterns, output)
+}
+
+// FromHelmOutput classifies the output of a failed helm command. It returns nil if the output isn't a known error.
+func FromHelmOutput(output string) *Error {
+	return fromOutput(helmPatterns, output)
+}
+
+func fromOutput(patterns []outputPattern, output string) *Error {
+	output = strings.TrimSpace(output)
+	line, _, _ := strings.Cut(output, "\n")
+	for _, p := range patterns {
+		if p.pattern.MatchString(line) {
+			return New(p.code, output)
+		}
+	}
+	return nil
+}
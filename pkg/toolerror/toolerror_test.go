@@ -0,0 +1,100 @@
+package toolerror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFromKubectlOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		want   Code
+	}{
+		{"Error from server (NotFound): pods \"web\" not found\n", NotFound},
+		{"Error from server (Forbidden): pods is forbidden: User \"dev\" cannot list resource \"pods\"", Forbidden},
+		{"Error from server (AlreadyExists): deployments.apps \"web\" already exists", Conflict},
+		{"Error from server (Conflict): Operation cannot be fulfilled on deployments.apps \"web\"", Conflict},
+		{"Error from server (ServiceUnavailable): the server is currently unable to handle the request", Unavailable},
+		{"Error from server (Timeout): the server was unable to return a response in the time allotted", Timeout},
+		{"error: the server doesn't have a resource type \"widgets\"", NotFound},
+		{"error: You must be logged in to the server (Unauthorized)", Forbidden},
+		{"The connection to the server localhost:8080 was refused - did you specify the right host or port?", Unavailable},
+		{"Unable to connect to the server: dial tcp: lookup example.com: no such host", Unavailable},
+		{"NAME   READY   STATUS\nweb    1/1     Running", ""},
+		{"Error: file not found", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		err := FromKubectlOutput(tt.output)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("FromKubectlOutput(%q) = %v, want nil", tt.output, err)
+			}
+			continue
+		}
+		if err == nil || err.Code != tt.want {
+			t.Errorf("FromKubectlOutput(%q) = %v, want code %s", tt.output, err, tt.want)
+		}
+	}
+}
+
+func TestFromHelmOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		want   Code
+	}{
+		{"Error: release: not found", NotFound},
+		{"Error: INSTALLATION FAILED: cannot re-use a name that is still in use", Conflict},
+		{"Error: UPGRADE FAILED: another operation (install/upgrade/rollback) is in progress", Conflict},
+		{"Error: Kubernetes cluster unreachable: Get \"https://10.0.0.1/version\": dial tcp: i/o timeout", Unavailable},
+		{"Error: list: failed to list: secrets is forbidden: User \"dev\" cannot list resource \"secrets\"", Forbidden},
+		{"Error: INSTALLATION FAILED: timed out waiting for the condition", Timeout},
+		{"NAME\tNAMESPACE\tREVISION\nweb\tdefault\t1", ""},
+	}
+
+	for _, tt := range tests {
+		err := FromHelmOutput(tt.output)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("FromHelmOutput(%q) = %v, want nil", tt.output, err)
+			}
+			continue
+		}
+		if err == nil || err.Code != tt.want {
+			t.Errorf("FromHelmOutput(%q) = %v, want code %s", tt.output, err, tt.want)
+		}
+	}
+}
+
+func TestCodeAndHint(t *testing.T) {
+	cause := errors.New("unexpected EOF")
+	err := Newf(InvalidArguments, "invalid manifest document %d: %w", 1, cause)
+	if err.Error() != "invalid manifest document 1: unexpected EOF" || !errors.Is(err, cause) {
+		t.Errorf("Newf() = %v, want message wrapping the cause", err)
+	}
+
+	// Codes are found through wrapping errors
+	wrapped := fmt.Errorf("running tool: %w", err)
+	if CodeOf(wrapped) != InvalidArguments || HintOf(wrapped) != hints[InvalidArguments] {
+		t.Errorf("CodeOf() = %q, HintOf() = %q", CodeOf(wrapped), HintOf(wrapped))
+	}
+
+	custom := &Error{Code: NotFound, Message: "release web not found", Hint: "List the releases with helm list."}
+	if HintOf(custom) != "List the releases with helm list." {
+		t.Errorf("HintOf() = %q, want custom hint", HintOf(custom))
+	}
+
+	plain := errors.New("boom")
+	if CodeOf(plain) != "" || HintOf(plain) != "" {
+		t.Errorf("Expected no code or hint for plain errors, got %q, %q", CodeOf(plain), HintOf(plain))
+	}
+
+	// Every code has a default hint
+	for _, code := range []Code{PolicyDenied, InvalidArguments, Timeout, NotFound, Forbidden, Conflict, Unavailable} {
+		if hints[code] == "" {
+			t.Errorf("Missing hint for code %s", code)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WriteVariantSuffix is appended to the name of the write variant of a tool split into read and write variants
+const WriteVariantSuffix = "_write"
+
+// Annotations holds the safety hints of a tool, as reported to clients in the tool annotations
+type Annotations struct {
+	// ReadOnly tools don't modify their environment
+	ReadOnly bool
+	// Destructive tools may delete or overwrite state, only meaningful for tools that aren't read-only
+	Destructive bool
+	// Idempotent tools have no additional effect when called repeatedly with the same arguments
+	Idempotent bool
+	// OpenWorld tools interact with external entities beyond the cluster, such as chart repositories
+	OpenWorld bool
+}
+
+// ReadOnlyAnnotations are the annotations of tools that only query the cluster
+var ReadOnlyAnnotations = Annotations{ReadOnly: true, Idempotent: true}
+
+// WithAnnotations sets the safety hints of a tool definition
+func WithAnnotations(annotations Annotations) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		SetAnnotations(t, annotations)
+	}
+}
+
+// SetAnnotations sets the safety hints of a tool, keeping its title
+func SetAnnotations(t *mcp.Tool, annotations Annotations) {
+	destructive := annotations.Destructive && !annotations.ReadOnly
+	t.Annotations.ReadOnlyHint = mcp.ToBoolPtr(annotations.ReadOnly)
+	t.Annotations.DestructiveHint = mcp.ToBoolPtr(destructive)
+	t.Annotations.IdempotentHint = mcp.ToBoolPtr(annotations.Idempotent || annotations.ReadOnly)
+	t.Annotations.OpenWorldHint = mcp.ToBoolPtr(annotations.OpenWorld)
+}
+
+// WriteVariantName returns the name of the write variant of a tool
+func WriteVariantName(toolName string) string {
+	return toolName + WriteVariantSuffix
+}
+
+// BaseToolName returns the name of the tool a variant was split from, and whether it is the write variant
+func BaseToolName(toolName string) (string, bool) {
+	return strings.CutSuffix(toolName, WriteVariantSuffix)
+}
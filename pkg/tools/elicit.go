@@ -0,0 +1,14 @@
+package tools
+
+import "errors"
+
+var (
+	// ErrElicitationUnavailable is returned by elicitors for clients that don't support elicitation
+	ErrElicitationUnavailable = errors.New("the client doesn't support elicitation")
+	// ErrElicitationDeclined is returned by elicitors when the user declines or cancels the choice
+	ErrElicitationDeclined = errors.New("the user declined to choose")
+)
+
+// Elicitor asks the user of the client of a session to choose one of several values with
+// elicitation/create, and returns the chosen value
+type Elicitor func(sessionID, message string, choices []string) (string, error)
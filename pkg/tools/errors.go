@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Metadata keys of error results
+const (
+	ErrorCodeMetaKey = "errorCode"
+	HintMetaKey      = "hint"
+)
+
+// ErrorResult converts an error to an MCP tool error result. Typed errors get their code and remediation
+// hint appended to the text for the model, and in the result metadata for programmatic clients.
+func ErrorResult(err error) *mcp.CallToolResult {
+	code := toolerror.CodeOf(err)
+	if code == "" {
+		return mcp.NewToolResultError(err.Error())
+	}
+	hint := toolerror.HintOf(err)
+	result := mcp.NewToolResultError(fmt.Sprintf("%s\n\nError code: %s\nHint: %s", err.Error(), code, hint))
+	result.Meta = mcp.NewMetaFromMap(map[string]any{
+		ErrorCodeMetaKey: string(code),
+		HintMetaKey:      hint,
+	})
+	return result
+}
+
+// errorCodeOf returns the error code of a tool result, or empty if it has none
+func errorCodeOf(result *mcp.CallToolResult) string {
+	if result == nil || result.Meta == nil {
+		return ""
+	}
+	code, _ := result.Meta.AdditionalFields[ErrorCodeMetaKey].(string)
+	return code
+}
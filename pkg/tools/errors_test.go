@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestErrorResult(t *testing.T) {
+	result := ErrorResult(toolerror.New(toolerror.NotFound, "Error from server (NotFound): pods \"web\" not found"))
+	text := textOf(t, result)
+	if !result.IsError || !strings.HasPrefix(text, "Error from server (NotFound)") || !strings.Contains(text, "Error code: NotFound\nHint: ") {
+		t.Errorf("Unexpected error result text %q", text)
+	}
+	if result.Meta == nil || result.Meta.AdditionalFields[ErrorCodeMetaKey] != "NotFound" || result.Meta.AdditionalFields[HintMetaKey] == "" {
+		t.Errorf("Expected error code and hint metadata, got %+v", result.Meta)
+	}
+
+	// Errors without a code are returned as they are
+	result = ErrorResult(errors.New("boom"))
+	if textOf(t, result) != "boom" || result.Meta != nil {
+		t.Errorf("Unexpected error result %+v", result)
+	}
+}
+
+func TestErrorCodeTelemetry(t *testing.T) {
+	mockTelemetry := &mockTelemetryService{}
+	handler := Chain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}, Metrics(mockTelemetry), ValidateArguments(mcp.NewTool("helm", mcp.WithString("command", mcp.Required()))))
+
+	_, _ = handler(context.Background(), newRequest("helm", map[string]interface{}{}))
+	want := invocation{toolName: "helm", success: false, errorCode: string(toolerror.InvalidArguments)}
+	if len(mockTelemetry.invocations) != 1 || mockTelemetry.invocations[0] != want {
+		t.Errorf("Expected invocation %+v, got %+v", want, mockTelemetry.invocations)
+	}
+}
@@ -9,3 +9,17 @@ import (
 type CommandExecutor interface {
 	Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error)
 }
+
+// Result is the outcome of a command with optional structured content and metadata
+type Result struct {
+	Text string
+	// Structured is returned as the structured content of the tool result, nil for none
+	Structured interface{}
+	// Meta is returned as the _meta of the tool result, nil for none
+	Meta map[string]interface{}
+}
+
+// StructuredExecutor is implemented by executors that can return structured content and metadata alongside the text result
+type StructuredExecutor interface {
+	ExecuteStructured(params map[string]interface{}, cfg *config.ConfigData) (*Result, error)
+}
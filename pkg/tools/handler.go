@@ -2,65 +2,92 @@ package tools
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 
 	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
+// SessionIDParam passes the session of a tool call to executors, so they log its events for the session
+const SessionIDParam = "_session_id"
+
+// ContextParam passes the context of a tool call to executors, so its commands stop when it is cancelled
+const ContextParam = "_context"
+
 // CreateToolHandler creates an adapter that converts CommandExecutor to the format expected by MCP server
-func CreateToolHandler(executor CommandExecutor, cfg *config.ConfigData) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func CreateToolHandler(executor CommandExecutor, cfg *config.ConfigData) Handler {
+	return Chain(NewHandler(executor, cfg), Metrics(cfg.TelemetryService))
+}
+
+// CreateToolHandlerWithName creates an adapter for tools that need the tool name injected
+func CreateToolHandlerWithName(executor CommandExecutor, cfg *config.ConfigData, toolName string) Handler {
+	return Chain(NewHandler(executor, cfg), InjectToolName(toolName), Metrics(cfg.TelemetryService))
+}
+
+// NewHandler creates a handler running tool calls with an executor, without any middleware. Calls denied by
+// the security policy are logged.
+func NewHandler(executor CommandExecutor, cfg *config.ConfigData) Handler {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := req.Params.Arguments.(map[string]interface{})
 		if !ok {
-			err := fmt.Errorf("arguments must be a map[string]interface{}, got %T", req.Params.Arguments)
-			// Track failed tool invocation
-			if cfg.TelemetryService != nil {
-				cfg.TelemetryService.TrackToolInvocation(ctx, req.Params.Name, "", false)
-			}
-			return mcp.NewToolResultError(err.Error()), nil
+			err := toolerror.Newf(toolerror.InvalidArguments, "arguments must be a map[string]interface{}, got %T", req.Params.Arguments)
+			return ErrorResult(err), nil
 		}
-
-		result, err := executor.Execute(args, cfg)
-		if cfg.TelemetryService != nil {
-			operation, _ := args["operation"].(string)
-			cfg.TelemetryService.TrackToolInvocation(ctx, req.Params.Name, operation, err == nil)
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			args[SessionIDParam] = session.SessionID()
 		}
+		args[ContextParam] = ctx
 
-		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+		result, err := execute(executor, args, cfg)
+		if toolerror.CodeOf(err) == toolerror.PolicyDenied {
+			Logger(args).Warn("tool call denied by policy", "tool", req.Params.Name, "operation", operationOf(req), "error", err)
 		}
-
-		return mcp.NewToolResultText(result), nil
+		return toolResult(result, err), nil
 	}
 }
 
-// CreateToolHandlerWithName creates an adapter for tools that need the tool name injected
-func CreateToolHandlerWithName(executor CommandExecutor, cfg *config.ConfigData, toolName string) func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, ok := req.Params.Arguments.(map[string]interface{})
-		if !ok {
-			err := fmt.Errorf("arguments must be a map[string]interface{}, got %T", req.Params.Arguments)
-			// Track failed tool invocation
-			if cfg.TelemetryService != nil {
-				cfg.TelemetryService.TrackToolInvocation(ctx, req.Params.Name, "", false)
-			}
-			return mcp.NewToolResultError(err.Error()), nil
-		}
+// SessionID returns the session of a tool call, empty if it isn't known
+func SessionID(params map[string]interface{}) string {
+	sessionID, _ := params[SessionIDParam].(string)
+	return sessionID
+}
 
-		// Inject the tool name into the arguments
-		args["_tool_name"] = toolName
+// Context returns the context of a tool call, a context that is never cancelled if it isn't known
+func Context(params map[string]interface{}) context.Context {
+	if ctx, ok := params[ContextParam].(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
 
-		result, err := executor.Execute(args, cfg)
-		if cfg.TelemetryService != nil {
-			operation, _ := args["operation"].(string)
-			cfg.TelemetryService.TrackToolInvocation(ctx, toolName, operation, err == nil)
-		}
+// Logger returns the logger of the session of a tool call
+func Logger(params map[string]interface{}) *slog.Logger {
+	return logging.ForSession(SessionID(params))
+}
 
-		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
-		}
+// execute runs a command, with structured content and metadata if the executor supports them
+func execute(executor CommandExecutor, args map[string]interface{}, cfg *config.ConfigData) (*Result, error) {
+	if structuredExecutor, ok := executor.(StructuredExecutor); ok {
+		return structuredExecutor.ExecuteStructured(args, cfg)
+	}
+	text, err := executor.Execute(args, cfg)
+	return &Result{Text: text}, err
+}
 
-		return mcp.NewToolResultText(result), nil
+// toolResult converts the outcome of a command to an MCP tool result
+func toolResult(result *Result, err error) *mcp.CallToolResult {
+	if err != nil {
+		return ErrorResult(err)
+	}
+	toolResult := mcp.NewToolResultText(result.Text)
+	if result.Structured != nil {
+		toolResult = mcp.NewToolResultStructured(result.Structured, result.Text)
+	}
+	if result.Meta != nil {
+		toolResult.Meta = mcp.NewMetaFromMap(result.Meta)
 	}
+	return toolResult
 }
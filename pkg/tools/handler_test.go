@@ -1,12 +1,19 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log/slog"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/Azure/mcp-kubernetes/pkg/config"
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -32,13 +39,15 @@ type invocation struct {
 	toolName  string
 	operation string
 	success   bool
+	errorCode string
 }
 
-func (m *mockTelemetryService) TrackToolInvocation(ctx context.Context, toolName string, operation string, success bool) {
+func (m *mockTelemetryService) TrackToolInvocation(ctx context.Context, toolName string, operation string, success bool, errorCode string) {
 	m.invocations = append(m.invocations, invocation{
 		toolName:  toolName,
 		operation: operation,
 		success:   success,
+		errorCode: errorCode,
 	})
 }
 
@@ -328,3 +337,91 @@ func TestCreateToolHandlerWithNameError(t *testing.T) {
 		t.Error("Expected success to be false")
 	}
 }
+
+// Mock StructuredExecutor for testing
+type mockStructuredExecutor struct {
+	mockExecutor
+	structured interface{}
+}
+
+func (m *mockStructuredExecutor) ExecuteStructured(args map[string]interface{}, cfg *config.ConfigData) (*Result, error) {
+	text, err := m.Execute(args, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Text: text, Structured: m.structured, Meta: map[string]interface{}{"renderedBytes": len(text)}}, nil
+}
+
+func TestCreateToolHandlerStructured(t *testing.T) {
+	structured := map[string]interface{}{"items": []interface{}{}}
+	executor := &mockStructuredExecutor{
+		mockExecutor: mockExecutor{result: "compact text"},
+		structured:   structured,
+	}
+	cfg := &config.ConfigData{}
+
+	handler := CreateToolHandlerWithName(executor, cfg, "structured-tool")
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{"operation": "get"},
+		},
+	}
+
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.StructuredContent == nil {
+		t.Error("Expected structured content")
+	}
+	if result.Meta == nil || result.Meta.AdditionalFields["renderedBytes"] != len("compact text") {
+		t.Errorf("Expected result metadata, got %+v", result.Meta)
+	}
+	if len(result.Content) != 1 {
+		t.Fatalf("Expected text fallback content, got %d items", len(result.Content))
+	}
+	if text, ok := result.Content[0].(mcp.TextContent); !ok || text.Text != "compact text" {
+		t.Errorf("Expected text fallback 'compact text', got %+v", result.Content[0])
+	}
+
+	// Errors are returned without structured content
+	executor.shouldError = true
+	result, _ = handler(context.Background(), req)
+	if !result.IsError || result.StructuredContent != nil {
+		t.Errorf("Expected error result without structured content, got %+v", result)
+	}
+}
+
+// executorFunc adapts a function to a CommandExecutor
+type executorFunc func(params map[string]interface{}, cfg *config.ConfigData) (string, error)
+
+func (f executorFunc) Execute(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+	return f(params, cfg)
+}
+
+func TestNewHandlerSessionLogging(t *testing.T) {
+	var logs bytes.Buffer
+	defer logging.SetOutput(os.Stderr, slog.LevelInfo)
+	logging.SetOutput(&logs, slog.LevelInfo)
+
+	var sessionID interface{}
+	handler := NewHandler(executorFunc(func(params map[string]interface{}, cfg *config.ConfigData) (string, error) {
+		sessionID = params[SessionIDParam]
+		return "", toolerror.New(toolerror.PolicyDenied, "namespace kube-system is not allowed")
+	}), &config.ConfigData{})
+
+	mcpServer := server.NewMCPServer("test", "1.0")
+	ctx := mcpServer.WithContext(context.Background(), server.NewInProcessSession("s1", nil))
+	result, err := handler(ctx, newRequest("kubectl_resources", map[string]interface{}{"operation": "get"}))
+	if err != nil || !result.IsError {
+		t.Fatalf("Expected error result, got %+v, %v", result, err)
+	}
+	if sessionID != "s1" {
+		t.Errorf("executor session = %v, want s1", sessionID)
+	}
+	for _, want := range []string{`msg="tool call denied by policy"`, "session=s1", "tool=kubectl_resources", "operation=get"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("Expected %q in logs, got %q", want, logs.String())
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/telemetry"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Handler handles a tool call. It is the handler type of the MCP server, so handlers can be registered directly.
+type Handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// Middleware wraps a handler with cross-cutting behavior
+type Middleware func(next Handler) Handler
+
+// Chain wraps a handler with middlewares. The first middleware is the outermost and sees calls first.
+func Chain(handler Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Recovery turns panics in the wrapped handler into tool errors. The panic is logged with its stack.
+func Recovery() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				stack := debug.Stack()
+				// Panics forwarded from another goroutine carry the stack where they happened
+				if p, ok := recovered.(*forwardedPanic); ok {
+					recovered, stack = p.value, p.stack
+				}
+				sessionLogger(ctx).Error("panic in tool", "tool", req.Params.Name, "panic", fmt.Sprint(recovered), "stack", string(stack))
+				result, err = mcp.NewToolResultError(fmt.Sprintf("internal error in tool %s: %v", req.Params.Name, recovered)), nil
+			}()
+			return next(ctx, req)
+		}
+	}
+}
+
+// Logging logs every tool call with its operation, duration and outcome to the logger of its session,
+// which redacts secrets. Arguments are not logged since they may contain manifests or secrets.
+func Logging() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, req)
+			logger := sessionLogger(ctx).With("tool", req.Params.Name, "operation", operationOf(req),
+				"duration", time.Since(start).Round(time.Millisecond))
+			switch {
+			case err != nil:
+				logger.Error("tool call failed", "error", err.Error())
+			case result != nil && result.IsError:
+				logger.Warn("tool call returned an error", "code", errorCodeOf(result))
+			default:
+				logger.Info("tool call completed")
+			}
+			return result, err
+		}
+	}
+}
+
+// Metrics tracks every tool call with the telemetry service. A nil service disables tracking.
+func Metrics(service telemetry.TelemetryInterface) Middleware {
+	return func(next Handler) Handler {
+		if service == nil {
+			return next
+		}
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, req)
+			service.TrackToolInvocation(ctx, req.Params.Name, operationOf(req), succeeded(result, err), errorCodeOf(result))
+			return result, err
+		}
+	}
+}
+
+// Tracing runs every tool call in a span of the telemetry service. A nil service disables tracing.
+func Tracing(service telemetry.TelemetryInterface) Middleware {
+	return func(next Handler) Handler {
+		if service == nil {
+			return next
+		}
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx, span := service.StartActivity(ctx, "tools/call "+req.Params.Name)
+			defer span.End()
+			span.SetAttributes(
+				attribute.String("tool.name", req.Params.Name),
+				attribute.String("tool.operation", operationOf(req)),
+			)
+
+			result, err := next(ctx, req)
+			switch {
+			case err != nil:
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			case result != nil && result.IsError:
+				span.SetStatus(codes.Error, "tool returned an error")
+				if code := errorCodeOf(result); code != "" {
+					span.SetAttributes(attribute.String("tool.error_code", code))
+				}
+			}
+			return result, err
+		}
+	}
+}
+
+// Timeout fails tool calls that don't complete within the timeout and cancels their context. Executors
+// that don't watch the context keep running in the background until their own timeout ends them.
+// A timeout of 0 or less disables the middleware.
+func Timeout(timeout time.Duration) Middleware {
+	return func(next Handler) Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			type outcome struct {
+				result *mcp.CallToolResult
+				err    error
+				panic  *forwardedPanic
+			}
+			done := make(chan outcome, 1)
+			go func() {
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						done <- outcome{panic: &forwardedPanic{value: recovered, stack: debug.Stack()}}
+					}
+				}()
+				result, err := next(ctx, req)
+				done <- outcome{result: result, err: err}
+			}()
+
+			select {
+			case o := <-done:
+				if o.panic != nil {
+					// Panic in the caller's goroutine so that Recovery can handle it
+					panic(o.panic)
+				}
+				return o.result, o.err
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					return ErrorResult(toolerror.Newf(toolerror.Timeout, "tool %s timed out after %s", req.Params.Name, timeout)), nil
+				}
+				return nil, ctx.Err()
+			}
+		}
+	}
+}
+
+// ValidateArguments checks tool call arguments against the input schema of the tool: arguments must be an
+// object, required parameters must be set and values must match the declared type and enum.
+func ValidateArguments(tool mcp.Tool) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if err := validateArguments(tool, req.Params.Arguments); err != nil {
+				return ErrorResult(err), nil
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// InjectToolName sets the tool name of every call to the given name, and passes it to the executor in
+// the _tool_name argument. Executors serving several tools use it to tell them apart.
+func InjectToolName(toolName string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			req.Params.Name = toolName
+			if args, ok := req.Params.Arguments.(map[string]interface{}); ok {
+				args["_tool_name"] = toolName
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// forwardedPanic carries a panic and its stack from the goroutine it happened in
+type forwardedPanic struct {
+	value interface{}
+	stack []byte
+}
+
+// sessionLogger returns the logger of the session of a tool call
+func sessionLogger(ctx context.Context) *slog.Logger {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return logging.ForSession(session.SessionID())
+	}
+	return logging.Logger()
+}
+
+// operationOf returns the operation argument of a tool call, or empty if it has none
+func operationOf(req mcp.CallToolRequest) string {
+	args, _ := req.Params.Arguments.(map[string]interface{})
+	operation, _ := args["operation"].(string)
+	return operation
+}
+
+// succeeded checks if a tool call completed without an error
+func succeeded(result *mcp.CallToolResult, err error) bool {
+	return err == nil && (result == nil || !result.IsError)
+}
@@ -0,0 +1,243 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/mcp-kubernetes/pkg/logging"
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// textOf returns the text content of a tool result
+func textOf(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	if result == nil || len(result.Content) == 0 {
+		t.Fatalf("Expected result content, got %+v", result)
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("Expected text content, got %T", result.Content[0])
+	}
+	return text.Text
+}
+
+// newRequest creates a tool call request
+func newRequest(name string, args interface{}) mcp.CallToolRequest {
+	return mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: args}}
+}
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls = append(calls, name)
+				return next(ctx, req)
+			}
+		}
+	}
+	handler := Chain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls = append(calls, "handler")
+		return mcp.NewToolResultText("ok"), nil
+	}, record("first"), record("second"))
+
+	if _, err := handler(context.Background(), newRequest("tool", nil)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := strings.Join(calls, ","); got != "first,second,handler" {
+		t.Errorf("Expected calls first,second,handler, got %s", got)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	var logs bytes.Buffer
+	defer logging.SetOutput(os.Stderr, slog.LevelInfo)
+	logging.SetOutput(&logs, slog.LevelInfo)
+
+	panicking := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		panic("boom")
+	}
+
+	tests := []struct {
+		name    string
+		handler Handler
+	}{
+		{name: "panic in handler", handler: Chain(panicking, Recovery())},
+		{name: "panic forwarded by timeout", handler: Chain(panicking, Recovery(), Timeout(time.Minute))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			result, err := tt.handler(context.Background(), newRequest("kubectl_resources", nil))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !result.IsError || !strings.Contains(textOf(t, result), "internal error in tool kubectl_resources: boom") {
+				t.Errorf("Expected internal error result, got %+v", result)
+			}
+			// The logged stack points to the panicking handler
+			if !strings.Contains(logs.String(), "goroutine") || !strings.Contains(logs.String(), "TestRecovery") {
+				t.Errorf("Expected panic stack in log, got %q", logs.String())
+			}
+		})
+	}
+}
+
+func TestLogging(t *testing.T) {
+	var logs bytes.Buffer
+	defer logging.SetOutput(os.Stderr, slog.LevelInfo)
+	logging.SetOutput(&logs, slog.LevelInfo)
+
+	handler := Chain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("denied"), nil
+	}, Logging())
+	_, _ = handler(context.Background(), newRequest("kubectl_resources", map[string]interface{}{"operation": "delete", "args": "secret-value"}))
+	line := logs.String()
+	if !strings.Contains(line, "tool=kubectl_resources operation=delete") || !strings.Contains(line, "level=WARN") {
+		t.Errorf("Unexpected log line %q", line)
+	}
+	if strings.Contains(line, "secret-value") {
+		t.Errorf("Arguments must not be logged: %q", line)
+	}
+
+	// Errors are redacted and logged to the session of the call
+	logs.Reset()
+	handler = Chain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("kubectl --token=abc123 failed")
+	}, Logging())
+	ctx := server.NewMCPServer("test", "1.0").WithContext(context.Background(), server.NewInProcessSession("s1", nil))
+	_, _ = handler(ctx, newRequest("kubectl_resources", map[string]interface{}{"operation": "get"}))
+	line = logs.String()
+	if !strings.Contains(line, "session=s1") || !strings.Contains(line, "level=ERROR") {
+		t.Errorf("Unexpected log line %q", line)
+	}
+	if strings.Contains(line, "abc123") {
+		t.Errorf("Secrets in errors must be redacted: %q", line)
+	}
+}
+
+func TestMetricsAndTracing(t *testing.T) {
+	mockTelemetry := &mockTelemetryService{}
+	handler := Chain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("transport failure")
+	}, Tracing(mockTelemetry), Metrics(mockTelemetry))
+
+	if _, err := handler(context.Background(), newRequest("helm", map[string]interface{}{"operation": "list"})); err == nil {
+		t.Error("Expected handler error to be passed on")
+	}
+	if len(mockTelemetry.invocations) != 1 || mockTelemetry.invocations[0] != (invocation{toolName: "helm", operation: "list", success: false}) {
+		t.Errorf("Unexpected invocations %+v", mockTelemetry.invocations)
+	}
+
+	// Without a telemetry service the middlewares do nothing
+	handler = Chain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}, Tracing(nil), Metrics(nil))
+	if result, err := handler(context.Background(), newRequest("helm", nil)); err != nil || textOf(t, result) != "ok" {
+		t.Errorf("Unexpected result %+v, %v", result, err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	handler := Chain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return mcp.NewToolResultText("late"), nil
+	}, Timeout(20*time.Millisecond))
+
+	result, err := handler(context.Background(), newRequest("kubectl_diagnostics", nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.IsError || !strings.Contains(textOf(t, result), "timed out after 20ms") || errorCodeOf(result) != string(toolerror.Timeout) {
+		t.Errorf("Expected timeout error result, got %+v", result)
+	}
+
+	// Calls completing in time return their result
+	handler = Chain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("fast"), nil
+	}, Timeout(time.Minute))
+	if result, err := handler(context.Background(), newRequest("kubectl_diagnostics", nil)); err != nil || textOf(t, result) != "fast" {
+		t.Errorf("Unexpected result %+v, %v", result, err)
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	tool := mcp.NewTool("kubectl_resources",
+		mcp.WithString("operation", mcp.Required(), mcp.Enum("get", "describe")),
+		mcp.WithString("resource", mcp.Required()),
+		mcp.WithString("output", mcp.Enum("text", "json")),
+		mcp.WithBoolean("all_namespaces"),
+		mcp.WithNumber("tail"),
+	)
+
+	tests := []struct {
+		name        string
+		args        interface{}
+		expectError string
+	}{
+		{name: "valid", args: map[string]interface{}{"operation": "get", "resource": "", "tail": float64(10), "all_namespaces": true}},
+		{name: "unknown parameters are left to the executor", args: map[string]interface{}{"operation": "get", "resource": "pods", "_tool_name": "kubectl_resources"}},
+		{name: "empty optional enum", args: map[string]interface{}{"operation": "get", "resource": "pods", "output": ""}},
+		{name: "null optional value", args: map[string]interface{}{"operation": "get", "resource": "pods", "tail": nil}},
+		{name: "not a map", args: "get pods", expectError: "arguments must be a map"},
+		{name: "missing required", args: map[string]interface{}{"operation": "get"}, expectError: "missing required parameter 'resource'"},
+		{name: "missing arguments", args: nil, expectError: "missing required parameter 'operation'"},
+		{name: "wrong type", args: map[string]interface{}{"operation": "get", "resource": "pods", "tail": "10"}, expectError: "parameter 'tail' must be of type number, got string"},
+		{name: "invalid enum", args: map[string]interface{}{"operation": "delete", "resource": "pods"}, expectError: "invalid value 'delete' for parameter 'operation'. Valid values are: get, describe"},
+		{name: "empty required enum", args: map[string]interface{}{"operation": "", "resource": "pods"}, expectError: "invalid value '' for parameter 'operation'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := Chain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				called = true
+				return mcp.NewToolResultText("ok"), nil
+			}, ValidateArguments(tool))
+
+			result, err := handler(context.Background(), newRequest(tool.Name, tt.args))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if tt.expectError == "" {
+				if !called || result.IsError {
+					t.Errorf("Expected call to pass validation, got %+v", result)
+				}
+				return
+			}
+			if called || !result.IsError || !strings.Contains(textOf(t, result), tt.expectError) {
+				t.Errorf("Expected error %q, got %+v", tt.expectError, result)
+			}
+		})
+	}
+}
+
+func TestInjectToolName(t *testing.T) {
+	var got mcp.CallToolRequest
+	handler := Chain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		got = req
+		return mcp.NewToolResultText("ok"), nil
+	}, InjectToolName("kubectl_config"))
+
+	_, _ = handler(context.Background(), newRequest("other", map[string]interface{}{"operation": "diff"}))
+	if got.Params.Name != "kubectl_config" {
+		t.Errorf("Expected tool name kubectl_config, got %s", got.Params.Name)
+	}
+	if args := got.Params.Arguments.(map[string]interface{}); args["_tool_name"] != "kubectl_config" {
+		t.Errorf("Expected _tool_name argument, got %v", args)
+	}
+}
@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TimeoutSecondsParam is the optional per-call timeout argument accepted by all tools
+const TimeoutSecondsParam = "timeout_seconds"
+
+// WithTimeoutSeconds adds the optional per-call timeout parameter to a tool definition
+func WithTimeoutSeconds() mcp.ToolOption {
+	return mcp.WithNumber(TimeoutSecondsParam,
+		mcp.Description("Optional timeout in seconds for this call. Overrides the server's default for the operation, capped by the server maximum"),
+	)
+}
+
+// GetTimeoutSeconds returns the per-call timeout requested in params, or 0 if none was given
+func GetTimeoutSeconds(params map[string]interface{}) (int, error) {
+	value, ok := params[TimeoutSecondsParam]
+	if !ok || value == nil {
+		return 0, nil
+	}
+
+	var seconds int
+	switch v := value.(type) {
+	case float64:
+		seconds = int(v)
+	case int:
+		seconds = v
+	default:
+		return 0, toolerror.Newf(toolerror.InvalidArguments, "%s must be a number, got %T", TimeoutSecondsParam, value)
+	}
+
+	if seconds < 0 {
+		return 0, toolerror.Newf(toolerror.InvalidArguments, "%s must not be negative", TimeoutSecondsParam)
+	}
+
+	return seconds, nil
+}
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/mcp-kubernetes/pkg/toolerror"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// validateArguments checks tool call arguments against the input schema of a tool. Parameters the
+// schema doesn't declare are left to the executor, and null values count as unset.
+func validateArguments(tool mcp.Tool, arguments interface{}) error {
+	args, ok := arguments.(map[string]interface{})
+	if !ok && arguments != nil {
+		return toolerror.Newf(toolerror.InvalidArguments, "arguments must be a map[string]interface{}, got %T", arguments)
+	}
+	// Tools with a raw schema are validated by their executor
+	if tool.RawInputSchema != nil {
+		return nil
+	}
+
+	for _, name := range tool.InputSchema.Required {
+		if args[name] == nil {
+			return toolerror.Newf(toolerror.InvalidArguments, "missing required parameter '%s'", name)
+		}
+	}
+
+	for name, value := range args {
+		property, ok := tool.InputSchema.Properties[name].(map[string]any)
+		if !ok || value == nil {
+			continue
+		}
+		schemaType, _ := property["type"].(string)
+		if !hasType(value, schemaType) {
+			return toolerror.Newf(toolerror.InvalidArguments, "parameter '%s' must be of type %s, got %T", name, schemaType, value)
+		}
+
+		// Optional strings may be empty to select the default
+		enum := enumValues(property["enum"])
+		if s, ok := value.(string); ok && len(enum) > 0 && !contains(enum, s) && (s != "" || isRequired(tool, name)) {
+			return toolerror.Newf(toolerror.InvalidArguments, "invalid value '%s' for parameter '%s'. Valid values are: %s", s, name, strings.Join(enum, ", "))
+		}
+	}
+	return nil
+}
+
+// hasType checks if a decoded JSON value has a JSON schema type, an empty type accepts any value
+func hasType(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		switch value.(type) {
+		case float64, float32, int, int64:
+			return true
+		}
+		return false
+	case "integer":
+		switch v := value.(type) {
+		case float64:
+			return v == float64(int64(v))
+		case int, int64:
+			return true
+		}
+		return false
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	default:
+		return true
+	}
+}
+
+// enumValues returns the allowed values of a schema enum
+func enumValues(enum interface{}) []string {
+	switch v := enum.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// isRequired checks if a tool requires a parameter
+func isRequired(tool mcp.Tool, name string) bool {
+	return contains(tool.InputSchema.Required, name)
+}
+
+// contains checks if a string is in a list
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}